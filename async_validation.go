@@ -0,0 +1,77 @@
+package pbft
+
+import "context"
+
+// AsyncProposalValidator validates the proposals without blocking the state machine (see WithAsyncProposalValidator),
+// for the validations too heavy to run in the state machine loop (i.e. executing a block)
+type AsyncProposalValidator interface {
+	// ValidateAsync starts the validation of the proposal and returns the channel delivering its result.
+	// The context is canceled when the node gives up on the proposal (i.e. round timeout).
+	ValidateAsync(ctx context.Context, proposal *Proposal) <-chan error
+}
+
+// asyncValidation is a proposal validation running in the background
+type asyncValidation struct {
+	proposal *Proposal
+	cancel   context.CancelFunc
+
+	// done is closed once err is set
+	done chan struct{}
+	err  error
+}
+
+// completed returns whether the result of the validation is available
+func (v *asyncValidation) completed() bool {
+	select {
+	case <-v.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// startAsyncValidation starts validating the proposal with the AsyncProposalValidator. The state machine
+// is woken up once the result is available.
+func (p *Pbft) startAsyncValidation(ctx context.Context, proposal *Proposal) {
+	ctx, cancel := context.WithCancel(ctx)
+	v := &asyncValidation{proposal: proposal, cancel: cancel, done: make(chan struct{})}
+	p.validation = v
+
+	go func() {
+		v.err = p.awaitAsyncValidation(ctx, proposal.Copy())
+		close(v.done)
+		select {
+		case p.updateCh <- struct{}{}:
+		default:
+		}
+	}()
+}
+
+// awaitAsyncValidation runs the validation within a slot of the ValidationLimiter, if any, and waits for its result
+func (p *Pbft) awaitAsyncValidation(ctx context.Context, proposal *Proposal) error {
+	if limiter := p.config.ValidationLimiter; limiter != nil {
+		if err := limiter.Acquire(ctx); err != nil {
+			return err
+		}
+		defer limiter.Release()
+	}
+	select {
+	case err := <-p.config.AsyncProposalValidator.ValidateAsync(ctx, proposal):
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// asyncValidationCompleted returns whether the running validation has completed
+func (p *Pbft) asyncValidationCompleted() bool {
+	return p.validation != nil && p.validation.completed()
+}
+
+// stopAsyncValidation cancels the running validation, if any
+func (p *Pbft) stopAsyncValidation() {
+	if p.validation != nil {
+		p.validation.cancel()
+		p.validation = nil
+	}
+}
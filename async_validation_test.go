@@ -0,0 +1,91 @@
+package pbft
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// asyncValidatorFunc delivers the result of the function, run in the background
+type asyncValidatorFunc func(ctx context.Context, proposal *Proposal) error
+
+func (f asyncValidatorFunc) ValidateAsync(ctx context.Context, proposal *Proposal) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		result <- f(ctx, proposal)
+	}()
+	return result
+}
+
+func newAsyncValidationNode(t *testing.T, validator asyncValidatorFunc, timeout time.Duration) *mockPbft {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+	WithAsyncProposalValidator(validator)(m.config)
+	m.state.view = ViewMsg(1, 0)
+	m.state.timeoutChan = time.After(timeout)
+	m.setState(AcceptState)
+	return m
+}
+
+func TestTransition_AcceptState_AsyncValidation(t *testing.T) {
+	validated := make(chan struct{})
+	m := newAsyncValidationNode(t, func(ctx context.Context, proposal *Proposal) error {
+		<-validated
+		return nil
+	}, 5*time.Second)
+
+	m.emitMsg(createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0)))
+	go func() {
+		// the node keeps handling the messages while the proposal is validated
+		time.Sleep(20 * time.Millisecond)
+		m.emitMsg(createMessage("C", MessageReq_Prepare, ViewMsg(1, 0)))
+		time.Sleep(20 * time.Millisecond)
+		close(validated)
+	}()
+
+	m.runCycle(context.Background())
+
+	m.expect(expectResult{
+		sequence: 1,
+		state:    ValidateState,
+		outgoing: 1, // prepare
+	})
+	assert.Equal(t, MessageReq_Prepare, m.respMsg[0].Type)
+	assert.Nil(t, m.validation)
+}
+
+func TestTransition_AcceptState_AsyncValidation_Fails(t *testing.T) {
+	m := newAsyncValidationNode(t, func(context.Context, *Proposal) error {
+		time.Sleep(10 * time.Millisecond)
+		return errors.New("invalid block")
+	}, 5*time.Second)
+	m.emitMsg(createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0)))
+
+	m.runCycle(context.Background())
+
+	assert.Equal(t, RoundChangeState, m.getState())
+	assert.Equal(t, RoundChange_InvalidProposal, m.LastRoundChangeReason())
+	assert.Empty(t, m.respMsg)
+}
+
+func TestTransition_AcceptState_AsyncValidation_Deadline(t *testing.T) {
+	canceled := make(chan struct{})
+	m := newAsyncValidationNode(t, func(ctx context.Context, _ *Proposal) error {
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	}, 20*time.Millisecond)
+	m.emitMsg(createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0)))
+
+	m.runCycle(context.Background())
+
+	assert.Equal(t, RoundChangeState, m.getState())
+	assert.Equal(t, RoundChange_Timeout, m.LastRoundChangeReason())
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("validation not canceled")
+	}
+}
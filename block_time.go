@@ -0,0 +1,31 @@
+package pbft
+
+import (
+	"context"
+	"time"
+)
+
+// minBlockTimeRemaining returns how long the proposer still has to wait before proposing, so that at least
+// MinBlockTime elapses since the previous sequence was finalized. The later rounds of a sequence are not delayed.
+func (p *Pbft) minBlockTimeRemaining() time.Duration {
+	if p.config.MinBlockTime <= 0 || p.lastFinalized.IsZero() || p.state.GetCurrentRound() != 0 {
+		return 0
+	}
+	return p.config.MinBlockTime - p.config.Clock.Now().Sub(p.lastFinalized)
+}
+
+// waitMinBlockTime waits out the remaining MinBlockTime. It returns false if the context is done in the meantime.
+func (p *Pbft) waitMinBlockTime(ctx context.Context) bool {
+	remaining := p.minBlockTimeRemaining()
+	if remaining <= 0 {
+		return true
+	}
+	p.logger.Printf("[DEBUG] waiting %s for the minimum block time", remaining)
+
+	select {
+	case <-time.After(remaining):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
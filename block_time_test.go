@@ -0,0 +1,69 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransition_AcceptState_Proposer_MinBlockTime(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithClock(clock)(m.config)
+	WithMinBlockTime(100 * time.Millisecond)(m.config)
+	m.setProposal(&Proposal{
+		Data: mockProposal,
+		Time: time.Now(),
+	})
+
+	// the previous sequence got finalized 40ms ago
+	m.sequenceFinalized(0)
+	clock.Advance(40 * time.Millisecond)
+	assert.Equal(t, 60*time.Millisecond, m.minBlockTimeRemaining())
+
+	m.setState(AcceptState)
+	start := time.Now()
+	m.runCycle(context.Background())
+
+	assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+	m.expect(expectResult{
+		sequence: 1,
+		outgoing: 2, // preprepare and prepare
+		state:    ValidateState,
+	})
+}
+
+func TestPbft_MinBlockTimeRemaining(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithClock(clock)(m.config)
+
+	// disabled
+	m.sequenceFinalized(0)
+	assert.Zero(t, m.minBlockTimeRemaining())
+
+	WithMinBlockTime(time.Second)(m.config)
+	assert.Equal(t, time.Second, m.minBlockTimeRemaining())
+
+	// the later rounds of the sequence are not delayed
+	m.state.SetCurrentRound(1)
+	assert.Zero(t, m.minBlockTimeRemaining())
+
+	// the interval already elapsed
+	m.state.SetCurrentRound(0)
+	clock.Advance(2 * time.Second)
+	assert.Negative(t, int64(m.minBlockTimeRemaining()))
+}
+
+func TestPbft_WaitMinBlockTime_Cancelled(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithClock(NewManualClock(time.Now()))(m.config)
+	WithMinBlockTime(time.Hour)(m.config)
+	m.sequenceFinalized(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, m.waitMinBlockTime(ctx))
+}
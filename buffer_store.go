@@ -0,0 +1,260 @@
+package pbft
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// BufferKind is a message buffer of the node kept in a BufferStore
+type BufferKind uint8
+
+const (
+	// Buffer_Dedup are the identities of the messages received for the active sequences (see WithSequenceDedup)
+	Buffer_Dedup BufferKind = iota
+
+	// Buffer_Future are the messages buffered for the sequences ahead of the node
+	Buffer_Future
+)
+
+func (b BufferKind) String() string {
+	switch b {
+	case Buffer_Dedup:
+		return "Dedup"
+	case Buffer_Future:
+		return "Future"
+	default:
+		return fmt.Sprintf("BufferKind(%d)", b)
+	}
+}
+
+// BufferStore keeps the sequence dedup and the future messages buffers, so that a restarted node
+// does not process again the messages it already received. The buffers are restored when the node is created.
+type BufferStore interface {
+	// Append records the message in the buffer
+	Append(buffer BufferKind, msg *MessageReq) error
+
+	// Load returns the messages of the buffer in insertion order
+	Load(buffer BufferKind) ([]*MessageReq, error)
+
+	// Trim removes the messages of the sequence and of the previous ones from all the buffers
+	Trim(sequence uint64) error
+}
+
+// MemoryBufferStore is a BufferStore keeping the buffers in memory, they do not survive restarts.
+// Each buffer keeps up to max messages, the oldest one is evicted when the buffer is full.
+type MemoryBufferStore struct {
+	lock    sync.Mutex
+	max     int
+	buffers map[BufferKind][]*MessageReq
+}
+
+// NewMemoryBufferStore creates an in-memory BufferStore keeping up to max messages per buffer
+func NewMemoryBufferStore(max int) *MemoryBufferStore {
+	return &MemoryBufferStore{
+		max:     max,
+		buffers: map[BufferKind][]*MessageReq{},
+	}
+}
+
+// Append implements BufferStore interface
+func (m *MemoryBufferStore) Append(buffer BufferKind, msg *MessageReq) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.max <= 0 {
+		return nil
+	}
+	msgs := m.buffers[buffer]
+	if len(msgs) >= m.max {
+		msgs[0] = nil
+		msgs = msgs[1:]
+	}
+	m.buffers[buffer] = append(msgs, msg)
+	return nil
+}
+
+// Load implements BufferStore interface
+func (m *MemoryBufferStore) Load(buffer BufferKind) ([]*MessageReq, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return append([]*MessageReq{}, m.buffers[buffer]...), nil
+}
+
+// Trim implements BufferStore interface
+func (m *MemoryBufferStore) Trim(sequence uint64) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for buffer, msgs := range m.buffers {
+		m.buffers[buffer] = trimBuffer(msgs, sequence)
+	}
+	return nil
+}
+
+// trimBuffer returns the messages of the sequences after the given one
+func trimBuffer(msgs []*MessageReq, sequence uint64) []*MessageReq {
+	remaining := []*MessageReq{}
+	for _, msg := range msgs {
+		if msg.View != nil && msg.View.Sequence > sequence {
+			remaining = append(remaining, msg)
+		}
+	}
+	return remaining
+}
+
+// FileBufferStore is a BufferStore keeping a file per buffer in a directory, with a JSON encoded message per line
+type FileBufferStore struct {
+	lock sync.Mutex
+	dir  string
+}
+
+// NewFileBufferStore creates a file backed BufferStore in the given directory
+func NewFileBufferStore(dir string) (*FileBufferStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileBufferStore{dir: dir}, nil
+}
+
+func (f *FileBufferStore) path(buffer BufferKind) string {
+	return filepath.Join(f.dir, fmt.Sprintf("buffer-%d.jsonl", buffer))
+}
+
+// Append implements BufferStore interface
+func (f *FileBufferStore) Append(buffer BufferKind, msg *MessageReq) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	file, err := os.OpenFile(f.path(buffer), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Load implements BufferStore interface
+func (f *FileBufferStore) Load(buffer BufferKind) ([]*MessageReq, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.load(buffer)
+}
+
+func (f *FileBufferStore) load(buffer BufferKind) ([]*MessageReq, error) {
+	data, err := os.ReadFile(f.path(buffer))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := []*MessageReq{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, maxWireProposalSize)
+	for scanner.Scan() {
+		msg := &MessageReq{}
+		if err := json.Unmarshal(scanner.Bytes(), msg); err != nil {
+			// a crash while appending leaves the last line partially written
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s buffer: %w", buffer, err)
+	}
+	return msgs, nil
+}
+
+// Trim implements BufferStore interface
+func (f *FileBufferStore) Trim(sequence uint64) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, buffer := range []BufferKind{Buffer_Dedup, Buffer_Future} {
+		msgs, err := f.load(buffer)
+		if err != nil {
+			return err
+		}
+		if msgs == nil {
+			continue
+		}
+
+		var data []byte
+		for _, msg := range trimBuffer(msgs, sequence) {
+			line, err := json.Marshal(msg)
+			if err != nil {
+				return err
+			}
+			data = append(append(data, line...), '\n')
+		}
+		// write to a temporary file first so that a crash never leaves a partially trimmed buffer
+		tmp := f.path(buffer) + ".tmp"
+		if err := os.WriteFile(tmp, data, 0600); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, f.path(buffer)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dedupIdentity returns the copy of the message kept in the dedup buffer, without the payload
+func dedupIdentity(msg *MessageReq) *MessageReq {
+	return &MessageReq{
+		Type: msg.Type,
+		From: msg.From,
+		View: msg.View.Copy(),
+		Hash: append([]byte{}, msg.Hash...),
+	}
+}
+
+// persistBuffered records the message in the buffer of the BufferStore
+func (p *Pbft) persistBuffered(buffer BufferKind, msg *MessageReq) {
+	if err := p.config.BufferStore.Append(buffer, msg); err != nil {
+		p.logger.Printf("[ERROR] failed to store message in the %s buffer: %v", buffer, err)
+	}
+}
+
+// restoreBuffers loads the sequence dedup and the future messages buffers from the BufferStore
+func (p *Pbft) restoreBuffers() {
+	if p.dedup != nil {
+		msgs, err := p.config.BufferStore.Load(Buffer_Dedup)
+		if err != nil {
+			p.logger.Printf("[ERROR] failed to restore the %s buffer: %v", Buffer_Dedup, err)
+		}
+		for _, msg := range msgs {
+			p.dedup.seen(msg)
+		}
+	}
+
+	msgs, err := p.config.BufferStore.Load(Buffer_Future)
+	if err != nil {
+		p.logger.Printf("[ERROR] failed to restore the %s buffer: %v", Buffer_Future, err)
+	}
+	p.futureMsgs.restore(msgs)
+}
+
+// trimBuffers removes the messages of the finalized sequence from the BufferStore
+func (p *Pbft) trimBuffers(sequence uint64) {
+	if err := p.config.BufferStore.Trim(sequence); err != nil {
+		p.logger.Printf("[ERROR] failed to trim the buffers: sequence=%d, err=%v", sequence, err)
+	}
+}
@@ -0,0 +1,70 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileBufferStore_RestartPreservesDedup(t *testing.T) {
+	dir := t.TempDir()
+	newNode := func() *mockPbft {
+		store, err := NewFileBufferStore(dir)
+		require.NoError(t, err)
+
+		m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+		m.dedup = newSequenceDedup()
+		WithBufferStore(store)(m.config)
+		m.restoreBuffers()
+		return m
+	}
+
+	m := newNode()
+	prepare := createMessage("B", MessageReq_Prepare, ViewMsg(1, 0))
+	m.emitMsg(prepare)
+	m.emitMsg(createMessage("C", MessageReq_Prepare, ViewMsg(2, 0)))
+	assert.Equal(t, 1, m.msgQueue.validateStateQueue.Len())
+	assert.Equal(t, 1, m.futureMsgs.len())
+
+	// the restarted node drops the messages it already received and keeps the future ones
+	m = newNode()
+	m.emitMsg(prepare.Copy())
+	assert.Equal(t, 0, m.msgQueue.validateStateQueue.Len())
+	assert.Equal(t, uint64(1), m.stats.DroppedMsgCount(MessageReq_Prepare.String()))
+	assert.Equal(t, 2, m.dedup.len())
+	assert.Equal(t, 1, m.futureMsgs.len())
+
+	// the buffers are trimmed once the sequence is finalized
+	m.sequenceFinalized(1)
+	store, err := NewFileBufferStore(dir)
+	require.NoError(t, err)
+	dedup, err := store.Load(Buffer_Dedup)
+	require.NoError(t, err)
+	require.Len(t, dedup, 1)
+	assert.Equal(t, NodeID("C"), dedup[0].From)
+	assert.Nil(t, dedup[0].Proposal)
+	future, err := store.Load(Buffer_Future)
+	require.NoError(t, err)
+	require.Len(t, future, 1)
+	assert.Equal(t, uint64(2), future[0].View.Sequence)
+}
+
+func TestMemoryBufferStore_Bounded(t *testing.T) {
+	store := NewMemoryBufferStore(2)
+	for sequence := uint64(1); sequence <= 3; sequence++ {
+		require.NoError(t, store.Append(Buffer_Future, createMessage("A", MessageReq_Prepare, ViewMsg(sequence, 0))))
+	}
+
+	// the oldest message is evicted
+	msgs, err := store.Load(Buffer_Future)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, uint64(2), msgs[0].View.Sequence)
+
+	require.NoError(t, store.Trim(2))
+	msgs, err = store.Load(Buffer_Future)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, uint64(3), msgs[0].View.Sequence)
+}
@@ -0,0 +1,32 @@
+package pbft
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCheckpoint is returned when the checkpoint the node starts from is not a finalized proposal
+var ErrInvalidCheckpoint = errors.New("invalid checkpoint")
+
+// InitFromCheckpoint starts the node from a trusted checkpoint instead of genesis, without replaying the history:
+// lastProposal is the proposal finalized in the given sequence. The node moves to the next sequence and
+// the next proposals are validated against the checkpoint (see WithParentHashVerification and WithProposalTimePolicy),
+// while the checkpoint and the previous sequences are not delivered to the OnFinalize callback.
+// It must be called after SetBackend, whose validator set must be the one of the sequence after the checkpoint and,
+// like SetBackend, not concurrently with the state machine.
+func (p *Pbft) InitFromCheckpoint(sequence uint64, lastProposal *Proposal) error {
+	if lastProposal == nil || lastProposal.IsNil() {
+		return fmt.Errorf("%w: sequence %d has no finalized proposal", ErrInvalidCheckpoint, sequence)
+	}
+	if len(lastProposal.Hash) == 0 {
+		return fmt.Errorf("%w: proposal of sequence %d without hash", ErrInvalidCheckpoint, sequence)
+	}
+
+	p.setSequence(sequence + 1)
+	p.proposalFinalized(lastProposal)
+	p.finalizationProof = nil
+	p.lastNotified = sequence
+
+	p.logger.Printf("[INFO] started from checkpoint: sequence=%d, hash=%x", sequence, lastProposal.Hash)
+	return nil
+}
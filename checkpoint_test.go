@@ -0,0 +1,52 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPbft_InitFromCheckpoint(t *testing.T) {
+	run := func(parentHash []byte) *mockPbft {
+		m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+		WithParentHashVerification()(m.config)
+		m.proposerOverride = func(view *View) NodeID {
+			return "A"
+		}
+
+		checkpoint := &Proposal{Data: mockProposal1, Time: time.Now(), Hash: digest1}
+		require.NoError(t, m.InitFromCheckpoint(10, checkpoint))
+		assert.Equal(t, uint64(11), m.state.GetSequence())
+		assert.Equal(t, digest1, m.LastFinalizedHash())
+		m.setState(AcceptState)
+
+		// the messages of the sequences before the checkpoint are not processed
+		m.emitMsg(createMessage("A", MessageReq_Preprepare, ViewMsg(10, 0)))
+
+		preprepare := createMessage("A", MessageReq_Preprepare, ViewMsg(11, 0))
+		preprepare.ProposalParentHash = parentHash
+		m.emitMsg(preprepare)
+		m.runCycle(context.Background())
+		return m
+	}
+
+	m := run(digest1)
+	assert.Equal(t, ValidateState, m.getState())
+	assert.Equal(t, digest, m.state.proposal.Hash)
+
+	// the next proposal must extend the checkpoint
+	assert.Equal(t, RoundChangeState, run([]byte("fork")).getState())
+}
+
+func TestPbft_InitFromCheckpoint_Invalid(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+
+	assert.ErrorIs(t, m.InitFromCheckpoint(10, nil), ErrInvalidCheckpoint)
+	assert.ErrorIs(t, m.InitFromCheckpoint(10, NilProposal()), ErrInvalidCheckpoint)
+	assert.ErrorIs(t, m.InitFromCheckpoint(10, &Proposal{Data: mockProposal}), ErrInvalidCheckpoint)
+	assert.Equal(t, uint64(1), m.state.GetSequence())
+	assert.Nil(t, m.LastFinalizedHash())
+}
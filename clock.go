@@ -0,0 +1,35 @@
+package pbft
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrClockSkew is returned when a peer timestamp is further ahead of the local clock than the configured MaxClockSkew
+var ErrClockSkew = fmt.Errorf("timestamp exceeds the maximum clock skew")
+
+// Clock is the time source used by the state machine. It enables tests to drive time-based logic deterministically.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+}
+
+// realClock is the default Clock implementation backed by the system time
+type realClock struct{}
+
+// Now implements Clock interface
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// checkClockSkew validates that the given peer timestamp is not ahead of the local clock by more than MaxClockSkew.
+// Zero timestamps are not validated since older peers do not send them.
+func (p *Pbft) checkClockSkew(timestamp time.Time) error {
+	if timestamp.IsZero() {
+		return nil
+	}
+	if ahead := timestamp.Sub(p.config.Clock.Now()); ahead > p.config.MaxClockSkew {
+		return fmt.Errorf("%w: %s ahead of the local clock", ErrClockSkew, ahead)
+	}
+	return nil
+}
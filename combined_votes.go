@@ -0,0 +1,62 @@
+package pbft
+
+// canCombineVotes returns whether the node sends its Prepare and its Commit in a single message (see WithCombinedVotes):
+// the prepare messages counted before the proposal was accepted, with the Prepare of the node, reach the prepare quorum,
+// so that the node is already allowed to commit.
+func (p *Pbft) canCombineVotes() bool {
+	if !p.config.CombinedVotes || p.IsPaused() || p.state.IsLocked() || p.state.competingPrepareQuorum(p.validator.NodeID()) != nil {
+		return false
+	}
+	self := p.validator.NodeID()
+	votingPower, prepared := p.state.prepared.getAccumulatedVotingPower(), false
+	p.state.rangePrepared(func(from NodeID, _ *MessageReq) bool {
+		prepared = from == self
+		return !prepared
+	})
+	if !prepared {
+		votingPower += p.state.validators.VotingPower()[self]
+	}
+	return votingPower >= p.state.getPrepareQuorumSize()
+}
+
+// sendVotes sends the Prepare of the node, combined with its Commit when allowed. It falls back to the sole Prepare
+// if the combined message could not be sent.
+func (p *Pbft) sendVotes() {
+	if p.canCombineVotes() {
+		p.gossip(MessageReq_PrepareCommit)
+		if p.combinedCommit {
+			return
+		}
+	}
+	p.sendPrepareMsg()
+}
+
+// takeCombinedCommit returns whether the Commit of the node was sent with its Prepare in the current round
+func (p *Pbft) takeCombinedCommit() bool {
+	combined := p.combinedCommit
+	p.combinedCommit = false
+	return combined
+}
+
+// splitPrepareCommit splits a PrepareCommit message into the Prepare and the Commit it carries
+func splitPrepareCommit(msg *MessageReq) (prepare, commit *MessageReq) {
+	prepare = &MessageReq{
+		Type: MessageReq_Prepare,
+		From: msg.From,
+		Hash: append([]byte{}, msg.Hash...),
+	}
+	if msg.PrepareSeal != nil {
+		prepare.Seal = append([]byte{}, msg.PrepareSeal...)
+	}
+	commit = &MessageReq{
+		Type: MessageReq_Commit,
+		From: msg.From,
+		Hash: append([]byte{}, msg.Hash...),
+		Seal: append([]byte{}, msg.Seal...),
+	}
+	if msg.View != nil {
+		prepare.View = msg.View.Copy()
+		commit.View = msg.View.Copy()
+	}
+	return prepare, commit
+}
@@ -0,0 +1,90 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransition_ValidateState_PrepareCommit(t *testing.T) {
+	// the combined messages are counted as both a prepare and a commit
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.setState(ValidateState)
+
+	m.emitMsg(createMessage(NodeID("A"), MessageReq_Prepare, nil))
+	m.emitMsg(createMessage(NodeID("B"), MessageReq_PrepareCommit, nil))
+	m.emitMsg(createMessage(NodeID("C"), MessageReq_PrepareCommit, nil))
+
+	m.runCycle(context.Background())
+
+	m.expect(expectResult{
+		sequence:               1,
+		state:                  CommitState,
+		prepareMsgs:            3,
+		prepareMsgsVotingPower: 3,
+		commitMsgs:             3,
+		commitMsgsVotingPower:  3,
+		locked:                 true,
+		outgoing:               1, // A commit message
+	})
+}
+
+func TestTransition_AcceptState_CombinedVotes(t *testing.T) {
+	cases := []struct {
+		name     string
+		prepared []NodeID
+		typ      MsgType
+	}{
+		{"quorum reached", []NodeID{"C", "D"}, MessageReq_PrepareCommit},
+		{"quorum not reached", []NodeID{"C"}, MessageReq_Prepare},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+			WithCombinedVotes()(m.config)
+			m.state.view = ViewMsg(1, 0)
+			m.setState(AcceptState)
+			// the prepares received before the pre-prepare
+			m.state.orphans = newOrphanPrepares(4)
+			for _, from := range c.prepared {
+				prepare := createMessage(from, MessageReq_Prepare, ViewMsg(1, 0))
+				prepare.Hash = digest
+				require.True(t, m.state.bufferOrphanPrepare(prepare))
+			}
+			WithScheduler(NewDeterministicScheduler(MessageEvent(createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0)))))(m.config)
+
+			m.runCycle(context.Background())
+
+			require.Len(t, m.respMsg, 1)
+			assert.Equal(t, c.typ, m.respMsg[0].Type)
+			assert.Equal(t, ValidateState, m.getState())
+		})
+	}
+}
+
+func TestTransition_ValidateState_CombinedCommitNotResent(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+	WithCombinedVotes()(m.config)
+	m.state.view = ViewMsg(1, 0)
+	m.setState(AcceptState)
+	m.state.orphans = newOrphanPrepares(4)
+	for _, from := range []NodeID{"C", "D"} {
+		prepare := createMessage(from, MessageReq_Prepare, ViewMsg(1, 0))
+		prepare.Hash = digest
+		require.True(t, m.state.bufferOrphanPrepare(prepare))
+	}
+	m.emitMsg(createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0)))
+	m.runCycle(context.Background())
+
+	m.emitMsg(createMessage(NodeID("C"), MessageReq_Commit, nil))
+	m.emitMsg(createMessage(NodeID("D"), MessageReq_Commit, nil))
+	m.runCycle(context.Background())
+
+	// the own commit was received back from the combined message and not sent again
+	assert.Equal(t, CommitState, m.getState())
+	assert.Equal(t, 3, m.state.committed.length())
+	require.Len(t, m.respMsg, 1)
+	assert.Equal(t, MessageReq_PrepareCommit, m.respMsg[0].Type)
+}
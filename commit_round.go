@@ -0,0 +1,155 @@
+package pbft
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CommitRoundPolicy is the handling of the commit messages of the current sequence sent in another round
+type CommitRoundPolicy uint8
+
+const (
+	// CommitRound_Strict only counts the commit messages of the current round, the ones of the earlier rounds are dropped
+	CommitRound_Strict CommitRoundPolicy = iota
+
+	// CommitRound_Lenient also counts the commit messages of the earlier rounds of the current sequence, as long as
+	// they agree on the proposal of the current round. A node that moved to a new round can then still finalize
+	// a proposal committed by a quorum across the rounds.
+	CommitRound_Lenient
+)
+
+func (c CommitRoundPolicy) String() string {
+	switch c {
+	case CommitRound_Strict:
+		return "Strict"
+	case CommitRound_Lenient:
+		return "Lenient"
+	default:
+		return fmt.Sprintf("CommitRoundPolicy(%d)", uint8(c))
+	}
+}
+
+// roundCommits holds the commit messages of the current sequence, so that the ones of the earlier rounds
+// are counted again in the later rounds (see CommitRound_Lenient). It holds the latest commit message of each
+// sender only, so that it is bounded by the validator set whatever the number of rounds of the sequence.
+type roundCommits struct {
+	// sequence is the sequence of the commit messages
+	sequence uint64
+
+	// msgs are the latest commit messages of the sequence, by sender
+	msgs map[NodeID]*MessageReq
+
+	// pending are the commit messages of the earlier rounds not delivered in the current round yet
+	pending []*MessageReq
+}
+
+func newRoundCommits() *roundCommits {
+	return &roundCommits{
+		msgs: map[NodeID]*MessageReq{},
+	}
+}
+
+// reset starts holding the commit messages of the sequence, unless it is the current one
+func (r *roundCommits) reset(sequence uint64) {
+	if r.sequence == sequence {
+		return
+	}
+	r.sequence = sequence
+	r.msgs = map[NodeID]*MessageReq{}
+	r.pending = nil
+}
+
+// record holds the commit message of the sequence, unless the sender has one of the same or a later round held.
+// The replaced message is not delivered anymore. It returns false if the message was not held.
+func (r *roundCommits) record(msg *MessageReq) bool {
+	r.reset(msg.View.Sequence)
+
+	held, ok := r.msgs[msg.From]
+	if ok && held.View.Round >= msg.View.Round {
+		return false
+	}
+	if ok {
+		for i, pending := range r.pending {
+			if pending == held {
+				r.pending = append(r.pending[:i], r.pending[i+1:]...)
+				break
+			}
+		}
+	}
+	r.msgs[msg.From] = msg
+	return true
+}
+
+// replay schedules the delivery of the held commit messages of the rounds before the view
+func (r *roundCommits) replay(view *View) {
+	r.reset(view.Sequence)
+
+	r.pending = nil
+	for _, msg := range r.msgs {
+		if msg.View.Round < view.Round {
+			r.pending = append(r.pending, msg)
+		}
+	}
+	sort.Slice(r.pending, func(i, j int) bool {
+		if r.pending[i].View.Round != r.pending[j].View.Round {
+			return r.pending[i].View.Round < r.pending[j].View.Round
+		}
+		return r.pending[i].From < r.pending[j].From
+	})
+}
+
+// next returns the next commit message to deliver, if any
+func (r *roundCommits) next() *MessageReq {
+	if len(r.pending) == 0 {
+		return nil
+	}
+	msg := r.pending[0]
+	r.pending = r.pending[1:]
+	return msg
+}
+
+// replayEarlierCommits delivers the commit messages of the earlier rounds of the sequence again in the validate state
+func (p *Pbft) replayEarlierCommits() {
+	if p.config.CommitRoundPolicy != CommitRound_Lenient {
+		return
+	}
+	view := p.state.CurrentView()
+	p.state.roundCommits.replay(&view)
+}
+
+// recordCommit holds the commit message so that it is counted again in the later rounds of the sequence
+func (p *Pbft) recordCommit(msg *MessageReq) {
+	if p.config.CommitRoundPolicy == CommitRound_Lenient {
+		p.state.roundCommits.record(msg)
+	}
+}
+
+// nextEarlierCommit returns the next commit message of an earlier round to deliver in the validate state, if any
+func (p *Pbft) nextEarlierCommit() *MessageReq {
+	if p.config.CommitRoundPolicy != CommitRound_Lenient || p.getState() != ValidateState {
+		return nil
+	}
+	return p.state.roundCommits.next()
+}
+
+// holdStaleCommits holds the stale commit messages of the current sequence read from the queue, to deliver them
+// in the validate state. It returns the other stale messages, which are dropped.
+func (p *Pbft) holdStaleCommits(discards []*MessageReq) []*MessageReq {
+	if p.config.CommitRoundPolicy != CommitRound_Lenient || p.getState() != ValidateState {
+		return discards
+	}
+
+	view := p.state.CurrentView()
+	dropped := make([]*MessageReq, 0, len(discards))
+	for _, msg := range discards {
+		if msg.Type == MessageReq_Commit && msg.View.Sequence == view.Sequence && msg.View.Round < view.Round &&
+			p.state.validators.Includes(msg.From) {
+			if p.state.roundCommits.record(msg) {
+				p.state.roundCommits.pending = append(p.state.roundCommits.pending, msg)
+			}
+			continue
+		}
+		dropped = append(dropped, msg)
+	}
+	return dropped
+}
@@ -0,0 +1,115 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransition_ValidateState_EarlierRoundCommits(t *testing.T) {
+	run := func(policy CommitRoundPolicy, hash []byte) *mockPbft {
+		m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+		WithCommitRoundPolicy(policy)(m.config)
+		m.state.view = ViewMsg(1, 1)
+		m.state.proposer = "B"
+		m.state.proposal = &Proposal{Data: mockProposal, Hash: digest}
+		m.setState(ValidateState)
+
+		// the commit messages of round 0 arrive once the node moved to round 1
+		for _, from := range []NodeID{"A", "C", "D"} {
+			commit := createMessage(from, MessageReq_Commit, ViewMsg(1, 0))
+			commit.Hash = hash
+			m.emitMsg(commit)
+		}
+		m.state.timeoutChan = time.After(100 * time.Millisecond)
+		m.runCycle(context.Background())
+		return m
+	}
+
+	// by default the commit messages of round 0 are stale
+	m := run(CommitRound_Strict, digest)
+	assert.Equal(t, RoundChangeState, m.getState())
+	assert.Equal(t, 0, m.state.numCommitted())
+
+	// the lenient policy counts them, since they agree on the proposal
+	m = run(CommitRound_Lenient, digest)
+	m.expect(expectResult{
+		sequence:   1,
+		round:      1,
+		state:      CommitState,
+		commitMsgs: 3,
+		locked:     true,
+		outgoing:   1, // commit
+
+		commitMsgsVotingPower: 3,
+	})
+
+	// the commit messages of another proposal are not counted
+	m = run(CommitRound_Lenient, digest1)
+	assert.Equal(t, RoundChangeState, m.getState())
+	assert.Equal(t, 0, m.state.numCommitted())
+}
+
+func TestTransition_ValidateState_CommitsReplayedAcrossRounds(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+	WithCommitRoundPolicy(CommitRound_Lenient)(m.config)
+	m.state.view = ViewMsg(1, 0)
+	m.state.proposer = "A"
+	m.state.proposal = &Proposal{Data: mockProposal, Hash: digest}
+	m.setState(ValidateState)
+
+	// two commit messages in round 0 do not reach the quorum
+	for _, from := range []NodeID{"A", "C"} {
+		commit := createMessage(from, MessageReq_Commit, ViewMsg(1, 0))
+		commit.Hash = digest
+		m.emitMsg(commit)
+	}
+	m.state.timeoutChan = time.After(100 * time.Millisecond)
+	m.runCycle(context.Background())
+	assert.Equal(t, RoundChangeState, m.getState())
+
+	// in round 1, the commit messages of round 0 are counted with the new one
+	m.setRound(1)
+	m.state.proposal = &Proposal{Data: mockProposal, Hash: digest}
+	m.setState(ValidateState)
+	commit := createMessage("D", MessageReq_Commit, ViewMsg(1, 1))
+	commit.Hash = digest
+	m.emitMsg(commit)
+	m.state.timeoutChan = time.After(time.Second)
+	m.runCycle(context.Background())
+	assert.Equal(t, CommitState, m.getState())
+	assert.Equal(t, 3, m.state.numCommitted())
+}
+
+// Test that the held commit messages are bounded by the validator set and cleared with the sequence.
+func TestPbft_RoundCommits_Bounded(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+	WithCommitRoundPolicy(CommitRound_Lenient)(m.config)
+	m.setSequence(1)
+	m.setRound(100)
+	m.setState(ValidateState)
+
+	// a sender is held once, with its latest round, whatever the number of rounds
+	var stale []*MessageReq
+	for round := uint64(0); round < 100; round++ {
+		for _, from := range []NodeID{"A", "C", "X"} {
+			stale = append(stale, createMessage(from, MessageReq_Commit, ViewMsg(1, round)))
+		}
+	}
+	// the commit messages of a non validator are dropped
+	assert.Len(t, m.holdStaleCommits(stale), 100)
+	require.Len(t, m.state.roundCommits.msgs, 2)
+	assert.Len(t, m.state.roundCommits.pending, 2)
+	assert.Equal(t, uint64(99), m.state.roundCommits.msgs["A"].View.Round)
+	assert.NotContains(t, m.state.roundCommits.msgs, NodeID("X"))
+
+	// an earlier round does not replace the held commit
+	assert.False(t, m.state.roundCommits.record(createMessage("A", MessageReq_Commit, ViewMsg(1, 3))))
+
+	m.setSequence(2)
+	assert.Empty(t, m.state.roundCommits.msgs)
+	assert.Empty(t, m.state.roundCommits.pending)
+}
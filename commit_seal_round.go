@@ -0,0 +1,37 @@
+package pbft
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// commitSealPrefix separates the content of the round bound commit seals from the one of the prepare seals
+var commitSealPrefix = []byte("pbft-commit")
+
+// CommitSealHash returns the digest sealed by the commit messages with WithRoundBoundCommitSeals. It binds the round
+// the commit is cast in and the seal hash (see Pbft.SealHash), so that a commit seal of a round can not be replayed
+// as the commit seal of another round. The verifiers rebuild it out of the round of the commit (or of the
+// FinalizationProof view) before applying SignableContent.
+func CommitSealHash(round uint64, sealHash []byte) []byte {
+	var encodedRound [8]byte
+	binary.BigEndian.PutUint64(encodedRound[:], round)
+
+	h := sha256.New()
+	h.Write(commitSealPrefix)
+	h.Write(encodedRound[:])
+	h.Write(sealHash)
+	return h.Sum(nil)
+}
+
+// roundSealHash returns the hash sealed by a commit message of the round, bound to the round if enabled
+func (p *Pbft) roundSealHash(round uint64, sealHash []byte) []byte {
+	if !p.config.RoundBoundCommitSeals {
+		return sealHash
+	}
+	return CommitSealHash(round, sealHash)
+}
+
+// commitSealHash returns the hash sealed by a commit message of the round for the proposal
+func (p *Pbft) commitSealHash(round uint64, proposal *Proposal) []byte {
+	return p.roundSealHash(round, p.SealHash(proposal))
+}
@@ -0,0 +1,93 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitSealHash(t *testing.T) {
+	hash := []byte("hash")
+
+	assert.Equal(t, CommitSealHash(1, hash), CommitSealHash(1, hash))
+	assert.NotEqual(t, CommitSealHash(1, hash), CommitSealHash(2, hash))
+	assert.NotEqual(t, CommitSealHash(1, hash), CommitSealHash(1, []byte("other")))
+	// a prepare seal can not be replayed as a commit seal
+	assert.NotEqual(t, prepareSealHash(1, hash), CommitSealHash(1, hash))
+}
+
+// Test that a commit seal cast in round R fails the verification when presented as a commit of round R+1.
+func TestPbft_RoundBoundCommitSeals(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithRoundBoundCommitSeals()(m.config)
+	m.pool.useMockSigners()
+	m.backend = &sealValidatorBackend{mockBackend: m.backend.(*mockBackend), verifier: m.pool.verifier()}
+	m.state.view = ViewMsg(1, 3)
+
+	m.sendCommitMsg()
+	require.Len(t, m.respMsg, 1)
+	commit := m.respMsg[0]
+	assert.NoError(t, m.pool.verifier().Verify("A", CommitSealHash(3, digest), commit.Seal))
+
+	assert.NoError(t, m.collectCommitSeal(commit))
+	assert.NoError(t, m.verifyVote(commit))
+
+	replayed := commit.Copy()
+	replayed.View = ViewMsg(1, 4)
+	assert.ErrorIs(t, m.collectCommitSeal(replayed), ErrBadSignature)
+	assert.ErrorIs(t, m.verifyVote(replayed), ErrBadSignature)
+
+	// without the option, the seal covers the proposal hash only and verifies in any round
+	m.config.RoundBoundCommitSeals = false
+	m.respMsg = nil
+	m.sendCommitMsg()
+	replayed = m.respMsg[0].Copy()
+	replayed.View = ViewMsg(1, 4)
+	assert.NoError(t, m.collectCommitSeal(replayed))
+}
+
+// Test that the finalization proofs of round bound commit seals are verified against the round of their view.
+func TestFinalizationProof_RoundBoundCommitSeals(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+	pool.useMockSigners()
+	verifier := pool.verifier()
+	validators := pool.validatorSet()
+	sealing := ProofSealing{RoundBound: true}
+
+	sealHash := CommitSealHash(3, digest)
+	proof := &FinalizationProof{Hash: digest, SealHash: sealHash, View: ViewMsg(1, 3)}
+	for _, id := range []NodeID{"A", "B", "C"} {
+		seal, err := pool.signer(id).Sign(sealHash)
+		require.NoError(t, err)
+		proof.CommittedSeals = append(proof.CommittedSeals, CommittedSeal{NodeID: id, Signature: seal})
+	}
+	assert.NoError(t, VerifyFinalizationProof(proof, validators, verifier.Verify, sealing))
+	assert.ErrorIs(t, VerifyFinalizationProof(proof, validators, verifier.Verify, ProofSealing{}), ErrInvalidSealHash)
+
+	// the proof of another round carries a seal hash not bound to its round
+	replayed := *proof
+	replayed.View = ViewMsg(1, 4)
+	assert.ErrorIs(t, VerifyFinalizationProof(&replayed, validators, verifier.Verify, sealing), ErrInvalidSealHash)
+
+	// without the seal hash, the digest of the round is rebuilt and the seals do not verify
+	replayed.SealHash = nil
+	assert.ErrorIs(t, VerifyFinalizationProof(&replayed, validators, verifier.Verify, sealing), ErrBadSignature)
+}
+
+// Test that the inserted proposal carries the round it was committed in.
+func TestTransition_CommitState_InsertRound(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
+	WithRoundBoundCommitSeals()(m.config)
+	m.state.view = ViewMsg(1, 2)
+	m.state.proposer = "A"
+	m.setState(CommitState)
+
+	m.runCycle(context.Background())
+
+	inserted := m.backend.(*mockBackend).inserted
+	require.Len(t, inserted, 1)
+	assert.Equal(t, uint64(2), inserted[0].Round)
+}
@@ -0,0 +1,13 @@
+package pbft
+
+import "time"
+
+// startCommitTimeout replaces the round timeout with the CommitTimeout, if set, once the node enters the commit
+// phase: the commit quorum must be reached before it expires, or the node starts a round change
+func (p *Pbft) startCommitTimeout() {
+	if p.config.CommitTimeout <= 0 {
+		return
+	}
+	p.logger.Printf("[DEBUG] commit phase started: timeout=%s", p.config.CommitTimeout)
+	p.state.timeoutChan = time.NewTimer(p.config.jitter(p.config.CommitTimeout)).C
+}
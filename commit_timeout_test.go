@@ -0,0 +1,40 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the commit quorum not reached within the commit timeout starts a round change,
+// while the round timeout alone would never expire.
+func TestTransition_ValidateState_CommitTimeout(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithRoundTimeout(func(uint64) <-chan time.Time { return nil })(m.config)
+	WithCommitTimeout(20 * time.Millisecond)(m.config)
+	m.state.timeoutChan = nil
+	m.setState(ValidateState)
+
+	m.emitMsg(createMessage("A", MessageReq_Prepare, nil))
+	m.emitMsg(createMessage("B", MessageReq_Prepare, nil))
+	m.emitMsg(createMessage("C", MessageReq_Prepare, nil))
+	m.emitMsg(createMessage("B", MessageReq_Commit, nil))
+
+	start := time.Now()
+	m.runCycle(context.Background())
+
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	m.expect(expectResult{
+		sequence:               1,
+		state:                  RoundChangeState,
+		prepareMsgs:            3,
+		prepareMsgsVotingPower: 3,
+		commitMsgs:             2,
+		commitMsgsVotingPower:  2,
+		locked:                 true,
+		outgoing:               1, // A commit message
+	})
+	assert.Equal(t, RoundChange_Timeout, m.LastRoundChangeReason())
+}
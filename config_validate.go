@@ -0,0 +1,169 @@
+package pbft
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidConfig is returned by Config.Validate, wrapped with the description of the invalid setting
+var ErrInvalidConfig = errors.New("invalid config")
+
+// Validate checks the config without starting the consensus: the timeouts, the required components,
+// the sizes and the enumerated settings. It returns the first invalid setting found.
+func (c *Config) Validate() error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, fmt.Sprintf(format, args...))
+	}
+
+	for _, timeout := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"Timeout", c.Timeout},
+		{"ProposalTimeout", c.ProposalTimeout},
+	} {
+		if timeout.value <= 0 {
+			return invalid("%s must be positive, got %s", timeout.name, timeout.value)
+		}
+	}
+	for _, duration := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"Round0Timeout", c.Round0Timeout},
+		{"CommitTimeout", c.CommitTimeout},
+		{"HealthThreshold", c.HealthThreshold},
+		{"MaxClockSkew", c.MaxClockSkew},
+		{"MaxMessageAge", c.MaxMessageAge},
+		{"ValidationRetryDelay", c.ValidationRetryDelay},
+		{"MinBlockTime", c.MinBlockTime},
+		{"SyncPeerTimeout", c.SyncPeerTimeout},
+		{"SyncDeadline", c.SyncDeadline},
+		{"PreprepareRetransmitInterval", c.PreprepareRetransmitInterval},
+		{"ViewSyncInterval", c.ViewSyncInterval},
+		{"SequenceDeadline", c.SequenceDeadline},
+		{"SinkRetryBackoff", c.SinkRetryBackoff},
+		{"SinkMaxRetryBackoff", c.SinkMaxRetryBackoff},
+	} {
+		if duration.value < 0 {
+			return invalid("%s can not be negative, got %s", duration.name, duration.value)
+		}
+	}
+
+	for _, component := range []struct {
+		name   string
+		isNil  bool
+		option string
+	}{
+		{"Logger", c.Logger == nil, "WithLogger"},
+		{"Tracer", c.Tracer == nil, "WithTracer"},
+		{"RoundTimeout", c.RoundTimeout == nil, "WithRoundTimeout"},
+		{"Notifier", c.Notifier == nil, "WithNotifier"},
+		{"Clock", c.Clock == nil, "WithClock"},
+		{"Scheduler", c.Scheduler == nil, "WithScheduler"},
+		{"ProposerSelector", c.ProposerSelector == nil, "WithProposerSelector"},
+		{"Metrics", c.Metrics == nil, "WithMetrics"},
+		{"RandSource", c.RandSource == nil, "WithRandSource"},
+		{"BufferStore", c.BufferStore == nil, "WithBufferStore"},
+		{"FinalizedStore", c.FinalizedStore == nil, "WithFinalizedStore"},
+	} {
+		if component.isNil {
+			return invalid("%s is not set (see %s)", component.name, component.option)
+		}
+	}
+
+	for _, size := range []struct {
+		name  string
+		value int
+	}{
+		{"MaxFutureMessagesPerSequence", c.MaxFutureMessagesPerSequence},
+		{"MaxFutureMessages", c.MaxFutureMessages},
+		{"MaxFuturePreprepares", c.MaxFuturePreprepares},
+		{"InboundQueueSize", c.InboundQueueSize},
+		{"OrphanPrepareLimit", c.OrphanPrepareLimit},
+		{"MemoryBudget", c.MemoryBudget},
+		{"ParticipationHistory", c.ParticipationHistory},
+		{"ValidationRetries", c.ValidationRetries},
+		{"EvidenceLimit", c.EvidenceLimit},
+		{"StartupPeers", c.StartupPeers},
+		{"DropLogRateLimit", c.DropLogRateLimit},
+		{"MaxValidators", c.MaxValidators},
+	} {
+		if size.value < 0 {
+			return invalid("%s can not be negative, got %d", size.name, size.value)
+		}
+	}
+	if c.MinValidators < 1 {
+		return invalid("MinValidators must be at least 1, got %d", c.MinValidators)
+	}
+	if c.MaxValidators > 0 && c.MaxValidators < c.MinValidators {
+		return invalid("MaxValidators (%d) can not be lower than MinValidators (%d)", c.MaxValidators, c.MinValidators)
+	}
+	if c.TimeoutJitter < 0 || c.TimeoutJitter > 1 {
+		return invalid("TimeoutJitter must be between 0 and 1, got %v", c.TimeoutJitter)
+	}
+	if c.ProposerEvictionThreshold > 0 && c.ProposerEvictionCooldown == 0 {
+		return invalid("ProposerEvictionCooldown must be positive when the proposer eviction is enabled")
+	}
+	if c.ProposerEvictionThreshold > 0 && c.PipelineDepth > 0 {
+		return invalid("the proposer eviction can not be combined with pipelining")
+	}
+	if c.RoundBoundCommitSeals && c.CommitRoundPolicy == CommitRound_Lenient {
+		return invalid("the round bound commit seals can not be combined with CommitRound_Lenient")
+	}
+	if c.FinalizationSink != nil && c.SinkRetryBackoff == 0 {
+		return invalid("SinkRetryBackoff must be positive when a FinalizationSink is set")
+	}
+	if c.StrictValidation && c.MaxRound == 0 {
+		return invalid("MaxRound must be positive when StrictValidation is enabled")
+	}
+
+	for _, enum := range []struct {
+		value fmt.Stringer
+		valid bool
+	}{
+		{c.SealFormat, c.SealFormat <= SealFormat_CompactRecoverable},
+		{c.SealOrdering, c.SealOrdering <= SealOrdering_SigningTime},
+		{c.SealSelection, c.SealSelection <= SealSelection_Minimal},
+		{c.ProposalTimePolicy, c.ProposalTimePolicy <= ProposalTime_Strict},
+		{c.SmallValidatorSetPolicy, c.SmallValidatorSetPolicy <= SmallValidatorSet_Halt},
+		{c.RoundSelection, c.RoundSelection <= RoundSelection_Lowest},
+		{c.SelfMessagePolicy, c.SelfMessagePolicy <= SelfMessage_Confirm},
+		{c.EarlyCommitPolicy, c.EarlyCommitPolicy <= EarlyCommit_Reject},
+		{c.CommitRoundPolicy, c.CommitRoundPolicy <= CommitRound_Lenient},
+		{c.ZeroVotingPowerPolicy, c.ZeroVotingPowerPolicy <= ZeroVotingPower_EqualWeight},
+		{c.ConflictingJustificationPolicy, c.ConflictingJustificationPolicy <= ConflictingJustification_DropBoth},
+		{c.LockConflictPolicy, c.LockConflictPolicy <= LockConflict_RoundChange},
+		{c.PrepareTieResolution, c.PrepareTieResolution <= PrepareTie_LowerHash},
+		{c.ProposalBodyMismatchPolicy, c.ProposalBodyMismatchPolicy <= BodyMismatch_Rerequest},
+	} {
+		if !enum.valid {
+			return invalid("unknown %s", enum.value)
+		}
+	}
+	return nil
+}
+
+// Describe reports the consensus parameters resulting from the config, without starting the consensus.
+// Since the quorum depends on the validator set, it is reported for the smallest validator set (MinValidators)
+// with equal voting power, see ComputeQuorum for other validator sets.
+func (c *Config) Describe() string {
+	quorum, maxFaulty := ComputeQuorum(c, uint(c.MinValidators), nil)
+	round0Timeout := "round timeout"
+	if c.Round0Timeout > 0 {
+		round0Timeout = c.Round0Timeout.String()
+	}
+
+	lines := []string{
+		fmt.Sprintf("quorum size: %d of %d validators (max faulty: %d)", quorum, c.MinValidators, maxFaulty),
+		fmt.Sprintf("small validator set policy: %s", c.SmallValidatorSetPolicy),
+		"voting power: from the validator set",
+		fmt.Sprintf("timeout: %s, proposal timeout: %s, round 0 timeout: %s", c.Timeout, c.ProposalTimeout, round0Timeout),
+		fmt.Sprintf("max clock skew: %s, min block time: %s", c.MaxClockSkew, c.MinBlockTime),
+		fmt.Sprintf("seals: format %s, ordering %s, selection %s", c.SealFormat, c.SealOrdering, c.SealSelection),
+		fmt.Sprintf("round selection: %s", c.RoundSelection),
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,62 @@
+package pbft
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	require.NoError(t, DefaultConfig().Validate())
+
+	cases := []struct {
+		name   string
+		modify func(c *Config)
+		err    string
+	}{
+		{"zero timeout", func(c *Config) { c.Timeout = 0 }, "Timeout must be positive, got 0s"},
+		{"negative proposal timeout", func(c *Config) { c.ProposalTimeout = -time.Second }, "ProposalTimeout must be positive, got -1s"},
+		{"negative round 0 timeout", func(c *Config) { c.Round0Timeout = -time.Second }, "Round0Timeout can not be negative"},
+		{"nil round timeout", func(c *Config) { c.RoundTimeout = nil }, "RoundTimeout is not set (see WithRoundTimeout)"},
+		{"nil clock", func(c *Config) { c.Clock = nil }, "Clock is not set (see WithClock)"},
+		{"negative inbound queue", func(c *Config) { c.InboundQueueSize = -1 }, "InboundQueueSize can not be negative, got -1"},
+		{"no min validators", func(c *Config) { c.MinValidators = 0 }, "MinValidators must be at least 1, got 0"},
+		{"round bound seals with lenient commit round", func(c *Config) {
+			c.RoundBoundCommitSeals = true
+			c.CommitRoundPolicy = CommitRound_Lenient
+		}, "the round bound commit seals can not be combined with CommitRound_Lenient"},
+		{"max validators below min", func(c *Config) { c.MaxValidators = 3 }, "MaxValidators (3) can not be lower than MinValidators (4)"},
+		{"timeout jitter above 1", func(c *Config) { c.TimeoutJitter = 1.5 }, "TimeoutJitter must be between 0 and 1, got 1.5"},
+		{"proposer eviction without cooldown", func(c *Config) { WithProposerEviction(3, 0)(c) }, "ProposerEvictionCooldown must be positive"},
+		{"proposer eviction with pipelining", func(c *Config) {
+			WithProposerEviction(3, 10)(c)
+			WithPipelineDepth(2)(c)
+		}, "can not be combined with pipelining"},
+		{"strict validation without max round", func(c *Config) { WithStrictValidation(0)(c) }, "MaxRound must be positive"},
+		{"finalization sink without backoff", func(c *Config) { WithFinalizationSink(&recordingSink{}, 0, 0)(c) }, "SinkRetryBackoff must be positive"},
+		{"unknown seal format", func(c *Config) { c.SealFormat = 10 }, "unknown SealFormat(10)"},
+		{"unknown round selection", func(c *Config) { c.RoundSelection = 5 }, "unknown RoundSelection(5)"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config := DefaultConfig()
+			c.modify(config)
+			err := config.Validate()
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+			assert.Contains(t, err.Error(), c.err)
+		})
+	}
+}
+
+func TestConfig_Describe(t *testing.T) {
+	config := DefaultConfig()
+	config.ApplyOps(WithMinValidators(7, SmallValidatorSet_Halt), WithRound0Timeout(5*time.Second))
+
+	description := config.Describe()
+	assert.Contains(t, description, "quorum size: 5 of 7 validators (max faulty: 2)")
+	assert.Contains(t, description, "small validator set policy: Halt")
+	assert.Contains(t, description, "timeout: 2s, proposal timeout: 2s, round 0 timeout: 5s")
+	assert.Contains(t, description, "seals: format Native, ordering NodeID, selection All")
+}
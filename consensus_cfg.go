@@ -2,6 +2,7 @@ package pbft
 
 import (
 	"log"
+	"math/rand"
 	"os"
 	"time"
 
@@ -14,6 +15,13 @@ const (
 	defaultTimeout     = 2 * time.Second
 	maxTimeout         = 300 * time.Second
 	maxTimeoutExponent = 8
+
+	defaultHealthThreshold = maxTimeout
+	defaultMaxClockSkew    = defaultTimeout
+	defaultMaxRound        = 1024
+	defaultMinValidators   = 4
+
+	defaultMaxFuturePreprepares = 16
 )
 
 type RoundTimeout func(round uint64) <-chan time.Time
@@ -22,6 +30,9 @@ type StatsCallback func(stats.Stats)
 
 type ConfigOption func(*Config)
 
+// QuorumCalculator returns the quorum size for the given total voting power
+type QuorumCalculator func(totalVotingPower uint64) uint64
+
 func WithLogger(l Logger) ConfigOption {
 	return func(c *Config) {
 		c.Logger = l
@@ -50,6 +61,650 @@ func WithNotifier(notifier StateNotifier) ConfigOption {
 	}
 }
 
+func WithProposalHandler(typ ProposalType, handler ProposalHandler) ConfigOption {
+	return func(c *Config) {
+		if handler == nil {
+			return
+		}
+		if c.ProposalHandlers == nil {
+			c.ProposalHandlers = map[ProposalType]ProposalHandler{}
+		}
+		c.ProposalHandlers[typ] = handler
+	}
+}
+
+func WithClock(clock Clock) ConfigOption {
+	return func(c *Config) {
+		if clock != nil {
+			c.Clock = clock
+		}
+	}
+}
+
+// WithScheduler sets the source of the events processed by the run loop (i.e. a deterministic one in tests)
+func WithScheduler(scheduler Scheduler) ConfigOption {
+	return func(c *Config) {
+		if scheduler != nil {
+			c.Scheduler = scheduler
+		}
+	}
+}
+
+func WithHealthThreshold(threshold time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.HealthThreshold = threshold
+	}
+}
+
+// WithMaxClockSkew sets the tolerated difference between the local clock and the peers timestamps
+func WithMaxClockSkew(skew time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.MaxClockSkew = skew
+	}
+}
+
+// WithMaxMessageAge sets the maximum age of the proposal of a message, the messages with older proposals are dropped
+func WithMaxMessageAge(age time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.MaxMessageAge = age
+	}
+}
+
+func WithProposerSelector(selector ProposerSelector) ConfigOption {
+	return func(c *Config) {
+		if selector != nil {
+			c.ProposerSelector = selector
+		}
+	}
+}
+
+func WithFutureMessagesLimits(maxPerSequence, maxTotal int) ConfigOption {
+	return func(c *Config) {
+		c.MaxFutureMessagesPerSequence = maxPerSequence
+		c.MaxFutureMessages = maxTotal
+	}
+}
+
+// WithInboundQueueSize bounds the queue of the messages received from the transport.
+// Zero disables the bound and messages are queued as they arrive.
+func WithInboundQueueSize(size int) ConfigOption {
+	return func(c *Config) {
+		c.InboundQueueSize = size
+	}
+}
+
+// WithSequenceDeadline caps the time spent on a sequence, across its rounds. Once the deadline is exceeded,
+// the node assumes that the network moved on and moves to the sync state.
+func WithSequenceDeadline(deadline time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.SequenceDeadline = deadline
+	}
+}
+
+// WithViewSync broadcasts the current view and state of the node every interval (see BroadcastViewSync),
+// so that the lagging peers learn the view of the network
+func WithViewSync(interval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.ViewSyncInterval = interval
+	}
+}
+
+// WithFinalizationSink delivers the finalized sequences to the sink, at least once and in sequence order. A failed
+// delivery is retried after the backoff, doubled on every failure up to maxBackoff (zero is unbounded).
+func WithFinalizationSink(sink FinalizationSink, backoff, maxBackoff time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.FinalizationSink = sink
+		c.SinkRetryBackoff = backoff
+		c.SinkMaxRetryBackoff = maxBackoff
+	}
+}
+
+// WithMaxFuturePreprepares bounds the pre-prepare messages buffered for the future rounds of the current sequence,
+// the ones over the bound are dropped. Zero disables the bound.
+func WithMaxFuturePreprepares(max int) ConfigOption {
+	return func(c *Config) {
+		c.MaxFuturePreprepares = max
+	}
+}
+
+// WithDropLogRateLimit limits the logs of the dropped messages to perSecond logs per drop reason, the number of
+// the suppressed logs is summarized with the next log of the reason. Zero logs every dropped message.
+func WithDropLogRateLimit(perSecond int) ConfigOption {
+	return func(c *Config) {
+		c.DropLogRateLimit = perSecond
+	}
+}
+
+// WithOrphanPrepareBuffer buffers up to limit prepare messages received before the pre-prepare of their proposal,
+// they are counted once the pre-prepare is accepted
+func WithOrphanPrepareBuffer(limit int) ConfigOption {
+	return func(c *Config) {
+		c.OrphanPrepareLimit = limit
+	}
+}
+
+// WithMemoryBudget caps the approximate memory, in bytes, used by the messages held by the node.
+// Zero disables the cap.
+func WithMemoryBudget(bytes int) ConfigOption {
+	return func(c *Config) {
+		c.MemoryBudget = bytes
+	}
+}
+
+// WithInactivityTracking reports as offline (see InactiveValidators) the validators that did not send any message
+// in the last window sequences, until they send a message again. The offline validators still count
+// in the quorum: the quorum stays 2f+1 of the whole validator set, since lowering it would break the quorum
+// intersection, and the offline validators are observed locally, so the nodes could not agree on it.
+func WithInactivityTracking(window uint64) ConfigOption {
+	return func(c *Config) {
+		c.InactivityWindow = window
+	}
+}
+
+// WithProposerEviction skips in the proposer rotation, for cooldown sequences, the proposers whose proposal
+// was not committed in threshold consecutive rounds they were proposer for. Their votes still count.
+func WithProposerEviction(threshold, cooldown uint64) ConfigOption {
+	return func(c *Config) {
+		c.ProposerEvictionThreshold = threshold
+		c.ProposerEvictionCooldown = cooldown
+	}
+}
+
+// WithOnFutureMessageDropped sets the callback notified of the buffered future messages discarded,
+// e.g. because the buffer is full
+func WithOnFutureMessageDropped(callback FutureMessageDroppedCallback) ConfigOption {
+	return func(c *Config) {
+		c.OnFutureMessageDropped = callback
+	}
+}
+
+// WithMetrics reports the measurements of the state machine to the given Metrics
+func WithMetrics(metrics Metrics) ConfigOption {
+	return func(c *Config) {
+		c.Metrics = metrics
+	}
+}
+
+// WithSequenceDedup drops the repeated messages of the active sequences, keeping track of them until
+// their sequence is finalized
+func WithSequenceDedup() ConfigOption {
+	return func(c *Config) {
+		c.SequenceDedup = true
+	}
+}
+
+// WithGossipEfficiency counts the messages the node sends and the unique messages it receives per sequence
+// (see Pbft.GossipEfficiency and GossipMetrics)
+func WithGossipEfficiency() ConfigOption {
+	return func(c *Config) {
+		c.GossipEfficiency = true
+	}
+}
+
+// WithSequenceLeakDetection verifies, before every quorum check, that the prepared and committed messages
+// belong to the current sequence. The leaks are reported to the handler, or panic when it is nil.
+// It is meant for the tests and debugging, the check is skipped when disabled.
+func WithSequenceLeakDetection(handler SequenceLeakHandler) ConfigOption {
+	return func(c *Config) {
+		c.SequenceLeakDetection = true
+		c.SequenceLeakHandler = handler
+	}
+}
+
+// WithPipelineDepth enables tracking the quorum of up to depth sequences ahead of the current one,
+// so that they are finalized right after the current sequence
+func WithPipelineDepth(depth uint64) ConfigOption {
+	return func(c *Config) {
+		c.PipelineDepth = depth
+	}
+}
+
+// WithParticipationHistory sets the number of finalized sequences kept for the participation statistics
+func WithParticipationHistory(sequences int) ConfigOption {
+	return func(c *Config) {
+		c.ParticipationHistory = sequences
+	}
+}
+
+// WithStrictValidation enables the strict validation of the incoming messages view.
+// Messages without a view, with a zero sequence or with a round above maxRound are rejected.
+func WithStrictValidation(maxRound uint64) ConfigOption {
+	return func(c *Config) {
+		c.StrictValidation = true
+		c.MaxRound = maxRound
+	}
+}
+
+// WithSealFormat sets the encoding of the committed seals produced and accepted by the node
+func WithSealFormat(format SealFormat) ConfigOption {
+	return func(c *Config) {
+		c.SealFormat = format
+	}
+}
+
+// WithSealOrdering sets the order of the committed seals of the sealed proposals
+func WithSealOrdering(ordering SealOrdering) ConfigOption {
+	return func(c *Config) {
+		c.SealOrdering = ordering
+	}
+}
+
+// WithSealSelection sets the subset of the committed seals included in the sealed proposals
+func WithSealSelection(selection SealSelection) ConfigOption {
+	return func(c *Config) {
+		c.SealSelection = selection
+	}
+}
+
+// WithSealDomain sets the domain separation tag of the committed seals,
+// so that they can not be replayed into other protocols
+func WithSealDomain(domain []byte) ConfigOption {
+	return func(c *Config) {
+		c.SealDomain = append([]byte{}, domain...)
+	}
+}
+
+// WithOnFinalize sets the callback invoked for every sequence finalized by the node
+func WithOnFinalize(onFinalize FinalizeCallback) ConfigOption {
+	return func(c *Config) {
+		c.OnFinalize = onFinalize
+	}
+}
+
+// WithRoundChangeCatchUp enables jumping to a higher round as soon as round change messages
+// for it are received from more than the max faulty voting power, without waiting for the round timeout
+func WithRoundChangeCatchUp() ConfigOption {
+	return func(c *Config) {
+		c.RoundChangeCatchUp = true
+	}
+}
+
+// WithValidatorStore persists the validator set of each epoch of epochSize sequences in the given store
+func WithValidatorStore(store ValidatorStore, epochSize uint64) ConfigOption {
+	return func(c *Config) {
+		c.ValidatorStore = store
+		c.EpochSize = epochSize
+	}
+}
+
+// WithEpochManager switches the validator set with the given epoch manager at the epoch boundaries
+func WithEpochManager(manager *EpochManager) ConfigOption {
+	return func(c *Config) {
+		c.EpochManager = manager
+	}
+}
+
+// WithPhaseQuorums sets the calculators of the prepare and commit quorums (nil keeps the default quorum).
+// The commit quorum can be set higher than the prepare one to harden finalization without changing progress.
+func WithPhaseQuorums(prepareQuorum, commitQuorum QuorumCalculator) ConfigOption {
+	return func(c *Config) {
+		c.PrepareQuorum = prepareQuorum
+		c.CommitQuorum = commitQuorum
+	}
+}
+
+// WithValidationRetry retries up to retries times, delay apart, the proposal validations failing with a RetryableError
+func WithValidationRetry(retries int, delay time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.ValidationRetries = retries
+		c.ValidationRetryDelay = delay
+	}
+}
+
+// WithNilProposal enables committing the nil proposal starting from the given round,
+// so that a sequence can be finalized empty when no valid proposal exists
+func WithNilProposal(fromRound uint64) ConfigOption {
+	return func(c *Config) {
+		c.NilProposalEnabled = true
+		c.NilProposalRound = fromRound
+	}
+}
+
+// WithMinBlockTime sets the minimum time between the finalization of a sequence and the proposal of the next one
+func WithMinBlockTime(minBlockTime time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.MinBlockTime = minBlockTime
+	}
+}
+
+// WithProposalTimePolicy sets the validation of the proposal time against the time of the last finalized proposal
+func WithProposalTimePolicy(policy ProposalTimePolicy) ConfigOption {
+	return func(c *Config) {
+		c.ProposalTimePolicy = policy
+	}
+}
+
+// WithMaxValidators sets the maximum size of the validator set, the larger sets are refused
+func WithMaxValidators(maxValidators int) ConfigOption {
+	return func(c *Config) {
+		c.MaxValidators = maxValidators
+	}
+}
+
+// WithOnRoleChange sets the callback invoked when the node becomes, or stops being, the proposer
+func WithOnRoleChange(onRoleChange RoleChangeCallback) ConfigOption {
+	return func(c *Config) {
+		c.OnRoleChange = onRoleChange
+	}
+}
+
+// WithMinValidators sets the minimum size of the validator set and the handling of the smaller ones
+func WithMinValidators(minValidators int, policy SmallValidatorSetPolicy) ConfigOption {
+	return func(c *Config) {
+		c.MinValidators = minValidators
+		c.SmallValidatorSetPolicy = policy
+	}
+}
+
+// WithValidatorChangePolicy sets the policy approving the validator set updates
+func WithValidatorChangePolicy(policy ValidatorChangePolicy) ConfigOption {
+	return func(c *Config) {
+		c.ValidatorChangePolicy = policy
+	}
+}
+
+// WithHaltOnSafetyBreach halts the consensus with ErrSafetyThresholdBreached when the validators with evidence
+// of a fault (see FaultyValidators) exceed the max faulty voting power
+func WithHaltOnSafetyBreach() ConfigOption {
+	return func(c *Config) {
+		c.HaltOnSafetyBreach = true
+	}
+}
+
+// WithProposalBuilder sets the builder of the proposals of the node, in place of Backend.BuildProposal
+func WithProposalBuilder(builder ProposalBuilder) ConfigOption {
+	return func(c *Config) {
+		c.ProposalBuilder = builder
+	}
+}
+
+// WithRound0Timeout sets the timeout of the first round of a sequence, in place of the RoundTimeout of round 0.
+// The later rounds keep the RoundTimeout, so they can fail fast while the proposer has more time on round 0.
+func WithRound0Timeout(timeout time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.Round0Timeout = timeout
+	}
+}
+
+// WithStartupPeers holds back the first proposal of the node until messages from at least peers other validators
+// were received (or the peers were reported with ObservePeer), so that a proposal on a fresh cluster start
+// does not die before the peers are online
+func WithStartupPeers(peers int) ConfigOption {
+	return func(c *Config) {
+		c.StartupPeers = peers
+	}
+}
+
+// WithStartupQuorum holds back the first proposal of the node until the validators known to be online,
+// the node included, hold the quorum voting power (see WithStartupPeers)
+func WithStartupQuorum() ConfigOption {
+	return func(c *Config) {
+		c.StartupQuorum = true
+	}
+}
+
+// WithCommitTimeout sets the time allowed to collect the commit quorum once the node enters the commit phase,
+// in place of the remainder of the round timeout
+func WithCommitTimeout(timeout time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.CommitTimeout = timeout
+	}
+}
+
+// WithMaxRoundJump drops the round change messages claiming a round more than maxJump rounds ahead of the current one,
+// so that a few forged messages can not force a huge round jump
+func WithMaxRoundJump(maxJump uint64) ConfigOption {
+	return func(c *Config) {
+		c.MaxRoundJump = maxJump
+	}
+}
+
+// WithStrictProposalValidation validates every proposal before sending a prepare message, without trusting
+// the proposal the node is locked on when it proposes it again
+func WithStrictProposalValidation() ConfigOption {
+	return func(c *Config) {
+		c.StrictProposalValidation = true
+	}
+}
+
+// WithOutgoingInterceptor sets the interceptor invoked with every consensus message before it is sent
+func WithOutgoingInterceptor(interceptor OutgoingInterceptor) ConfigOption {
+	return func(c *Config) {
+		c.OutgoingInterceptor = interceptor
+	}
+}
+
+// WithRoundSelection sets the rule picking the round to fast-track to when several rounds reach the threshold
+func WithRoundSelection(selection RoundSelection) ConfigOption {
+	return func(c *Config) {
+		c.RoundSelection = selection
+	}
+}
+
+// WithEarlyCommitPolicy sets the handling of the commit messages received before the prepare quorum
+func WithEarlyCommitPolicy(policy EarlyCommitPolicy) ConfigOption {
+	return func(c *Config) {
+		c.EarlyCommitPolicy = policy
+	}
+}
+
+// WithRandSource sets the random source of the non-deterministic choices, such as the timeout jitter.
+// A seeded source makes them reproducible.
+func WithRandSource(source *rand.Rand) ConfigOption {
+	return func(c *Config) {
+		c.RandSource = source
+	}
+}
+
+// WithTimeoutJitter extends the round 0 timeout and the validation retry delay by a random amount of up to
+// fraction times their duration, so that the nodes do not time out or retry in lockstep
+func WithTimeoutJitter(fraction float64) ConfigOption {
+	return func(c *Config) {
+		c.TimeoutJitter = fraction
+	}
+}
+
+// WithBufferStore keeps the sequence dedup and the future messages buffers in the given store, they are restored
+// from it when the node is created. Use a FileBufferStore for the buffers to survive restarts.
+func WithBufferStore(store BufferStore) ConfigOption {
+	return func(c *Config) {
+		c.BufferStore = store
+	}
+}
+
+// WithParentHashVerification rejects the proposals whose ParentHash is not the hash of the last finalized proposal,
+// so that a proposer can not fork the chain. The genesis sequence is not verified (see WithGenesisSequence),
+// see Pbft.SetLastFinalizedHash to set the parent of the first sequence after a restart.
+func WithParentHashVerification() ConfigOption {
+	return func(c *Config) {
+		c.VerifyParentHash = true
+	}
+}
+
+// WithGenesisSequence makes the given sequence the explicit genesis of the chain: its proposal is accepted without
+// a parent and without checking its time against a previous proposal, while the later sequences must extend a known
+// parent (see WithParentHashVerification and Pbft.SetLastFinalizedHash) and follow the time of the last proposal
+// (see WithProposalTimePolicy)
+func WithGenesisSequence(sequence uint64) ConfigOption {
+	return func(c *Config) {
+		c.GenesisMode = true
+		c.GenesisSequence = sequence
+	}
+}
+
+// WithMaxLockedRounds releases the lock of a node that stayed locked for maxRounds rounds, once the round change
+// messages of a new round prove that the locked proposal can not reach a commit quorum anymore (zero disables it)
+func WithMaxLockedRounds(maxRounds uint64) ConfigOption {
+	return func(c *Config) {
+		c.MaxLockedRounds = maxRounds
+	}
+}
+
+// WithFinalizedStore keeps the finalized sequences replayed by FinalizedStream in the given store
+func WithFinalizedStore(store FinalizedStore) ConfigOption {
+	return func(c *Config) {
+		c.FinalizedStore = store
+	}
+}
+
+// WithSignatureAggregator treats the commit seals as the partial signatures of a threshold signature scheme,
+// combined by the aggregator into the signature carried by the finalization proof
+func WithSignatureAggregator(aggregator SignatureAggregator) ConfigOption {
+	return func(c *Config) {
+		c.SignatureAggregator = aggregator
+	}
+}
+
+// WithValidationLimiter caps, with the given limiter, the number of proposal validations running at once.
+// The limiter can be shared by several nodes.
+func WithValidationLimiter(limiter *ValidationLimiter) ConfigOption {
+	return func(c *Config) {
+		c.ValidationLimiter = limiter
+	}
+}
+
+// WithLockConflictPolicy sets the handling of a pre-prepare for a proposal different from the locked one
+func WithLockConflictPolicy(policy LockConflictPolicy) ConfigOption {
+	return func(c *Config) {
+		c.LockConflictPolicy = policy
+	}
+}
+
+// WithPrepareTieResolution sets the handling of a round in which two proposals reach the prepare quorum
+func WithPrepareTieResolution(resolution PrepareTieResolution) ConfigOption {
+	return func(c *Config) {
+		c.PrepareTieResolution = resolution
+	}
+}
+
+// WithProposalBodyMismatchPolicy sets the handling of a proposal body that does not match the pre-prepare hash
+func WithProposalBodyMismatchPolicy(policy ProposalBodyMismatchPolicy) ConfigOption {
+	return func(c *Config) {
+		c.ProposalBodyMismatchPolicy = policy
+	}
+}
+
+// WithPreparedCertificates seals the prepare messages and proves the justification of the round change messages
+// with a prepared certificate. The round change messages with a justification not proven are rejected.
+func WithPreparedCertificates() ConfigOption {
+	return func(c *Config) {
+		c.PreparedCertificates = true
+	}
+}
+
+// WithProposalHash sets the function computing the hash of the proposals out of their content (e.g. HashProposal).
+// The engine sets it on the proposals of the ProposalBuilder and verifies the hash of every received proposal with it.
+// Without it, the hash is only verified for the proposals of a ProposalBuilder, with HashProposal.
+func WithProposalHash(hash ProposalHashFunc) ConfigOption {
+	return func(c *Config) {
+		c.ProposalHash = hash
+	}
+}
+
+// WithSealHash seals the hash computed by the given function in place of the proposal hash
+func WithSealHash(sealHash SealHashFunc) ConfigOption {
+	return func(c *Config) {
+		c.SealHash = sealHash
+	}
+}
+
+// WithProofSealHash sets the function rebuilding the seal hash of a finalized proposal out of its hash, to verify
+// the finalization proofs applied by the node when the commits seal a seal hash (see WithSealHash)
+func WithProofSealHash(proofSealHash ProofSealHashFunc) ConfigOption {
+	return func(c *Config) {
+		c.ProofSealHash = proofSealHash
+	}
+}
+
+// WithRoundBoundCommitSeals binds the commit seals to the round they are cast in (see CommitSealHash)
+func WithRoundBoundCommitSeals() ConfigOption {
+	return func(c *Config) {
+		c.RoundBoundCommitSeals = true
+	}
+}
+
+// WithSyncTimeouts sets the timeout of a sync request to a peer, before trying the next one (see SyncFrom),
+// and the deadline of the whole sync. A zero duration disables the timeout.
+func WithSyncTimeouts(peerTimeout, deadline time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.SyncPeerTimeout = peerTimeout
+		c.SyncDeadline = deadline
+	}
+}
+
+// WithPreprepareRetransmission makes the proposer retransmit its Preprepare at the given interval,
+// until it observes the prepare quorum or the round times out, for the peers that missed it
+func WithPreprepareRetransmission(interval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.PreprepareRetransmitInterval = interval
+	}
+}
+
+// WithAsyncProposalValidator validates the proposals of the other nodes in the background: the node keeps
+// handling the events and sends its prepare once the validation succeeds, or starts a round change if it fails
+// or the round times out first
+func WithAsyncProposalValidator(validator AsyncProposalValidator) ConfigOption {
+	return func(c *Config) {
+		c.AsyncProposalValidator = validator
+	}
+}
+
+// WithImplicitProposerPrepare counts the Preprepare of the proposer as its Prepare, so that the proposer
+// does not send a Prepare message
+func WithImplicitProposerPrepare() ConfigOption {
+	return func(c *Config) {
+		c.ImplicitProposerPrepare = true
+	}
+}
+
+// WithCombinedVotes sends the Prepare and the Commit of the node in a single PrepareCommit message when the prepare
+// messages received before the proposal already reach the prepare quorum with the Prepare of the node, so that
+// the Commit would follow the Prepare right away. The node sends separate messages otherwise.
+func WithCombinedVotes() ConfigOption {
+	return func(c *Config) {
+		c.CombinedVotes = true
+	}
+}
+
+// WithEvidenceRetention bounds the equivocation evidence retained by the node (see DoubleProposals, WrongProposers and CrossPhaseEquivocations)
+// to limit proofs of each kind, recorded in the last maxAge sequences. Over the limit, the oldest proofs are dropped
+// and the ones of the current sequence are dropped last. Zero disables the respective bound.
+func WithEvidenceRetention(limit int, maxAge uint64) ConfigOption {
+	return func(c *Config) {
+		c.EvidenceLimit = limit
+		c.EvidenceMaxAge = maxAge
+	}
+}
+
+// WithCommitRoundPolicy sets the handling of the commit messages of the current sequence sent in an earlier round
+func WithCommitRoundPolicy(policy CommitRoundPolicy) ConfigOption {
+	return func(c *Config) {
+		c.CommitRoundPolicy = policy
+	}
+}
+
+// WithZeroVotingPowerPolicy sets the handling of a validator set whose total voting power is zero
+func WithZeroVotingPowerPolicy(policy ZeroVotingPowerPolicy) ConfigOption {
+	return func(c *Config) {
+		c.ZeroVotingPowerPolicy = policy
+	}
+}
+
+// WithConflictingJustificationPolicy sets the handling of a node sending round change messages for the same round
+// with different justifications
+func WithConflictingJustificationPolicy(policy ConflictingJustificationPolicy) ConfigOption {
+	return func(c *Config) {
+		c.ConflictingJustificationPolicy = policy
+	}
+}
+
+// WithSelfMessagePolicy sets the handling of the echoes of the node messages received from the transport
+func WithSelfMessagePolicy(policy SelfMessagePolicy) ConfigOption {
+	return func(c *Config) {
+		c.SelfMessagePolicy = policy
+	}
+}
+
 type Config struct {
 	// ProposalTimeout is the time to wait for the proposal
 	// from the validator. It defaults to Timeout
@@ -72,16 +727,382 @@ type Config struct {
 	Notifier StateNotifier
 
 	StatsCallback StatsCallback
+
+	// ProposalHandlers are the application callbacks used to validate and insert proposals of a given type.
+	// Proposals whose type has no registered handler are dispatched to the Backend.
+	ProposalHandlers map[ProposalType]ProposalHandler
+
+	// Clock is the time source used by the time based features (health checks...)
+	Clock Clock
+
+	// Scheduler feeds the run loop with the messages, timeouts and commands to process
+	Scheduler Scheduler
+
+	// HealthThreshold is the maximum time the node can stay in the same view without reaching quorum
+	// before it is reported as unhealthy. Zero disables the check.
+	HealthThreshold time.Duration
+
+	// MaxClockSkew is the maximum time a peer timestamp (i.e. Proposal.Time) can be ahead of the local clock.
+	// Timestamps beyond it are rejected.
+	MaxClockSkew time.Duration
+
+	// MaxMessageAge is the maximum time a message proposal (i.e. Proposal.Time) can be behind the local clock.
+	// Older messages are dropped, the messages without a proposal time are not checked. Zero disables the check.
+	MaxMessageAge time.Duration
+
+	// ProposerSelector calculates the proposer for each round
+	ProposerSelector ProposerSelector
+
+	// MaxFutureMessagesPerSequence is the maximum number of buffered messages for a single future sequence
+	MaxFutureMessagesPerSequence int
+
+	// MaxFutureMessages is the maximum number of buffered messages for all the future sequences
+	MaxFutureMessages int
+
+	// InboundQueueSize is the capacity of the queue of the messages received from the transport.
+	// When the queue is full, lower priority messages (Preprepare, then Prepare) are dropped first.
+	// Zero disables the bound.
+	InboundQueueSize int
+
+	// ViewSyncInterval is the interval of the view sync broadcasts. Zero disables them.
+	ViewSyncInterval time.Duration
+
+	// SequenceDeadline is the maximum time spent on a sequence, across its rounds, before the node syncs.
+	// Zero disables the deadline.
+	SequenceDeadline time.Duration
+
+	// FinalizationSink, when set, receives the finalized sequences (see WithFinalizationSink)
+	FinalizationSink FinalizationSink
+
+	// SinkRetryBackoff is the delay before the first retry of a failed delivery to the FinalizationSink
+	SinkRetryBackoff time.Duration
+
+	// SinkMaxRetryBackoff bounds the delay between the retries of a failed delivery. Zero is unbounded.
+	SinkMaxRetryBackoff time.Duration
+
+	// MaxFuturePreprepares is the maximum number of buffered pre-prepare messages for the future rounds of the
+	// current sequence. Zero is unbounded.
+	MaxFuturePreprepares int
+
+	// DropLogRateLimit is the number of logs of the dropped messages per second and drop reason. Zero is unlimited.
+	DropLogRateLimit int
+
+	// OrphanPrepareLimit is the number of prepare messages for an unknown proposal buffered until the pre-prepare
+	// of the proposal arrives. Zero disables the buffer and such messages are dropped.
+	OrphanPrepareLimit int
+
+	// MemoryBudget is the approximate memory, in bytes, the messages held by the node may use.
+	// When exceeded, the buffered messages are shed (see EstimatedMemoryUsage). Zero disables the cap.
+	MemoryBudget int
+
+	// OnFutureMessageDropped is notified of the buffered future messages discarded to respect the bounds of
+	// the buffer or the MemoryBudget. It is invoked from its own goroutine, so that it never blocks the ingestion
+	// of the messages, and the drops beyond a backlog of 1024 are not reported.
+	OnFutureMessageDropped FutureMessageDroppedCallback
+
+	// InactivityWindow is the number of sequences without messages after which a validator is reported offline.
+	// Zero disables the tracking.
+	InactivityWindow uint64
+
+	// ProposerEvictionThreshold is the number of consecutive rounds without committing its proposal after which
+	// a proposer is skipped in the proposer rotation. The failures are counted out of the rounds of the finalized
+	// sequences, so that all the nodes agree on the evicted proposers. Zero disables the eviction.
+	ProposerEvictionThreshold uint64
+
+	// ProposerEvictionCooldown is the number of sequences an evicted proposer is skipped for
+	ProposerEvictionCooldown uint64
+
+	// Metrics receives the measurements of the state machine (state transitions, round durations,
+	// dropped messages and quorum latencies). They are discarded by default.
+	Metrics Metrics
+
+	// SequenceDedup drops the messages already received for the active sequences.
+	// The messages of a sequence are forgotten once it is finalized.
+	SequenceDedup bool
+
+	// GossipEfficiency counts the messages sent and the unique messages received per sequence. Disabled by default.
+	GossipEfficiency bool
+
+	// SequenceLeakDetection verifies that only the messages of the current sequence are counted toward its quorums
+	SequenceLeakDetection bool
+
+	// SequenceLeakHandler receives the detected leaks. When nil, a leak panics.
+	SequenceLeakHandler SequenceLeakHandler
+
+	// PipelineDepth is the number of sequences ahead of the current one whose messages are tracked
+	// with independent quorums. Zero disables pipelining.
+	PipelineDepth uint64
+
+	// ParticipationHistory is the maximum number of finalized sequences kept for the participation statistics
+	ParticipationHistory int
+
+	// StrictValidation rejects the incoming messages with a missing or malformed view.
+	// When disabled (the default), such messages are handled as before for compatibility.
+	StrictValidation bool
+
+	// MaxRound is the highest round accepted when StrictValidation is enabled
+	MaxRound uint64
+
+	// SealFormat is the encoding of the committed seals. The seals produced by the SignKey are encoded
+	// in this format and the received ones are rejected if not encoded in it.
+	// It defaults to SealFormat_Native, which keeps the seals as produced by the SignKey.
+	SealFormat SealFormat
+
+	// SealOrdering is the order of the committed seals of the sealed proposals (by NodeID by default)
+	SealOrdering SealOrdering
+
+	// SealSelection is the subset of the committed seals of the sealed proposals (all of them by default)
+	SealSelection SealSelection
+
+	// SealDomain is the domain separation tag of the committed seals, prepended to the proposal hash
+	// before signing and verifying (see SignableContent). Empty by default, namely the bare hash is signed.
+	SealDomain []byte
+
+	// OnFinalize is invoked synchronously for every sequence finalized by the node, in strictly ascending order.
+	// While it returns an error, the node retries the delivery and does not advance to the next sequence.
+	OnFinalize FinalizeCallback
+
+	// RoundChangeCatchUp enables the f+1 rule outside of the round change state: when the round change messages
+	// for a higher round reach more than the max faulty voting power, the node moves to that round immediately
+	RoundChangeCatchUp bool
+
+	// ValidatorStore persists the validator set of each epoch. When set, the validator set of an epoch is loaded
+	// from the store on startup (or saved from the backend if missing) and kept for the whole epoch.
+	ValidatorStore ValidatorStore
+
+	// EpochSize is the number of sequences of an epoch. Zero means a single epoch.
+	EpochSize uint64
+
+	// EpochManager switches the validator set at the epoch boundaries, in place of the one provided by the backend.
+	// It is independent from EpochSize, which only sets the epochs of the ValidatorStore.
+	EpochManager *EpochManager
+
+	// PrepareQuorum calculates the voting power of prepare messages needed to lock and commit.
+	// It defaults to the quorum size.
+	PrepareQuorum QuorumCalculator
+
+	// CommitQuorum calculates the voting power of commit messages needed to finalize.
+	// It defaults to the quorum size and can not be lower than the prepare quorum.
+	CommitQuorum QuorumCalculator
+
+	// ValidationRetries is the number of times a proposal validation failing with a RetryableError is retried
+	// before starting a round change. The retries should fit in the round timeout.
+	ValidationRetries int
+
+	// ValidationRetryDelay is the delay between the retries of a proposal validation
+	ValidationRetryDelay time.Duration
+
+	// NilProposalEnabled enables committing the nil proposal to make progress during proposer failures
+	NilProposalEnabled bool
+
+	// NilProposalRound is the first round in which the nil proposal is proposed and accepted
+	NilProposalRound uint64
+
+	// MinBlockTime is the minimum time, measured with the Clock, the proposer waits after the previous sequence
+	// is finalized before proposing. It bounds the block rate and only applies to the first round of a sequence.
+	MinBlockTime time.Duration
+
+	// ProposalTimePolicy is the validation of the proposal time against the time of the last finalized proposal.
+	// It defaults to ProposalTime_Unchecked.
+	ProposalTimePolicy ProposalTimePolicy
+
+	// OnRoleChange is invoked synchronously, at the start of a round, when the node becomes or stops being
+	// the proposer as chosen by the ProposerSelector
+	OnRoleChange RoleChangeCallback
+
+	// MinValidators is the minimum size of the validator set, checked at every validator set update.
+	// It defaults to 4, the smallest set tolerating a faulty validator.
+	MinValidators int
+
+	// SmallValidatorSetPolicy is the handling of a validator set smaller than MinValidators.
+	// It defaults to SmallValidatorSet_Degraded.
+	SmallValidatorSetPolicy SmallValidatorSetPolicy
+
+	// MaxValidators is the maximum size of the validator set, checked at every validator set update before the set
+	// is applied. The message count grows quadratically with the set size. Zero leaves the size unbounded.
+	MaxValidators int
+
+	// ValidatorChangePolicy approves the validator set updates. When it rejects one, SetBackend returns
+	// the error and the current validator set is kept.
+	ValidatorChangePolicy ValidatorChangePolicy
+
+	// HaltOnSafetyBreach halts the consensus when the faulty validators exceed the max faulty voting power,
+	// instead of stalling. Once halted, Run returns and SetBackend fails with ErrSafetyThresholdBreached.
+	HaltOnSafetyBreach bool
+
+	// ProposalBuilder builds the proposals of the node when it is the proposer.
+	// When nil, the proposals are built by Backend.BuildProposal.
+	ProposalBuilder ProposalBuilder
+
+	// Round0Timeout is the timeout of the first round of a sequence (zero uses RoundTimeout for every round)
+	Round0Timeout time.Duration
+
+	// StartupPeers is the number of other validators that must be online before the node first proposes (zero disables the wait)
+	StartupPeers int
+
+	// StartupQuorum holds back the first proposal until the validators online hold the quorum voting power
+	StartupQuorum bool
+
+	// CommitTimeout is the time allowed to collect the commit quorum once the node reached the prepare quorum
+	// and sent its commit message. It replaces the remainder of the round timeout, its expiry starts
+	// a round change. Zero keeps the round timeout.
+	CommitTimeout time.Duration
+
+	// MaxRoundJump is the highest number of rounds a round change message can be ahead of the current round (zero disables the check)
+	MaxRoundJump uint64
+
+	// StrictProposalValidation validates the locked proposal too before the node proposes it again
+	StrictProposalValidation bool
+
+	// OutgoingInterceptor is invoked with every consensus message before it is sent, it can replace or suppress it
+	OutgoingInterceptor OutgoingInterceptor
+
+	// RoundSelection is the rule picking the round to fast-track to, and whose justification wins, when several rounds
+	// reach the fast-track threshold. It defaults to RoundSelection_Highest.
+	RoundSelection RoundSelection
+
+	// EarlyCommitPolicy is the handling of the commit messages received before the prepare quorum.
+	// It defaults to EarlyCommit_Count, which counts them right away.
+	EarlyCommitPolicy EarlyCommitPolicy
+
+	// RandSource is the random source of the non-deterministic choices. It defaults to a source seeded from crypto/rand.
+	// It is used from the consensus loop only, it must not be shared with other nodes.
+	RandSource *rand.Rand
+
+	// TimeoutJitter is the highest fraction of the round 0 timeout and of the validation retry delay added to them
+	// at random (zero disables the jitter)
+	TimeoutJitter float64
+
+	// BufferStore keeps the sequence dedup and the future messages buffers, trimmed as the sequences are finalized.
+	// It defaults to a MemoryBufferStore, which does not survive restarts.
+	BufferStore BufferStore
+
+	// VerifyParentHash rejects the proposals whose ParentHash is not the hash of the last finalized proposal
+	VerifyParentHash bool
+
+	// GenesisMode relaxes the parent and the proposal time checks in GenesisSequence only, and requires a known parent
+	// in the later sequences. Without it, the checks are skipped while the last finalized proposal is not known.
+	GenesisMode bool
+
+	// GenesisSequence is the first sequence of the chain, whose proposal has no parent (only with GenesisMode)
+	GenesisSequence uint64
+
+	// MaxLockedRounds is the number of rounds after which a locked node releases its lock, if the round change messages
+	// prove that the locked proposal can not reach a commit quorum (zero keeps the lock until the sequence ends)
+	MaxLockedRounds uint64
+
+	// FinalizedStore keeps the finalized sequences replayed by FinalizedStream.
+	// It defaults to a MemoryFinalizedStore keeping the last 64 finalized sequences.
+	FinalizedStore FinalizedStore
+
+	// SignatureAggregator, when set, verifies the commit seals as partial signatures and combines them into
+	// the aggregated signature of the finalization proof. Disabled by default.
+	SignatureAggregator SignatureAggregator
+
+	// ValidationLimiter, when set, caps the number of proposal validations running at once. Unlimited by default.
+	ValidationLimiter *ValidationLimiter
+
+	// LockConflictPolicy is the handling of a pre-prepare for a proposal different from the locked one.
+	// It defaults to LockConflict_Ignore.
+	LockConflictPolicy LockConflictPolicy
+
+	// PrepareTieResolution is the handling of a round in which two proposals reach the prepare quorum.
+	// It defaults to PrepareTie_RoundChange.
+	PrepareTieResolution PrepareTieResolution
+
+	// ProposalBodyMismatchPolicy is the handling of a requested proposal body that does not match the pre-prepare hash.
+	// It defaults to BodyMismatch_Drop.
+	ProposalBodyMismatchPolicy ProposalBodyMismatchPolicy
+
+	// PreparedCertificates enables the prepared certificates carried by the round change justifications.
+	// Disabled by default.
+	PreparedCertificates bool
+
+	// ProposalHash computes the hash of the proposals out of their content, to verify the received proposals.
+	// It defaults to HashProposal with a ProposalBuilder, otherwise the backend verifies the hash.
+	ProposalHash ProposalHashFunc
+
+	// SealHash computes the hash sealed by the commit seals of a proposal. It defaults to the proposal hash.
+	SealHash SealHashFunc
+
+	// ProofSealHash rebuilds the seal hash of a finalized proposal out of its hash, to verify the finalization proofs
+	// applied by the node. Without it, the proofs of a node with a SealHash are rejected.
+	ProofSealHash ProofSealHashFunc
+
+	// RoundBoundCommitSeals seals the seal hash along with the round of the commit (see CommitSealHash), so that the
+	// commit seals of a round are rejected in another one. It can not be combined with CommitRound_Lenient.
+	RoundBoundCommitSeals bool
+
+	// SyncPeerTimeout is the time to wait for a peer to serve a sync request before trying the next one.
+	// It defaults to Timeout, zero waits for the SyncDeadline.
+	SyncPeerTimeout time.Duration
+
+	// SyncDeadline is the time to wait for the peers to serve a sync request, zero disables the deadline
+	SyncDeadline time.Duration
+
+	// PreprepareRetransmitInterval is the interval of the retransmissions of the Preprepare by the proposer,
+	// zero disables them
+	PreprepareRetransmitInterval time.Duration
+
+	// AsyncProposalValidator validates the proposals of the other nodes without blocking the state machine,
+	// in place of the backend and the proposal handlers. The nil proposals are still validated by the node.
+	AsyncProposalValidator AsyncProposalValidator
+
+	// ImplicitProposerPrepare counts the Preprepare of the proposer as its Prepare. With the prepared certificates,
+	// the proposer still sends its Prepare, since the certificates need its prepare seal.
+	ImplicitProposerPrepare bool
+
+	// CombinedVotes sends the Prepare and the Commit in a single message when the prepare quorum is already reached
+	CombinedVotes bool
+
+	// EvidenceLimit is the maximum number of equivocation proofs of each kind retained. Zero is unbounded.
+	EvidenceLimit int
+
+	// EvidenceMaxAge is the number of sequences the equivocation proofs are retained for. Zero is unbounded.
+	EvidenceMaxAge uint64
+
+	// CommitRoundPolicy is the handling of the commit messages of the current sequence sent in an earlier round.
+	// It defaults to CommitRound_Strict, which only counts the commit messages of the current round.
+	CommitRoundPolicy CommitRoundPolicy
+
+	// ZeroVotingPowerPolicy is the handling of a validator set, or a voting power update, whose total voting power
+	// is zero. It defaults to ZeroVotingPower_Error, which refuses it.
+	ZeroVotingPowerPolicy ZeroVotingPowerPolicy
+
+	// ConflictingJustificationPolicy is the handling of a node sending round change messages for the same round
+	// with different justifications. It defaults to ConflictingJustification_KeepFirst.
+	ConflictingJustificationPolicy ConflictingJustificationPolicy
+
+	// SelfMessagePolicy is the handling of the messages received from the transport with the NodeID of the node.
+	// It defaults to SelfMessage_Accept, which processes them like any other message.
+	SelfMessagePolicy SelfMessagePolicy
 }
 
 func DefaultConfig() *Config {
 	return &Config{
-		Timeout:         defaultTimeout,
-		ProposalTimeout: defaultTimeout,
-		Logger:          log.New(os.Stderr, "", log.LstdFlags),
-		Tracer:          trace.NewNoopTracerProvider().Tracer(""),
-		RoundTimeout:    exponentialTimeout,
-		Notifier:        &DefaultStateNotifier{},
+		Timeout:          defaultTimeout,
+		ProposalTimeout:  defaultTimeout,
+		Logger:           log.New(os.Stderr, "", log.LstdFlags),
+		Tracer:           trace.NewNoopTracerProvider().Tracer(""),
+		RoundTimeout:     exponentialTimeout,
+		Notifier:         &DefaultStateNotifier{},
+		Clock:            realClock{},
+		Scheduler:        realScheduler{},
+		Metrics:          noopMetrics{},
+		HealthThreshold:  defaultHealthThreshold,
+		MaxClockSkew:     defaultMaxClockSkew,
+		SyncPeerTimeout:  defaultTimeout,
+		ProposerSelector: ValidatorSetProposerSelector{},
+		RandSource:       newRandSource(),
+		BufferStore:      NewMemoryBufferStore(defaultMaxFutureMessages),
+		FinalizedStore:   NewMemoryFinalizedStore(defaultFinalizedHistory),
+
+		MaxFutureMessagesPerSequence: defaultMaxFutureMessagesPerSequence,
+		MaxFutureMessages:            defaultMaxFutureMessages,
+		MaxFuturePreprepares:         defaultMaxFuturePreprepares,
+		ParticipationHistory:         defaultParticipationHistory,
+		MaxRound:                     defaultMaxRound,
+		MinValidators:                defaultMinValidators,
 	}
 }
 
@@ -91,6 +1112,20 @@ func (c *Config) ApplyOps(opts ...ConfigOption) {
 	}
 }
 
+// roundTimeout returns the RoundTimeout of the config, with the Round0Timeout for the first round if set
+func (c *Config) roundTimeout() RoundTimeout {
+	if c.Round0Timeout <= 0 {
+		return c.RoundTimeout
+	}
+	laterRoundTimeout := c.RoundTimeout
+	return func(round uint64) <-chan time.Time {
+		if round == 0 {
+			return time.NewTimer(c.jitter(c.Round0Timeout)).C
+		}
+		return laterRoundTimeout(round)
+	}
+}
+
 // exponentialTimeout is the default RoundTimeout function
 func exponentialTimeout(round uint64) <-chan time.Time {
 	return time.NewTimer(exponentialTimeoutDuration(round)).C
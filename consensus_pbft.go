@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -60,6 +61,9 @@ type SealedProposal struct {
 	CommittedSeals []CommittedSeal
 	Proposer       NodeID
 	Number         uint64
+
+	// Round is the round the proposal was committed in, sealed by the commits with WithRoundBoundCommitSeals
+	Round uint64
 }
 
 // RoundInfo is the information about the round
@@ -93,6 +97,9 @@ type Pbft struct {
 	// msgQueue is a queue that stores all the incomming gossip messages
 	msgQueue *msgQueue
 
+	// futureMsgs is a bounded buffer for the messages of future sequences
+	futureMsgs *futureMessages
+
 	// updateCh is a channel used to notify when a new gossip message arrives
 	updateCh chan struct{}
 
@@ -110,6 +117,159 @@ type Pbft struct {
 
 	// stats encapsulates logic for statistics reporting
 	stats *stats.Stats
+
+	// syncMsgs buffers the messages received while the node is syncing
+	syncMsgs *syncMessages
+
+	// health tracks the progress made by the node
+	health *healthTracker
+
+	// penalties tracks the proposers that failed to get their proposal committed
+	penalties *proposerPenalties
+
+	// eviction skips the repeatedly failing proposers in the proposer rotation (nil if disabled)
+	eviction *proposerEviction
+
+	// proposerSeed is the per-sequence seed provided to the ProposerSelector
+	proposerSeed []byte
+
+	// proposerScores are the per-sequence performance scores provided to a ScoredProposerSelector
+	proposerScores map[NodeID]uint64
+
+	// finalizationProof is the proof of the last finalized sequence
+	finalizationProof *FinalizationProof
+
+	// paused signals whether the node participation in consensus is suspended
+	paused uint64
+
+	// roundChangeReason is the RoundChangeReason of the last round change
+	roundChangeReason uint32
+
+	// roundChangeHistory keeps the most recent round changes (see DumpState)
+	roundChangeHistory roundChangeHistory
+
+	// preparedCertificate proves the prepare quorum of the locked proposal (see WithPreparedCertificates)
+	preparedCertificate *PreparedCertificate
+
+	// doubleProposals detects proposers sending different proposals for the same view
+	doubleProposals *doubleProposalDetector
+
+	// inbound is the bounded queue of the messages received from the transport (nil if unbounded)
+	inbound *inboundQueue
+
+	// pipeline tracks the quorum of the sequences ahead of the current one (nil if pipelining is disabled)
+	pipeline *pipeline
+
+	// participation tracks the validators participation in the finalized sequences
+	participation *participationTracker
+
+	// justificationConflicts keeps the evidences of conflicting round change justifications
+	justificationConflicts justificationConflicts
+
+	// crossPhase detects the validators sending a Prepare and a Commit message for different proposals
+	crossPhase *crossPhaseDetector
+
+	// startup holds back the first proposal until enough peers are online (nil if disabled)
+	startup *startupGate
+
+	// viewSync keeps the views advertised by the validators with view sync messages
+	viewSync *viewSyncTracker
+
+	// viewSyncBroadcaster marks the periodic view sync message as due (nil if not running)
+	viewSyncBroadcaster *viewSyncBroadcaster
+
+	// sink delivers the finalized sequences to the FinalizationSink (nil if not configured)
+	sink *finalizationSinkWorker
+
+	// dropLog limits the logs of the dropped messages (nil if not limited)
+	dropLog *dropLog
+
+	// combinedCommit is whether the commit message of the node was sent with its prepare message in the current round
+	combinedCommit bool
+
+	// validatorCache keeps the validator sets looked up by ValidatorsForSequence
+	validatorCache *validatorSetCache
+
+	// epochSet is the validator set of the epoch epochSetEpoch (only with a ValidatorStore)
+	epochSet      ValidatorSet
+	epochSetEpoch uint64
+
+	// lastNotified is the last sequence delivered to the OnFinalize callback
+	lastNotified uint64
+
+	// liveness tracks the last sequence each validator sent a message in (nil if the exclusion is disabled)
+	liveness *livenessTracker
+
+	// inactive are the validators considered offline in the current sequence
+	inactive map[NodeID]struct{}
+
+	// relay keeps the proposals of the current sequence to serve them to the peers missing them
+	relay *proposalRelay
+
+	// bodyRequests are the peers asked for the proposal body of the pending pre-prepare
+	bodyRequests map[NodeID]struct{}
+
+	// roundStart is the time the current round started (zero once it ended)
+	roundStart time.Time
+
+	// lastFinalized is the time the last sequence was finalized (zero before the first one)
+	lastFinalized time.Time
+
+	// sequenceEntered is the time the node moved to the current sequence (see SequenceDeadline)
+	sequenceEntered time.Time
+
+	// sequenceStart is the time the first proposal of the current sequence was built or received (zero before)
+	sequenceStart time.Time
+
+	// lastSequenceLatency is the time from the first proposal to the commit quorum of the last committed sequence
+	lastSequenceLatency int64
+
+	// haltErr is the reason why the consensus halted (see HaltOnSafetyBreach), guarded by haltLock
+	haltErr  error
+	haltLock sync.Mutex
+
+	// degraded is whether the validator set is smaller than the configured minimum
+	degraded bool
+
+	// isProposer is whether the node was the proposer of the last view it entered
+	isProposer bool
+
+	// lastProposalTime is the time of the last finalized proposal, excluding the nil ones (zero before the first one)
+	lastProposalTime time.Time
+
+	// finalizedFeed wakes up the streams of the finalized sequences (see FinalizedStream)
+	finalizedFeed *finalizedFeed
+
+	// events delivers the events of the state machine to the subscribers (see SubscribeEvents)
+	events *eventStream
+
+	// syncPeers tracks the peers failing the sync requests (see SyncFrom)
+	syncPeers *syncPeers
+
+	// retransmitter retransmits the Preprepare of the proposer in the validate state (nil if not running)
+	retransmitter *preprepareRetransmitter
+
+	// validation is the proposal validation running in the background in the accept state (nil if none)
+	validation *asyncValidation
+
+	// lastFinalizedHash is the hash of the last finalized proposal, excluding the nil ones (nil if not known)
+	lastFinalizedHash []byte
+
+	// partials collects the partial signatures of the proposal hash when a SignatureAggregator is configured
+	partials *PartialAggregator
+
+	// dedup drops the messages already received for the active sequences (nil if disabled)
+	dedup *sequenceDedup
+
+	// gossipCounter counts the messages sent and received per active sequence (nil if disabled)
+	gossipCounter *gossipCounter
+
+	// selfConfirmations is the number of echoes of the node messages received with the SelfMessage_Confirm policy
+	selfConfirmations uint64
+
+	// proposerOverride forces the proposer of a view. It is unexported on purpose,
+	// so that it can only be set by the package tests and never in production.
+	proposerOverride func(view *View) NodeID
 }
 
 // New creates a new instance of the PBFT state machine
@@ -118,18 +278,74 @@ func New(validator SignKey, transport Transport, opts ...ConfigOption) *Pbft {
 	config.ApplyOps(opts...)
 
 	p := &Pbft{
-		validator:    validator,
-		state:        newState(),
-		transport:    transport,
-		msgQueue:     newMsgQueue(),
-		updateCh:     make(chan struct{}, 1), //hack. There is a bug when you have several messages pushed on the same time.
-		config:       config,
-		logger:       config.Logger,
-		tracer:       config.Tracer,
-		roundTimeout: config.RoundTimeout,
-		notifier:     config.Notifier,
-		stats:        stats.NewStats(),
+		validator:       validator,
+		state:           newState(),
+		transport:       transport,
+		msgQueue:        newMsgQueue(),
+		futureMsgs:      newFutureMessages(config.MaxFutureMessagesPerSequence, config.MaxFutureMessages),
+		syncMsgs:        newSyncMessages(config.MaxFutureMessages),
+		updateCh:        make(chan struct{}, 1), //hack. There is a bug when you have several messages pushed on the same time.
+		config:          config,
+		logger:          config.Logger,
+		tracer:          config.Tracer,
+		roundTimeout:    config.roundTimeout(),
+		notifier:        config.Notifier,
+		stats:           stats.NewStats(),
+		health:          &healthTracker{},
+		validatorCache:  newValidatorSetCache(),
+		penalties:       newProposerPenalties(),
+		doubleProposals: newDoubleProposalDetector(config.EvidenceLimit, config.EvidenceMaxAge),
+		crossPhase:      newCrossPhaseDetector(config.EvidenceLimit, config.EvidenceMaxAge),
+		participation:   newParticipationTracker(config.ParticipationHistory),
+		relay:           &proposalRelay{},
+		finalizedFeed:   newFinalizedFeed(),
+		events:          newEventStream(),
+		syncPeers:       newSyncPeers(),
+		viewSync:        newViewSyncTracker(),
+	}
+
+	p.state.selfID = validator.NodeID()
+
+	if config.InboundQueueSize > 0 {
+		p.inbound = newInboundQueue(config.InboundQueueSize)
+	}
+	if config.PipelineDepth > 0 {
+		p.pipeline = newPipeline(config.PipelineDepth)
+		p.pipeline.sealHash = p.SealHash
+		if config.RoundBoundCommitSeals {
+			p.pipeline.roundSealHash = CommitSealHash
+		}
+		p.pipeline.verifyHash = p.verifyProposalHash
+	}
+	if config.InactivityWindow > 0 {
+		p.liveness = newLivenessTracker(config.InactivityWindow)
+	}
+	if config.SequenceDedup {
+		p.dedup = newSequenceDedup()
 	}
+	if config.GossipEfficiency {
+		p.gossipCounter = newGossipCounter()
+		p.transport = &countingTransport{Transport: transport, counter: p.gossipCounter}
+	}
+	if config.StartupPeers > 0 || config.StartupQuorum {
+		p.startup = newStartupGate()
+	}
+	if config.FinalizationSink != nil {
+		p.sink = newFinalizationSinkWorker(config.FinalizationSink, config.SinkRetryBackoff, config.SinkMaxRetryBackoff, p.logger)
+	}
+	if config.DropLogRateLimit > 0 {
+		p.dropLog = newDropLog(config.DropLogRateLimit)
+	}
+	if config.OnFutureMessageDropped != nil {
+		p.futureMsgs.onDrop = newFutureDropNotifier(config.OnFutureMessageDropped).notify
+	}
+	if config.ProposerEvictionThreshold > 0 {
+		p.eviction = newProposerEviction(config.ProposerEvictionThreshold, config.ProposerEvictionCooldown)
+	}
+	if config.OrphanPrepareLimit > 0 {
+		p.state.orphans = newOrphanPrepares(config.OrphanPrepareLimit)
+	}
+	p.restoreBuffers()
 
 	p.logger.Printf("[INFO] validator key: addr=%s\n", p.validator.NodeID())
 	return p
@@ -142,14 +358,62 @@ func (p *Pbft) SetBackend(backend Backend) error {
 	p.setSequence(p.backend.Height())
 
 	// set the current set of validators
-	p.state.validators = p.backend.ValidatorSet()
+	validators := p.backend.ValidatorSet()
+	if p.config.EpochManager != nil {
+		var err error
+		if validators, err = p.config.EpochManager.validatorSet(p.state.view.Sequence, validators); err != nil {
+			return err
+		}
+	}
+	validators, err := p.epochValidators(p.state.view.Sequence, validators)
+	if err != nil {
+		return err
+	}
+	validators = p.equalWeightValidators(validators)
+	if err := p.checkMaxValidators(validators); err != nil {
+		return err
+	}
+	if err := p.approveValidatorChange(validators); err != nil {
+		return err
+	}
+	previous := p.state.validators
+	p.state.validators = validators
+	p.state.retainValidators(previous)
+	if previous != nil && !sameValidators(previous, validators) {
+		p.validatorCache.invalidate(p.validatorsEpoch(p.state.view.Sequence))
+	}
+
+	// set the seed for the proposer rotation of this sequence
+	p.proposerSeed = nil
+	if seedBackend, ok := backend.(ProposerSeedBackend); ok {
+		p.proposerSeed = seedBackend.ProposerSeed()
+	}
+	p.proposerScores = nil
+	if scoresBackend, ok := backend.(ProposerScoresBackend); ok {
+		p.proposerScores = scoresBackend.ProposerScores()
+	}
+
+	// track the proposals and the votes of the new sequence
+	p.resetDoubleProposalDetector()
+	p.crossPhase.reset(p.state.view.Sequence, p.state.validators)
 
 	// initialize voting info
 	if err := p.state.initializeVotingInfo(); err != nil {
 		return err
 	}
+	if err := p.checkValidatorSetSize(); err != nil {
+		return err
+	}
+	p.inactive = nil
+	if p.liveness != nil {
+		p.liveness.observe(p.validator.NodeID(), p.state.view.Sequence)
+		p.inactive = p.liveness.inactive(p.state.validators, p.state.view.Sequence)
+	}
+	if err := p.state.initializePhaseQuorums(p.config.PrepareQuorum, p.config.CommitQuorum); err != nil {
+		return err
+	}
 
-	return nil
+	return p.checkSafetyThreshold()
 }
 
 // Run starts the PBFT consensus state machine
@@ -160,8 +424,11 @@ func (p *Pbft) Run(ctx context.Context) {
 	spanCtx, span := p.tracer.Start(context.Background(), fmt.Sprintf("Sequence-%d", p.state.view.Sequence))
 	defer span.End()
 
+	stopViewSync := p.startViewSync()
+	defer stopViewSync()
+
 	// loop until we reach the a finish state
-	for p.getState() != DoneState && p.getState() != SyncState {
+	for p.getState() != DoneState && p.getState() != SyncState && p.Halted() == nil {
 		select {
 		case <-ctx.Done():
 			return
@@ -182,6 +449,11 @@ func (p *Pbft) SetInitialState(ctx context.Context) {
 	// the iteration always starts with the AcceptState.
 	// AcceptState stages will reset the rest of the message queues.
 	p.setState(AcceptState)
+
+	// process the messages received while syncing, the ones of the synced sequences are discarded
+	for _, msg := range p.syncMsgs.drain(p.state.view.Sequence) {
+		p.PushMessageInternal(msg)
+	}
 }
 
 func (p *Pbft) emitStats() {
@@ -226,15 +498,32 @@ func (p *Pbft) runCycle(ctx context.Context) {
 }
 
 func (p *Pbft) setSequence(sequence uint64) {
-	p.state.view = &View{
-		Sequence: sequence,
+	if p.state.view != nil {
+		p.endRound()
 	}
+	p.state.resetForSequence(sequence)
+	p.partials = nil
+	p.preparedCertificate = nil
+	p.sequenceStart = time.Time{}
+	p.sequenceEntered = p.config.Clock.Now()
 	p.setRound(0)
-	p.state.unlock()
+
+	if p.pipeline != nil {
+		p.pipeline.advance(sequence)
+	}
+
+	// flush the buffered messages of the new sequence into the message queue
+	for _, msg := range p.futureMsgs.advance(sequence) {
+		p.PushMessageInternal(msg)
+	}
 }
 
 func (p *Pbft) setRound(round uint64) {
+	p.penalizeRoundProposer(round)
+	p.endRound()
 	p.state.SetCurrentRound(round)
+	p.startRound()
+	p.markProgress()
 
 	// reset current timeout and start a new one
 	p.state.timeoutChan = p.roundTimeout(round)
@@ -261,9 +550,10 @@ func (p *Pbft) runAcceptState(ctx context.Context) { // start new round
 
 	// reset round messages
 	p.state.resetRoundMsgs()
-	p.state.CalcProposer()
+	p.state.proposer = p.calcProposer(p.state.GetCurrentRound())
 
 	isProposer := p.state.proposer == p.validator.NodeID()
+	p.updateRole(isProposer)
 	p.backend.Init(&RoundInfo{
 		Proposer:     p.state.proposer,
 		IsProposer:   isProposer,
@@ -283,14 +573,36 @@ func (p *Pbft) runAcceptState(ctx context.Context) { // start new round
 	if isProposer {
 		p.logger.Printf("[INFO] we are the proposer")
 
-		if !p.state.IsLocked() {
-			// since the state is not locked, we need to build a new proposal
-			p.state.proposal, err = p.backend.BuildProposal()
-			if err != nil {
-				p.logger.Printf("[ERROR] failed to build proposal: %v", err)
-				p.setState(RoundChangeState)
+		if !p.waitStartupPeers(ctx) {
+			return
+		}
+
+		if p.state.IsLocked() {
+			// the locked proposal must be proposed again, proposing a fresh one would break safety
+			if p.state.proposal == nil {
+				p.handleStateErr(errIncorrectLockedProposal, RoundChange_MissingProposal)
 				return
 			}
+			p.logger.Printf("[INFO] proposing the locked proposal again: locked round=%d", p.state.lockedRound)
+			p.proposalObtained()
+		} else {
+			// since the state is not locked, we need to build a new proposal
+			if p.isNilProposalRound() {
+				p.logger.Printf("[INFO] proposing nil proposal")
+				p.state.proposal = NilProposal()
+				p.state.proposal.Time = p.config.Clock.Now()
+			} else {
+				if !p.waitMinBlockTime(ctx) {
+					return
+				}
+				p.state.proposal, err = p.buildProposal(ctx)
+				if err != nil {
+					p.logger.Printf("[ERROR] failed to build proposal: %v", err)
+					p.startRoundChange(RoundChange_MissingProposal)
+					return
+				}
+			}
+			p.proposalObtained()
 
 			// calculate how much time do we have to wait to gossip the proposal
 			delay := time.Until(p.state.proposal.Time)
@@ -301,11 +613,31 @@ func (p *Pbft) runAcceptState(ctx context.Context) { // start new round
 			}
 		}
 
+		// validate our own proposal the same way the followers do, so that
+		// a doomed proposal is not broadcast and the round change starts right away.
+		// The locked proposal is trusted, unless the strict proposal validation is enabled.
+		if !p.state.IsLocked() || p.config.StrictProposalValidation {
+			if err := p.validateProposalWithRetry(ctx, p.state.proposal); err != nil {
+				p.logger.Printf("[ERROR] invalid proposal, abstaining: %v", err)
+				if !p.state.IsLocked() {
+					p.state.proposal = nil
+				}
+				p.startRoundChange(RoundChange_InvalidProposal)
+				return
+			}
+		}
+
 		// send the preprepare message
+		p.relay.store(p.state.view.Sequence, p.state.proposal)
 		p.sendPreprepareMsg()
 
-		// send the prepare message since we are ready to move the state
-		p.sendPrepareMsg()
+		// send the prepare message since we are ready to move the state,
+		// unless the preprepare already counts as the prepare of the proposer
+		if p.implicitProposerPrepare() && !p.IsPaused() {
+			p.addImplicitPrepare(p.validator.NodeID())
+		} else {
+			p.sendPrepareMsg()
+		}
 
 		// move to validation state for new prepare messages
 		p.setState(ValidateState)
@@ -320,13 +652,46 @@ func (p *Pbft) runAcceptState(ctx context.Context) { // start new round
 	// We only need to wait here for one type of message, the pre-prepare message from the proposer.
 	// However, since we can receive bad pre-prepare messages we have to wait (or timeout) until
 	// we get the message from the correct proposer.
+	// A pre-prepare without the proposal body waits for the body requested to the proposer.
+	// With an AsyncProposalValidator, the proposal waits for its validation in the background.
+	var pending *MessageReq
+	defer p.stopAsyncValidation()
 	for p.getState() == AcceptState {
 		msg, ok := p.getNextMessage(span)
 		if !ok {
 			return
 		}
+		if msg == nil && p.getState() == AcceptState && p.asyncValidationCompleted() {
+			proposal, err := p.validation.proposal, p.validation.err
+			p.stopAsyncValidation()
+			if err != nil {
+				p.logger.Printf("[ERROR] failed to validate proposal. Error message: %v", err)
+				p.startRoundChange(RoundChange_InvalidProposal)
+				return
+			}
+			p.acceptProposal(proposal)
+			continue
+		}
 		if msg == nil {
-			p.setState(RoundChangeState)
+			p.startRoundChange(RoundChange_Timeout)
+			continue
+		}
+		if p.validation != nil {
+			// the proposal is being validated, the retransmissions of the pre-prepare are ignored
+			continue
+		}
+		if msg.Type == MessageReq_ProposalResponse {
+			if pending == nil {
+				continue
+			}
+			completed, err := p.completePreprepare(pending, msg)
+			if err != nil {
+				p.rejectProposalBody(pending, msg, err)
+				continue
+			}
+			msg, pending = completed, nil
+		}
+		if !p.checkPreprepareView(msg) {
 			continue
 		}
 		// TODO: Validate that the fields required for Preprepare are set (Proposal and Hash)
@@ -334,32 +699,66 @@ func (p *Pbft) runAcceptState(ctx context.Context) { // start new round
 			p.logger.Printf("[ERROR] msg received from wrong proposer: expected=%s, found=%s", p.state.proposer, msg.From)
 			continue
 		}
+		if missingProposal(msg) {
+			pending = msg
+			p.requestProposal(msg)
+			continue
+		}
 
-		// retrieve the proposal, the backend MUST validate that the hash belongs to the proposal
+		// retrieve the proposal, its hash is verified with the proposal hasher, if any (see verifyProposalHash),
+		// otherwise the backend MUST validate that the hash belongs to the proposal
 		proposal := &Proposal{
-			Data: msg.Proposal,
-			Hash: msg.Hash,
+			Type:       msg.ProposalType,
+			Time:       msg.ProposalTime,
+			Data:       msg.Proposal,
+			Hash:       msg.Hash,
+			ParentHash: msg.ProposalParentHash,
+			Metadata:   msg.ProposalMetadata,
 		}
 		if p.state.IsLocked() && !p.state.proposal.Equal(proposal) {
-			p.handleStateErr(errIncorrectLockedProposal)
-			return
+			if p.handleLockConflict(msg) {
+				return
+			}
+			continue
 		}
 
-		if err := p.backend.Validate(proposal); err != nil {
+		if p.config.AsyncProposalValidator != nil && !proposal.IsNil() {
+			if err := p.precheckProposal(proposal); err != nil {
+				p.logger.Printf("[ERROR] failed to validate proposal. Error message: %v", err)
+				p.startRoundChange(RoundChange_InvalidProposal)
+				return
+			}
+			p.startAsyncValidation(ctx, proposal)
+			continue
+		}
+		if err := p.validateProposalWithRetry(ctx, proposal); err != nil {
 			p.logger.Printf("[ERROR] failed to validate proposal. Error message: %v", err)
-			p.setState(RoundChangeState)
+			p.startRoundChange(RoundChange_InvalidProposal)
 			return
 		}
+		p.acceptProposal(proposal)
+	}
+}
 
-		if p.state.IsLocked() {
-			// fast-track and send a commit message and wait for validations
-			p.sendCommitMsg()
-			p.setState(ValidateState)
-		} else {
-			p.state.proposal = proposal
-			p.sendPrepareMsg()
-			p.setState(ValidateState)
+// acceptProposal votes for the validated proposal of the proposer and moves to the validate state
+func (p *Pbft) acceptProposal(proposal *Proposal) {
+	p.proposalObtained()
+	p.relay.store(p.state.view.Sequence, proposal)
+
+	if p.state.IsLocked() {
+		// fast-track and send a commit message and wait for validations
+		p.sendCommitMsg()
+		p.setState(ValidateState)
+	} else {
+		p.state.proposal = proposal
+		if n := p.state.attachOrphanPrepares(); n > 0 {
+			p.logger.Printf("[DEBUG] %d prepare messages received before the pre-prepare attached", n)
+		}
+		if p.implicitProposerPrepare() {
+			p.addImplicitPrepare(p.state.proposer)
 		}
+		p.sendVotes()
+		p.setState(ValidateState)
 	}
 }
 
@@ -374,22 +773,50 @@ func (p *Pbft) runValidateState(ctx context.Context) { // start new round
 		span.End()
 	}()
 
+	// the proposer retransmits its Preprepare until the prepare quorum
+	p.startPreprepareRetransmission()
+	defer p.stopPreprepareRetransmission()
+
+	// the commit messages of the earlier rounds are counted again in this round
+	p.replayEarlierCommits()
+
 	hasCommitted := false
+	// the commit message may have been sent with the prepare message (see WithCombinedVotes)
+	combined := p.takeCombinedCommit()
+	early := &earlyCommits{}
 	sendCommit := func(span trace.Span) {
+		p.stopPreprepareRetransmission()
+
 		// at this point either we have enough prepare messages
 		// or commit messages so we can lock the proposal
+		if p.config.PreparedCertificates && !p.state.IsLocked() {
+			certificate, err := p.buildPreparedCertificate()
+			if err != nil {
+				p.logger.Printf("[WARN] locked without a prepared certificate: %v", err)
+			}
+			p.preparedCertificate = certificate
+		}
 		p.state.lock()
 
 		if !hasCommitted {
 			// send the commit message
-			p.sendCommitMsg()
+			if !combined {
+				p.sendCommitMsg()
+			}
 			hasCommitted = true
+			p.startCommitTimeout()
 
 			span.AddEvent("Commit")
 		}
+
+		// count the commit messages received before the prepare quorum
+		for _, msg := range early.drain() {
+			if err := p.state.addCommitMsg(msg); err != nil {
+				p.rejectMessage(msg, err)
+			}
+		}
 	}
 
-	quorum := p.state.getQuorumSize()
 	for p.getState() == ValidateState {
 		msg, ok := p.getNextMessage(span)
 		if !ok {
@@ -398,40 +825,90 @@ func (p *Pbft) runValidateState(ctx context.Context) { // start new round
 		}
 		if msg == nil {
 			// timeout
-			p.setState(RoundChangeState)
+			p.startRoundChange(RoundChange_Timeout)
 			return
 		}
 
 		// the message must have our local hash
 		if !bytes.Equal(msg.Hash, p.state.proposal.Hash) {
+			if err := p.verifyVote(msg); err == nil {
+				p.recordVote(msg)
+			}
+			if p.state.addConflictingPrepare(msg) && p.recoverPrepareSplit() {
+				return
+			}
+			if p.state.bufferOrphanPrepare(msg) {
+				p.logger.Printf("[DEBUG] buffered prepare message from node %s for an unknown proposal", msg.From)
+				continue
+			}
 			p.logger.Printf(fmt.Sprintf("[WARN]: incorrect hash in %s message from node %s", msg.Type.String(), msg.From))
 			continue
 		}
 
 		switch msg.Type {
 		case MessageReq_Prepare:
-			p.state.addPrepareMsg(msg)
+			if p.config.PreparedCertificates {
+				if err := p.validatePrepareSeal(msg); err != nil {
+					p.logger.Printf("[ERROR]: failed to validate prepare: %v: %v", ErrBadSignature, err)
+					continue
+				}
+				p.recordVote(msg)
+			}
+			if err := p.state.addPrepareMsg(msg); err != nil {
+				p.rejectMessage(msg, err)
+			}
+			if p.recoverPrepareSplit() {
+				return
+			}
 		case MessageReq_Commit:
-			if err := p.backend.ValidateCommit(msg.From, msg.Seal); err != nil {
-				p.logger.Printf("[ERROR]: failed to validate commit: %v", err)
+			if _, err := DecodeSeal(p.config.SealFormat, msg.Seal); err != nil {
+				p.logger.Printf("[ERROR]: failed to validate commit: %v: %v", ErrBadSignature, err)
+				continue
+			}
+			if err := p.collectCommitSeal(msg); err != nil {
+				p.logger.Printf("[ERROR]: failed to validate commit: %v: %v", ErrBadSignature, err)
 				continue
 			}
-			p.state.addCommitMsg(msg)
+			p.recordVote(msg)
+			if p.config.EarlyCommitPolicy == EarlyCommit_Reject && !hasCommitted && !p.state.IsLocked() {
+				p.logDrop(DropReasonOutOfPhase, "[DEBUG] %s message from node %s dropped before the prepare quorum", msg.Type, msg.From)
+				p.dropMessage(msg, DropReasonOutOfPhase)
+				continue
+			}
+			p.recordCommit(msg)
+			if early.buffer(p.config.EarlyCommitPolicy, hasCommitted || p.state.IsLocked(), msg) {
+				p.logger.Printf("[DEBUG] buffered %s message from node %s until the prepare quorum", msg.Type, msg.From)
+				continue
+			}
+			if err := p.state.addCommitMsg(msg); err != nil {
+				p.rejectMessage(msg, err)
+			}
 		default:
 			panic(fmt.Errorf("BUG: Unexpected message type: %s in %s from node %s", msg.Type, p.getState(), msg.From))
 		}
 
-		if p.state.prepared.getAccumulatedVotingPower() >= quorum {
+		p.checkSequenceLeaks()
+
+		if p.state.prepared.getAccumulatedVotingPower() >= p.state.getPrepareQuorumSize() {
 			// we have received enough prepare messages
+			if !hasCommitted {
+				if p.resolvePrepareTie() {
+					return
+				}
+				p.quorumReached(MessageReq_Prepare)
+			}
 			sendCommit(span)
 		}
 
-		if p.state.committed.getAccumulatedVotingPower() >= quorum {
+		if p.state.committed.getAccumulatedVotingPower() >= p.state.getCommitQuorumSize() {
 			// we have received enough commit messages
+			p.quorumReached(MessageReq_Commit)
+			p.sequenceCommitted()
 			sendCommit(span)
 
 			// change to commit state just to get out of the loop
 			p.setState(CommitState)
+			p.markProgress()
 		}
 	}
 }
@@ -477,9 +954,10 @@ func (p *Pbft) setStateSpanAttributes(span trace.Span) {
 	attr = append(attr, attribute.Int64("prepared.votingPower", int64(p.state.prepared.getAccumulatedVotingPower())))
 
 	// number of round change messages per round
-	for round, msgs := range p.state.roundMessages {
-		attr = append(attr, attribute.Int(fmt.Sprintf("roundChange_%d", round), msgs.length()))
-	}
+	p.state.RangeRoundMessages(func(round uint64, msgs map[NodeID]*MessageReq) bool {
+		attr = append(attr, attribute.Int(fmt.Sprintf("roundChange_%d", round), len(msgs)))
+		return true
+	})
 	span.SetAttributes(attr...)
 }
 
@@ -497,7 +975,13 @@ func (p *Pbft) runCommitState(ctx context.Context) {
 	_, span := p.tracer.Start(ctx, "CommitState")
 	defer span.End()
 
-	committedSeals := p.state.getCommittedSeals()
+	p.checkSequenceLeaks()
+	if err := p.AssertCommittedAgreement(); err != nil {
+		p.logger.Printf("[ERROR] failed to build the committed seals: %v", err)
+		p.handleStateErr(err, RoundChange_CommitFailed)
+		return
+	}
+	committedSeals := p.state.getCommittedSeals(p.config.SealOrdering, p.config.SealSelection)
 	proposal := p.state.proposal.Copy()
 
 	pp := &SealedProposal{
@@ -505,28 +989,158 @@ func (p *Pbft) runCommitState(ctx context.Context) {
 		CommittedSeals: committedSeals,
 		Proposer:       p.state.proposer,
 		Number:         p.state.view.Sequence,
+		Round:          p.state.view.Round,
 	}
-	if err := p.backend.Insert(pp); err != nil {
+	if err := p.insertProposal(pp); err != nil {
 		// start a new round with the state unlocked since we need to
 		// be able to propose/validate a different proposal
 		p.logger.Printf("[ERROR] failed to insert proposal. Error message: %v", err)
-		p.handleStateErr(errFailedToInsertProposal)
+		p.handleStateErr(errFailedToInsertProposal, RoundChange_CommitFailed)
 	} else {
+		// the sequence is finalized, proposer failures are not relevant anymore
+		p.penalties.reset()
+		p.endRound()
+
+		// on failure, the proof of the previous sequence is kept rather than reporting no proof at all
+		proof, err := p.BuildFinalizationProof()
+		if err != nil {
+			p.logger.Printf("[ERROR] failed to build finalization proof. Error message: %v", err)
+		} else {
+			p.finalizationProof = proof
+		}
+		p.recordParticipation()
+		p.recordProposerEviction(p.state.view.Sequence, p.state.view.Round)
+		p.proposalFinalized(proposal)
+		p.sequenceFinalized(p.state.view.Sequence)
+		p.publishFinalized(&FinalizedProposal{Sequence: p.state.view.Sequence, Proposal: pp, Proof: proof})
+
+		if !p.notifyFinalized(ctx, p.state.view.Sequence, proof) {
+			return
+		}
+		p.finalizePipelined(ctx)
+
 		// move to done state to finish the current iteration of the state machine
 		p.setState(DoneState)
 	}
 }
 
+// sequenceFinalized releases the state kept for the finalized sequence and switches the epoch if needed
+func (p *Pbft) sequenceFinalized(sequence uint64) {
+	if p.dedup != nil {
+		p.dedup.finalize(sequence)
+	}
+	p.reportGossipEfficiency(sequence)
+	p.trimBuffers(sequence)
+	p.epochFinalized(sequence)
+	p.lastFinalized = p.config.Clock.Now()
+}
+
+// finalizePipelined inserts, in order, the pipelined sequences following the current one
+// that already reached a commit quorum. It stops at the first sequence without quorum.
+func (p *Pbft) finalizePipelined(ctx context.Context) {
+	if p.pipeline == nil {
+		return
+	}
+	for sequence := p.state.view.Sequence + 1; ; sequence++ {
+		pp := p.pipeline.committed(sequence, p.state.validators, p.batchVerifySeals)
+		if pp == nil {
+			return
+		}
+		if err := p.validateProposal(ctx, pp.Proposal); err != nil {
+			p.logger.Printf("[ERROR] failed to validate pipelined proposal: sequence=%d, err=%v", sequence, err)
+			return
+		}
+		if err := p.insertProposal(pp.SealedProposal); err != nil {
+			p.logger.Printf("[ERROR] failed to insert pipelined proposal: sequence=%d, err=%v", sequence, err)
+			return
+		}
+		p.logger.Printf("[INFO] pipelined sequence finalized: sequence=%d", sequence)
+		p.proposalFinalized(pp.Proposal)
+		p.sequenceFinalized(sequence)
+		proof := pp.proof()
+		p.publishFinalized(&FinalizedProposal{Sequence: sequence, Proposal: pp.SealedProposal, Proof: proof})
+
+		if !p.notifyFinalized(ctx, sequence, proof) {
+			return
+		}
+	}
+}
+
+// validateProposal dispatches the proposal validation to the handler registered
+// for the proposal type, falling back to the backend. With a ValidationLimiter, the dispatch
+// waits for a validation slot.
+func (p *Pbft) validateProposal(ctx context.Context, proposal *Proposal) error {
+	if err := p.precheckProposal(proposal); err != nil {
+		return err
+	}
+	if proposal.IsNil() {
+		return p.validateNilProposal(proposal)
+	}
+	if limiter := p.config.ValidationLimiter; limiter != nil {
+		if err := limiter.Acquire(ctx); err != nil {
+			return err
+		}
+		defer limiter.Release()
+	}
+	if handler, ok := p.config.ProposalHandlers[proposal.Type]; ok {
+		return handler.Validate(proposal)
+	}
+	return p.backend.Validate(proposal)
+}
+
+// precheckProposal runs the checks of the proposal that do not involve the backend
+func (p *Pbft) precheckProposal(proposal *Proposal) error {
+	if err := p.verifyProposalHash(proposal); err != nil {
+		return err
+	}
+	if err := p.checkClockSkew(proposal.Time); err != nil {
+		return err
+	}
+	if err := p.checkProposalTime(proposal); err != nil {
+		return err
+	}
+	return p.checkParentHash(proposal)
+}
+
+// insertProposal dispatches the sealed proposal insertion to the handler registered
+// for the proposal type, falling back to the backend
+func (p *Pbft) insertProposal(pp *SealedProposal) error {
+	if handler, ok := p.config.ProposalHandlers[pp.Proposal.Type]; ok {
+		return handler.Insert(pp)
+	}
+	return p.backend.Insert(pp)
+}
+
+// isNilProposalRound returns whether the nil proposal is allowed in the current round
+func (p *Pbft) isNilProposalRound() bool {
+	return p.config.NilProposalEnabled && p.state.GetCurrentRound() >= p.config.NilProposalRound
+}
+
+// validateNilProposal validates that the nil proposal is allowed and well formed
+func (p *Pbft) validateNilProposal(proposal *Proposal) error {
+	if !p.isNilProposalRound() {
+		return errNilProposalNotAllowed
+	}
+	if len(proposal.Data) != 0 || !bytes.Equal(proposal.Hash, nilProposalHash[:]) {
+		return errInvalidNilProposal
+	}
+	return nil
+}
+
 var (
-	errIncorrectLockedProposal = fmt.Errorf("locked proposal is incorrect")
-	errVerificationFailed      = fmt.Errorf("proposal verification failed")
-	errFailedToInsertProposal  = fmt.Errorf("failed to insert proposal")
-	errInvalidTotalVotingPower = fmt.Errorf("invalid voting power configuration provided: total voting power must be greater than 0")
+	errNilProposalNotAllowed    = fmt.Errorf("nil proposal is not allowed in the current round")
+	errInvalidNilProposal       = fmt.Errorf("nil proposal is malformed")
+	errIncorrectLockedProposal  = fmt.Errorf("locked proposal is incorrect")
+	errVerificationFailed       = fmt.Errorf("proposal verification failed")
+	errFailedToInsertProposal   = fmt.Errorf("failed to insert proposal")
+	errInvalidTotalVotingPower  = fmt.Errorf("invalid voting power configuration provided: total voting power must be greater than 0")
+	errInvalidPhaseQuorum       = fmt.Errorf("invalid phase quorum configuration")
+	errInvalidVotingPowerUpdate = fmt.Errorf("voting power update does not match the validator set")
 )
 
-func (p *Pbft) handleStateErr(err error) {
+func (p *Pbft) handleStateErr(err error, reason RoundChangeReason) {
 	p.state.err = err
-	p.setState(RoundChangeState)
+	p.startRoundChange(reason)
 }
 
 func (p *Pbft) runRoundChangeState(ctx context.Context) {
@@ -575,6 +1189,9 @@ func (p *Pbft) runRoundChangeState(ctx context.Context) {
 		sendNextRoundChange()
 	}
 
+	// the round change messages of the current round received since the new round started may release a stale lock
+	p.releaseStaleLock(p.state.GetCurrentRound())
+
 	// if the round was triggered due to an error, we send our own
 	// next round change
 	if err := p.state.getErr(); err != nil {
@@ -584,7 +1201,7 @@ func (p *Pbft) runRoundChangeState(ctx context.Context) {
 		// otherwise, it is due to a timeout in any stage
 		// First, we try to sync up with any max round already available
 		// F + 1 round change messages for given round, where F denotes MaxFaulty is expected, in order to fast-track to maxRound
-		if maxRound, ok := p.state.maxRound(); ok {
+		if maxRound, ok := p.state.selectRound(p.config.RoundSelection); ok {
 			p.logger.Printf("[DEBUG] round change, max round=%d", maxRound)
 			sendRoundChange(maxRound)
 		} else {
@@ -602,6 +1219,7 @@ func (p *Pbft) runRoundChangeState(ctx context.Context) {
 		}
 		if msg == nil {
 			p.logger.Print("[DEBUG] round change timeout")
+			p.recordRoundChange(RoundChange_Timeout)
 
 			// checkTimeout will either produce a sync event and exit
 			// or restart the timeout
@@ -610,13 +1228,19 @@ func (p *Pbft) runRoundChangeState(ctx context.Context) {
 		}
 
 		// we only expect RoundChange messages right now
-		p.state.addRoundChangeMsg(msg)
+		if err := p.addRoundChangeMsg(msg); err != nil {
+			p.rejectMessage(msg, err)
+			continue
+		}
 
 		currentVotingPower := p.state.roundMessages[msg.View.Round].getAccumulatedVotingPower()
 		// Round change quorum is 2*F round change messages (F denotes max faulty voting power)
 		if currentVotingPower >= 2*p.state.getMaxFaultyVotingPower() {
 			// start a new round immediately
+			p.penalizeRoundProposer(msg.View.Round)
 			p.state.SetCurrentRound(msg.View.Round)
+			p.releaseStaleLock(msg.View.Round)
+			p.markProgress()
 			// set state span attributes and terminate it
 			p.setStateSpanAttributes(span)
 			span.End()
@@ -625,6 +1249,7 @@ func (p *Pbft) runRoundChangeState(ctx context.Context) {
 			// weak certificate, try to catch up if our round number is smaller
 			if p.state.GetCurrentRound() < msg.View.Round {
 				// update timer
+				p.recordRoundChange(RoundChange_CatchUp)
 				sendRoundChange(msg.View.Round)
 			}
 		}
@@ -650,6 +1275,12 @@ func (p *Pbft) sendCommitMsg() {
 }
 
 func (p *Pbft) gossip(msgType MsgType) {
+	if p.IsPaused() {
+		// paused nodes keep following the network but do not vote
+		p.logger.Printf("[DEBUG] participation paused, %s message not sent", msgType)
+		return
+	}
+
 	msg := &MessageReq{
 		Type: msgType,
 		From: p.validator.NodeID(),
@@ -665,33 +1296,90 @@ func (p *Pbft) gossip(msgType MsgType) {
 	// add View
 	msg.View = p.state.view.Copy()
 
+	// if we are sending a round change while locked, we need to include the locked proposal
+	if msg.Type == MessageReq_RoundChange && p.state.IsLocked() {
+		msg.Justification = &Justification{
+			Round:    p.state.lockedRound,
+			Proposal: p.state.proposal.Copy(),
+		}
+		if certificate := p.preparedCertificate; certificate != nil && certificate.Round == p.state.lockedRound &&
+			bytes.Equal(certificate.Hash, p.state.proposal.Hash) {
+			msg.Justification.Certificate = certificate.Copy()
+		}
+	}
+
+	// with the prepared certificates, the prepare messages are sealed
+	if (msg.Type == MessageReq_Prepare || msg.Type == MessageReq_PrepareCommit) && p.config.PreparedCertificates {
+		if err := p.sealPrepare(msg); err != nil {
+			p.logger.Printf("[ERROR] failed to seal prepare. Error message: %v", err)
+			return
+		}
+		if msg.Type == MessageReq_PrepareCommit {
+			msg.PrepareSeal, msg.Seal = msg.Seal, nil
+		}
+	}
+
 	// if we are sending a preprepare message we need to include the proposal
 	if msg.Type == MessageReq_Preprepare {
 		msg.SetProposal(p.state.proposal.Data)
+		msg.ProposalType = p.state.proposal.Type
+		msg.ProposalTime = p.state.proposal.Time
+		msg.ProposalParentHash = p.state.proposal.ParentHash
+		msg.ProposalMetadata = p.state.proposal.Metadata
 	}
 
 	// if the message is commit, we need to add the committed seal
-	if msg.Type == MessageReq_Commit {
+	if msg.Type == MessageReq_Commit || msg.Type == MessageReq_PrepareCommit {
 		// seal the hash of the proposal
-		seal, err := p.validator.Sign(p.state.proposal.Hash)
+		seal, err := p.validator.Sign(SignableContent(p.config.SealDomain, p.commitSealHash(msg.View.Round, p.state.proposal)))
 		if err != nil {
 			p.logger.Printf("[ERROR] failed to commit seal. Error message: %v", err)
 			return
 		}
-		msg.Seal = seal
+		if msg.Seal, err = EncodeSeal(p.config.SealFormat, seal); err != nil {
+			p.logger.Printf("[ERROR] failed to encode commit seal. Error message: %v", err)
+			return
+		}
+	}
+
+	msg, ok := p.interceptOutgoing(msg)
+	if !ok {
+		return
 	}
 
-	if msg.Type != MessageReq_Preprepare {
+	if msg.Type == MessageReq_PrepareCommit {
+		// send the split messages to ourselves, the commit one is then not sent again
+		prepare, commit := splitPrepareCommit(msg)
+		p.pushMessage(prepare)
+		p.pushMessage(commit)
+		p.combinedCommit = true
+	} else if msg.Type != MessageReq_Preprepare {
 		// send a copy to ourselves so that we can process this message as well
 		msg2 := msg.Copy()
 		msg2.From = p.validator.NodeID()
-		p.PushMessage(msg2)
+		p.pushMessage(msg2)
 	}
 	if err := p.transport.Gossip(msg); err != nil {
 		p.logger.Printf("[ERROR] failed to gossip. Error message: %v", err)
 	}
 }
 
+// Pause suspends the node participation in consensus. While paused, the node keeps ingesting
+// messages and following the view of the network, but it does not send any message.
+func (p *Pbft) Pause() {
+	atomic.StoreUint64(&p.paused, 1)
+}
+
+// Resume resumes the node participation in consensus from the current view
+func (p *Pbft) Resume() {
+	atomic.StoreUint64(&p.paused, 0)
+}
+
+// IsPaused returns whether the node participation in consensus is suspended
+func (p *Pbft) IsPaused() bool {
+	return atomic.LoadUint64(&p.paused) == 1
+}
+
 // GetValidatorId returns validator NodeID
 func (p *Pbft) GetValidatorId() NodeID {
 	return p.validator.NodeID()
@@ -719,7 +1407,14 @@ func (p *Pbft) SetState(s State) {
 // setState sets the PBFT state
 func (p *Pbft) setState(s State) {
 	p.logger.Printf("[DEBUG] state change: '%s'", s)
+	from := p.getState()
+	if from != s {
+		p.config.Metrics.StateTransition(from, s)
+	}
 	p.state.setState(s)
+	if from != s {
+		p.publishEvent(ConsensusEvent{Type: ConsensusEvent_StateChange, State: s})
+	}
 }
 
 // IsLocked returns if the current proposal is locked
@@ -733,13 +1428,50 @@ func (p *Pbft) GetProposal() *Proposal {
 }
 
 func (p *Pbft) Round() uint64 {
-	return atomic.LoadUint64(&p.state.view.Round)
+	return p.state.GetCurrentRound()
+}
+
+// CurrentView returns a copy of the current view (sequence and round).
+// Unlike reading the view of the state, it is safe to call concurrently with the state machine.
+func (p *Pbft) CurrentView() View {
+	return p.state.CurrentView()
 }
 
 // getNextMessage reads a new message from the message queue
 func (p *Pbft) getNextMessage(span trace.Span) (*MessageReq, bool) {
 	for {
+		p.drainInbound()
+		p.enforceMemoryBudget()
+		p.retransmitPreprepare()
+		p.broadcastViewSyncIfDue()
+		if p.viewSyncBehind() {
+			p.logger.Printf("[INFO] validators ahead of the local sequence %d, syncing", p.state.view.Sequence)
+			p.setState(SyncState)
+			return nil, false
+		}
+		if p.sequenceDeadlineExceeded() {
+			p.logger.Printf("[WARN] sequence %d not finalized within %s, syncing", p.state.view.Sequence, p.config.SequenceDeadline)
+			p.setState(SyncState)
+			return nil, false
+		}
+		if p.asyncValidationCompleted() {
+			return nil, true
+		}
+
+		if p.catchUpRound() {
+			return nil, true
+		}
+		if p.Halted() != nil {
+			return nil, false
+		}
+		if msg := p.nextEarlierCommit(); msg != nil {
+			p.spanAddEventMessage("message", span, msg)
+			p.logger.Printf("[TRACE] Received %s of an earlier round", msg)
+			return msg, true
+		}
+
 		msg, discards := p.notifier.ReadNextMessage(p)
+		discards = p.holdStaleCommits(discards)
 		// send the discard messages
 		p.logger.Printf("[TRACE] Current state %s, number of prepared messages: %d (voting power: %d), number of committed messages %d (voting power: %d)",
 			p.getState(), p.state.numPrepared(), p.state.prepared.getAccumulatedVotingPower(), p.state.numCommitted(), p.state.committed.getAccumulatedVotingPower())
@@ -747,6 +1479,10 @@ func (p *Pbft) getNextMessage(span trace.Span) (*MessageReq, bool) {
 		for _, msg := range discards {
 			p.logger.Printf("[TRACE] Discarded %s ", msg)
 			p.spanAddEventMessage("dropMessage", span, msg)
+			p.config.Metrics.MessageDropped(msg.Type, DropReasonStale)
+		}
+		if msg == nil {
+			msg = p.nextEarlierCommit()
 		}
 		if msg != nil {
 			// add the event to the span
@@ -757,23 +1493,74 @@ func (p *Pbft) getNextMessage(span trace.Span) (*MessageReq, bool) {
 
 		// wait until there is a new message or
 		// someone closes the stopCh (i.e. timeout for round change)
-		select {
-		case <-p.state.timeoutChan:
+		event, ok := p.config.Scheduler.Next(p.ctx, p.state.timeoutChan, p.updateCh)
+		if !ok {
+			return nil, false
+		}
+		switch event.Type {
+		case EventMessage:
+			if event.Msg != nil {
+				p.PushMessageInternal(event.Msg)
+			}
+		case EventTimeout:
 			span.AddEvent("Timeout")
-			p.notifier.HandleTimeout(p.validator.NodeID(), stateToMsg(p.getState()), &View{
-				Round:    p.state.GetCurrentRound(),
-				Sequence: p.state.view.Sequence,
-			})
+			view := p.state.CurrentView()
+			p.notifier.HandleTimeout(p.validator.NodeID(), stateToMsg(p.getState()), &view)
 			p.logger.Printf("[TRACE] Message read timeout occurred")
 			return nil, true
-		case <-p.ctx.Done():
-			return nil, false
-		case <-p.updateCh:
+		case EventCommand:
+			event.Command(p)
 		}
 	}
 }
 
 func (p *Pbft) PushMessageInternal(msg *MessageReq) {
+	if msg.Type == MessageReq_PrepareCommit {
+		prepare, commit := splitPrepareCommit(msg)
+		p.PushMessageInternal(prepare)
+		p.PushMessageInternal(commit)
+		return
+	}
+	if p.liveness != nil && msg.View != nil {
+		p.liveness.observe(msg.From, msg.View.Sequence)
+	}
+	p.ObservePeer(msg.From)
+	if msg.Type == MessageReq_ProposalRequest {
+		// requests are served right away, they are not part of the consensus
+		p.serveProposal(msg)
+		return
+	}
+	if msg.Type == MessageReq_ViewSync {
+		// the views are tracked right away, they are not part of the consensus
+		p.handleViewSync(msg)
+		return
+	}
+	if p.getState() == SyncState {
+		// the node is syncing, the message is processed once the consensus is resumed
+		p.syncMsgs.add(msg)
+		return
+	}
+	if p.pipeline != nil {
+		p.pipeline.track(msg)
+	}
+	if p.futureMsgs.add(msg) {
+		// message belongs to a future sequence, it is buffered until the node advances to it
+		p.persistBuffered(Buffer_Future, msg)
+		return
+	}
+	if proof := p.doubleProposals.checkProposer(msg); proof != nil {
+		p.logDrop(DropReasonWrongProposer, "[WARN] preprepare from a node that is not the round proposer: sender=%s, proposer=%s, view=%s",
+			proof.Sender, proof.Proposer, msg.View)
+		p.dropMessage(msg, DropReasonWrongProposer)
+		return
+	}
+	if proof := p.doubleProposals.check(msg); proof != nil {
+		p.logger.Printf("[WARN] double proposal detected: proposer=%s, view=%s", proof.Proposer, msg.View)
+		p.checkSafetyThreshold()
+	}
+	if !p.admitFuturePreprepare(msg) {
+		return
+	}
 	p.msgQueue.pushMessage(msg)
 
 	select {
@@ -784,17 +1571,161 @@ func (p *Pbft) PushMessageInternal(msg *MessageReq) {
 
 // PushMessage pushes a new message to the message queue
 func (p *Pbft) PushMessage(msg *MessageReq) {
+	if p.gossipCounter != nil {
+		p.gossipCounter.received(msg)
+	}
+	p.pushReceived(msg)
+}
+
+// pushReceived validates and queues a message received from the transport
+func (p *Pbft) pushReceived(msg *MessageReq) {
+	if msg.Type == MessageReq_PrepareCommit {
+		prepare, commit := splitPrepareCommit(msg)
+		p.pushReceived(prepare)
+		p.pushReceived(commit)
+		return
+	}
 	if err := msg.Validate(); err != nil {
 		p.logger.Printf("[ERROR]: failed to validate msg: %v", err)
 		return
 	}
+	if p.config.StrictValidation {
+		if err := msg.ValidateView(p.config.MaxRound); err != nil {
+			p.logger.Printf("[ERROR]: failed to validate msg view: %v", err)
+			return
+		}
+	}
+	if p.exceedsMaxRoundJump(msg) {
+		p.logDrop(DropReasonRoundTooFar, "[ERROR]: round change message from node %s is too far ahead: view %s", msg.From, msg.View)
+		p.dropMessage(msg, DropReasonRoundTooFar)
+		return
+	}
+	if p.exceedsMaxMessageAge(msg) {
+		p.logDrop(DropReasonTooOld, "[ERROR]: message from node %s is too old: proposal time %s", msg.From, msg.ProposalTime)
+		p.dropMessage(msg, DropReasonTooOld)
+		return
+	}
+	if msg.Type == MessageReq_Commit {
+		if err := CheckSealLength(p.config.SealFormat, msg.Seal); err != nil {
+			p.logDrop(DropReasonMalformedSeal, "[ERROR]: invalid seal in commit message from node %s: %v", msg.From, err)
+			p.dropMessage(msg, DropReasonMalformedSeal)
+			return
+		}
+	}
+	if p.handleSelfMessage(msg) {
+		return
+	}
+	if p.dedup != nil && msg.View != nil {
+		if p.dedup.seen(msg) {
+			p.logDrop(DropReasonDuplicate, "[TRACE] dropped duplicate %s", msg)
+			p.dropMessage(msg, DropReasonDuplicate)
+			return
+		}
+		p.persistBuffered(Buffer_Dedup, dedupIdentity(msg))
+	}
+	p.state.markSeen(msg.From, p.config.Clock.Now())
+	p.pushMessage(msg)
+}
+
+// pushMessage queues a message, in the inbound queue if enabled
+func (p *Pbft) pushMessage(msg *MessageReq) {
+	if p.inbound == nil {
+		p.PushMessageInternal(msg)
+		return
+	}
+	// the inbound queue is only drained while reading the next message, the peer is observed right away so that
+	// the node waiting for the startup peers sees it (see waitStartupPeers)
+	p.ObservePeer(msg.From)
+	if dropped := p.inbound.push(msg); dropped != nil {
+		p.logDrop(DropReasonInboundQueueFull, "[TRACE] inbound queue full, dropped %s", dropped)
+		p.dropMessage(dropped, DropReasonInboundQueueFull)
+	}
+
+	select {
+	case p.updateCh <- struct{}{}:
+	default:
+	}
+}
+
+// catchUpRound moves the node to the round change state when the queued round change messages for a higher round
+// are sent by more than the max faulty voting power (f+1), so that the node jumps to that round without waiting for its timeout.
+// The queued round change messages are added to the state, where the round change state finds the round to jump to.
+func (p *Pbft) catchUpRound() bool {
+	if !p.config.RoundChangeCatchUp {
+		return false
+	}
+	if st := p.getState(); st != AcceptState && st != ValidateState {
+		return false
+	}
+
+	current := p.state.CurrentView()
+	view := &current
+	votingPower := p.state.validators.VotingPower()
+	senders := map[uint64]map[NodeID]struct{}{}
+	power := map[uint64]uint64{}
+	found := false
+	for _, msg := range p.msgQueue.roundChangesAbove(view) {
+		round := msg.View.Round
+		if senders[round] == nil {
+			senders[round] = map[NodeID]struct{}{}
+		}
+		if _, ok := senders[round][msg.From]; ok {
+			continue
+		}
+		senders[round][msg.From] = struct{}{}
+		power[round] += votingPower[msg.From]
+		if power[round] >= p.state.getMaxFaultyVotingPower()+1 {
+			found = true
+		}
+	}
+	if !found {
+		return false
+	}
+
+	for msg := p.msgQueue.readMessage(RoundChangeState, view); msg != nil; msg = p.msgQueue.readMessage(RoundChangeState, view) {
+		if err := p.addRoundChangeMsg(msg); err != nil {
+			p.rejectMessage(msg, err)
+		}
+	}
+	p.logger.Printf("[DEBUG] round change messages for a higher round received, catching up")
+	p.startRoundChange(RoundChange_CatchUp)
+	return true
+}
+
+// drainInbound moves the messages of the inbound queue to the message queue
+func (p *Pbft) drainInbound() {
+	if p.inbound == nil {
+		return
+	}
+	for msg := p.inbound.pop(); msg != nil; msg = p.inbound.pop() {
+		p.PushMessageInternal(msg)
+	}
+}
 
-	p.PushMessageInternal(msg)
+// DroppedMessages returns the number of messages dropped because the inbound queue was full
+func (p *Pbft) DroppedMessages() uint64 {
+	if p.inbound == nil {
+		return 0
+	}
+	return p.inbound.droppedCount()
 }
 
 // ReadMessageWithDiscards reads next message with discards from message queue based on current state, sequence and round
 func (p *Pbft) ReadMessageWithDiscards() (*MessageReq, []*MessageReq) {
-	return p.msgQueue.readMessageWithDiscards(p.getState(), p.state.view)
+	view := p.state.CurrentView()
+	return p.msgQueue.readMessageWithDiscards(p.getState(), &view)
+}
+
+// RangeCommitted calls fn with a copy of every committed message of the current round.
+// Iteration stops as soon as fn returns false.
+func (p *Pbft) RangeCommitted(fn func(NodeID, *MessageReq) bool) {
+	p.state.rangeCommitted(fn)
+}
+
+// RangePrepared calls fn with a copy of every prepared message of the current round.
+// Iteration stops as soon as fn returns false.
+func (p *Pbft) RangePrepared(fn func(NodeID, *MessageReq) bool) {
+	p.state.rangePrepared(fn)
 }
 
 // MaxFaultyVotingPower is a wrapper function around state.MaxFaultyVotingPower
@@ -807,6 +1738,62 @@ func (p *Pbft) QuorumSize() uint64 {
 	return p.state.getQuorumSize()
 }
 
+// PrepareQuorumSize returns the voting power of prepare messages needed to lock and commit
+func (p *Pbft) PrepareQuorumSize() uint64 {
+	return p.state.getPrepareQuorumSize()
+}
+
+// CommitQuorumSize returns the voting power of commit messages needed to finalize
+func (p *Pbft) CommitQuorumSize() uint64 {
+	return p.state.getCommitQuorumSize()
+}
+
+// RemainingPowerForPrepare returns the voting power of prepare messages still needed to reach
+// the prepare quorum in the current round, zero once it is reached
+func (p *Pbft) RemainingPowerForPrepare() uint64 {
+	return remainingPower(p.state.prepared.getAccumulatedVotingPower(), p.state.getPrepareQuorumSize())
+}
+
+// RemainingPowerForCommit returns the voting power of commit messages still needed to reach
+// the commit quorum in the current round, zero once it is reached
+func (p *Pbft) RemainingPowerForCommit() uint64 {
+	return remainingPower(p.state.committed.getAccumulatedVotingPower(), p.state.getCommitQuorumSize())
+}
+
+// remainingPower returns the voting power missing to the quorum size
+func remainingPower(accumulated, quorumSize uint64) uint64 {
+	if accumulated >= quorumSize {
+		return 0
+	}
+	return quorumSize - accumulated
+}
+
+// LockedProposal returns a copy of the proposal the node is locked on, or nil if it is not locked
+func (p *Pbft) LockedProposal() *Proposal {
+	if !p.state.IsLocked() || p.state.proposal == nil {
+		return nil
+	}
+	return p.state.proposal.Copy()
+}
+
+// AssertCommittedAgreement verifies that all the committed messages of the current round reference the same proposal.
+// The returned error wraps ErrCommittedDisagreement and lists the dissenting senders.
+func (p *Pbft) AssertCommittedAgreement() error {
+	return p.state.assertCommittedAgreement()
+}
+
+// HasCommitQuorum returns whether the committed messages referencing the given proposal hash reach the commit quorum
+// (QuorumSize unless configured otherwise). Unlike the overall commit count, the commits backing other proposals are not counted.
+func (p *Pbft) HasCommitQuorum(proposalHash []byte) bool {
+	return p.state.committedVotingPowerFor(proposalHash) >= p.state.getCommitQuorumSize()
+}
+
+// HighestObservedRound returns the highest round of the round change, prepared and committed messages
+// of the current sequence, regardless of any quorum
+func (p *Pbft) HighestObservedRound() uint64 {
+	return p.state.highestObservedRound()
+}
+
 // CalculateQuorum calculates max faulty voting power and quorum size for given voting power map
 func CalculateQuorum(votingPower map[NodeID]uint64) (maxFaultyVotingPower uint64, quorumSize uint64, err error) {
 	totalVotingPower := uint64(0)
@@ -11,6 +11,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -66,6 +67,95 @@ func TestTransition_AcceptState_Proposer_Propose(t *testing.T) {
 	})
 }
 
+func TestTransition_AcceptState_Proposer_Paused(t *testing.T) {
+	i := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	i.setProposal(&Proposal{
+		Data: mockProposal,
+		Time: time.Now(),
+	})
+
+	// paused proposer does not send any message
+	i.Pause()
+	i.setState(AcceptState)
+	i.runCycle(context.Background())
+
+	i.expect(expectResult{
+		sequence: 1,
+		state:    ValidateState,
+	})
+
+	// once resumed, it participates again
+	i.Resume()
+	i.setState(AcceptState)
+	i.runCycle(context.Background())
+
+	i.expect(expectResult{
+		sequence: 1,
+		outgoing: 2, // preprepare and prepare
+		state:    ValidateState,
+	})
+}
+
+func TestTransition_AcceptState_NilProposal(t *testing.T) {
+	nilPreprepare := func() *MessageReq {
+		msg := createMessage("B", MessageReq_Preprepare, ViewMsg(1, 1))
+		msg.ProposalType = ProposalType_Nil
+		msg.Proposal = nil
+		msg.Hash = NilProposal().Hash
+		return msg
+	}
+
+	t.Run("Proposer proposes nil proposal", func(t *testing.T) {
+		m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+		WithNilProposal(1)(m.config)
+		clock := NewManualClock(time.Unix(1000, 0))
+		WithClock(clock)(m.config)
+		m.state.view = ViewMsg(1, 1)
+		m.setState(AcceptState)
+
+		m.runCycle(context.Background())
+
+		m.expect(expectResult{
+			sequence: 1,
+			round:    1,
+			outgoing: 2, // preprepare and prepare
+			state:    ValidateState,
+		})
+		assert.True(t, m.state.proposal.IsNil())
+		assert.Equal(t, clock.Now(), m.state.proposal.Time)
+		assert.Equal(t, ProposalType_Nil, m.respMsg[0].ProposalType)
+	})
+
+	t.Run("Validator accepts nil proposal", func(t *testing.T) {
+		m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+		WithNilProposal(1)(m.config)
+		m.state.view = ViewMsg(1, 1)
+		m.setState(AcceptState)
+		m.emitMsg(nilPreprepare())
+
+		m.runCycle(context.Background())
+
+		m.expect(expectResult{
+			sequence: 1,
+			round:    1,
+			outgoing: 1, // prepare
+			state:    ValidateState,
+		})
+		assert.True(t, m.state.proposal.IsNil())
+	})
+
+	t.Run("Validator rejects nil proposal when disabled", func(t *testing.T) {
+		m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+		m.state.view = ViewMsg(1, 1)
+		m.setState(AcceptState)
+		m.emitMsg(nilPreprepare())
+
+		m.runCycle(context.Background())
+
+		assert.True(t, m.IsState(RoundChangeState))
+	})
+}
+
 func TestTransition_AcceptState_Proposer_Locked(t *testing.T) {
 	// we are in AcceptState, we are the proposer but the value is locked.
 	// it needs to send the locked proposal again
@@ -89,6 +179,142 @@ func TestTransition_AcceptState_Proposer_Locked(t *testing.T) {
 	assert.Equal(t, i.state.proposal.Data, mockProposal)
 }
 
+// Test that in strict mode a proposer does not send a prepare message for an invalid locked proposal.
+func TestTransition_AcceptState_Proposer_Locked_StrictValidation(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	validated := 0
+	backend := newMockBackend(validatorIds, CreateEqualVotingPowerMap(validatorIds), nil).HookValidateHandler(func(*Proposal) error {
+		validated++
+		return errors.New("invalid proposal")
+	})
+
+	run := func(opts ...ConfigOption) *mockPbft {
+		m := newMockPbft(t, validatorIds, nil, "A", backend)
+		m.config.ApplyOps(opts...)
+		m.setState(AcceptState)
+		m.state.lock()
+		m.state.proposal = &Proposal{
+			Data: mockProposal,
+			Hash: digest,
+		}
+		m.runCycle(context.Background())
+		return m
+	}
+
+	// by default the locked proposal is trusted
+	m := run()
+	m.expect(expectResult{
+		sequence: 1,
+		state:    ValidateState,
+		locked:   true,
+		outgoing: 2, // preprepare and prepare
+	})
+	assert.Equal(t, 0, validated)
+
+	// in strict mode the invalid proposal never produces a prepare message
+	m = run(WithStrictProposalValidation())
+	m.expect(expectResult{
+		sequence: 1,
+		state:    RoundChangeState,
+		locked:   true,
+	})
+	assert.Equal(t, 1, validated)
+	assert.Equal(t, mockProposal, m.state.proposal.Data)
+}
+
+// Test that a proposer locked in a previous round proposes the locked value again after the round change.
+func TestTransition_RoundChange_LockedProposerReproposes(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	backend := newMockBackend(validatorIds, CreateEqualVotingPowerMap(validatorIds), nil).HookBuildProposalHandler(func() (*Proposal, error) {
+		t.Fatal("a locked proposer must not build a fresh proposal")
+		return nil, nil
+	})
+	m := newMockPbft(t, validatorIds, nil, "B", backend)
+	m.state.view = ViewMsg(1, 0)
+	m.state.proposal = &Proposal{Data: mockProposal, Hash: digest}
+	m.setState(ValidateState)
+
+	message := func(from NodeID, typ MsgType, round uint64) Event {
+		msg := createMessage(from, typ, ViewMsg(1, round))
+		msg.Hash = digest
+		return MessageEvent(msg)
+	}
+	WithScheduler(NewDeterministicScheduler(
+		// the prepare quorum locks the proposal in round 0, the commits never arrive
+		message("A", MessageReq_Prepare, 0),
+		message("C", MessageReq_Prepare, 0),
+		message("D", MessageReq_Prepare, 0),
+		TimeoutEvent(),
+		// the round change quorum moves to round 1, where B is the proposer
+		message("A", MessageReq_RoundChange, 1),
+		message("C", MessageReq_RoundChange, 1),
+	))(m.config)
+
+	m.runCycle(context.Background())
+	require.Equal(t, RoundChangeState, m.getState())
+	require.True(t, m.state.IsLocked())
+	m.runCycle(context.Background())
+	require.Equal(t, AcceptState, m.getState())
+	m.respMsg = nil
+	m.runCycle(context.Background())
+
+	m.expect(expectResult{
+		sequence: 1,
+		round:    1,
+		state:    ValidateState,
+		locked:   true,
+		outgoing: 2, // preprepare and prepare
+	})
+	preprepare := m.respMsg[0]
+	assert.Equal(t, MessageReq_Preprepare, preprepare.Type)
+	assert.Equal(t, uint64(1), preprepare.View.Round)
+	assert.Equal(t, digest, preprepare.Hash)
+	assert.Equal(t, mockProposal, preprepare.Proposal)
+	assert.Equal(t, digest, m.LockedProposal().Hash)
+}
+
+// Test that the current view can be read while the state machine moves through the sequences and rounds (run with -race).
+func TestPbft_CurrentView_Concurrent(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.emitMsg(createMessage("B", MessageReq_RoundChange, ViewMsg(50, 1)))
+
+	done := make(chan struct{})
+	var wg, started sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		started.Add(1)
+		go func() {
+			defer wg.Done()
+			started.Done()
+			last := View{}
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				view := m.CurrentView()
+				assert.GreaterOrEqual(t, view.Sequence, last.Sequence)
+				last = view
+				m.Round()
+				m.ReadMessageWithDiscards()
+			}
+		}()
+	}
+
+	started.Wait()
+	for sequence := uint64(1); sequence <= 100; sequence++ {
+		m.setSequence(sequence)
+		for round := uint64(0); round < 3; round++ {
+			m.setRound(round)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	assert.Equal(t, View{Sequence: 100, Round: 2}, m.CurrentView())
+}
+
 func TestTransition_AcceptState_Validator_VerifyCorrect(t *testing.T) {
 	i := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "B")
 	i.state.view = ViewMsg(1, 0)
@@ -106,6 +332,35 @@ func TestTransition_AcceptState_Validator_VerifyCorrect(t *testing.T) {
 	})
 }
 
+func TestTransition_AcceptState_Validator_ClockSkew(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		name  string
+		ahead time.Duration
+		state State
+	}{
+		{"within skew", 2 * time.Second, ValidateState},
+		{"beyond skew", 2*time.Second + time.Millisecond, RoundChangeState},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			i := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "B")
+			WithClock(NewManualClock(now))(i.config)
+			WithMaxClockSkew(2 * time.Second)(i.config)
+			i.state.view = ViewMsg(1, 0)
+			i.setState(AcceptState)
+
+			msg := createMessage(NodeID("A"), MessageReq_Preprepare, ViewMsg(1, 0))
+			msg.ProposalTime = now.Add(c.ahead)
+			i.emitMsg(msg)
+
+			i.runCycle(context.Background())
+
+			assert.Equal(t, c.state, i.getState())
+		})
+	}
+}
+
 func TestTransition_AcceptState_Validator_VerifyFails(t *testing.T) {
 	t.Skip("involves validation of hash that is not done yet")
 
@@ -125,6 +380,31 @@ func TestTransition_AcceptState_Validator_VerifyFails(t *testing.T) {
 	})
 }
 
+// Test that the proposer validates its own proposal and abstains from broadcasting an invalid one.
+func TestTransition_AcceptState_Proposer_InvalidProposal(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	votingPowerMap := CreateEqualVotingPowerMap(validatorIds)
+	backend := newMockBackend(validatorIds, votingPowerMap, nil).HookValidateHandler(func(*Proposal) error {
+		return errors.New("invalid proposal")
+	})
+
+	m := newMockPbft(t, validatorIds, votingPowerMap, "A", backend)
+	m.setState(AcceptState)
+	m.setProposal(&Proposal{
+		Data: mockProposal,
+		Time: time.Now(),
+	})
+
+	m.runCycle(context.Background())
+
+	m.expect(expectResult{
+		sequence: 1,
+		state:    RoundChangeState,
+	})
+	assert.Empty(t, m.respMsg)
+	assert.Nil(t, m.state.proposal)
+}
+
 // Test that if build proposal fails, state machine will change state from AcceptState to RoundChangeState.
 func TestTransition_AcceptState_Proposer_FailedBuildProposal(t *testing.T) {
 	buildProposalFailure := func() (*Proposal, error) {
@@ -193,8 +473,10 @@ func TestTransition_AcceptState_Validator_ProposerInvalid(t *testing.T) {
 
 func TestTransition_AcceptState_Validator_LockWrong(t *testing.T) {
 	// We are a validator and have a locked state in 'proposal1'.
-	// We receive an invalid proposal 'proposal2' with different data.
+	// We receive an invalid proposal 'proposal2' with different data
+	// and treat it as a faulty proposer.
 	i := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "B")
+	WithLockConflictPolicy(LockConflict_RoundChange)(i.config)
 	i.state.view = ViewMsg(1, 0)
 	i.setState(AcceptState)
 
@@ -221,6 +503,40 @@ func TestTransition_AcceptState_Validator_LockWrong(t *testing.T) {
 	})
 }
 
+func TestTransition_AcceptState_Validator_LockConflictIgnored(t *testing.T) {
+	// By default, the pre-prepare conflicting with the locked proposal is dropped
+	// and the node keeps waiting for the locked proposal.
+	metrics := &recordingMetrics{}
+	i := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "B")
+	WithMetrics(metrics)(i.config)
+	i.state.view = ViewMsg(1, 0)
+	i.setState(AcceptState)
+
+	// locked proposal
+	i.state.proposal = &Proposal{
+		Data: mockProposal,
+		Hash: digest,
+	}
+	i.state.lock()
+
+	conflicting := createMessage(NodeID("A"), MessageReq_Preprepare, ViewMsg(1, 0))
+	conflicting.Proposal = mockProposal1
+	conflicting.Hash = digest1
+	i.emitMsg(conflicting)
+	i.emitMsg(createMessage(NodeID("A"), MessageReq_Preprepare, ViewMsg(1, 0)))
+
+	i.runCycle(context.Background())
+
+	i.expect(expectResult{
+		sequence: 1,
+		state:    ValidateState,
+		locked:   true,
+		outgoing: 1, // commit message of the locked proposal
+	})
+	assert.Equal(t, digest, i.state.proposal.Hash)
+	assert.Contains(t, metrics.records, "dropped Preprepare lock_conflict")
+}
+
 func TestTransition_AcceptState_Validator_LockCorrect(t *testing.T) {
 	i := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "B")
 	i.state.view = ViewMsg(1, 0)
@@ -266,6 +582,37 @@ func TestTransition_AcceptState_Validate_ProposalFail(t *testing.T) {
 	assert.True(t, m.IsState(RoundChangeState))
 }
 
+// Test that proposals are validated by the handler registered for their type instead of the backend.
+func TestTransition_AcceptState_Validate_ProposalHandler(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C"}
+	votingPowerMap := CreateEqualVotingPowerMap(validatorIds)
+	backend := newMockBackend(validatorIds, votingPowerMap, nil).HookValidateHandler(func(p *Proposal) error {
+		return errors.New("backend must not validate epoch change proposals")
+	})
+
+	m := newMockPbft(t, validatorIds, votingPowerMap, "B", backend)
+	handler := &mockProposalHandler{}
+	WithProposalHandler(ProposalType_EpochChange, handler)(m.config)
+
+	m.state.view = ViewMsg(1, 0)
+	m.setState(AcceptState)
+
+	msg := createMessage(NodeID("A"), MessageReq_Preprepare, ViewMsg(1, 0))
+	msg.ProposalType = ProposalType_EpochChange
+	m.emitMsg(msg)
+
+	m.runCycle(m.ctx)
+
+	m.expect(expectResult{
+		sequence: 1,
+		state:    ValidateState,
+		outgoing: 1, // prepare
+	})
+	require.Len(t, handler.validated, 1)
+	assert.Equal(t, ProposalType_EpochChange, handler.validated[0].Type)
+	assert.Equal(t, ProposalType_EpochChange, m.state.proposal.Type)
+}
+
 // Local node sending a messages isn't among validator set, so state machine should set state to SyncState
 func TestTransition_AcceptState_NonValidatorNode(t *testing.T) {
 	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "")
@@ -376,6 +723,45 @@ func TestTransition_RoundChangeState_WeakCertificate(t *testing.T) {
 	})
 }
 
+func TestTransition_AcceptState_RoundChangeCatchUp(t *testing.T) {
+	newNode := func() *mockPbft {
+		m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+		WithRoundChangeCatchUp()(m.config)
+		m.state.view = ViewMsg(1, 0)
+		m.setState(AcceptState)
+		return m
+	}
+
+	t.Run("f+1 higher round changes", func(t *testing.T) {
+		m := newNode()
+		m.emitMsg(createMessage("C", MessageReq_RoundChange, ViewMsg(1, 2)))
+		m.emitMsg(createMessage("D", MessageReq_RoundChange, ViewMsg(1, 2)))
+
+		m.runCycle(context.Background())
+		assert.Equal(t, RoundChangeState, m.getState())
+		assert.Empty(t, m.respMsg)
+
+		// the node jumps straight to the round 2
+		m.Close()
+		m.runCycle(context.Background())
+		m.expect(expectResult{
+			sequence: 1,
+			round:    2,
+			outgoing: 1, // round change for round 2
+			state:    RoundChangeState,
+		})
+	})
+
+	t.Run("f higher round changes", func(t *testing.T) {
+		m := newNode()
+		m.emitMsg(createMessage("C", MessageReq_RoundChange, ViewMsg(1, 2)))
+
+		assert.False(t, m.catchUpRound())
+		assert.Equal(t, AcceptState, m.getState())
+		assert.Equal(t, 1, m.msgQueue.roundChangeStateQueue.Len())
+	})
+}
+
 func TestTransition_RoundChangeState_ErrStartNewRound(t *testing.T) {
 	// if we start a round change because there was an error we start
 	// a new round right away
@@ -395,6 +781,43 @@ func TestTransition_RoundChangeState_ErrStartNewRound(t *testing.T) {
 	})
 }
 
+func TestTransition_RoundChangeState_FailedProposers(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.Close()
+
+	// round 0 proposer (A) failed
+	m.state.err = errVerificationFailed
+	m.setState(RoundChangeState)
+	m.runCycle(context.Background())
+	assert.Equal(t, map[NodeID]uint64{"A": 1}, m.FailedProposers())
+
+	// round 1 proposer (B) failed too
+	m.state.err = errVerificationFailed
+	m.setState(RoundChangeState)
+	m.runCycle(context.Background())
+	assert.Equal(t, map[NodeID]uint64{"A": 1, "B": 1}, m.FailedProposers())
+
+	// finalizing the sequence resets the counters
+	m.state.proposer = "C"
+	m.setState(CommitState)
+	m.runCycle(context.Background())
+	assert.True(t, m.IsState(DoneState))
+	assert.Empty(t, m.FailedProposers())
+}
+
+func TestPbft_PenalizeSkippedRoundProposers(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+
+	// jumping from round 0 to round 3 penalizes the proposers of the rounds 0 to 2
+	m.penalizeRoundProposer(3)
+	assert.Equal(t, map[NodeID]uint64{"A": 1, "B": 1, "C": 1}, m.FailedProposers())
+
+	// at most one rotation is penalized for a round far ahead
+	m.state.SetCurrentRound(3)
+	m.penalizeRoundProposer(1 << 40)
+	assert.Equal(t, map[NodeID]uint64{"A": 2, "B": 2, "C": 2, "D": 1}, m.FailedProposers())
+}
+
 func TestTransition_RoundChangeState_StartNewRound(t *testing.T) {
 	// if we start round change due to a state timeout and we are on the
 	// correct sequence, we start a new round
@@ -509,6 +932,36 @@ func TestTransition_ValidateState_MoveToCommitState(t *testing.T) {
 
 }
 
+// Test that the commit phase consults the commit quorum, which can be higher than the prepare one.
+func TestTransition_ValidateState_CommitQuorum(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	require.NoError(t, m.state.initializePhaseQuorums(nil, func(total uint64) uint64 { return total }))
+	m.setState(ValidateState)
+
+	m.emitMsg(createMessage(NodeID("A"), MessageReq_Prepare, nil))
+	m.emitMsg(createMessage(NodeID("B"), MessageReq_Prepare, nil))
+	m.emitMsg(createMessage(NodeID("C"), MessageReq_Prepare, nil))
+
+	m.emitMsg(createMessage(NodeID("B"), MessageReq_Commit, nil))
+	m.emitMsg(createMessage(NodeID("C"), MessageReq_Commit, nil))
+
+	m.runCycle(context.Background())
+
+	// the commits of A, B and C reach the default quorum but not the commit quorum
+	m.expect(expectResult{
+		sequence:               1,
+		state:                  RoundChangeState,
+		prepareMsgs:            3,
+		prepareMsgsVotingPower: 3,
+		commitMsgs:             3,
+		commitMsgsVotingPower:  3,
+		locked:                 true,
+		outgoing:               1, // A commit message
+	})
+	assert.Equal(t, uint64(3), m.PrepareQuorumSize())
+	assert.Equal(t, uint64(4), m.CommitQuorumSize())
+}
+
 // Not enough messages are sent, so ensure that destination state is RoundChangeState and that state machine jumps out of the loop.
 func TestTransition_ValidateState_MoveToRoundChangeState(t *testing.T) {
 	t.Run("All the validators have the same voting powers", func(t *testing.T) {
@@ -609,6 +1062,36 @@ func TestTransition_CommitState_DoneState(t *testing.T) {
 	})
 }
 
+// Test that the pipelined sequences with a commit quorum are finalized in order after the current one.
+func TestTransition_CommitState_Pipelined(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
+	m.pipeline = newPipeline(2)
+	m.pipeline.advance(1)
+	m.state.view = ViewMsg(1, 0)
+	m.state.proposer = "A"
+	m.setState(CommitState)
+
+	// sequence 2 reached the commit quorum, sequence 3 has no proposal
+	m.emitMsg(createMessage("B", MessageReq_Preprepare, ViewMsg(2, 0)))
+	for _, from := range []NodeID{"A", "B", "C"} {
+		m.emitMsg(createMessage(from, MessageReq_Commit, ViewMsg(2, 0)))
+		m.emitMsg(createMessage(from, MessageReq_Commit, ViewMsg(3, 0)))
+	}
+
+	m.runCycle(context.Background())
+
+	m.expect(expectResult{
+		sequence: 1,
+		state:    DoneState,
+	})
+	inserted := m.backend.(*mockBackend).inserted
+	require.Len(t, inserted, 2)
+	assert.Equal(t, uint64(1), inserted[0].Number)
+	assert.Equal(t, uint64(2), inserted[1].Number)
+	assert.Equal(t, NodeID("B"), inserted[1].Proposer)
+	assert.Len(t, inserted[1].CommittedSeals, 3)
+}
+
 // Test CommitState to RoundChange transition.
 func TestTransition_CommitState_RoundChange(t *testing.T) {
 	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
@@ -625,6 +1108,29 @@ func TestTransition_CommitState_RoundChange(t *testing.T) {
 	assert.True(t, m.IsState(RoundChangeState))
 }
 
+// Test that a commit referencing another proposal prevents the insertion and starts a round change.
+func TestTransition_CommitState_CommittedDisagreement(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.state.view = ViewMsg(1, 0)
+	m.state.proposer = "A"
+	m.state.proposal = &Proposal{Data: mockProposal, Hash: digest}
+	for _, from := range []NodeID{"A", "B", "C", "D"} {
+		msg := createMessage(from, MessageReq_Commit, ViewMsg(1, 0))
+		msg.Hash = digest
+		if from == "C" {
+			msg.Hash = []byte{0x2}
+		}
+		m.state.committed.addMessage(msg, 1)
+	}
+	m.setState(CommitState)
+
+	m.runCycle(context.Background())
+
+	assert.Equal(t, RoundChangeState, m.getState())
+	assert.ErrorIs(t, m.state.getErr(), ErrCommittedDisagreement)
+	assert.Empty(t, m.backend.(*mockBackend).inserted)
+}
+
 // Test exponential timeout for various rounds.
 func TestExponentialTimeout(t *testing.T) {
 	testCases := []struct {
@@ -651,6 +1157,38 @@ func TestExponentialTimeout(t *testing.T) {
 	}
 }
 
+func TestConfig_Round0Timeout(t *testing.T) {
+	laterRounds := []uint64{}
+	laterRoundTimeout := func(round uint64) <-chan time.Time {
+		laterRounds = append(laterRounds, round)
+		return time.After(time.Hour)
+	}
+
+	fired := func(timeout <-chan time.Time, wait time.Duration) bool {
+		select {
+		case <-timeout:
+			return true
+		case <-time.After(wait):
+			return false
+		}
+	}
+
+	// without round 0 timeout every round uses the round timeout
+	config := DefaultConfig()
+	config.ApplyOps(WithRoundTimeout(laterRoundTimeout))
+	assert.False(t, fired(config.roundTimeout()(0), 10*time.Millisecond))
+	assert.Equal(t, []uint64{0}, laterRounds)
+
+	// round 0 uses its own timeout, the later rounds the round timeout
+	laterRounds = laterRounds[:0]
+	config = DefaultConfig()
+	config.ApplyOps(WithRound0Timeout(10*time.Millisecond), WithRoundTimeout(laterRoundTimeout))
+	roundTimeout := config.roundTimeout()
+	assert.True(t, fired(roundTimeout(0), time.Second))
+	assert.False(t, fired(roundTimeout(1), 50*time.Millisecond))
+	assert.Equal(t, []uint64{1}, laterRounds)
+}
+
 // Ensure that DoneState cannot be set as initial state of state machine.
 func TestDoneState_RunCycle_Panics(t *testing.T) {
 	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
@@ -727,6 +1265,39 @@ func TestGossip_SignProposalFailed(t *testing.T) {
 	assert.Empty(t, m.msgQueue.validateStateQueue)
 }
 
+// Test that a sync request and its response are delivered only to the addressed nodes.
+func TestTransport_Send_SyncRequestResponse(t *testing.T) {
+	transport := &TransportStub{}
+	for _, id := range []NodeID{"A", "B", "C", "D"} {
+		node := New(ValidatorKeyMock(id), transport, WithLogger(log.New(io.Discard, "", log.LstdFlags)))
+		node.setState(ValidateState)
+		transport.Nodes = append(transport.Nodes, node)
+	}
+	a, b, c, d := transport.Nodes[0], transport.Nodes[1], transport.Nodes[2], transport.Nodes[3]
+	a.setState(SyncState)
+
+	// A requests the sequence 1 to B
+	request := createMessage("A", MessageReq_RoundChange, ViewMsg(1, 0))
+	require.NoError(t, transport.Send("B", request))
+	assert.Equal(t, 1, b.msgQueue.getQueue(RoundChangeState).Len())
+
+	// B responds to A only
+	response := createMessage("B", MessageReq_Commit, ViewMsg(1, 0))
+	response.Hash = digest
+	require.NoError(t, transport.Send("A", response))
+	require.Len(t, a.syncMsgs.msgs, 1)
+	assert.True(t, response.Equal(a.syncMsgs.msgs[0]))
+
+	for _, node := range []*Pbft{a, c, d} {
+		assert.Equal(t, 0, node.msgQueue.getQueue(RoundChangeState).Len())
+	}
+	for _, node := range []*Pbft{b, c, d} {
+		assert.Equal(t, 0, node.msgQueue.getQueue(ValidateState).Len())
+	}
+
+	assert.Error(t, transport.Send("E", request))
+}
+
 func TestRoundChange_PropertyMajorityOfVotingPowerAggreement(t *testing.T) {
 	rapid.Check(t, func(t *rapid.T) {
 		numberOfNodes := rapid.IntRange(4, 100).Draw(t, "Generate number of nodes").(int)
@@ -792,6 +1363,7 @@ type mockPbft struct {
 	t        *testing.T
 	pool     *testerAccountPool
 	respMsg  []*MessageReq
+	sentTo   []NodeID
 	proposal *Proposal
 	sequence uint64
 	cancelFn context.CancelFunc
@@ -814,6 +1386,12 @@ func (m *mockPbft) Gossip(msg *MessageReq) error {
 	return nil
 }
 
+func (m *mockPbft) Send(to NodeID, msg *MessageReq) error {
+	m.respMsg = append(m.respMsg, msg)
+	m.sentTo = append(m.sentTo, to)
+	return nil
+}
+
 func (m *mockPbft) CalculateTimeout() time.Duration {
 	return time.Millisecond
 }
@@ -955,11 +1533,29 @@ func (m *mockPbft) expect(res expectResult) {
 	if size := len(m.respMsg); uint64(size) != res.outgoing {
 		m.t.Fatalf("incorrect outgoing messages actual: %v, expected: %v", size, res.outgoing)
 	}
+	if err := m.state.checkInvariants(); err != nil {
+		m.t.Fatal(err)
+	}
 	if m.state.err != res.err {
 		m.t.Fatalf("incorrect error actual: %v, expected: %v", m.state.err, res.err)
 	}
 }
 
+type mockProposalHandler struct {
+	validated []*Proposal
+	inserted  []*SealedProposal
+}
+
+func (m *mockProposalHandler) Validate(p *Proposal) error {
+	m.validated = append(m.validated, p)
+	return nil
+}
+
+func (m *mockProposalHandler) Insert(p *SealedProposal) error {
+	m.inserted = append(m.inserted, p)
+	return nil
+}
+
 type buildProposalDelegate func() (*Proposal, error)
 type validateDelegate func(*Proposal) error
 type isStuckDelegate func(uint64) (uint64, bool)
@@ -970,6 +1566,8 @@ type mockBackend struct {
 	buildProposalFn buildProposalDelegate
 	validateFn      validateDelegate
 	isStuckFn       isStuckDelegate
+	seed            []byte
+	inserted        []*SealedProposal
 }
 
 func (m *mockBackend) HookBuildProposalHandler(buildProposal buildProposalDelegate) *mockBackend {
@@ -1031,6 +1629,7 @@ func (m *mockBackend) Insert(pp *SealedProposal) error {
 	if pp.Proposer == "" {
 		return errVerificationFailed
 	}
+	m.inserted = append(m.inserted, pp)
 	return nil
 }
 
@@ -1040,3 +1639,62 @@ func (m *mockBackend) ValidatorSet() ValidatorSet {
 
 func (m *mockBackend) Init(*RoundInfo) {
 }
+
+func (m *mockBackend) ProposerSeed() []byte {
+	return m.seed
+}
+
+func TestPbft_HasCommitQuorum(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D", "E"}, nil, "A")
+	m.state.view = ViewMsg(1, 0)
+	require.Equal(t, uint64(3), m.QuorumSize())
+
+	otherDigest := []byte{0x2}
+	commit := func(from NodeID, hash []byte) {
+		msg := createMessage(from, MessageReq_Commit, ViewMsg(1, 0))
+		msg.Hash = hash
+		require.NoError(t, m.state.addCommitMsg(msg))
+	}
+
+	// the commits are split between two proposals, neither reaches the quorum
+	// even though all the commits together do
+	commit("A", digest)
+	commit("B", digest)
+	commit("C", otherDigest)
+	commit("D", otherDigest)
+	assert.GreaterOrEqual(t, m.state.committed.getAccumulatedVotingPower(), m.QuorumSize())
+	assert.False(t, m.HasCommitQuorum(digest))
+	assert.False(t, m.HasCommitQuorum(otherDigest))
+
+	// one more commit backing the first proposal reaches the quorum
+	commit("E", digest)
+	assert.True(t, m.HasCommitQuorum(digest))
+	assert.False(t, m.HasCommitQuorum(otherDigest))
+}
+
+func TestPbft_RemainingPower(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, map[NodeID]uint64{"A": 1, "B": 2, "C": 3, "D": 4}, "A")
+	m.state.view = ViewMsg(1, 0)
+	require.Equal(t, uint64(7), m.PrepareQuorumSize())
+	require.Equal(t, uint64(7), m.CommitQuorumSize())
+
+	// the remaining power decreases with every message and reaches zero exactly with the quorum
+	steps := []struct {
+		from      NodeID
+		remaining uint64
+	}{
+		{"D", 3},
+		{"A", 2},
+		{"C", 0},
+		{"B", 0},
+	}
+	for _, step := range steps {
+		require.NoError(t, m.state.addPrepareMsg(createMessage(step.from, MessageReq_Prepare, ViewMsg(1, 0))))
+		assert.Equal(t, step.remaining, m.RemainingPowerForPrepare())
+		assert.Equal(t, step.remaining == 0, m.state.prepared.getAccumulatedVotingPower() >= m.state.getPrepareQuorumSize())
+
+		require.NoError(t, m.state.addCommitMsg(createMessage(step.from, MessageReq_Commit, ViewMsg(1, 0))))
+		assert.Equal(t, step.remaining, m.RemainingPowerForCommit())
+		assert.Equal(t, step.remaining == 0, m.state.committed.getAccumulatedVotingPower() >= m.state.getCommitQuorumSize())
+	}
+}
@@ -0,0 +1,197 @@
+package pbft
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+)
+
+// errUnverifiableVote is returned when the seal of a vote can not be verified, so that it is no evidence of a fault
+var errUnverifiableVote = errors.New("vote seal can not be verified")
+
+// CrossPhaseEquivocationProof is the evidence of a node sending a Prepare and a Commit message for different
+// proposals in the same view. Unlike a double proposal, the conflicting messages belong to different phases.
+// Both messages are sealed by the sender, hence the detection requires the prepared certificates.
+type CrossPhaseEquivocationProof struct {
+	// Sender is the node that sent both messages
+	Sender NodeID
+
+	// Prepare is the Prepare message received from the sender
+	Prepare *MessageReq
+
+	// Commit is the Commit message received from the sender for another proposal
+	Commit *MessageReq
+}
+
+// crossPhaseVotes are the first Prepare and Commit messages received from a node in a round
+type crossPhaseVotes struct {
+	prepare *MessageReq
+	commit  *MessageReq
+}
+
+// crossPhaseDetector tracks the Prepare and Commit messages sent by the validators of the current sequence
+type crossPhaseDetector struct {
+	lock sync.Mutex
+
+	// sequence is the current sequence
+	sequence uint64
+
+	// validators is the validator set of the current sequence
+	validators ValidatorSet
+
+	// votes are the messages received for each round, by sender
+	votes map[uint64]map[NodeID]*crossPhaseVotes
+
+	// proofs are the detected cross phase equivocations
+	proofs []*CrossPhaseEquivocationProof
+
+	// limit is the maximum number of proofs retained (zero is unbounded)
+	limit int
+
+	// maxAge is the number of sequences the proofs are retained for (zero is unbounded)
+	maxAge uint64
+}
+
+func newCrossPhaseDetector(limit int, maxAge uint64) *crossPhaseDetector {
+	return &crossPhaseDetector{
+		votes:  map[uint64]map[NodeID]*crossPhaseVotes{},
+		limit:  limit,
+		maxAge: maxAge,
+	}
+}
+
+// reset starts tracking a new sequence
+func (d *crossPhaseDetector) reset(sequence uint64, validators ValidatorSet) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.sequence = sequence
+	d.validators = validators
+	d.votes = map[uint64]map[NodeID]*crossPhaseVotes{}
+	d.prune()
+}
+
+// check records the Prepare or Commit message and returns the cross phase equivocation proof if the sender
+// already sent a message of the other phase for another proposal in the same view.
+// The proof is recorded once per sender and round.
+func (d *crossPhaseDetector) check(msg *MessageReq) *CrossPhaseEquivocationProof {
+	if (msg.Type != MessageReq_Prepare && msg.Type != MessageReq_Commit) || msg.View == nil {
+		return nil
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.validators == nil || msg.View.Sequence != d.sequence || !d.validators.Includes(msg.From) {
+		return nil
+	}
+
+	senders, ok := d.votes[msg.View.Round]
+	if !ok {
+		senders = map[NodeID]*crossPhaseVotes{}
+		d.votes[msg.View.Round] = senders
+	}
+	votes, ok := senders[msg.From]
+	if !ok {
+		votes = &crossPhaseVotes{}
+		senders[msg.From] = votes
+	}
+
+	// only the first message of each phase is kept, the same phase equivocations are not in scope
+	conflicting := votes.commit
+	if msg.Type == MessageReq_Commit {
+		conflicting = votes.prepare
+	}
+	if msg.Type == MessageReq_Prepare && votes.prepare == nil {
+		votes.prepare = msg.Copy()
+	} else if msg.Type == MessageReq_Commit && votes.commit == nil {
+		votes.commit = msg.Copy()
+	}
+	if conflicting == nil || bytes.Equal(conflicting.Hash, msg.Hash) {
+		return nil
+	}
+
+	for _, proof := range d.proofs {
+		if proof.Sender == msg.From && proof.Prepare.View.Sequence == msg.View.Sequence &&
+			proof.Prepare.View.Round == msg.View.Round {
+			return nil
+		}
+	}
+
+	proof := &CrossPhaseEquivocationProof{Sender: msg.From}
+	if msg.Type == MessageReq_Prepare {
+		proof.Prepare, proof.Commit = msg.Copy(), conflicting.Copy()
+	} else {
+		proof.Prepare, proof.Commit = conflicting.Copy(), msg.Copy()
+	}
+	d.proofs = append(d.proofs, proof)
+	d.prune()
+	return proof
+}
+
+// prune drops the evidences beyond the retention bounds (see WithEvidenceRetention)
+func (d *crossPhaseDetector) prune() {
+	if d.limit == 0 && d.maxAge == 0 {
+		return
+	}
+
+	sequences := make([]uint64, len(d.proofs))
+	for i, proof := range d.proofs {
+		sequences[i] = proof.Prepare.View.Sequence
+	}
+	proofs := d.proofs[:0]
+	for i, keep := range retainEvidence(sequences, d.sequence, d.limit, d.maxAge) {
+		if keep {
+			proofs = append(proofs, d.proofs[i])
+		}
+	}
+	d.proofs = proofs
+}
+
+// getProofs returns the detected cross phase equivocations
+func (d *crossPhaseDetector) getProofs() []*CrossPhaseEquivocationProof {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return append([]*CrossPhaseEquivocationProof{}, d.proofs...)
+}
+
+// verifyVote verifies the sender and the seal of a Prepare or Commit message, so that it can be held as evidence.
+// The prepare messages are sealed with the prepared certificates only (see WithPreparedCertificates), and the seal
+// of a commit message for another proposal can only be verified when the seal covers the proposal hash.
+func (p *Pbft) verifyVote(msg *MessageReq) error {
+	if !p.state.validators.Includes(msg.From) {
+		return ErrNotValidator
+	}
+	switch msg.Type {
+	case MessageReq_Prepare:
+		if !p.config.PreparedCertificates || len(msg.Seal) == 0 {
+			return errUnverifiableVote
+		}
+		return p.validatePrepareSeal(msg)
+	case MessageReq_Commit:
+		if p.config.SealHash != nil {
+			return errUnverifiableVote
+		}
+		if _, err := DecodeSeal(p.config.SealFormat, msg.Seal); err != nil {
+			return err
+		}
+		return p.validateCommitSeal(msg.From, p.roundSealHash(msg.View.Round, msg.Hash), msg.Seal)
+	default:
+		return errUnverifiableVote
+	}
+}
+
+// recordVote holds the verified Prepare or Commit message, to detect the cross phase equivocation of its sender
+func (p *Pbft) recordVote(msg *MessageReq) {
+	if proof := p.crossPhase.check(msg); proof != nil {
+		p.logger.Printf("[WARN] prepare and commit for different proposals: sender=%s, view=%s", proof.Sender, msg.View)
+		p.checkSafetyThreshold()
+	}
+}
+
+// CrossPhaseEquivocations returns the evidences of nodes that sent a Prepare and a Commit message for
+// different proposals in the same view, within the retention bounds (see WithEvidenceRetention)
+func (p *Pbft) CrossPhaseEquivocations() []*CrossPhaseEquivocationProof {
+	return p.crossPhase.getProofs()
+}
@@ -0,0 +1,96 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPbft_CrossPhaseEquivocation(t *testing.T) {
+	vote := func(from NodeID, typ MsgType, round uint64, hash []byte) *MessageReq {
+		msg := createMessage(from, typ, ViewMsg(1, round))
+		msg.Hash = hash
+		return msg
+	}
+
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+
+	// consistent votes and same phase messages are not cross phase equivocations
+	m.crossPhase.check(vote("C", MessageReq_Prepare, 0, digest))
+	m.crossPhase.check(vote("C", MessageReq_Commit, 0, digest))
+	m.crossPhase.check(vote("D", MessageReq_Prepare, 0, digest))
+	m.crossPhase.check(vote("D", MessageReq_Prepare, 0, digest1))
+	// the votes of different rounds do not conflict
+	m.crossPhase.check(vote("D", MessageReq_Commit, 1, digest1))
+	// nor the ones of nodes outside the validator set
+	m.crossPhase.check(vote("E", MessageReq_Prepare, 0, digest))
+	m.crossPhase.check(vote("E", MessageReq_Commit, 0, digest1))
+	assert.Empty(t, m.CrossPhaseEquivocations())
+
+	// B prepares on one proposal and commits to another one
+	m.crossPhase.check(vote("B", MessageReq_Prepare, 0, digest))
+	m.crossPhase.check(vote("B", MessageReq_Commit, 0, digest1))
+
+	proofs := m.CrossPhaseEquivocations()
+	require.Len(t, proofs, 1)
+	assert.Equal(t, NodeID("B"), proofs[0].Sender)
+	assert.Equal(t, MessageReq_Prepare, proofs[0].Prepare.Type)
+	assert.Equal(t, digest, proofs[0].Prepare.Hash)
+	assert.Equal(t, MessageReq_Commit, proofs[0].Commit.Type)
+	assert.Equal(t, digest1, proofs[0].Commit.Hash)
+
+	// the proof is recorded once per view, regardless of the phase order
+	m.crossPhase.check(vote("B", MessageReq_Commit, 0, digest1))
+	assert.Len(t, m.CrossPhaseEquivocations(), 1)
+
+	m.crossPhase.check(vote("C", MessageReq_Commit, 2, digest1))
+	m.crossPhase.check(vote("C", MessageReq_Prepare, 2, digest))
+	proofs = m.CrossPhaseEquivocations()
+	require.Len(t, proofs, 2)
+	assert.Equal(t, digest, proofs[1].Prepare.Hash)
+	assert.Equal(t, digest1, proofs[1].Commit.Hash)
+}
+
+// Test that only the votes with a verified sender and seal are held as evidence, since the evidence may halt the node.
+func TestTransition_ValidateState_CrossPhaseEquivocation(t *testing.T) {
+	m := newCertificateMockPbft(t)
+	WithHaltOnSafetyBreach()(m.config)
+	m.state.proposer = "A"
+	m.setState(ValidateState)
+
+	sealedCommit := func(from NodeID, hash []byte, signer NodeID) *MessageReq {
+		msg := createMessage(from, MessageReq_Commit, ViewMsg(1, 0))
+		msg.Hash = hash
+		seal, err := m.pool.signer(signer).Sign(hash)
+		require.NoError(t, err)
+		msg.Seal = seal
+		return msg
+	}
+	unsealedPrepare := func(from NodeID, hash []byte) *MessageReq {
+		msg := createMessage(from, MessageReq_Prepare, ViewMsg(1, 0))
+		msg.Hash = hash
+		return msg
+	}
+
+	// B prepares on the proposal and commits to another one
+	m.emitMsg(sealedPrepare(t, m, "B", ViewMsg(1, 0)))
+	m.emitMsg(sealedCommit("B", digest1, "B"))
+	// the commit of D for another proposal is sealed with the key of C
+	m.emitMsg(sealedPrepare(t, m, "D", ViewMsg(1, 0)))
+	m.emitMsg(sealedCommit("D", digest1, "C"))
+	// the prepare of C for another proposal carries no seal
+	m.emitMsg(unsealedPrepare("C", digest1))
+	m.emitMsg(sealedCommit("C", digest, "C"))
+
+	m.state.timeoutChan = time.After(100 * time.Millisecond)
+	m.runCycle(context.Background())
+
+	proofs := m.CrossPhaseEquivocations()
+	require.Len(t, proofs, 1)
+	assert.Equal(t, NodeID("B"), proofs[0].Sender)
+	assert.Equal(t, []NodeID{"B"}, m.FaultyValidators())
+	assert.NoError(t, m.Halted())
+}
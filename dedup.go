@@ -0,0 +1,96 @@
+package pbft
+
+import "sync"
+
+// maxDedupSequences is the maximum number of sequences tracked at the same time by the sequence dedup
+const maxDedupSequences = 16
+
+// msgIdentity identifies a message within the dedup window of its sequence
+type msgIdentity struct {
+	round uint64
+	typ   MsgType
+	from  NodeID
+	hash  string
+}
+
+// sequenceDedup tracks the identities of the messages received per active sequence, so that
+// the repeated deliveries of the transport are dropped. The window of a sequence is released
+// as soon as it is finalized, so the memory is bounded by the number of active sequences.
+type sequenceDedup struct {
+	lock sync.Mutex
+
+	// finalized is the last finalized sequence, its messages and the older ones are not tracked
+	finalized uint64
+
+	// windows are the identities of the messages received per sequence
+	windows map[uint64]map[msgIdentity]struct{}
+}
+
+func newSequenceDedup() *sequenceDedup {
+	return &sequenceDedup{
+		windows: map[uint64]map[msgIdentity]struct{}{},
+	}
+}
+
+// seen records the message and returns true if it was already received. The messages of the finalized
+// sequences, and of new sequences once maxDedupSequences are tracked, are not recorded.
+func (d *sequenceDedup) seen(msg *MessageReq) bool {
+	if msg.View == nil {
+		return false
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	sequence := msg.View.Sequence
+	if sequence <= d.finalized {
+		return false
+	}
+	window, ok := d.windows[sequence]
+	if !ok {
+		if len(d.windows) >= maxDedupSequences {
+			return false
+		}
+		window = map[msgIdentity]struct{}{}
+		d.windows[sequence] = window
+	}
+
+	id := msgIdentity{
+		round: msg.View.Round,
+		typ:   msg.Type,
+		from:  msg.From,
+		hash:  string(msg.Hash),
+	}
+	if _, ok := window[id]; ok {
+		return true
+	}
+	window[id] = struct{}{}
+	return false
+}
+
+// finalize releases the windows of the sequence and of the previous ones
+func (d *sequenceDedup) finalize(sequence uint64) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if sequence > d.finalized {
+		d.finalized = sequence
+	}
+	for s := range d.windows {
+		if s <= sequence {
+			delete(d.windows, s)
+		}
+	}
+}
+
+// len returns the number of messages tracked across all the windows
+func (d *sequenceDedup) len() int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	n := 0
+	for _, window := range d.windows {
+		n += len(window)
+	}
+	return n
+}
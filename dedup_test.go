@@ -0,0 +1,68 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequenceDedup(t *testing.T) {
+	d := newSequenceDedup()
+	prepare := createMessage("A", MessageReq_Prepare, ViewMsg(1, 0))
+	prepare.Hash = digest
+
+	assert.False(t, d.seen(prepare))
+	assert.True(t, d.seen(prepare.Copy()))
+
+	// other rounds, types, senders and hashes are different messages
+	assert.False(t, d.seen(createMessage("A", MessageReq_Prepare, ViewMsg(1, 1))))
+	assert.False(t, d.seen(createMessage("A", MessageReq_Commit, ViewMsg(1, 0))))
+	assert.False(t, d.seen(createMessage("B", MessageReq_Prepare, ViewMsg(1, 0))))
+	other := prepare.Copy()
+	other.Hash = []byte("other")
+	assert.False(t, d.seen(other))
+	assert.False(t, d.seen(createMessage("A", MessageReq_Prepare, ViewMsg(2, 0))))
+	assert.Equal(t, 6, d.len())
+
+	// the windows of the finalized sequences are released
+	d.finalize(1)
+	assert.Equal(t, 1, d.len())
+	assert.False(t, d.seen(prepare))
+	assert.False(t, d.seen(prepare))
+	assert.Equal(t, 1, d.len())
+}
+
+func TestSequenceDedup_Bounded(t *testing.T) {
+	d := newSequenceDedup()
+	for sequence := uint64(1); sequence <= maxDedupSequences+4; sequence++ {
+		d.seen(createMessage("A", MessageReq_Prepare, ViewMsg(sequence, 0)))
+	}
+	assert.Len(t, d.windows, maxDedupSequences)
+
+	// the messages of untracked sequences are not deduplicated
+	msg := createMessage("A", MessageReq_Prepare, ViewMsg(maxDedupSequences+4, 0))
+	assert.False(t, d.seen(msg))
+}
+
+// Test that finalizing a sequence clears its dedup entries.
+func TestPbft_SequenceDedup_ClearedAtFinalization(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.dedup = newSequenceDedup()
+
+	prepare := createMessage("B", MessageReq_Prepare, ViewMsg(1, 0))
+	m.emitMsg(prepare)
+	m.emitMsg(prepare.Copy())
+	assert.Equal(t, 1, m.msgQueue.validateStateQueue.Len())
+	assert.Equal(t, uint64(1), m.stats.DroppedMsgCount(MessageReq_Prepare.String()))
+	assert.Equal(t, 1, m.dedup.len())
+
+	m.state.view = ViewMsg(1, 0)
+	m.state.proposer = "A"
+	m.setState(CommitState)
+	m.runCycle(context.Background())
+	require.Equal(t, DoneState, m.getState())
+
+	assert.Zero(t, m.dedup.len())
+}
@@ -0,0 +1,86 @@
+package pbft
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// dropLogInterval is the interval over which the logs of the dropped messages are limited (see WithDropLogRateLimit)
+const dropLogInterval = time.Second
+
+// dropLogRejected is the log reason of the messages rejected by the state for another reason than the sender
+const dropLogRejected = "rejected"
+
+// dropLog limits the logs of the dropped messages per drop reason, so that a flood of dropped messages does not
+// flood the logs. Up to limit logs of a reason are written in every interval, the number of the ones suppressed
+// is summarized with the first log of the reason in a later interval.
+type dropLog struct {
+	lock  sync.Mutex
+	limit int
+
+	// windows are the current intervals by drop reason
+	windows map[string]*dropLogWindow
+}
+
+// dropLogWindow is the interval of a drop reason
+type dropLogWindow struct {
+	start      time.Time
+	logged     int
+	suppressed int
+}
+
+func newDropLog(limit int) *dropLog {
+	return &dropLog{
+		limit:   limit,
+		windows: map[string]*dropLogWindow{},
+	}
+}
+
+// allow returns whether a drop of the reason at the given time is logged, and the number of the logs of the reason
+// suppressed in the previous interval, to be summarized
+func (d *dropLog) allow(reason string, now time.Time) (bool, int) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	suppressed := 0
+	window, ok := d.windows[reason]
+	if !ok || now.Sub(window.start) >= dropLogInterval {
+		if ok {
+			suppressed = window.suppressed
+		}
+		window = &dropLogWindow{start: now}
+		d.windows[reason] = window
+	}
+	if window.logged >= d.limit {
+		window.suppressed++
+		return false, suppressed
+	}
+	window.logged++
+	return true, suppressed
+}
+
+// logDrop logs a dropped message of the given reason, within the rate limit if set
+func (p *Pbft) logDrop(reason string, format string, args ...interface{}) {
+	if p.dropLog == nil {
+		p.logger.Printf(format, args...)
+		return
+	}
+	allowed, suppressed := p.dropLog.allow(reason, p.config.Clock.Now())
+	if suppressed > 0 {
+		p.logger.Printf("[WARN] %d logs of messages dropped as %s suppressed", suppressed, reason)
+	}
+	if allowed {
+		p.logger.Printf(format, args...)
+	}
+}
+
+// rejectMessage logs a message the state did not add, the messages of the non validators are reported as dropped
+func (p *Pbft) rejectMessage(msg *MessageReq, err error) {
+	reason := dropLogRejected
+	if errors.Is(err, ErrNotValidator) {
+		reason = DropReasonNotValidator
+		p.dropMessage(msg, reason)
+	}
+	p.logDrop(reason, "[DEBUG] %s message from node %s dropped: %v", msg.Type, msg.From, err)
+}
@@ -0,0 +1,79 @@
+package pbft
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDropLog_Allow(t *testing.T) {
+	d := newDropLog(2)
+	now := time.Now()
+
+	for i, expected := range []bool{true, true, false, false} {
+		allowed, suppressed := d.allow(DropReasonDuplicate, now)
+		assert.Equal(t, expected, allowed, i)
+		assert.Zero(t, suppressed)
+	}
+	// the reasons are limited independently
+	allowed, _ := d.allow(DropReasonTooOld, now)
+	assert.True(t, allowed)
+
+	// the suppressed logs are reported once the interval ends
+	allowed, suppressed := d.allow(DropReasonDuplicate, now.Add(dropLogInterval/2))
+	assert.False(t, allowed)
+	assert.Zero(t, suppressed)
+	allowed, suppressed = d.allow(DropReasonDuplicate, now.Add(dropLogInterval))
+	assert.True(t, allowed)
+	assert.Equal(t, 3, suppressed)
+}
+
+// Test that a burst of messages from a non validator is logged within the rate limit, with a summary of the suppressed logs.
+func TestPbft_DropLogRateLimit(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	output := &bytes.Buffer{}
+	m.logger = log.New(output, "", 0)
+	clock := NewManualClock(time.Now())
+	WithClock(clock)(m.config)
+	m.dropLog = newDropLog(5)
+
+	logged := func() (int, string) {
+		lines := strings.Split(output.String(), "\n")
+		drops, summary := 0, ""
+		for _, line := range lines {
+			if strings.Contains(line, "from node X dropped") {
+				drops++
+			}
+			if strings.Contains(line, "suppressed") {
+				summary = line
+			}
+		}
+		return drops, summary
+	}
+
+	m.setState(ValidateState)
+	m.state.timeoutChan = time.After(10 * time.Millisecond)
+	for i := 0; i < 100; i++ {
+		m.emitMsg(createMessage("X", MessageReq_Prepare, nil))
+	}
+	m.runCycle(context.Background())
+
+	drops, summary := logged()
+	assert.Equal(t, 5, drops)
+	assert.Empty(t, summary)
+
+	clock.Advance(dropLogInterval)
+	m.setState(ValidateState)
+	m.state.timeoutChan = time.After(10 * time.Millisecond)
+	m.emitMsg(createMessage("X", MessageReq_Prepare, nil))
+	m.runCycle(context.Background())
+
+	drops, summary = logged()
+	assert.Equal(t, 6, drops)
+	assert.Contains(t, summary, "95 logs of messages dropped as not_validator suppressed")
+}
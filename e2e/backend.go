@@ -78,8 +78,9 @@ func (bf *BackendFake) ValidatorSet() pbft.ValidatorSet {
 	}
 
 	return &ValidatorSet{
-		Nodes:        valsAsNode,
-		LastProposer: bf.lastProposer,
+		Nodes:          valsAsNode,
+		LastProposer:   bf.lastProposer,
+		VotingPowerMap: bf.votingPowerMap,
 	}
 }
 
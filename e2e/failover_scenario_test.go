@@ -0,0 +1,165 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+
+	"github.com/0xPolygon/pbft-consensus"
+	"github.com/0xPolygon/pbft-consensus/e2e/helper"
+)
+
+// maxFaultRound is the last round a scripted fault can happen in, the rounds after it are fault free
+const maxFaultRound = 3
+
+type faultKind int
+
+const (
+	// faultProposerOffline drops every message sent by the proposer of the round
+	faultProposerOffline faultKind = iota
+
+	// faultCommitPartition drops the commit messages crossing a partition of the network,
+	// while the round change, preprepare and prepare messages go through
+	faultCommitPartition
+)
+
+// scriptedFault is a fault injected in the messages of a round
+type scriptedFault struct {
+	kind  faultKind
+	round uint64
+
+	// partition is the group of nodes isolated from the others (only for faultCommitPartition)
+	partition map[int]struct{}
+}
+
+func (f scriptedFault) String() string {
+	switch f.kind {
+	case faultProposerOffline:
+		return fmt.Sprintf("proposer offline at round %d", f.round)
+	default:
+		nodes := make([]string, 0, len(f.partition))
+		for i := range f.partition {
+			nodes = append(nodes, strconv.Itoa(i))
+		}
+		return fmt.Sprintf("commits partitioned at round %d between {%s} and the others", f.round, strings.Join(nodes, ","))
+	}
+}
+
+// failoverScenario is a reproducible set of scripted faults on a cluster of numOfNodes validators
+type failoverScenario struct {
+	numOfNodes int
+	faults     []scriptedFault
+}
+
+func (s failoverScenario) String() string {
+	faults := make([]string, len(s.faults))
+	for i, f := range s.faults {
+		faults[i] = f.String()
+	}
+	return fmt.Sprintf("%d nodes: [%s]", s.numOfNodes, strings.Join(faults, "; "))
+}
+
+// proposer returns the proposer of the round with the validator set of the harness (round robin from node 0)
+func (s failoverScenario) proposer(round uint64) int {
+	return int(round % uint64(s.numOfNodes))
+}
+
+// deliver returns whether the scripted faults let the message go from the sender to the receiver
+func (s failoverScenario) deliver(from, to int, msg *pbft.MessageReq) bool {
+	for _, f := range s.faults {
+		if msg.View == nil || msg.View.Round != f.round {
+			continue
+		}
+		switch f.kind {
+		case faultProposerOffline:
+			if from == s.proposer(f.round) {
+				return false
+			}
+		case faultCommitPartition:
+			_, fromIsolated := f.partition[from]
+			_, toIsolated := f.partition[to]
+			if msg.Type == pbft.MessageReq_Commit && fromIsolated != toIsolated {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// failoverScenarioGen draws the scenarios. The draws are reproducible from the rapid seed
+// and shrink towards fewer nodes, fewer faults and earlier rounds.
+func failoverScenarioGen() *rapid.Generator {
+	return rapid.Custom(func(t *rapid.T) failoverScenario {
+		numOfNodes := rapid.IntRange(4, 10).Draw(t, "num of nodes").(int)
+		numOfFaults := rapid.IntRange(0, maxFaultRound+1).Draw(t, "num of faults").(int)
+
+		scenario := failoverScenario{numOfNodes: numOfNodes}
+		for i := 0; i < numOfFaults; i++ {
+			fault := scriptedFault{
+				kind:  faultKind(rapid.IntRange(0, 1).Draw(t, "fault kind").(int)),
+				round: rapid.Uint64Range(0, maxFaultRound).Draw(t, "fault round").(uint64),
+			}
+			if fault.kind == faultCommitPartition {
+				isolated := rapid.SliceOfNDistinct(rapid.IntRange(0, numOfNodes-1), 1, numOfNodes-1, func(i int) int {
+					return i
+				}).Draw(t, "partition").([]int)
+				fault.partition = map[int]struct{}{}
+				for _, i := range isolated {
+					fault.partition[i] = struct{}{}
+				}
+			}
+			scenario.faults = append(scenario.faults, fault)
+		}
+		return scenario
+	})
+}
+
+func TestProperty_LeaderFailoverScenarios(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		scenario := failoverScenarioGen().Draw(t, "scenario").(failoverScenario)
+		t.Log(scenario)
+
+		ft := &pbft.TransportStub{
+			GossipFunc: func(ft *pbft.TransportStub, msg *pbft.MessageReq) error {
+				from, err := strconv.Atoi(string(msg.From))
+				if err != nil {
+					t.Fatal(err)
+				}
+				for to, node := range ft.Nodes {
+					if to != from && scenario.deliver(from, to, msg) {
+						node.PushMessage(msg.Copy())
+					}
+				}
+				return nil
+			},
+		}
+		cluster, timeoutsChan := generateCluster(scenario.numOfNodes, ft, nil)
+		quorum, _ := pbft.ComputeQuorum(pbft.DefaultConfig(), uint(scenario.numOfNodes), nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		// a quorum of nodes eventually agrees once the faults are over, conflicting insertions panic in the harness
+		err := runCluster(ctx,
+			cluster,
+			sendTimeoutIfNNodesStucked(t, timeoutsChan, scenario.numOfNodes),
+			func(doneList *helper.BoolSlice) bool {
+				// the nodes isolated from the commits stay locked once the others are done
+				return uint64(doneList.CalculateNum(true)) >= quorum
+			}, func(maxRound uint64) bool {
+				if maxRound > maxFaultRound+10 {
+					t.Errorf("liveness issue in scenario %s", scenario)
+					return true
+				}
+				return false
+			}, 200)
+		if err != nil {
+			t.Fatalf("scenario %s: %v", scenario, err)
+		}
+	})
+}
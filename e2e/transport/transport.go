@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"fmt"
 	"log"
 	"sync"
 
@@ -68,3 +69,20 @@ func (t *Transport) Gossip(msg *pbft.MessageReq) error {
 	}
 	return nil
 }
+
+// Send delivers the message only to the addressed node, subject to the hook like gossiped messages
+func (t *Transport) Send(to pbft.NodeID, msg *pbft.MessageReq) error {
+	handler, ok := t.nodes[to]
+	if !ok {
+		return fmt.Errorf("node %s is not registered", to)
+	}
+	go func() {
+		if hook := t.GetHook(); hook != nil && !hook.Gossip(msg.From, to, msg) {
+			t.logger.Printf("[TRACE] Message not sent to %s - %s", to, msg)
+			return
+		}
+		handler(to, msg)
+		t.logger.Printf("[TRACE] Message sent to %s - %s", to, msg)
+	}()
+	return nil
+}
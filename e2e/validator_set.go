@@ -5,6 +5,9 @@ import "github.com/0xPolygon/pbft-consensus"
 type ValidatorSet struct {
 	Nodes        []pbft.NodeID
 	LastProposer pbft.NodeID
+
+	// VotingPowerMap is the voting power of the nodes (equal voting power if empty)
+	VotingPowerMap map[pbft.NodeID]uint64
 }
 
 func (n *ValidatorSet) CalcProposer(round uint64) pbft.NodeID {
@@ -47,5 +50,8 @@ func (n *ValidatorSet) Len() int {
 }
 
 func (n *ValidatorSet) VotingPower() map[pbft.NodeID]uint64 {
+	if len(n.VotingPowerMap) != 0 {
+		return n.VotingPowerMap
+	}
 	return pbft.CreateEqualVotingPowerMap(n.Nodes)
 }
@@ -0,0 +1,54 @@
+package pbft
+
+import "fmt"
+
+// EarlyCommitPolicy is the handling of the commit messages received before the node reaches the prepare quorum
+type EarlyCommitPolicy uint8
+
+const (
+	// EarlyCommit_Count counts the early commit messages right away, so the commit quorum may be reached
+	// before the prepare quorum
+	EarlyCommit_Count EarlyCommitPolicy = iota
+
+	// EarlyCommit_Buffer buffers the early commit messages and counts them once the node reaches the prepare quorum
+	// (or is locked), so the node always goes through the prepare phase like in strict PBFT
+	EarlyCommit_Buffer
+
+	// EarlyCommit_Reject drops the early commit messages as out of phase, the senders are expected to send them
+	// again, i.e. in a later round
+	EarlyCommit_Reject
+)
+
+func (e EarlyCommitPolicy) String() string {
+	switch e {
+	case EarlyCommit_Count:
+		return "Count"
+	case EarlyCommit_Buffer:
+		return "Buffer"
+	case EarlyCommit_Reject:
+		return "Reject"
+	default:
+		return fmt.Sprintf("EarlyCommitPolicy(%d)", uint8(e))
+	}
+}
+
+// earlyCommits holds the commit messages buffered until the node enters the commit phase
+type earlyCommits struct {
+	msgs []*MessageReq
+}
+
+// buffer keeps the commit message if the policy requires it while the node is not in the commit phase yet
+func (e *earlyCommits) buffer(policy EarlyCommitPolicy, inCommitPhase bool, msg *MessageReq) bool {
+	if policy != EarlyCommit_Buffer || inCommitPhase {
+		return false
+	}
+	e.msgs = append(e.msgs, msg)
+	return true
+}
+
+// drain returns the buffered commit messages, in the order they were received, and empties the buffer
+func (e *earlyCommits) drain() []*MessageReq {
+	msgs := e.msgs
+	e.msgs = nil
+	return msgs
+}
@@ -0,0 +1,89 @@
+package pbft
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransition_ValidateState_EarlyCommits(t *testing.T) {
+	run := func(policy EarlyCommitPolicy) (*mockPbft, *recordingMetrics) {
+		metrics := &recordingMetrics{}
+		m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+		WithEarlyCommitPolicy(policy)(m.config)
+		WithMetrics(metrics)(m.config)
+		m.state.view = ViewMsg(1, 0)
+		m.state.proposer = "A"
+		m.state.proposal = &Proposal{Data: mockProposal, Hash: digest}
+		m.setState(ValidateState)
+
+		// the commit messages are processed before the prepare messages
+		for _, from := range []NodeID{"A", "C", "D"} {
+			commit := createMessage(from, MessageReq_Commit, ViewMsg(1, 0))
+			commit.Hash = digest
+			m.emitMsg(commit)
+		}
+		for _, from := range []NodeID{"A", "B", "C"} {
+			prepare := createMessage(from, MessageReq_Prepare, ViewMsg(1, 0))
+			prepare.Hash = digest
+			m.emitMsg(prepare)
+		}
+		m.runCycle(context.Background())
+		return m, metrics
+	}
+
+	quorums := func(metrics *recordingMetrics) []string {
+		reached := []string{}
+		for _, record := range metrics.records {
+			if strings.HasPrefix(record, "quorum ") {
+				reached = append(reached, strings.Fields(record)[1])
+			}
+		}
+		return reached
+	}
+
+	// by default the early commits reach the commit quorum before the prepare quorum
+	m, metrics := run(EarlyCommit_Count)
+	assert.Equal(t, CommitState, m.getState())
+	assert.Equal(t, []string{"Commit"}, quorums(metrics))
+	assert.Equal(t, 0, m.state.numPrepared())
+
+	// the buffered early commits are applied once the prepare quorum is reached
+	m, metrics = run(EarlyCommit_Buffer)
+	m.expect(expectResult{
+		sequence:    1,
+		state:       CommitState,
+		prepareMsgs: 3,
+		commitMsgs:  3,
+		locked:      true,
+		outgoing:    1, // commit
+
+		prepareMsgsVotingPower: 3,
+		commitMsgsVotingPower:  3,
+	})
+	assert.Equal(t, []string{"Prepare", "Commit"}, quorums(metrics))
+
+	// the rejected early commits are not counted, the commit quorum is not reached
+	m, metrics = run(EarlyCommit_Reject)
+	m.expect(expectResult{
+		sequence:    1,
+		state:       RoundChangeState,
+		prepareMsgs: 3,
+		commitMsgs:  1, // own commit
+		locked:      true,
+		outgoing:    1, // commit
+
+		prepareMsgsVotingPower: 3,
+		commitMsgsVotingPower:  1,
+	})
+	assert.Equal(t, []string{"Prepare"}, quorums(metrics))
+	dropped := 0
+	for _, record := range metrics.records {
+		if record == "dropped Commit "+DropReasonOutOfPhase {
+			dropped++
+		}
+	}
+	assert.Equal(t, 3, dropped)
+}
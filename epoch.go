@@ -0,0 +1,137 @@
+package pbft
+
+import (
+	"fmt"
+	"sync"
+)
+
+// NextEpochFunc returns the validator set of the epoch starting after lastSequence, the last sequence
+// of the previous epoch. It must be deterministic (i.e. derived from the finalized sequences),
+// so that all the nodes switch to the same validator set at the same sequence.
+type NextEpochFunc func(epoch uint64, lastSequence uint64) (ValidatorSet, error)
+
+// EpochManager switches the validator set at the epoch boundaries. When the last sequence of an epoch
+// is finalized, the validator set of the next epoch is requested to the NextEpochFunc and is used
+// from the first sequence of that epoch, in place of the one provided by the backend.
+type EpochManager struct {
+	lock sync.Mutex
+
+	// size is the number of sequences of an epoch
+	size uint64
+
+	// next provides the validator set of the next epoch
+	next NextEpochFunc
+
+	// epoch is the epoch of the validator set swapped in
+	epoch uint64
+
+	// validators is the validator set of the epoch (nil if none was swapped in yet)
+	validators ValidatorSet
+}
+
+// NewEpochManager creates an EpochManager for epochs of size sequences. The first epoch (0)
+// uses the validator set of the backend. Zero size means a single epoch.
+func NewEpochManager(size uint64, next NextEpochFunc) *EpochManager {
+	return &EpochManager{
+		size: size,
+		next: next,
+	}
+}
+
+// EpochForSequence returns the epoch of the sequence
+func (e *EpochManager) EpochForSequence(sequence uint64) uint64 {
+	if e.size == 0 {
+		return 0
+	}
+	return sequence / e.size
+}
+
+// IsEpochBoundary returns whether the sequence is the last one of its epoch
+func (e *EpochManager) IsEpochBoundary(sequence uint64) bool {
+	if e.size == 0 {
+		return false
+	}
+	return (sequence+1)%e.size == 0
+}
+
+// finalized swaps in the validator set of the next epoch if the finalized sequence is the last one of its epoch
+func (e *EpochManager) finalized(sequence uint64) error {
+	if !e.IsEpochBoundary(sequence) {
+		return nil
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.swap(e.EpochForSequence(sequence) + 1)
+}
+
+// swap requests the validator set of the epoch to the NextEpochFunc, unless it is already swapped in
+func (e *EpochManager) swap(epoch uint64) error {
+	if e.validators != nil && e.epoch == epoch {
+		return nil
+	}
+
+	validators, err := e.next(epoch, epoch*e.size-1)
+	if err != nil {
+		return fmt.Errorf("failed to switch to the validator set of epoch %d: %w", epoch, err)
+	}
+	if validators == nil || validators.Len() == 0 {
+		return fmt.Errorf("failed to switch to the validator set of epoch %d: empty validator set", epoch)
+	}
+	e.epoch, e.validators = epoch, validators
+	return nil
+}
+
+// lookup returns the validator set of the epoch without swapping it in. The set of the first epoch
+// comes from the backend, so it is not known.
+func (e *EpochManager) lookup(epoch uint64) (ValidatorSet, error) {
+	if epoch == 0 {
+		return nil, ErrValidatorSetNotFound
+	}
+
+	e.lock.Lock()
+	swapped, validators := e.epoch, e.validators
+	e.lock.Unlock()
+	if validators != nil && swapped == epoch {
+		return validators, nil
+	}
+
+	validators, err := e.next(epoch, epoch*e.size-1)
+	if err != nil {
+		return nil, err
+	}
+	if validators == nil || validators.Len() == 0 {
+		return nil, ErrValidatorSetNotFound
+	}
+	return validators, nil
+}
+
+// validatorSet returns the validator set of the epoch of the sequence. The first epoch uses the given backend set.
+// The set of a later epoch is requested to the NextEpochFunc if it was not swapped in when its boundary was finalized
+// (i.e. after a restart or a sync).
+func (e *EpochManager) validatorSet(sequence uint64, backendSet ValidatorSet) (ValidatorSet, error) {
+	epoch := e.EpochForSequence(sequence)
+	if epoch == 0 {
+		return backendSet, nil
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if err := e.swap(epoch); err != nil {
+		return nil, err
+	}
+	return e.validators, nil
+}
+
+// epochFinalized notifies the epoch manager, if any, of the finalized sequence
+func (p *Pbft) epochFinalized(sequence uint64) {
+	if p.config.EpochManager == nil {
+		return
+	}
+	if err := p.config.EpochManager.finalized(sequence); err != nil {
+		// the switch is retried when the first sequence of the epoch starts
+		p.logger.Printf("[ERROR] epoch boundary at sequence %d: %v", sequence, err)
+	}
+}
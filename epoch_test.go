@@ -0,0 +1,83 @@
+package pbft
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEpochManager_EpochForSequence(t *testing.T) {
+	e := NewEpochManager(10, nil)
+	assert.Equal(t, uint64(0), e.EpochForSequence(0))
+	assert.Equal(t, uint64(0), e.EpochForSequence(9))
+	assert.Equal(t, uint64(1), e.EpochForSequence(10))
+	assert.Equal(t, uint64(3), e.EpochForSequence(35))
+	assert.True(t, e.IsEpochBoundary(9))
+	assert.False(t, e.IsEpochBoundary(10))
+
+	// zero size means a single epoch
+	e = NewEpochManager(0, nil)
+	assert.Equal(t, uint64(0), e.EpochForSequence(100))
+	assert.False(t, e.IsEpochBoundary(99))
+}
+
+// Test that the validator set of the next epoch is used from the first sequence after the finalized boundary.
+func TestPbft_EpochManager_CrossBoundary(t *testing.T) {
+	type call struct{ epoch, lastSequence uint64 }
+	calls := []call{}
+	nextSet := NewValStringStub([]NodeID{"A", "B", "C", "D"}, CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+	manager := NewEpochManager(2, func(epoch, lastSequence uint64) (ValidatorSet, error) {
+		calls = append(calls, call{epoch, lastSequence})
+		return nextSet, nil
+	})
+
+	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
+	WithEpochManager(manager)(m.config)
+	require.NoError(t, m.SetBackend(m.backend))
+	assert.Equal(t, 3, m.state.validators.Len())
+
+	// sequence 1 is the last one of the epoch 0
+	m.state.view = ViewMsg(1, 0)
+	m.state.proposer = "A"
+	m.state.proposal = &Proposal{Data: mockProposal, Hash: digest}
+	m.setState(CommitState)
+	m.runCycle(context.Background())
+	require.Equal(t, DoneState, m.getState())
+	assert.Equal(t, []call{{1, 1}}, calls)
+
+	m.sequence = 2
+	require.NoError(t, m.SetBackend(m.backend))
+	assert.Equal(t, 4, m.state.validators.Len())
+	assert.Equal(t, uint64(3), m.state.getQuorumSize())
+
+	// the set is kept for the whole epoch
+	m.sequence = 3
+	require.NoError(t, m.SetBackend(m.backend))
+	assert.Equal(t, 4, m.state.validators.Len())
+	assert.Len(t, calls, 1)
+
+	// a node starting in the epoch switches to the same set
+	restarted := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "B")
+	WithEpochManager(NewEpochManager(2, func(epoch, lastSequence uint64) (ValidatorSet, error) {
+		assert.Equal(t, uint64(1), epoch)
+		assert.Equal(t, uint64(1), lastSequence)
+		return nextSet, nil
+	}))(restarted.config)
+	restarted.sequence = 3
+	require.NoError(t, restarted.SetBackend(restarted.backend))
+	assert.Equal(t, 4, restarted.state.validators.Len())
+}
+
+func TestPbft_EpochManager_Error(t *testing.T) {
+	errNotReady := errors.New("not ready")
+	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
+	WithEpochManager(NewEpochManager(2, func(uint64, uint64) (ValidatorSet, error) {
+		return nil, errNotReady
+	}))(m.config)
+
+	m.sequence = 2
+	assert.ErrorIs(t, m.SetBackend(m.backend), errNotReady)
+}
@@ -0,0 +1,160 @@
+package pbft
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ConsensusEventType is the type of a ConsensusEvent
+type ConsensusEventType uint8
+
+const (
+	// ConsensusEvent_StateChange is a transition of the state machine to State
+	ConsensusEvent_StateChange ConsensusEventType = iota
+
+	// ConsensusEvent_RoundChange is a round abandoned for Reason
+	ConsensusEvent_RoundChange
+
+	// ConsensusEvent_Finalized is the finalization of the sequence of the View
+	ConsensusEvent_Finalized
+)
+
+func (c ConsensusEventType) String() string {
+	switch c {
+	case ConsensusEvent_StateChange:
+		return "StateChange"
+	case ConsensusEvent_RoundChange:
+		return "RoundChange"
+	case ConsensusEvent_Finalized:
+		return "Finalized"
+	default:
+		return fmt.Sprintf("ConsensusEventType(%d)", uint8(c))
+	}
+}
+
+// ConsensusEvent is an event of the state machine delivered to the subscribers (see SubscribeEvents)
+type ConsensusEvent struct {
+	Type ConsensusEventType
+
+	// View is the view of the node when the event occurred
+	View View
+
+	// State is the new state of a ConsensusEvent_StateChange
+	State State
+
+	// Reason is the reason of a ConsensusEvent_RoundChange
+	Reason RoundChangeReason
+}
+
+// OverflowPolicy is the handling of an event delivered to a subscriber whose buffer is full
+type OverflowPolicy uint8
+
+const (
+	// Overflow_DropOldest discards the oldest buffered event to make room for the new one
+	Overflow_DropOldest OverflowPolicy = iota
+
+	// Overflow_DropNewest discards the new event
+	Overflow_DropNewest
+
+	// Overflow_Close closes the subscription of the slow subscriber
+	Overflow_Close
+)
+
+func (o OverflowPolicy) String() string {
+	switch o {
+	case Overflow_DropOldest:
+		return "DropOldest"
+	case Overflow_DropNewest:
+		return "DropNewest"
+	case Overflow_Close:
+		return "Close"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", uint8(o))
+	}
+}
+
+// eventSubscription is a subscriber of the events with its buffer
+type eventSubscription struct {
+	events chan ConsensusEvent
+	policy OverflowPolicy
+}
+
+// eventStream delivers the events to the subscribers without ever blocking the state machine
+type eventStream struct {
+	lock          sync.Mutex
+	subscriptions map[*eventSubscription]struct{}
+}
+
+func newEventStream() *eventStream {
+	return &eventStream{subscriptions: map[*eventSubscription]struct{}{}}
+}
+
+// subscribe adds a subscription buffering up to bufferSize events
+func (e *eventStream) subscribe(bufferSize int, policy OverflowPolicy) *eventSubscription {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	sub := &eventSubscription{events: make(chan ConsensusEvent, bufferSize), policy: policy}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.subscriptions[sub] = struct{}{}
+	return sub
+}
+
+// unsubscribe removes the subscription and closes its channel, if not already done
+func (e *eventStream) unsubscribe(sub *eventSubscription) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.remove(sub)
+}
+
+func (e *eventStream) remove(sub *eventSubscription) {
+	if _, ok := e.subscriptions[sub]; ok {
+		delete(e.subscriptions, sub)
+		close(sub.events)
+	}
+}
+
+// publish delivers the event to every subscription, applying the overflow policy of the full ones
+func (e *eventStream) publish(event ConsensusEvent) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	for sub := range e.subscriptions {
+		select {
+		case sub.events <- event:
+			continue
+		default:
+		}
+
+		switch sub.policy {
+		case Overflow_DropOldest:
+			// the subscriber may read concurrently, so the room is made without blocking on either side
+			select {
+			case <-sub.events:
+			default:
+			}
+			select {
+			case sub.events <- event:
+			default:
+			}
+		case Overflow_Close:
+			e.remove(sub)
+		}
+	}
+}
+
+// SubscribeEvents subscribes to the events of the state machine. The events are buffered up to bufferSize,
+// the events delivered to a full buffer are handled with the overflow policy, so that the state machine never
+// blocks on a slow subscriber. The channel is closed by the returned cancel function, or by Overflow_Close.
+func (p *Pbft) SubscribeEvents(bufferSize int, policy OverflowPolicy) (<-chan ConsensusEvent, func()) {
+	sub := p.events.subscribe(bufferSize, policy)
+	return sub.events, func() { p.events.unsubscribe(sub) }
+}
+
+// publishEvent delivers the event of the current view to the subscribers
+func (p *Pbft) publishEvent(event ConsensusEvent) {
+	event.View = p.state.CurrentView()
+	p.events.publish(event)
+}
@@ -0,0 +1,62 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPbft_SubscribeEvents_Overflow(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "B")
+	m.state.view = ViewMsg(1, 0)
+	m.setState(DoneState)
+
+	// none of the subscribers reads
+	dropOldest, _ := m.SubscribeEvents(2, Overflow_DropOldest)
+	dropNewest, _ := m.SubscribeEvents(2, Overflow_DropNewest)
+	closed, _ := m.SubscribeEvents(2, Overflow_Close)
+	reader, cancel := m.SubscribeEvents(16, Overflow_Close)
+
+	// the round times out waiting for the pre-prepare, the state machine does not stall on the full buffers
+	m.setState(AcceptState)
+	m.runCycle(context.Background())
+	require.True(t, m.IsState(RoundChangeState))
+	m.setState(AcceptState)
+
+	drain := func(events <-chan ConsensusEvent) []ConsensusEvent {
+		var drained []ConsensusEvent
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return drained
+				}
+				drained = append(drained, event)
+			default:
+				return drained
+			}
+		}
+	}
+	stateChange := func(state State) ConsensusEvent {
+		return ConsensusEvent{Type: ConsensusEvent_StateChange, View: View{Sequence: 1}, State: state}
+	}
+	roundChange := ConsensusEvent{Type: ConsensusEvent_RoundChange, View: View{Sequence: 1}, Reason: RoundChange_Timeout}
+
+	all := []ConsensusEvent{stateChange(AcceptState), roundChange, stateChange(RoundChangeState), stateChange(AcceptState)}
+	assert.Equal(t, all, drain(reader))
+	assert.Equal(t, all[2:], drain(dropOldest))
+	assert.Equal(t, all[:2], drain(dropNewest))
+
+	// the slow subscriber is closed after the buffered events
+	assert.Equal(t, all[:2], drain(closed))
+	_, ok := <-closed
+	assert.False(t, ok)
+
+	// the canceled subscription is closed
+	cancel()
+	_, ok = <-reader
+	assert.False(t, ok)
+	m.setState(ValidateState)
+}
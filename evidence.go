@@ -0,0 +1,191 @@
+package pbft
+
+import (
+	"bytes"
+	"sync"
+)
+
+// DoubleProposalProof is the evidence of a proposer sending two different proposals for the same view
+type DoubleProposalProof struct {
+	// Proposer is the node that sent both proposals
+	Proposer NodeID
+
+	// First is the first Preprepare message received from the proposer
+	First *MessageReq
+
+	// Second is the conflicting Preprepare message received from the proposer
+	Second *MessageReq
+}
+
+// WrongProposerProof is the evidence of a node sending a Preprepare message for a round it is not the proposer of,
+// i.e. a proposer of a round tagging its proposal with another round
+type WrongProposerProof struct {
+	// Sender is the node that sent the Preprepare message
+	Sender NodeID
+
+	// Proposer is the proposer of the round claimed by the Preprepare message
+	Proposer NodeID
+
+	// Preprepare is the Preprepare message received from the sender
+	Preprepare *MessageReq
+}
+
+// doubleProposalDetector tracks the Preprepare messages sent by the proposers of the current sequence
+type doubleProposalDetector struct {
+	lock sync.Mutex
+
+	// sequence is the current sequence
+	sequence uint64
+
+	// proposerFor calculates the proposer of a round in the current sequence
+	proposerFor func(round uint64) NodeID
+
+	// preprepares are the first Preprepare messages received from the proposer for each round
+	preprepares map[uint64]*MessageReq
+
+	// proofs are the detected double proposals
+	proofs []*DoubleProposalProof
+
+	// wrongProposerRounds are the rounds of the current sequence with a wrong proposer proof, by sender
+	wrongProposerRounds map[NodeID]map[uint64]struct{}
+
+	// wrongProposers are the detected Preprepare messages of nodes that are not the proposer of their round
+	wrongProposers []*WrongProposerProof
+
+	// limit is the maximum number of proofs of each kind retained (zero is unbounded)
+	limit int
+
+	// maxAge is the number of sequences the proofs are retained for (zero is unbounded)
+	maxAge uint64
+}
+
+func newDoubleProposalDetector(limit int, maxAge uint64) *doubleProposalDetector {
+	return &doubleProposalDetector{
+		preprepares:         map[uint64]*MessageReq{},
+		wrongProposerRounds: map[NodeID]map[uint64]struct{}{},
+		limit:               limit,
+		maxAge:              maxAge,
+	}
+}
+
+// reset starts tracking a new sequence
+func (d *doubleProposalDetector) reset(sequence uint64, proposerFor func(round uint64) NodeID) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.sequence = sequence
+	d.proposerFor = proposerFor
+	d.preprepares = map[uint64]*MessageReq{}
+	d.wrongProposerRounds = map[NodeID]map[uint64]struct{}{}
+	d.prune()
+}
+
+// check records the Preprepare message and returns the double proposal proof if
+// the round proposer already sent a different proposal for the same view
+func (d *doubleProposalDetector) check(msg *MessageReq) *DoubleProposalProof {
+	if msg.Type != MessageReq_Preprepare || msg.View == nil {
+		return nil
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.proposerFor == nil || msg.View.Sequence != d.sequence || d.proposerFor(msg.View.Round) != msg.From {
+		return nil
+	}
+
+	first, exists := d.preprepares[msg.View.Round]
+	if !exists {
+		d.preprepares[msg.View.Round] = msg.Copy()
+		return nil
+	}
+	// the proposals are compared by hash: a relayed Preprepare without the proposal body
+	// followed by the full one for the same hash is the same proposal
+	if bytes.Equal(first.Hash, msg.Hash) {
+		return nil
+	}
+
+	proof := &DoubleProposalProof{
+		Proposer: msg.From,
+		First:    first.Copy(),
+		Second:   msg.Copy(),
+	}
+	d.proofs = append(d.proofs, proof)
+	d.prune()
+	return proof
+}
+
+// checkProposer returns the wrong proposer proof if the Preprepare message is not sent by the proposer
+// of the round it claims. The proof is recorded once per sender and round.
+func (d *doubleProposalDetector) checkProposer(msg *MessageReq) *WrongProposerProof {
+	if msg.Type != MessageReq_Preprepare || msg.View == nil {
+		return nil
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.proposerFor == nil || msg.View.Sequence != d.sequence {
+		return nil
+	}
+	proposer := d.proposerFor(msg.View.Round)
+	if proposer == msg.From {
+		return nil
+	}
+
+	proof := &WrongProposerProof{
+		Sender:     msg.From,
+		Proposer:   proposer,
+		Preprepare: msg.Copy(),
+	}
+	rounds, ok := d.wrongProposerRounds[msg.From]
+	if !ok {
+		rounds = map[uint64]struct{}{}
+		d.wrongProposerRounds[msg.From] = rounds
+	}
+	if _, recorded := rounds[msg.View.Round]; !recorded {
+		rounds[msg.View.Round] = struct{}{}
+		d.wrongProposers = append(d.wrongProposers, proof)
+		d.prune()
+	}
+	return proof
+}
+
+// getWrongProposers returns the detected Preprepare messages of nodes that are not the proposer of their round
+func (d *doubleProposalDetector) getWrongProposers() []*WrongProposerProof {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return append([]*WrongProposerProof{}, d.wrongProposers...)
+}
+
+// getProofs returns the detected double proposals
+func (d *doubleProposalDetector) getProofs() []*DoubleProposalProof {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return append([]*DoubleProposalProof{}, d.proofs...)
+}
+
+// DoubleProposals returns the evidences of proposers that sent two different proposals for the same view,
+// within the retention bounds (see WithEvidenceRetention)
+func (p *Pbft) DoubleProposals() []*DoubleProposalProof {
+	return p.doubleProposals.getProofs()
+}
+
+// WrongProposers returns the evidences of nodes that sent a Preprepare message for a round they are not the proposer of,
+// within the retention bounds (see WithEvidenceRetention)
+func (p *Pbft) WrongProposers() []*WrongProposerProof {
+	return p.doubleProposals.getWrongProposers()
+}
+
+// resetDoubleProposalDetector starts tracking the proposals of the current sequence
+func (p *Pbft) resetDoubleProposalDetector() {
+	sequence, validators, seed, scores, selector := p.state.view.Sequence, p.state.validators, p.proposerSeed, p.proposerScores, p.config.ProposerSelector
+	p.doubleProposals.reset(sequence, func(round uint64) NodeID {
+		if override := p.proposerOverride; override != nil {
+			return override(&View{Sequence: sequence, Round: round})
+		}
+		return selectProposer(selector, validators, scores, seed, round)
+	})
+}
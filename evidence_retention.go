@@ -0,0 +1,63 @@
+package pbft
+
+// retainEvidence returns which of the evidences, given by sequence in the order they were recorded, are kept:
+// the ones older than maxAge sequences are dropped, then the oldest ones are dropped until at most limit are left.
+// The evidences of the current sequence are dropped only once no historical evidence is left.
+// A zero limit or maxAge disables the respective bound.
+func retainEvidence(sequences []uint64, current uint64, limit int, maxAge uint64) []bool {
+	keep := make([]bool, len(sequences))
+	kept := 0
+	for i, sequence := range sequences {
+		keep[i] = maxAge == 0 || sequence+maxAge >= current
+		if keep[i] {
+			kept++
+		}
+	}
+	if limit == 0 {
+		return keep
+	}
+
+	for _, historical := range []bool{true, false} {
+		for i, sequence := range sequences {
+			if kept <= limit {
+				return keep
+			}
+			if keep[i] && (sequence != current) == historical {
+				keep[i] = false
+				kept--
+			}
+		}
+	}
+	return keep
+}
+
+// prune drops the evidences beyond the retention bounds (see WithEvidenceRetention)
+func (d *doubleProposalDetector) prune() {
+	if d.limit == 0 && d.maxAge == 0 {
+		return
+	}
+
+	sequences := make([]uint64, len(d.proofs))
+	for i, proof := range d.proofs {
+		sequences[i] = proof.First.View.Sequence
+	}
+	proofs := d.proofs[:0]
+	for i, keep := range retainEvidence(sequences, d.sequence, d.limit, d.maxAge) {
+		if keep {
+			proofs = append(proofs, d.proofs[i])
+		}
+	}
+	d.proofs = proofs
+
+	sequences = make([]uint64, len(d.wrongProposers))
+	for i, proof := range d.wrongProposers {
+		sequences[i] = proof.Preprepare.View.Sequence
+	}
+	wrongProposers := d.wrongProposers[:0]
+	for i, keep := range retainEvidence(sequences, d.sequence, d.limit, d.maxAge) {
+		if keep {
+			wrongProposers = append(wrongProposers, d.wrongProposers[i])
+		}
+	}
+	d.wrongProposers = wrongProposers
+}
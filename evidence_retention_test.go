@@ -0,0 +1,64 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvidenceRetention_DropsOldestKeepsCurrentSequence(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+	m.doubleProposals = newDoubleProposalDetector(2, 0)
+	m.resetDoubleProposalDetector()
+
+	// the proposer of round 0 and round 1 equivocate in the given sequence
+	equivocate := func(sequence uint64) {
+		for round, proposer := range []NodeID{"A", "B"} {
+			m.emitMsg(createMessage(proposer, MessageReq_Preprepare, ViewMsg(sequence, uint64(round))))
+			second := createMessage(proposer, MessageReq_Preprepare, ViewMsg(sequence, uint64(round)))
+			second.Proposal = mockProposal1
+			second.Hash = digest1
+			m.emitMsg(second)
+		}
+	}
+
+	equivocate(1)
+	require.Len(t, m.DoubleProposals(), 2)
+
+	// the evidences of the current sequence replace the historical ones
+	m.setSequence(2)
+	m.resetDoubleProposalDetector()
+	m.emitMsg(createMessage("A", MessageReq_Preprepare, ViewMsg(2, 0)))
+	second := createMessage("A", MessageReq_Preprepare, ViewMsg(2, 0))
+	second.Hash = digest1
+	m.emitMsg(second)
+
+	proofs := m.DoubleProposals()
+	require.Len(t, proofs, 2)
+	assert.Equal(t, ViewMsg(1, 1), proofs[0].First.View)
+	assert.Equal(t, ViewMsg(2, 0), proofs[1].First.View)
+
+	// over the limit within the current sequence, the oldest evidence is dropped
+	m.emitMsg(createMessage("B", MessageReq_Preprepare, ViewMsg(2, 1)))
+	second = createMessage("B", MessageReq_Preprepare, ViewMsg(2, 1))
+	second.Hash = digest1
+	m.emitMsg(second)
+
+	proofs = m.DoubleProposals()
+	require.Len(t, proofs, 2)
+	assert.Equal(t, ViewMsg(2, 0), proofs[0].First.View)
+	assert.Equal(t, ViewMsg(2, 1), proofs[1].First.View)
+}
+
+func TestEvidenceRetention_MaxAge(t *testing.T) {
+	// evidences older than the max age are dropped
+	assert.Equal(t, []bool{false, true, true}, retainEvidence([]uint64{1, 2, 3}, 4, 0, 2))
+
+	// historical evidences are dropped first, the oldest first
+	assert.Equal(t, []bool{true, true, false, false}, retainEvidence([]uint64{4, 4, 2, 3}, 4, 2, 0))
+	assert.Equal(t, []bool{false, true, true, true}, retainEvidence([]uint64{2, 4, 4, 3}, 4, 3, 0))
+
+	// no bound keeps everything
+	assert.Equal(t, []bool{true, true}, retainEvidence([]uint64{1, 9}, 9, 0, 0))
+}
@@ -0,0 +1,75 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoubleProposal_Detected(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+
+	// preprepare from a node that is not the proposer is not tracked
+	notProposer := createMessage("C", MessageReq_Preprepare, ViewMsg(1, 0))
+	m.emitMsg(notProposer)
+	notProposer = createMessage("C", MessageReq_Preprepare, ViewMsg(1, 0))
+	notProposer.Hash = digest1
+	m.emitMsg(notProposer)
+	assert.Empty(t, m.DoubleProposals())
+
+	// the same proposal sent twice is not a double proposal
+	first := createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0))
+	m.emitMsg(first)
+	m.emitMsg(first.Copy())
+	assert.Empty(t, m.DoubleProposals())
+
+	// nor the same proposal sent without its body
+	bodyless := first.Copy()
+	bodyless.Proposal = nil
+	m.emitMsg(bodyless)
+	assert.Empty(t, m.DoubleProposals())
+
+	second := createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0))
+	second.Proposal = mockProposal1
+	second.Hash = digest1
+	m.emitMsg(second)
+
+	proofs := m.DoubleProposals()
+	require.Len(t, proofs, 1)
+	assert.Equal(t, NodeID("A"), proofs[0].Proposer)
+	assert.Equal(t, digest, proofs[0].First.Hash)
+	assert.Equal(t, digest1, proofs[0].Second.Hash)
+}
+
+func TestWrongProposer_RoundTagged(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+	metrics := &recordingMetrics{}
+	WithMetrics(metrics)(m.config)
+
+	// A is the proposer of round 0, its proposal is not evidence
+	m.emitMsg(createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0)))
+	assert.Empty(t, m.WrongProposers())
+
+	// A tags its proposal with round 2, whose proposer is another node
+	proposer := m.ProposerFor(ViewMsg(1, 2))
+	require.NotEqual(t, NodeID("A"), proposer)
+	m.emitMsg(createMessage("A", MessageReq_Preprepare, ViewMsg(1, 2)))
+	m.emitMsg(createMessage("A", MessageReq_Preprepare, ViewMsg(1, 2)))
+
+	// the evidence is recorded once, both messages are dropped
+	proofs := m.WrongProposers()
+	require.Len(t, proofs, 1)
+	assert.Equal(t, NodeID("A"), proofs[0].Sender)
+	assert.Equal(t, proposer, proofs[0].Proposer)
+	assert.Equal(t, uint64(2), proofs[0].Preprepare.View.Round)
+	assert.Equal(t, []string{"dropped Preprepare wrong_proposer", "dropped Preprepare wrong_proposer"}, metrics.records)
+
+	// once in round 2 the proposal of A is not accepted
+	m.setRound(2)
+	m.setState(AcceptState)
+	m.runCycle(context.Background())
+	assert.Equal(t, RoundChangeState, m.getState())
+	assert.Empty(t, m.DoubleProposals())
+}
@@ -0,0 +1,202 @@
+package pbft
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	errNoProposal        = errors.New("no proposal to finalize")
+	errInsufficientSeals = errors.New("committed seals do not reach quorum voting power")
+	errEmptyProof        = errors.New("finalization proof is empty")
+)
+
+// FinalizationProof is a standalone proof that a proposal was decided in a given view.
+// It is what peers exchange during sync to prove a sequence was finalized.
+type FinalizationProof struct {
+	// Hash is the hash of the finalized proposal
+	Hash []byte `json:"hash"`
+
+	// View is the view in which the proposal was finalized
+	View *View `json:"view"`
+
+	// CommittedSeals are the seals of the validators that committed the proposal
+	CommittedSeals []CommittedSeal `json:"committedSeals"`
+
+	// SealHash is the hash sealed by the committed seals, when it is not the proposal hash (see WithSealHash
+	// and WithRoundBoundCommitSeals)
+	SealHash []byte `json:"sealHash,omitempty"`
+
+	// ParentHash is the hash of the proposal finalized in the previous sequence, when known (see VerifyProofChain)
+	ParentHash []byte `json:"parentHash,omitempty"`
+
+	// NextValidators is the validator set of the next sequence, when the proposal changes it. It is set by
+	// the application, whose proposal hash must commit to it (see VerifyProofChain).
+	NextValidators *StaticValidatorSet `json:"nextValidators,omitempty"`
+
+	// AggregatedSignature is the combination of the committed seals, as partial signatures, when
+	// a SignatureAggregator is configured
+	AggregatedSignature []byte `json:"aggregatedSignature,omitempty"`
+}
+
+// finalizeRetryDelay is the delay between the deliveries of a finalized sequence rejected by the OnFinalize callback
+var finalizeRetryDelay = 100 * time.Millisecond
+
+// FinalizeCallback is invoked for every finalized sequence with its finalization proof
+type FinalizeCallback func(sequence uint64, proof *FinalizationProof) error
+
+// SealVerifier verifies that the seal was produced by the given node over the given proposal hash
+type SealVerifier func(from NodeID, hash []byte, seal []byte) error
+
+// BuildFinalizationProof builds the finalization proof of the current proposal out of the committed messages.
+// It fails if the committed messages do not reach quorum.
+func (p *Pbft) BuildFinalizationProof() (*FinalizationProof, error) {
+	if p.state.proposal == nil {
+		return nil, errNoProposal
+	}
+	if p.state.committed.getAccumulatedVotingPower() < p.state.getCommitQuorumSize() {
+		return nil, errInsufficientSeals
+	}
+
+	proof := &FinalizationProof{
+		Hash:           append([]byte{}, p.state.proposal.Hash...),
+		View:           p.state.view.Copy(),
+		CommittedSeals: p.state.getCommittedSeals(p.config.SealOrdering, p.config.SealSelection),
+	}
+	sealedHash := p.commitSealHash(proof.View.Round, p.state.proposal)
+	if p.config.SealHash != nil || p.config.RoundBoundCommitSeals {
+		proof.SealHash = append([]byte{}, sealedHash...)
+	}
+	if len(p.state.proposal.ParentHash) != 0 {
+		proof.ParentHash = append([]byte{}, p.state.proposal.ParentHash...)
+	}
+	if p.config.SignatureAggregator != nil {
+		signature, err := p.aggregateSignature(sealedHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate the partial signatures: %w", err)
+		}
+		proof.AggregatedSignature = signature
+	}
+	return proof, nil
+}
+
+// LastFinalizationProof returns the finalization proof of the last sequence finalized by the node. If the proof of
+// a finalized sequence could not be built, the proof of the previous sequence is kept: check its View.
+func (p *Pbft) LastFinalizationProof() *FinalizationProof {
+	return p.finalizationProof
+}
+
+// VerifyFinalizationProof verifies that the proof seals were produced by distinct members of the validator set,
+// that every seal is valid for the hash sealed by the commits, as rebuilt out of the proof with the sealing,
+// and that the seals reach quorum voting power.
+func VerifyFinalizationProof(proof *FinalizationProof, validators ValidatorSet, verifySeal SealVerifier, sealing ProofSealing) error {
+	_, quorumSize, err := CalculateQuorum(validators.VotingPower())
+	if err != nil {
+		return err
+	}
+	return verifyFinalizationProof(proof, validators, verifySeal, sealing, quorumSize)
+}
+
+// verifyFinalizationProof verifies the finalization proof like VerifyFinalizationProof, requiring the given quorum size
+func verifyFinalizationProof(proof *FinalizationProof, validators ValidatorSet, verifySeal SealVerifier, sealing ProofSealing, quorumSize uint64) error {
+	if proof == nil || proof.View == nil || len(proof.Hash) == 0 {
+		return errEmptyProof
+	}
+	sealedHash, err := sealing.sealedHash(proof)
+	if err != nil {
+		return err
+	}
+
+	votingPower := validators.VotingPower()
+	signers := make(map[NodeID]struct{}, len(proof.CommittedSeals))
+	items := make([]SealItem, 0, len(proof.CommittedSeals))
+	accumulatedVotingPower := uint64(0)
+	for _, seal := range proof.CommittedSeals {
+		if !validators.Includes(seal.NodeID) {
+			return fmt.Errorf("seal signer %s: %w", seal.NodeID, ErrNotValidator)
+		}
+		if _, exists := signers[seal.NodeID]; exists {
+			return fmt.Errorf("seal signer %s: %w", seal.NodeID, ErrDuplicate)
+		}
+		signers[seal.NodeID] = struct{}{}
+		items = append(items, SealItem{From: seal.NodeID, Hash: sealedHash, Seal: seal.Signature})
+		accumulatedVotingPower += votingPower[seal.NodeID]
+	}
+
+	for i, err := range BatchVerifySeals(items, verifySeal, 0) {
+		if err != nil {
+			return fmt.Errorf("seal signer %s: %w: %v", items[i].From, ErrBadSignature, err)
+		}
+	}
+
+	if accumulatedVotingPower < quorumSize {
+		return errInsufficientSeals
+	}
+	return nil
+}
+
+// ApplyFinalizationProof finalizes the current sequence out of a finalization proof received during sync,
+// without the prepare and commit messages. The proof is verified against the current validator set and commit
+// quorum, then it is delivered to the OnFinalize callback and the node advances to the next sequence, ending the
+// iteration in the DoneState like a regular finalization. An error of the callback is returned and the node stays
+// in the current sequence.
+// Like SetBackend, it must not be called concurrently with the state machine (i.e. use a CommandEvent).
+func (p *Pbft) ApplyFinalizationProof(proof *FinalizationProof) error {
+	if proof == nil || proof.View == nil {
+		return errEmptyProof
+	}
+	sequence := p.state.GetSequence()
+	if proof.View.Sequence != sequence {
+		return fmt.Errorf("%w: proof of sequence %d, current sequence %d", ErrWrongView, proof.View.Sequence, sequence)
+	}
+	// the proof must reach the commit quorum of the node, which can be higher than the default one (see WithPhaseQuorums)
+	if err := verifyFinalizationProof(proof, p.state.validators, p.validateCommitSeal, p.proofSealing(), p.state.getCommitQuorumSize()); err != nil {
+		return err
+	}
+
+	if p.config.OnFinalize != nil && (p.lastNotified == 0 || sequence > p.lastNotified) {
+		if err := p.config.OnFinalize(sequence, proof); err != nil {
+			return err
+		}
+		p.lastNotified = sequence
+	}
+
+	p.penalties.reset()
+	p.recordProposerEviction(sequence, proof.View.Round)
+	p.finalizationProof = proof
+	if !bytes.Equal(proof.Hash, nilProposalHash[:]) {
+		p.lastFinalizedHash = append([]byte{}, proof.Hash...)
+	}
+	p.sequenceFinalized(sequence)
+	p.publishFinalized(&FinalizedProposal{Sequence: sequence, Proof: proof})
+	p.setSequence(sequence + 1)
+	p.setState(DoneState)
+	return nil
+}
+
+// notifyFinalized delivers the finalized sequence to the OnFinalize callback. Sequences already delivered are skipped,
+// so each sequence is delivered exactly once. A failed delivery is retried until it succeeds, blocking the node.
+// It returns false if the context is done before the sequence is delivered.
+func (p *Pbft) notifyFinalized(ctx context.Context, sequence uint64, proof *FinalizationProof) bool {
+	if p.config.OnFinalize == nil || (p.lastNotified != 0 && sequence <= p.lastNotified) {
+		return true
+	}
+
+	for {
+		err := p.config.OnFinalize(sequence, proof)
+		if err == nil {
+			p.lastNotified = sequence
+			return true
+		}
+		p.logger.Printf("[ERROR] finalized sequence delivery failed, retrying: sequence=%d, err=%v", sequence, err)
+
+		select {
+		case <-time.After(finalizeRetryDelay):
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
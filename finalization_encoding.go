@@ -0,0 +1,237 @@
+package pbft
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ErrProofEncoding is returned when a binary encoded finalization proof is malformed or not canonical
+var ErrProofEncoding = errors.New("invalid finalization proof encoding")
+
+const (
+	// finalizationProofVersion is the version of the binary layout of the finalization proof
+	finalizationProofVersion = 1
+
+	// extendedProofVersion is the version of the binary layout of the finalization proof
+	// carrying an aggregated signature, a seal hash, a parent hash or the next validators
+	extendedProofVersion = 2
+)
+
+// MarshalBinary encodes the proof in a canonical binary layout, so that every node encodes the same proof
+// to the same bytes regardless of the seal ordering it was built with:
+//
+//	version (1 byte) | view (16 bytes, see View.MarshalBinary) | hash |
+//	seals count (uvarint) | (node id | seal) for each seal, sorted by node id
+//
+// where the hash, the node ids and the seals are prefixed with their uvarint length. A proof with an aggregated
+// signature, a seal hash, a parent hash or the next validators is encoded with version 2, with the following
+// fields appended to the seals, each possibly empty:
+//
+//	aggregated signature | seal hash | parent hash |
+//	validators count (uvarint) | (node id | voting power (uvarint)) for each validator, in rotation order
+func (f *FinalizationProof) MarshalBinary() ([]byte, error) {
+	if f.View == nil || len(f.Hash) == 0 {
+		return nil, errEmptyProof
+	}
+
+	seals := append([]CommittedSeal{}, f.CommittedSeals...)
+	sort.Slice(seals, func(i, j int) bool { return seals[i].NodeID < seals[j].NodeID })
+	for i := 1; i < len(seals); i++ {
+		if seals[i].NodeID == seals[i-1].NodeID {
+			return nil, fmt.Errorf("seal signer %s: %w", seals[i].NodeID, ErrDuplicate)
+		}
+	}
+
+	buf := []byte{finalizationProofVersion}
+	extended := len(f.AggregatedSignature) != 0 || len(f.SealHash) != 0 || len(f.ParentHash) != 0 || f.NextValidators != nil
+	if extended {
+		buf[0] = extendedProofVersion
+	}
+	view, _ := f.View.MarshalBinary()
+	buf = append(buf, view...)
+	buf = appendBytes(buf, f.Hash)
+	buf = appendUvarint(buf, uint64(len(seals)))
+	for _, seal := range seals {
+		buf = appendBytes(buf, []byte(seal.NodeID))
+		buf = appendBytes(buf, seal.Signature)
+	}
+	if extended {
+		buf = appendBytes(buf, f.AggregatedSignature)
+		buf = appendBytes(buf, f.SealHash)
+		buf = appendBytes(buf, f.ParentHash)
+		buf = appendValidators(buf, f.NextValidators)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary. Only the canonical encoding is accepted,
+// namely the seals must be sorted by node id and no trailing bytes are allowed.
+func (f *FinalizationProof) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	version, err := r.ReadByte()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrProofEncoding, err)
+	}
+	if version != finalizationProofVersion && version != extendedProofVersion {
+		return fmt.Errorf("%w: unknown version %d", ErrProofEncoding, version)
+	}
+
+	encodedView := make([]byte, ViewEncodingSize)
+	if _, err := io.ReadFull(r, encodedView); err != nil {
+		return fmt.Errorf("%w: view: %v", ErrProofEncoding, err)
+	}
+	view := &View{}
+	if err := view.UnmarshalBinary(encodedView); err != nil {
+		return fmt.Errorf("%w: %v", ErrProofEncoding, err)
+	}
+	hash, err := readBytes(r)
+	if err != nil {
+		return fmt.Errorf("%w: hash: %v", ErrProofEncoding, err)
+	}
+	if len(hash) == 0 {
+		return fmt.Errorf("%w: empty hash", ErrProofEncoding)
+	}
+
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return fmt.Errorf("%w: seals count: %v", ErrProofEncoding, err)
+	}
+	// every seal takes at least its two length prefixes
+	if count > uint64(r.Len())/2 {
+		return fmt.Errorf("%w: %d seals exceed the encoding size", ErrProofEncoding, count)
+	}
+	seals := make([]CommittedSeal, 0, count)
+	for i := uint64(0); i < count; i++ {
+		nodeID, err := readBytes(r)
+		if err != nil {
+			return fmt.Errorf("%w: seal %d node id: %v", ErrProofEncoding, i, err)
+		}
+		signature, err := readBytes(r)
+		if err != nil {
+			return fmt.Errorf("%w: seal %d: %v", ErrProofEncoding, i, err)
+		}
+		seal := CommittedSeal{NodeID: NodeID(nodeID), Signature: signature}
+		if len(seals) > 0 && seals[len(seals)-1].NodeID >= seal.NodeID {
+			return fmt.Errorf("%w: seals not sorted by node id", ErrProofEncoding)
+		}
+		seals = append(seals, seal)
+	}
+	var aggregated, sealHash, parentHash []byte
+	var nextValidators *StaticValidatorSet
+	if version == extendedProofVersion {
+		if aggregated, err = readBytes(r); err != nil {
+			return fmt.Errorf("%w: aggregated signature: %v", ErrProofEncoding, err)
+		}
+		if sealHash, err = readBytes(r); err != nil {
+			return fmt.Errorf("%w: seal hash: %v", ErrProofEncoding, err)
+		}
+		if parentHash, err = readBytes(r); err != nil {
+			return fmt.Errorf("%w: parent hash: %v", ErrProofEncoding, err)
+		}
+		if nextValidators, err = readValidators(r); err != nil {
+			return fmt.Errorf("%w: next validators: %v", ErrProofEncoding, err)
+		}
+		if len(aggregated) == 0 && len(sealHash) == 0 && len(parentHash) == 0 && nextValidators == nil {
+			return fmt.Errorf("%w: empty extension", ErrProofEncoding)
+		}
+		aggregated, sealHash, parentHash = nilIfEmpty(aggregated), nilIfEmpty(sealHash), nilIfEmpty(parentHash)
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrProofEncoding, r.Len())
+	}
+
+	f.Hash = hash
+	f.View = view
+	f.CommittedSeals = seals
+	f.AggregatedSignature = aggregated
+	f.SealHash = sealHash
+	f.ParentHash = parentHash
+	f.NextValidators = nextValidators
+	return nil
+}
+
+// appendValidators appends the count of the validators followed by the id and the voting power of each of them
+func appendValidators(buf []byte, validators *StaticValidatorSet) []byte {
+	if validators == nil {
+		return appendUvarint(buf, 0)
+	}
+	buf = appendUvarint(buf, uint64(len(validators.Nodes)))
+	for _, id := range validators.Nodes {
+		buf = appendBytes(buf, []byte(id))
+		buf = appendUvarint(buf, validators.VotingPowerMap[id])
+	}
+	return buf
+}
+
+// readValidators reads the validators appended by appendValidators, nil if there are none
+func readValidators(r *bytes.Reader) (*StaticValidatorSet, error) {
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	// every validator takes at least its length prefix and its voting power
+	if count > uint64(r.Len())/2 {
+		return nil, fmt.Errorf("%d validators exceed the encoding size", count)
+	}
+	validators := &StaticValidatorSet{Nodes: make([]NodeID, 0, count), VotingPowerMap: map[NodeID]uint64{}}
+	for i := uint64(0); i < count; i++ {
+		id, err := readBytes(r)
+		if err != nil {
+			return nil, fmt.Errorf("validator %d: %v", i, err)
+		}
+		power, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("validator %d voting power: %v", i, err)
+		}
+		if _, ok := validators.VotingPowerMap[NodeID(id)]; ok {
+			return nil, fmt.Errorf("validator %s: %w", id, ErrDuplicate)
+		}
+		validators.Nodes = append(validators.Nodes, NodeID(id))
+		validators.VotingPowerMap[NodeID(id)] = power
+	}
+	return validators, nil
+}
+
+// nilIfEmpty returns nil for an empty slice, so that the decoded proofs match the encoded ones
+func nilIfEmpty(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+	return b
+}
+
+// appendUvarint appends the uvarint encoding of the value
+func appendUvarint(buf []byte, value uint64) []byte {
+	var encoded [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(encoded[:], value)
+	return append(buf, encoded[:n]...)
+}
+
+// appendBytes appends the data prefixed with its uvarint length
+func appendBytes(buf []byte, data []byte) []byte {
+	return append(appendUvarint(buf, uint64(len(data))), data...)
+}
+
+// readBytes reads data prefixed with its uvarint length
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(r.Len()) {
+		return nil, fmt.Errorf("length %d exceeds the remaining %d bytes", size, r.Len())
+	}
+	data := make([]byte, size)
+	if _, err := r.Read(data); err != nil && size > 0 {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,101 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinalizationProof_MarshalBinary_RoundTrip(t *testing.T) {
+	proof := &FinalizationProof{
+		Hash: []byte{0x1, 0x2, 0x3},
+		View: ViewMsg(10, 2),
+		CommittedSeals: []CommittedSeal{
+			{NodeID: "C", Signature: []byte{0xc}},
+			{NodeID: "A", Signature: []byte{0xa, 0xa}},
+			{NodeID: "B", Signature: []byte{}},
+		},
+	}
+
+	data, err := proof.MarshalBinary()
+	require.NoError(t, err)
+
+	decoded := &FinalizationProof{}
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, proof.Hash, decoded.Hash)
+	assert.Equal(t, proof.View, decoded.View)
+	assert.Equal(t, []CommittedSeal{
+		{NodeID: "A", Signature: []byte{0xa, 0xa}},
+		{NodeID: "B", Signature: []byte{}},
+		{NodeID: "C", Signature: []byte{0xc}},
+	}, decoded.CommittedSeals)
+
+	// the decoded proof encodes to the same bytes
+	encoded, err := decoded.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, data, encoded)
+}
+
+// Test that two nodes, receiving the commits in different orders and ordering the seals differently,
+// encode their finalization proofs to the same bytes.
+func TestFinalizationProof_MarshalBinary_Deterministic(t *testing.T) {
+	build := func(ordering SealOrdering, arrival []NodeID) []byte {
+		m := newMockPbft(t, []NodeID{"D", "A", "C", "B"}, nil, "A")
+		WithSealOrdering(ordering)(m.config)
+		m.state.view = ViewMsg(1, 0)
+		m.state.proposal = &Proposal{Data: mockProposal, Hash: digest}
+		for _, from := range arrival {
+			msg := createMessage(from, MessageReq_Commit, ViewMsg(1, 0))
+			msg.Seal = []byte("seal " + from)
+			require.NoError(t, m.state.addCommitMsg(msg))
+		}
+
+		proof, err := m.BuildFinalizationProof()
+		require.NoError(t, err)
+		data, err := proof.MarshalBinary()
+		require.NoError(t, err)
+		return data
+	}
+
+	first := build(SealOrdering_SigningTime, []NodeID{"C", "A", "D"})
+	second := build(SealOrdering_ValidatorIndex, []NodeID{"D", "C", "A"})
+	assert.Equal(t, first, second)
+}
+
+func TestFinalizationProof_UnmarshalBinary_Invalid(t *testing.T) {
+	proof := &FinalizationProof{
+		Hash:           digest,
+		View:           ViewMsg(1, 0),
+		CommittedSeals: []CommittedSeal{{NodeID: "A", Signature: []byte{0xa}}, {NodeID: "B", Signature: []byte{0xb}}},
+	}
+	data, err := proof.MarshalBinary()
+	require.NoError(t, err)
+
+	// the seals of A and B swapped
+	unsorted := append([]byte{}, data[:len(data)-8]...)
+	unsorted = append(unsorted, 0x1, 'B', 0x1, 0xb, 0x1, 'A', 0x1, 0xa)
+
+	versioned := append([]byte{}, data...)
+	versioned[0] = 3
+
+	cases := map[string][]byte{
+		"empty":           {},
+		"unknown version": versioned,
+		"truncated":       data[:len(data)-1],
+		"trailing bytes":  append(append([]byte{}, data...), 0x0),
+		"unsorted seals":  unsorted,
+	}
+	for name, encoded := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, (&FinalizationProof{}).UnmarshalBinary(encoded), ErrProofEncoding)
+		})
+	}
+
+	// duplicate signers and empty proofs can not be encoded
+	proof.CommittedSeals = append(proof.CommittedSeals, CommittedSeal{NodeID: "A"})
+	_, err = proof.MarshalBinary()
+	assert.ErrorIs(t, err, ErrDuplicate)
+	_, err = (&FinalizationProof{}).MarshalBinary()
+	assert.ErrorIs(t, err, errEmptyProof)
+}
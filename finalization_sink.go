@@ -0,0 +1,116 @@
+package pbft
+
+import (
+	"sync"
+	"time"
+)
+
+// FinalizationSink receives the finalized sequences, to deliver them to an external system (see WithFinalizationSink).
+// Unlike the event stream, the sequences are delivered at least once and in sequence order.
+type FinalizationSink interface {
+	// Deliver delivers the finalized sequence with its finalization proof (nil if the node could not build it).
+	// On error, the delivery of the sequence is retried and the next sequences wait for it, so a sequence
+	// may be delivered again after a failure reported for a delivery that actually succeeded.
+	Deliver(sequence uint64, proof *FinalizationProof) error
+}
+
+// finalizedDelivery is a finalized sequence waiting to be delivered to the sink
+type finalizedDelivery struct {
+	sequence uint64
+	proof    *FinalizationProof
+}
+
+// finalizationSinkWorker delivers the finalized sequences to the sink from its own goroutine, so that a failing
+// or slow sink never blocks the consensus. The goroutine runs only while sequences are pending.
+type finalizationSinkWorker struct {
+	lock       sync.Mutex
+	sink       FinalizationSink
+	backoff    time.Duration
+	maxBackoff time.Duration
+	logger     Logger
+
+	// pending are the sequences to deliver, in sequence order
+	pending []finalizedDelivery
+
+	// delivered is the high-water mark, the last sequence delivered (zero before the first one)
+	delivered uint64
+
+	running bool
+}
+
+func newFinalizationSinkWorker(sink FinalizationSink, backoff, maxBackoff time.Duration, logger Logger) *finalizationSinkWorker {
+	return &finalizationSinkWorker{
+		sink:       sink,
+		backoff:    backoff,
+		maxBackoff: maxBackoff,
+		logger:     logger,
+	}
+}
+
+// enqueue queues the delivery of the finalized sequence, the sequences already delivered or queued are ignored
+func (w *finalizationSinkWorker) enqueue(sequence uint64, proof *FinalizationProof) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	last := w.delivered
+	if n := len(w.pending); n > 0 {
+		last = w.pending[n-1].sequence
+	}
+	if sequence <= last {
+		return
+	}
+	w.pending = append(w.pending, finalizedDelivery{sequence: sequence, proof: proof})
+	if !w.running {
+		w.running = true
+		go w.run()
+	}
+}
+
+// run delivers the pending sequences in order, retrying each one with an exponential backoff until it succeeds
+func (w *finalizationSinkWorker) run() {
+	backoff := w.backoff
+	for {
+		w.lock.Lock()
+		if len(w.pending) == 0 {
+			w.running = false
+			w.lock.Unlock()
+			return
+		}
+		next := w.pending[0]
+		w.lock.Unlock()
+
+		if err := w.sink.Deliver(next.sequence, next.proof); err != nil {
+			w.logger.Printf("[WARN] finalized sequence delivery failed, retrying in %s: sequence=%d, err=%v", backoff, next.sequence, err)
+			time.Sleep(backoff)
+			if backoff *= 2; w.maxBackoff > 0 && backoff > w.maxBackoff {
+				backoff = w.maxBackoff
+			}
+			continue
+		}
+		backoff = w.backoff
+
+		w.lock.Lock()
+		w.delivered = next.sequence
+		w.pending[0] = finalizedDelivery{}
+		w.pending = w.pending[1:]
+		w.lock.Unlock()
+	}
+}
+
+// lastDelivered returns the high-water mark of the sink
+func (w *finalizationSinkWorker) lastDelivered() uint64 {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.delivered
+}
+
+// SinkDelivered returns the last sequence delivered to the FinalizationSink, zero if none was delivered or
+// no sink is configured. The sequences after it are pending and delivered again after a restart only if
+// the integrator replays them, i.e. out of the FinalizedStore (see FinalizedStream).
+func (p *Pbft) SinkDelivered() uint64 {
+	if p.sink == nil {
+		return 0
+	}
+	return p.sink.lastDelivered()
+}
@@ -0,0 +1,83 @@
+package pbft
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink records the delivery attempts, the first failures attempts fail
+type recordingSink struct {
+	lock      sync.Mutex
+	failures  int
+	attempts  []uint64
+	delivered []uint64
+}
+
+func (r *recordingSink) Deliver(sequence uint64, _ *FinalizationProof) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.attempts = append(r.attempts, sequence)
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("sink unavailable")
+	}
+	r.delivered = append(r.delivered, sequence)
+	return nil
+}
+
+func (r *recordingSink) snapshot() ([]uint64, []uint64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]uint64{}, r.attempts...), append([]uint64{}, r.delivered...)
+}
+
+func TestPbft_FinalizationSink_Retry(t *testing.T) {
+	sink := &recordingSink{failures: 2}
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.sink = newFinalizationSinkWorker(sink, time.Millisecond, 4*time.Millisecond, m.logger)
+
+	for sequence := uint64(1); sequence <= 3; sequence++ {
+		m.publishFinalized(&FinalizedProposal{Sequence: sequence, Proof: &FinalizationProof{View: ViewMsg(sequence, 0)}})
+	}
+	// an already queued sequence is not delivered twice
+	m.publishFinalized(&FinalizedProposal{Sequence: 2})
+
+	require.Eventually(t, func() bool { return m.SinkDelivered() == 3 }, time.Second, time.Millisecond)
+
+	// the first sequence is retried until it succeeds, the next ones wait for it
+	attempts, delivered := sink.snapshot()
+	assert.Equal(t, []uint64{1, 1, 1, 2, 3}, attempts)
+	assert.Equal(t, []uint64{1, 2, 3}, delivered)
+}
+
+func TestPbft_FinalizationSink_HighWaterMark(t *testing.T) {
+	sink := &recordingSink{failures: 1 << 30}
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.sink = newFinalizationSinkWorker(sink, time.Millisecond, time.Millisecond, m.logger)
+
+	m.publishFinalized(&FinalizedProposal{Sequence: 1})
+	m.publishFinalized(&FinalizedProposal{Sequence: 2})
+
+	// the high-water mark does not advance while the delivery fails
+	require.Eventually(t, func() bool {
+		attempts, _ := sink.snapshot()
+		return len(attempts) >= 3
+	}, time.Second, time.Millisecond)
+	assert.Zero(t, m.SinkDelivered())
+	attempts, _ := sink.snapshot()
+	for _, sequence := range attempts {
+		assert.Equal(t, uint64(1), sequence)
+	}
+
+	sink.lock.Lock()
+	sink.failures = 0
+	sink.lock.Unlock()
+	require.Eventually(t, func() bool { return m.SinkDelivered() == 2 }, time.Second, time.Millisecond)
+}
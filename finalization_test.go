@@ -0,0 +1,208 @@
+package pbft
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// verifyEchoSeal is a SealVerifier for the mock keys which sign by echoing the payload
+func verifyEchoSeal(_ NodeID, hash []byte, seal []byte) error {
+	if !bytes.Equal(hash, seal) {
+		return errors.New("seal does not match")
+	}
+	return nil
+}
+
+func TestFinalizationProof_BuildAndVerify(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	m := newMockPbft(t, validatorIds, nil, "A")
+
+	commit := func(from NodeID) {
+		msg := createMessage(from, MessageReq_Commit, ViewMsg(1, 0))
+		msg.Hash = digest
+		msg.Seal = digest
+		require.NoError(t, m.state.addCommitMsg(msg))
+	}
+
+	commit("A")
+	commit("B")
+	_, err := m.BuildFinalizationProof()
+	assert.ErrorIs(t, err, errInsufficientSeals)
+
+	commit("C")
+	proof, err := m.BuildFinalizationProof()
+	require.NoError(t, err)
+	assert.Equal(t, digest, proof.Hash)
+	assert.Equal(t, ViewMsg(1, 0), proof.View)
+	assert.Len(t, proof.CommittedSeals, 3)
+
+	validators := m.state.validators
+	assert.NoError(t, VerifyFinalizationProof(proof, validators, verifyEchoSeal, ProofSealing{}))
+
+	// seals below quorum
+	partial := *proof
+	partial.CommittedSeals = proof.CommittedSeals[:2]
+	assert.ErrorIs(t, VerifyFinalizationProof(&partial, validators, verifyEchoSeal, ProofSealing{}), errInsufficientSeals)
+
+	// seal from a node outside of the validator set
+	outsider := *proof
+	outsider.CommittedSeals = append([]CommittedSeal{{NodeID: "E", Signature: digest}}, proof.CommittedSeals[:2]...)
+	assert.ErrorIs(t, VerifyFinalizationProof(&outsider, validators, verifyEchoSeal, ProofSealing{}), ErrNotValidator)
+
+	// duplicated signer
+	duplicated := *proof
+	duplicated.CommittedSeals = append([]CommittedSeal{proof.CommittedSeals[0]}, proof.CommittedSeals...)
+	assert.ErrorIs(t, VerifyFinalizationProof(&duplicated, validators, verifyEchoSeal, ProofSealing{}), ErrDuplicate)
+
+	// invalid seal
+	forged := *proof
+	forged.Hash = digest1
+	assert.ErrorIs(t, VerifyFinalizationProof(&forged, validators, verifyEchoSeal, ProofSealing{}), ErrBadSignature)
+}
+
+func TestPbft_OnFinalize(t *testing.T) {
+	finalizeRetryDelay = time.Millisecond
+	defer func() { finalizeRetryDelay = 100 * time.Millisecond }()
+
+	delivered := []uint64{}
+	failures := 1
+	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
+	WithOnFinalize(func(sequence uint64, proof *FinalizationProof) error {
+		require.NotNil(t, proof)
+		assert.Equal(t, sequence, proof.View.Sequence)
+		if failures > 0 {
+			// the delivery of the first sequence fails once and has to be retried
+			failures--
+			return errors.New("application not ready")
+		}
+		delivered = append(delivered, sequence)
+		return nil
+	})(m.config)
+	m.pipeline = newPipeline(2)
+	m.pipeline.advance(1)
+
+	commitSequence := func(sequence uint64) {
+		m.setSequence(sequence)
+		m.state.resetRoundMsgs()
+		m.state.proposal = &Proposal{Data: mockProposal, Hash: digest}
+		m.state.proposer = "A"
+		for _, from := range []NodeID{"A", "B", "C"} {
+			require.NoError(t, m.state.addMessage(createMessage(from, MessageReq_Commit, ViewMsg(sequence, 0))))
+		}
+		m.setState(CommitState)
+		m.runCycle(context.Background())
+		require.Equal(t, DoneState, m.getState())
+	}
+
+	// sequence 2 is finalized through the pipeline right after sequence 1
+	m.emitMsg(createMessage("B", MessageReq_Preprepare, ViewMsg(2, 0)))
+	for _, from := range []NodeID{"A", "B", "C"} {
+		m.emitMsg(createMessage(from, MessageReq_Commit, ViewMsg(2, 0)))
+	}
+	commitSequence(1)
+	assert.Equal(t, []uint64{1, 2}, delivered)
+
+	// sequence 2 was already delivered
+	commitSequence(2)
+	commitSequence(3)
+	assert.Equal(t, []uint64{1, 2, 3}, delivered)
+}
+
+func TestPbft_OnFinalize_HaltsOnError(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
+	WithOnFinalize(func(uint64, *FinalizationProof) error {
+		return errors.New("application not ready")
+	})(m.config)
+	m.state.view = ViewMsg(1, 0)
+	m.state.proposer = "A"
+	for _, from := range []NodeID{"A", "B", "C"} {
+		require.NoError(t, m.state.addMessage(createMessage(from, MessageReq_Commit, ViewMsg(1, 0))))
+	}
+	m.setState(CommitState)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	m.runCycle(ctx)
+
+	// the node does not advance while the sequence is not delivered
+	assert.Equal(t, CommitState, m.getState())
+	assert.Zero(t, m.lastNotified)
+}
+
+func TestPbft_ApplyFinalizationProof(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	m := newMockPbft(t, validatorIds, nil, "A")
+	m.setSequence(1)
+
+	delivered := []uint64{}
+	WithOnFinalize(func(sequence uint64, proof *FinalizationProof) error {
+		delivered = append(delivered, sequence)
+		return nil
+	})(m.config)
+
+	proof := func(sequence uint64, signers ...NodeID) *FinalizationProof {
+		proof := &FinalizationProof{Hash: digest, View: ViewMsg(sequence, 2)}
+		for _, signer := range signers {
+			proof.CommittedSeals = append(proof.CommittedSeals, CommittedSeal{NodeID: signer, Signature: digest})
+		}
+		return proof
+	}
+
+	// insufficient quorum power
+	assert.ErrorIs(t, m.ApplyFinalizationProof(proof(1, "A", "B")), errInsufficientSeals)
+	// signer out of the validator set
+	assert.ErrorIs(t, m.ApplyFinalizationProof(proof(1, "A", "B", "E")), ErrNotValidator)
+	// proof of another sequence
+	assert.ErrorIs(t, m.ApplyFinalizationProof(proof(2, "A", "B", "C")), ErrWrongView)
+	assert.ErrorIs(t, m.ApplyFinalizationProof(nil), errEmptyProof)
+	assert.Empty(t, delivered)
+	assert.Equal(t, uint64(1), m.state.GetSequence())
+
+	// a valid proof finalizes the sequence and advances the node
+	valid := proof(1, "A", "B", "C")
+	require.NoError(t, m.ApplyFinalizationProof(valid))
+	assert.Equal(t, []uint64{1}, delivered)
+	assert.Equal(t, uint64(2), m.state.GetSequence())
+	assert.Equal(t, uint64(0), m.state.GetCurrentRound())
+	assert.Equal(t, DoneState, m.getState())
+	assert.Equal(t, valid, m.LastFinalizationProof())
+}
+
+// Test that a proof reaching the default quorum but not the commit quorum of the node is not applied.
+func TestPbft_ApplyFinalizationProof_CommitQuorum(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.setSequence(1)
+	require.NoError(t, m.state.initializePhaseQuorums(nil, func(total uint64) uint64 { return total }))
+
+	proof := &FinalizationProof{Hash: digest, View: ViewMsg(1, 0)}
+	for _, signer := range []NodeID{"A", "B", "C"} {
+		proof.CommittedSeals = append(proof.CommittedSeals, CommittedSeal{NodeID: signer, Signature: digest})
+	}
+	require.NoError(t, VerifyFinalizationProof(proof, m.state.validators, m.validateCommitSeal, ProofSealing{}))
+	assert.ErrorIs(t, m.ApplyFinalizationProof(proof), errInsufficientSeals)
+	assert.Equal(t, uint64(1), m.state.GetSequence())
+
+	proof.CommittedSeals = append(proof.CommittedSeals, CommittedSeal{NodeID: "D", Signature: digest})
+	assert.NoError(t, m.ApplyFinalizationProof(proof))
+	assert.Equal(t, uint64(2), m.state.GetSequence())
+}
+
+func TestPbft_LastFinalizationProof_KeptOnBuildFailure(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	previous := &FinalizationProof{Hash: digest1, View: ViewMsg(1, 0)}
+	m.finalizationProof = previous
+	m.state.proposer = "A"
+
+	// the commit state is entered without the commit quorum, the proof can not be built
+	m.setState(CommitState)
+	m.runCycle(context.Background())
+
+	assert.Equal(t, DoneState, m.getState())
+	assert.Equal(t, previous, m.LastFinalizationProof())
+}
@@ -0,0 +1,182 @@
+package pbft
+
+import (
+	"errors"
+	"sync"
+)
+
+// defaultFinalizedHistory is the number of finalized sequences kept by the default FinalizedStore
+const defaultFinalizedHistory = 64
+
+// ErrFinalizedNotFound is returned by a FinalizedStore when the sequence is not stored
+var ErrFinalizedNotFound = errors.New("finalized sequence not found")
+
+// FinalizedProposal is a sequence finalized by the node, as delivered by FinalizedStream
+type FinalizedProposal struct {
+	// Sequence is the finalized sequence
+	Sequence uint64
+
+	// Proposal is the sealed proposal of the sequence, nil when the sequence was finalized out of a
+	// finalization proof (see ApplyFinalizationProof)
+	Proposal *SealedProposal
+
+	// Proof is the finalization proof of the sequence
+	Proof *FinalizationProof
+}
+
+// FinalizedStore keeps the finalized sequences replayed by FinalizedStream
+type FinalizedStore interface {
+	// Save stores the finalized sequence
+	Save(finalized *FinalizedProposal) error
+
+	// Load returns the finalized sequence or ErrFinalizedNotFound
+	Load(sequence uint64) (*FinalizedProposal, error)
+}
+
+// MemoryFinalizedStore is a FinalizedStore keeping the last finalized sequences in memory
+type MemoryFinalizedStore struct {
+	lock      sync.Mutex
+	max       int
+	finalized map[uint64]*FinalizedProposal
+	sequences []uint64
+}
+
+// NewMemoryFinalizedStore creates an in-memory FinalizedStore keeping the last max finalized sequences
+func NewMemoryFinalizedStore(max int) *MemoryFinalizedStore {
+	return &MemoryFinalizedStore{
+		max:       max,
+		finalized: map[uint64]*FinalizedProposal{},
+	}
+}
+
+// Save implements FinalizedStore interface
+func (m *MemoryFinalizedStore) Save(finalized *FinalizedProposal) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.max <= 0 {
+		return nil
+	}
+	if _, ok := m.finalized[finalized.Sequence]; !ok {
+		if len(m.sequences) >= m.max {
+			delete(m.finalized, m.sequences[0])
+			m.sequences = m.sequences[1:]
+		}
+		m.sequences = append(m.sequences, finalized.Sequence)
+	}
+	m.finalized[finalized.Sequence] = finalized
+	return nil
+}
+
+// Load implements FinalizedStore interface
+func (m *MemoryFinalizedStore) Load(sequence uint64) (*FinalizedProposal, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	finalized, ok := m.finalized[sequence]
+	if !ok {
+		return nil, ErrFinalizedNotFound
+	}
+	return finalized, nil
+}
+
+// finalizedFeed broadcasts the finalized sequences to the streams
+type finalizedFeed struct {
+	lock sync.Mutex
+
+	// last is the last finalized sequence (zero before the first one)
+	last uint64
+
+	// updated is closed, and replaced, every time a sequence is finalized
+	updated chan struct{}
+}
+
+func newFinalizedFeed() *finalizedFeed {
+	return &finalizedFeed{updated: make(chan struct{})}
+}
+
+// publish records the finalized sequence and wakes up the streams
+func (f *finalizedFeed) publish(sequence uint64) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if sequence > f.last {
+		f.last = sequence
+	}
+	close(f.updated)
+	f.updated = make(chan struct{})
+}
+
+// current returns the last finalized sequence and the channel closed on the next one
+func (f *finalizedFeed) current() (uint64, <-chan struct{}) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.last, f.updated
+}
+
+// publishFinalized stores the finalized sequence and delivers it to the streams
+func (p *Pbft) publishFinalized(finalized *FinalizedProposal) {
+	if err := p.config.FinalizedStore.Save(finalized); err != nil {
+		p.logger.Printf("[ERROR] failed to store finalized sequence: sequence=%d, err=%v", finalized.Sequence, err)
+	}
+	p.finalizedFeed.publish(finalized.Sequence)
+	if p.sink != nil {
+		p.sink.enqueue(finalized.Sequence, finalized.Proof)
+	}
+
+	event := ConsensusEvent{Type: ConsensusEvent_Finalized, View: View{Sequence: finalized.Sequence}}
+	if finalized.Proof != nil && finalized.Proof.View != nil {
+		event.View.Round = finalized.Proof.View.Round
+	}
+	p.events.publish(event)
+}
+
+// FinalizedStream streams the finalized sequences from the given one onwards, in strict sequence order: the ones
+// already in the FinalizedStore are replayed, then the ones finalized by the node are delivered as they come.
+// The stream is closed by the returned cancel function, or when a sequence to deliver is no longer in the store
+// (i.e. the reader fell behind the history kept by the store), so that a sequence is never skipped.
+// The stream does not block the consensus, a slow reader only falls behind.
+func (p *Pbft) FinalizedStream(fromSequence uint64) (<-chan FinalizedProposal, func()) {
+	stream := make(chan FinalizedProposal)
+	done := make(chan struct{})
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() { close(done) })
+	}
+
+	if fromSequence == 0 {
+		// sequences start at 1
+		fromSequence = 1
+	}
+	go func() {
+		defer close(stream)
+
+		for next := fromSequence; ; {
+			last, updated := p.finalizedFeed.current()
+			finalized, err := p.config.FinalizedStore.Load(next)
+			switch {
+			case err == nil:
+				select {
+				case stream <- *finalized:
+					next++
+				case <-done:
+					return
+				}
+				continue
+			case errors.Is(err, ErrFinalizedNotFound) && next > last:
+				// not finalized yet
+			default:
+				p.logger.Printf("[WARN] finalized stream closed: sequence=%d, err=%v", next, err)
+				return
+			}
+
+			select {
+			case <-updated:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return stream, cancel
+}
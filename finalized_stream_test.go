@@ -0,0 +1,80 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPbft_FinalizedStream(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	commitSequence := func(sequence uint64) {
+		m.setSequence(sequence)
+		m.state.proposal = &Proposal{Data: mockProposal, Hash: digest}
+		m.state.proposer = "A"
+		for _, from := range []NodeID{"A", "B", "C"} {
+			require.NoError(t, m.state.addMessage(createMessage(from, MessageReq_Commit, ViewMsg(sequence, 0))))
+		}
+		m.setState(CommitState)
+		m.runCycle(context.Background())
+		require.Equal(t, DoneState, m.getState())
+	}
+	receive := func(stream <-chan FinalizedProposal) FinalizedProposal {
+		select {
+		case finalized, ok := <-stream:
+			require.True(t, ok, "stream closed")
+			return finalized
+		case <-time.After(time.Second):
+			t.Fatal("no finalized sequence received")
+		}
+		return FinalizedProposal{}
+	}
+
+	for sequence := uint64(1); sequence <= 3; sequence++ {
+		commitSequence(sequence)
+	}
+
+	// the past sequences are replayed from the requested one
+	stream, cancel := m.FinalizedStream(2)
+	for _, sequence := range []uint64{2, 3} {
+		finalized := receive(stream)
+		assert.Equal(t, sequence, finalized.Sequence)
+		assert.Equal(t, sequence, finalized.Proposal.Number)
+		assert.Equal(t, sequence, finalized.Proof.View.Sequence)
+	}
+
+	// then the new ones are delivered as they are finalized
+	select {
+	case finalized := <-stream:
+		t.Fatalf("unexpected sequence %d", finalized.Sequence)
+	case <-time.After(20 * time.Millisecond):
+	}
+	commitSequence(4)
+	assert.Equal(t, uint64(4), receive(stream).Sequence)
+
+	cancel()
+	cancel()
+	_, ok := <-stream
+	assert.False(t, ok)
+}
+
+func TestPbft_FinalizedStream_History(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithFinalizedStore(NewMemoryFinalizedStore(2))(m.config)
+	for sequence := uint64(1); sequence <= 3; sequence++ {
+		m.publishFinalized(&FinalizedProposal{Sequence: sequence})
+	}
+
+	// sequence 1 is no longer stored, the stream is closed instead of skipping it
+	stream, cancel := m.FinalizedStream(1)
+	defer cancel()
+	select {
+	case finalized, ok := <-stream:
+		assert.False(t, ok, "unexpected sequence %d", finalized.Sequence)
+	case <-time.After(time.Second):
+		t.Fatal("the stream was not closed")
+	}
+}
@@ -0,0 +1,75 @@
+package pbft
+
+import "sync"
+
+// Reasons of the buffered future messages discarded, reported to the OnFutureMessageDropped callback
+const (
+	// FutureDropReasonSequenceFull is a message evicted because its sequence reached MaxFutureMessagesPerSequence
+	FutureDropReasonSequenceFull = "sequence_full"
+
+	// FutureDropReasonBufferFull is a message evicted because the buffer reached MaxFutureMessages
+	FutureDropReasonBufferFull = "buffer_full"
+
+	// FutureDropReasonMemoryBudget is a message shed because the MemoryBudget is exceeded
+	FutureDropReasonMemoryBudget = "memory_budget"
+)
+
+// maxPendingFutureDrops is the maximum number of discarded messages waiting to be delivered to the callback.
+// Beyond it, the discarded messages are not reported.
+const maxPendingFutureDrops = 1024
+
+// FutureMessageDroppedCallback is invoked for every buffered future message discarded, with one of
+// the FutureDropReason values
+type FutureMessageDroppedCallback func(msg *MessageReq, reason string)
+
+// futureDrop is a discarded future message waiting to be delivered to the callback
+type futureDrop struct {
+	msg    *MessageReq
+	reason string
+}
+
+// futureDropNotifier delivers the discarded future messages to the callback from its own goroutine, so that
+// a slow callback never blocks the ingestion of the messages. The goroutine runs only while drops are pending.
+type futureDropNotifier struct {
+	lock     sync.Mutex
+	callback FutureMessageDroppedCallback
+	pending  []futureDrop
+	running  bool
+}
+
+func newFutureDropNotifier(callback FutureMessageDroppedCallback) *futureDropNotifier {
+	return &futureDropNotifier{callback: callback}
+}
+
+// notify queues the delivery of the discarded message, it never blocks
+func (n *futureDropNotifier) notify(msg *MessageReq, reason string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if len(n.pending) >= maxPendingFutureDrops {
+		return
+	}
+	n.pending = append(n.pending, futureDrop{msg: msg, reason: reason})
+	if !n.running {
+		n.running = true
+		go n.run()
+	}
+}
+
+// run delivers the pending drops in order, until none is left
+func (n *futureDropNotifier) run() {
+	for {
+		n.lock.Lock()
+		if len(n.pending) == 0 {
+			n.running = false
+			n.lock.Unlock()
+			return
+		}
+		drop := n.pending[0]
+		n.pending[0] = futureDrop{}
+		n.pending = n.pending[1:]
+		n.lock.Unlock()
+
+		n.callback(drop.msg, drop.reason)
+	}
+}
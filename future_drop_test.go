@@ -0,0 +1,61 @@
+package pbft
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFutureMessages_DropCallback(t *testing.T) {
+	type drop struct {
+		from   NodeID
+		reason string
+	}
+	drops := make(chan drop, 10)
+	release := make(chan struct{})
+	callback := func(msg *MessageReq, reason string) {
+		// a slow callback does not block the ingestion
+		<-release
+		drops <- drop{msg.From, reason}
+	}
+
+	f := newFutureMessages(2, 3)
+	f.onDrop = newFutureDropNotifier(callback).notify
+	f.advance(1)
+
+	for _, msg := range []*MessageReq{
+		createMessage("A", MessageReq_Prepare, ViewMsg(2, 0)),
+		createMessage("B", MessageReq_Prepare, ViewMsg(2, 0)),
+		createMessage("C", MessageReq_Prepare, ViewMsg(2, 0)), // evicts A
+		createMessage("D", MessageReq_Prepare, ViewMsg(3, 0)),
+		createMessage("E", MessageReq_Prepare, ViewMsg(4, 0)), // evicts B
+	} {
+		require.True(t, f.add(msg))
+	}
+	assert.Equal(t, 3, f.len())
+	close(release)
+
+	received := []drop{}
+	for len(received) < 2 {
+		select {
+		case d := <-drops:
+			received = append(received, d)
+		case <-time.After(time.Second):
+			t.Fatal("drop not notified")
+		}
+	}
+	assert.Equal(t, []drop{
+		{"A", FutureDropReasonSequenceFull},
+		{"B", FutureDropReasonBufferFull},
+	}, received)
+
+	// advancing past a sequence is not a drop
+	f.advance(4)
+	select {
+	case d := <-drops:
+		t.Fatalf("unexpected drop %v", d)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
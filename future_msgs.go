@@ -0,0 +1,204 @@
+package pbft
+
+import "sync"
+
+const (
+	defaultMaxFutureMessagesPerSequence = 1000
+	defaultMaxFutureMessages            = 10000
+)
+
+// futureMessages is a bounded buffer for messages that belong to a sequence ahead of the node.
+// Once the node advances to a buffered sequence, its messages are flushed back to the message queue.
+type futureMessages struct {
+	lock sync.Mutex
+
+	// sequence is the current sequence of the node
+	sequence uint64
+
+	// initialized is set once the current sequence of the node is known
+	initialized bool
+
+	// msgs are the buffered messages in insertion order
+	msgs []*MessageReq
+
+	// perSequence is the number of buffered messages per sequence
+	perSequence map[uint64]int
+
+	// maxPerSequence is the maximum number of buffered messages for a single sequence
+	maxPerSequence int
+
+	// maxTotal is the maximum number of buffered messages
+	maxTotal int
+
+	// onDrop is notified of the buffered messages discarded to respect the bounds (nil if not set)
+	onDrop func(msg *MessageReq, reason string)
+}
+
+// newFutureMessages creates a new future messages buffer with the given bounds
+func newFutureMessages(maxPerSequence, maxTotal int) *futureMessages {
+	return &futureMessages{
+		perSequence:    map[uint64]int{},
+		maxPerSequence: maxPerSequence,
+		maxTotal:       maxTotal,
+	}
+}
+
+// add buffers the message if it belongs to a future sequence. It returns false if the message is not a future one.
+// When the buffer is full, the oldest buffered message (of the same sequence first) is evicted.
+func (f *futureMessages) add(msg *MessageReq) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if !f.initialized || msg.View == nil || msg.View.Sequence <= f.sequence {
+		return false
+	}
+	f.push(msg)
+	return true
+}
+
+// restore buffers the messages restored from a BufferStore, that can happen before the current sequence of the node
+// is known. The messages of the past sequences are discarded once the node advances.
+func (f *futureMessages) restore(msgs []*MessageReq) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, msg := range msgs {
+		if msg.View == nil || (f.initialized && msg.View.Sequence <= f.sequence) {
+			continue
+		}
+		f.push(msg)
+	}
+}
+
+// push buffers the message within the bounds, evicting the oldest buffered messages if needed
+func (f *futureMessages) push(msg *MessageReq) {
+	sequence := msg.View.Sequence
+	if f.perSequence[sequence] >= f.maxPerSequence {
+		f.evict(func(m *MessageReq) bool { return m.View.Sequence == sequence }, FutureDropReasonSequenceFull)
+	}
+	if len(f.msgs) >= f.maxTotal {
+		f.evict(func(*MessageReq) bool { return true }, FutureDropReasonBufferFull)
+	}
+	if f.maxPerSequence <= 0 || f.maxTotal <= 0 {
+		// buffering is disabled, the message is dropped
+		f.dropped(msg, FutureDropReasonBufferFull)
+		return
+	}
+
+	f.msgs = append(f.msgs, msg)
+	f.perSequence[sequence]++
+}
+
+// evict removes the oldest buffered message matching the filter
+func (f *futureMessages) evict(filter func(*MessageReq) bool, reason string) {
+	for i, msg := range f.msgs {
+		if !filter(msg) {
+			continue
+		}
+		f.msgs = append(f.msgs[:i], f.msgs[i+1:]...)
+		f.decrement(msg.View.Sequence)
+		f.dropped(msg, reason)
+		return
+	}
+}
+
+// dropped notifies the discarded message
+func (f *futureMessages) dropped(msg *MessageReq, reason string) {
+	if f.onDrop != nil {
+		f.onDrop(msg, reason)
+	}
+}
+
+func (f *futureMessages) decrement(sequence uint64) {
+	f.perSequence[sequence]--
+	if f.perSequence[sequence] <= 0 {
+		delete(f.perSequence, sequence)
+	}
+}
+
+// advance sets the current sequence of the node and returns the buffered messages of that sequence in insertion order.
+// Messages of older sequences are discarded.
+func (f *futureMessages) advance(sequence uint64) []*MessageReq {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.sequence = sequence
+	f.initialized = true
+
+	flushed := []*MessageReq{}
+	remaining := f.msgs[:0]
+	for _, msg := range f.msgs {
+		switch {
+		case msg.View.Sequence == sequence:
+			flushed = append(flushed, msg)
+			f.decrement(msg.View.Sequence)
+		case msg.View.Sequence > sequence:
+			remaining = append(remaining, msg)
+		default:
+			f.decrement(msg.View.Sequence)
+		}
+	}
+	// release references to the removed messages
+	for i := len(remaining); i < len(f.msgs); i++ {
+		f.msgs[i] = nil
+	}
+	f.msgs = remaining
+
+	return flushed
+}
+
+// len returns the number of buffered messages
+func (f *futureMessages) len() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return len(f.msgs)
+}
+
+// syncMessages buffers the messages received while the node is syncing,
+// so that they are processed once the node resumes the consensus
+type syncMessages struct {
+	lock sync.Mutex
+
+	// msgs are the buffered messages in insertion order
+	msgs []*MessageReq
+
+	// max is the maximum number of buffered messages, the oldest one is evicted when the buffer is full
+	max int
+}
+
+// newSyncMessages creates a new sync messages buffer with the given bound
+func newSyncMessages(max int) *syncMessages {
+	return &syncMessages{max: max}
+}
+
+// add buffers the message
+func (s *syncMessages) add(msg *MessageReq) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.max <= 0 {
+		return
+	}
+	if len(s.msgs) >= s.max {
+		s.msgs[0] = nil
+		s.msgs = s.msgs[1:]
+	}
+	s.msgs = append(s.msgs, msg)
+}
+
+// drain empties the buffer and returns the messages of the given sequence onwards in insertion order.
+// Messages of older sequences are discarded since those sequences got finalized during the sync.
+func (s *syncMessages) drain(sequence uint64) []*MessageReq {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	drained := []*MessageReq{}
+	for _, msg := range s.msgs {
+		if msg.View != nil && msg.View.Sequence >= sequence {
+			drained = append(drained, msg)
+		}
+	}
+	s.msgs = nil
+	return drained
+}
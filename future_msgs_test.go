@@ -0,0 +1,98 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFutureMessages_Bounds(t *testing.T) {
+	f := newFutureMessages(2, 3)
+	f.advance(1)
+
+	// messages of the current and past sequences are not buffered
+	assert.False(t, f.add(createMessage("A", MessageReq_Prepare, ViewMsg(1, 0))))
+	assert.False(t, f.add(createMessage("A", MessageReq_Prepare, ViewMsg(0, 0))))
+
+	// per sequence limit evicts the oldest message of the same sequence
+	assert.True(t, f.add(createMessage("A", MessageReq_Prepare, ViewMsg(2, 0))))
+	assert.True(t, f.add(createMessage("B", MessageReq_Prepare, ViewMsg(2, 0))))
+	assert.True(t, f.add(createMessage("C", MessageReq_Prepare, ViewMsg(2, 0))))
+	assert.Equal(t, 2, f.len())
+
+	// total limit evicts the oldest message overall
+	assert.True(t, f.add(createMessage("A", MessageReq_Prepare, ViewMsg(3, 0))))
+	assert.True(t, f.add(createMessage("B", MessageReq_Prepare, ViewMsg(3, 0))))
+	assert.Equal(t, 3, f.len())
+
+	flushed := f.advance(2)
+	require.Len(t, flushed, 1)
+	assert.Equal(t, NodeID("C"), flushed[0].From)
+	assert.Equal(t, 2, f.len())
+
+	// advancing past a sequence discards its messages
+	assert.Empty(t, f.advance(4))
+	assert.Zero(t, f.len())
+}
+
+func TestPbft_FutureMessagesFlushedOnSequence(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+
+	m.emitMsg(createMessage("B", MessageReq_Prepare, ViewMsg(2, 0)))
+	m.emitMsg(createMessage("C", MessageReq_Prepare, ViewMsg(2, 0)))
+	m.emitMsg(createMessage("D", MessageReq_Prepare, ViewMsg(3, 0)))
+
+	// future messages do not reach the message queue
+	assert.Equal(t, 3, m.futureMsgs.len())
+	assert.Zero(t, m.msgQueue.validateStateQueue.Len())
+
+	m.sequence = 2
+	require.NoError(t, m.SetBackend(m.backend))
+
+	assert.Equal(t, 2, m.msgQueue.validateStateQueue.Len())
+	assert.Equal(t, 1, m.futureMsgs.len())
+}
+
+func TestSyncMessages_Drain(t *testing.T) {
+	s := newSyncMessages(3)
+	s.add(createMessage("A", MessageReq_Prepare, ViewMsg(1, 0)))
+	s.add(createMessage("B", MessageReq_Prepare, ViewMsg(2, 0)))
+	s.add(createMessage("C", MessageReq_Commit, ViewMsg(3, 0)))
+	// the buffer is full, the oldest message is evicted
+	s.add(createMessage("D", MessageReq_Commit, ViewMsg(2, 1)))
+
+	drained := s.drain(2)
+	require.Len(t, drained, 3)
+	assert.Equal(t, NodeID("B"), drained[0].From)
+	assert.Equal(t, NodeID("C"), drained[1].From)
+	assert.Equal(t, NodeID("D"), drained[2].From)
+
+	assert.Empty(t, s.drain(0))
+}
+
+func TestPbft_SyncMessages(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
+	m.setState(SyncState)
+
+	// messages received while syncing sequence 1
+	for _, msg := range []*MessageReq{
+		createMessage("B", MessageReq_Prepare, ViewMsg(1, 0)),
+		createMessage("B", MessageReq_Prepare, ViewMsg(2, 0)),
+		createMessage("C", MessageReq_Commit, ViewMsg(2, 0)),
+	} {
+		msg.Hash = digest
+		m.PushMessage(msg)
+	}
+	assert.Zero(t, m.msgQueue.validateStateQueue.Len())
+
+	// the sync finalized sequence 1
+	m.sequence = 2
+	require.NoError(t, m.SetBackend(m.backend))
+	m.SetInitialState(m.ctx)
+
+	require.Equal(t, 2, m.msgQueue.validateStateQueue.Len())
+	for _, msg := range m.msgQueue.validateStateQueue {
+		assert.Equal(t, uint64(2), msg.View.Sequence)
+	}
+}
@@ -0,0 +1,163 @@
+package pbft
+
+import "sync"
+
+// GossipMetrics is an optional extension of Metrics. When the configured Metrics implements it and the gossip
+// efficiency is tracked (see WithGossipEfficiency), it receives the counts of every finalized sequence.
+type GossipMetrics interface {
+	// GossipEfficiency is invoked when a sequence is finalized with the number of messages the node sent,
+	// the number of unique messages it received and their ratio (see Pbft.GossipEfficiency)
+	GossipEfficiency(sequence uint64, sent, unique uint64, ratio float64)
+}
+
+// gossipCounts are the messages sent and received by the node for a sequence
+type gossipCounts struct {
+	sent   uint64
+	unique map[msgIdentity]struct{}
+}
+
+// ratio returns the number of messages sent per unique message received, zero if none was received
+func (c *gossipCounts) ratio() float64 {
+	if len(c.unique) == 0 {
+		return 0
+	}
+	return float64(c.sent) / float64(len(c.unique))
+}
+
+// gossipCounter counts, per active sequence, the messages sent through the transport and the unique messages
+// received from it. Like the sequence dedup, the counts of a sequence are released once it is finalized
+// and at most maxDedupSequences sequences are tracked.
+type gossipCounter struct {
+	lock sync.Mutex
+
+	// finalized is the last finalized sequence, its messages and the older ones are not counted
+	finalized uint64
+
+	// sequences are the counts per sequence
+	sequences map[uint64]*gossipCounts
+}
+
+func newGossipCounter() *gossipCounter {
+	return &gossipCounter{
+		sequences: map[uint64]*gossipCounts{},
+	}
+}
+
+// counts returns the counts of the sequence, nil if the sequence is not counted. The lock must be held.
+func (g *gossipCounter) counts(sequence uint64) *gossipCounts {
+	if sequence <= g.finalized {
+		return nil
+	}
+	counts, ok := g.sequences[sequence]
+	if !ok {
+		if len(g.sequences) >= maxDedupSequences {
+			return nil
+		}
+		counts = &gossipCounts{unique: map[msgIdentity]struct{}{}}
+		g.sequences[sequence] = counts
+	}
+	return counts
+}
+
+// sent counts a message sent by the node
+func (g *gossipCounter) sent(msg *MessageReq) {
+	if msg.View == nil {
+		return
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if counts := g.counts(msg.View.Sequence); counts != nil {
+		counts.sent++
+	}
+}
+
+// received counts a message received from the transport, the repeated deliveries are counted once
+func (g *gossipCounter) received(msg *MessageReq) {
+	if msg.View == nil {
+		return
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if counts := g.counts(msg.View.Sequence); counts != nil {
+		counts.unique[msgIdentity{
+			round: msg.View.Round,
+			typ:   msg.Type,
+			from:  msg.From,
+			hash:  string(msg.Hash),
+		}] = struct{}{}
+	}
+}
+
+// ratio returns the number of messages sent per unique message received for the sequence
+func (g *gossipCounter) ratio(sequence uint64) float64 {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	counts, ok := g.sequences[sequence]
+	if !ok {
+		return 0
+	}
+	return counts.ratio()
+}
+
+// finalize returns the counts of the sequence and releases them along with the ones of the previous sequences
+func (g *gossipCounter) finalize(sequence uint64) (sent, unique uint64, ratio float64) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if counts, ok := g.sequences[sequence]; ok {
+		sent, unique, ratio = counts.sent, uint64(len(counts.unique)), counts.ratio()
+	}
+	if sequence > g.finalized {
+		g.finalized = sequence
+	}
+	for s := range g.sequences {
+		if s <= sequence {
+			delete(g.sequences, s)
+		}
+	}
+	return sent, unique, ratio
+}
+
+// countingTransport is the Transport wrapper counting the messages sent by the node
+type countingTransport struct {
+	Transport
+	counter *gossipCounter
+}
+
+// Gossip implements Transport interface
+func (t *countingTransport) Gossip(msg *MessageReq) error {
+	t.counter.sent(msg)
+	return t.Transport.Gossip(msg)
+}
+
+// Send implements Transport interface
+func (t *countingTransport) Send(to NodeID, msg *MessageReq) error {
+	t.counter.sent(msg)
+	return t.Transport.Send(to, msg)
+}
+
+// GossipEfficiency returns the number of messages the node sent per unique message it received for the current
+// sequence, to detect the redundancy of the gossip. It returns zero when the efficiency is not tracked
+// (see WithGossipEfficiency) or no message of the sequence was received yet.
+func (p *Pbft) GossipEfficiency() float64 {
+	if p.gossipCounter == nil {
+		return 0
+	}
+	return p.gossipCounter.ratio(p.state.GetSequence())
+}
+
+// reportGossipEfficiency reports the gossip efficiency of the finalized sequence to the metrics supporting it
+func (p *Pbft) reportGossipEfficiency(sequence uint64) {
+	if p.gossipCounter == nil {
+		return
+	}
+	sent, unique, ratio := p.gossipCounter.finalize(sequence)
+	if metrics, ok := p.config.Metrics.(GossipMetrics); ok {
+		metrics.GossipEfficiency(sequence, sent, unique, ratio)
+	}
+}
@@ -0,0 +1,57 @@
+package pbft
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type gossipRecordingMetrics struct {
+	noopMetrics
+	reports []string
+}
+
+func (g *gossipRecordingMetrics) GossipEfficiency(sequence uint64, sent, unique uint64, ratio float64) {
+	g.reports = append(g.reports, fmt.Sprintf("sequence %d: sent %d, unique %d, ratio %.2f", sequence, sent, unique, ratio))
+}
+
+func TestPbft_GossipEfficiency(t *testing.T) {
+	metrics := &gossipRecordingMetrics{}
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithMetrics(metrics)(m.config)
+	m.gossipCounter = newGossipCounter()
+	m.transport = &countingTransport{Transport: m.transport, counter: m.gossipCounter}
+	m.setSequence(1)
+
+	// the node sends two messages of the sequence
+	require.NoError(t, m.transport.Gossip(createMessage("A", MessageReq_Prepare, ViewMsg(1, 0))))
+	require.NoError(t, m.transport.Send("B", createMessage("A", MessageReq_RoundChange, ViewMsg(1, 1))))
+	assert.Zero(t, m.GossipEfficiency())
+
+	// and receives four unique messages of the sequence, a combined vote counts once
+	prepare := createMessage("B", MessageReq_Prepare, ViewMsg(1, 0))
+	for _, msg := range []*MessageReq{
+		prepare,
+		prepare.Copy(),
+		createMessage("C", MessageReq_Prepare, ViewMsg(1, 0)),
+		createMessage("C", MessageReq_Commit, ViewMsg(1, 0)),
+		createMessage("D", MessageReq_PrepareCommit, ViewMsg(1, 0)),
+		// the messages of other sequences are counted apart
+		createMessage("D", MessageReq_Prepare, ViewMsg(2, 0)),
+	} {
+		m.PushMessage(msg)
+	}
+	assert.Equal(t, 0.5, m.GossipEfficiency())
+
+	m.sequenceFinalized(1)
+	assert.Equal(t, []string{"sequence 1: sent 2, unique 4, ratio 0.50"}, metrics.reports)
+
+	// the counts of the finalized sequence are released, the later ones are kept
+	assert.Zero(t, m.GossipEfficiency())
+	m.setSequence(2)
+	assert.Zero(t, m.GossipEfficiency())
+	m.sequenceFinalized(2)
+	assert.Equal(t, "sequence 2: sent 0, unique 1, ratio 0.00", metrics.reports[1])
+}
@@ -0,0 +1,94 @@
+package pbft
+
+import (
+	"sync"
+	"time"
+)
+
+// Health is a snapshot of the node liveness used for readiness and liveness probes
+type Health struct {
+	// Healthy is false when the node stays in the same view beyond the configured threshold or is syncing
+	Healthy bool
+
+	// State is the current PBFT state
+	State State
+
+	// View is the current view of the node
+	View View
+
+	// Since is the time when the node last made progress
+	Since time.Time
+}
+
+// healthTracker keeps track of the last time the node made progress
+type healthTracker struct {
+	lock     sync.Mutex
+	view     View
+	progress time.Time
+}
+
+// markProgress records that the node made progress in the given view
+func (h *healthTracker) markProgress(view View, now time.Time) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.view = view
+	h.progress = now
+}
+
+// lastProgress returns the view and the time of the last progress
+func (h *healthTracker) lastProgress() (View, time.Time) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.view, h.progress
+}
+
+// Health returns the liveness snapshot of the node
+func (p *Pbft) Health() Health {
+	view, since := p.health.lastProgress()
+	st := p.getState()
+
+	healthy := st != SyncState
+	if healthy && p.config.HealthThreshold > 0 {
+		healthy = p.config.Clock.Now().Sub(since) <= p.config.HealthThreshold
+	}
+
+	return Health{
+		Healthy: healthy,
+		State:   st,
+		View:    view,
+		Since:   since,
+	}
+}
+
+// IsHealthy returns whether the node is making progress
+func (p *Pbft) IsHealthy() bool {
+	return p.Health().Healthy
+}
+
+// markSeen records that a message from the node was received at the given time
+func (s *state) markSeen(from NodeID, at time.Time) {
+	s.msgLock.Lock()
+	defer s.msgLock.Unlock()
+
+	s.lastSeen[from] = at
+}
+
+// LastSeen returns the time the node last received a message from each validator, as measured by the Clock.
+// The validators the node never received a message from are missing.
+func (p *Pbft) LastSeen() map[NodeID]time.Time {
+	p.state.msgLock.RLock()
+	defer p.state.msgLock.RUnlock()
+
+	lastSeen := make(map[NodeID]time.Time, len(p.state.lastSeen))
+	for id, at := range p.state.lastSeen {
+		lastSeen[id] = at
+	}
+	return lastSeen
+}
+
+// markProgress records the current view as progress made by the node
+func (p *Pbft) markProgress() {
+	p.health.markProgress(View{Sequence: p.state.view.Sequence, Round: p.state.GetCurrentRound()}, p.config.Clock.Now())
+}
@@ -0,0 +1,66 @@
+package pbft
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPbft_Health(t *testing.T) {
+	clock := NewManualClock(time.Now())
+
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithClock(clock)(m.config)
+	WithHealthThreshold(10 * time.Second)(m.config)
+	m.setSequence(1)
+	m.setState(ValidateState)
+
+	assert.True(t, m.IsHealthy())
+
+	// staying in the same view within the threshold is healthy
+	clock.Advance(10 * time.Second)
+	assert.True(t, m.IsHealthy())
+
+	// staying in the same view beyond the threshold is unhealthy
+	clock.Advance(time.Second)
+	health := m.Health()
+	assert.False(t, health.Healthy)
+	assert.Equal(t, View{Sequence: 1, Round: 0}, health.View)
+
+	// moving to a new round is progress
+	m.setRound(1)
+	health = m.Health()
+	assert.True(t, health.Healthy)
+	assert.Equal(t, View{Sequence: 1, Round: 1}, health.View)
+
+	// syncing node is never healthy
+	m.setState(SyncState)
+	assert.False(t, m.IsHealthy())
+}
+
+func TestPbft_LastSeen(t *testing.T) {
+	start := time.Now()
+	clock := NewManualClock(start)
+
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithClock(clock)(m.config)
+	m.setSequence(1)
+
+	assert.Empty(t, m.LastSeen())
+
+	m.PushMessage(createMessage("B", MessageReq_RoundChange, ViewMsg(1, 0)))
+	clock.Advance(5 * time.Second)
+	m.PushMessage(createMessage("C", MessageReq_RoundChange, ViewMsg(1, 0)))
+
+	assert.Equal(t, map[NodeID]time.Time{"B": start, "C": start.Add(5 * time.Second)}, m.LastSeen())
+
+	// a new message from the node moves its last seen time
+	clock.Advance(5 * time.Second)
+	m.PushMessage(createMessage("B", MessageReq_RoundChange, ViewMsg(1, 1)))
+
+	lastSeen := m.LastSeen()
+	assert.Equal(t, start.Add(10*time.Second), lastSeen["B"])
+	assert.Equal(t, start.Add(5*time.Second), lastSeen["C"])
+	assert.NotContains(t, lastSeen, NodeID("D"))
+}
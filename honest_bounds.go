@@ -0,0 +1,73 @@
+package pbft
+
+import "sort"
+
+// minHonestForLiveness returns the voting power the honest validators need to make progress on their own,
+// while the faulty ones stay silent: enough to reach both the prepare and the commit quorums.
+func (s *state) minHonestForLiveness() uint64 {
+	quorum := s.getPrepareQuorumSize()
+	if commit := s.getCommitQuorumSize(); commit > quorum {
+		quorum = commit
+	}
+	return quorum
+}
+
+// minHonestForSafety returns the voting power the honest validators need so that two prepare quorums
+// always intersect in an honest validator, namely the faulty voting power stays below the quorums overlap.
+// It is the whole voting power when the quorums may not overlap.
+func (s *state) minHonestForSafety() uint64 {
+	total := s.totalVotingPower()
+	overlap := 2 * s.getPrepareQuorumSize()
+	if overlap <= total {
+		return total
+	}
+	// the faulty voting power must be lower than overlap - total
+	return 2*total - overlap + 1
+}
+
+// totalVotingPower returns the voting power of the validator set
+func (s *state) totalVotingPower() uint64 {
+	total := uint64(0)
+	for _, power := range s.validators.VotingPower() {
+		total += power
+	}
+	return total
+}
+
+// minValidatorsFor returns the fewest validators whose voting power adds up to at least the given one.
+// It returns the size of the set plus one if the whole set does not reach it.
+func (s *state) minValidatorsFor(votingPower uint64) int {
+	powers := make([]uint64, 0, s.validators.Len())
+	for _, power := range s.validators.VotingPower() {
+		powers = append(powers, power)
+	}
+	sort.Slice(powers, func(i, j int) bool { return powers[i] > powers[j] })
+
+	accumulated := uint64(0)
+	for i, power := range powers {
+		if accumulated >= votingPower {
+			return i
+		}
+		accumulated += power
+	}
+	if accumulated >= votingPower {
+		return len(powers)
+	}
+	return len(powers) + 1
+}
+
+// MinHonestForLiveness returns the minimum number of honest validators, and their minimum voting power,
+// required to finalize sequences with the current validator set and quorums. The number of validators
+// assumes the heaviest ones are honest, so it is a lower bound with unequal voting power.
+func (p *Pbft) MinHonestForLiveness() (int, uint64) {
+	votingPower := p.state.minHonestForLiveness()
+	return p.state.minValidatorsFor(votingPower), votingPower
+}
+
+// MinHonestForSafety returns the minimum number of honest validators, and their minimum voting power,
+// required to never finalize conflicting proposals with the current validator set and quorums. The number of validators
+// assumes the heaviest ones are honest, so it is a lower bound with unequal voting power.
+func (p *Pbft) MinHonestForSafety() (int, uint64) {
+	votingPower := p.state.minHonestForSafety()
+	return p.state.minValidatorsFor(votingPower), votingPower
+}
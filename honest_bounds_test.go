@@ -0,0 +1,89 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestState_MinHonest(t *testing.T) {
+	cases := []struct {
+		name                       string
+		votingPower                map[NodeID]uint64
+		livenessNodes, safetyNodes int
+		livenessPower, safetyPower uint64
+	}{
+		{
+			name:          "4 equal",
+			votingPower:   CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}),
+			livenessNodes: 3, livenessPower: 3,
+			safetyNodes: 3, safetyPower: 3,
+		},
+		{
+			name:          "7 equal",
+			votingPower:   CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D", "E", "F", "G"}),
+			livenessNodes: 5, livenessPower: 5,
+			safetyNodes: 5, safetyPower: 5,
+		},
+		{
+			// the quorums of 3 out of 5 overlap in a single validator, which must then be honest
+			name:          "5 equal",
+			votingPower:   CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D", "E"}),
+			livenessNodes: 3, livenessPower: 3,
+			safetyNodes: 5, safetyPower: 5,
+		},
+		{
+			// the quorums of 3 out of 6 may not overlap
+			name:          "6 equal",
+			votingPower:   CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D", "E", "F"}),
+			livenessNodes: 3, livenessPower: 3,
+			safetyNodes: 6, safetyPower: 6,
+		},
+		{
+			name:          "dominant validator",
+			votingPower:   map[NodeID]uint64{"A": 5, "B": 1, "C": 1, "D": 1},
+			livenessNodes: 1, livenessPower: 5,
+			safetyNodes: 3, safetyPower: 7,
+		},
+		{
+			name:          "weighted",
+			votingPower:   map[NodeID]uint64{"A": 4, "B": 3, "C": 2, "D": 1},
+			livenessNodes: 2, livenessPower: 7,
+			safetyNodes: 2, safetyPower: 7,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pool := newTesterAccountPool()
+			pool.addAccounts(c.votingPower)
+			s, err := initState(pool)
+			require.NoError(t, err)
+
+			assert.Equal(t, c.livenessPower, s.minHonestForLiveness())
+			assert.Equal(t, c.livenessNodes, s.minValidatorsFor(c.livenessPower))
+			assert.Equal(t, c.safetyPower, s.minHonestForSafety())
+			assert.Equal(t, c.safetyNodes, s.minValidatorsFor(c.safetyPower))
+
+			// the honest voting power outweighs the tolerated faulty one
+			assert.Greater(t, s.minHonestForLiveness(), s.getMaxFaultyVotingPower())
+		})
+	}
+}
+
+func TestPbft_MinHonest_PhaseQuorums(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	nodes, votingPower := m.MinHonestForLiveness()
+	assert.Equal(t, 3, nodes)
+	assert.Equal(t, uint64(3), votingPower)
+
+	// a higher commit quorum requires more honest validators to finalize
+	require.NoError(t, m.state.initializePhaseQuorums(nil, func(total uint64) uint64 { return total }))
+	nodes, votingPower = m.MinHonestForLiveness()
+	assert.Equal(t, 4, nodes)
+	assert.Equal(t, uint64(4), votingPower)
+
+	nodes, votingPower = m.MinHonestForSafety()
+	assert.Equal(t, 3, nodes)
+	assert.Equal(t, uint64(3), votingPower)
+}
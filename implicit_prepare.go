@@ -0,0 +1,21 @@
+package pbft
+
+// implicitProposerPrepare returns whether the proposer skips its Prepare message, its Preprepare counting as
+// its Prepare (see WithImplicitProposerPrepare). The prepared certificates need the sealed Prepare of the proposer.
+func (p *Pbft) implicitProposerPrepare() bool {
+	return p.config.ImplicitProposerPrepare && !p.config.PreparedCertificates
+}
+
+// addImplicitPrepare counts the Preprepare of the proposer as a Prepare of the proposal in the current view.
+// An explicit Prepare of the proposer is then dropped as a duplicate, so the proposer is counted once.
+func (p *Pbft) addImplicitPrepare(proposer NodeID) {
+	msg := &MessageReq{
+		Type: MessageReq_Prepare,
+		From: proposer,
+		View: p.state.view.Copy(),
+		Hash: append([]byte{}, p.state.proposal.Hash...),
+	}
+	if err := p.state.addPrepareMsg(msg); err != nil {
+		p.logger.Printf("[DEBUG] implicit prepare of the proposer %s not counted: %v", proposer, err)
+	}
+}
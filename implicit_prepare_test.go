@@ -0,0 +1,79 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransition_AcceptState_ImplicitProposerPrepare(t *testing.T) {
+	cases := []struct {
+		name     string
+		implicit bool
+		prepared uint64
+	}{
+		{"explicit", false, 0},
+		{"implicit", true, 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+			if c.implicit {
+				WithImplicitProposerPrepare()(m.config)
+			}
+			m.state.view = ViewMsg(1, 0)
+			m.setState(AcceptState)
+			WithScheduler(NewDeterministicScheduler(MessageEvent(createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0)))))(m.config)
+
+			m.runCycle(context.Background())
+
+			// the own prepare is counted once received back from the transport
+			m.expect(expectResult{
+				sequence:               1,
+				state:                  ValidateState,
+				prepareMsgs:            c.prepared,
+				prepareMsgsVotingPower: c.prepared,
+				outgoing:               1, // prepare
+			})
+
+			// an explicit prepare of the proposer is not counted twice
+			prepare := createMessage("A", MessageReq_Prepare, ViewMsg(1, 0))
+			prepare.Hash = digest
+			_ = m.state.addPrepareMsg(prepare)
+			assert.Equal(t, uint64(1), m.state.prepared.getAccumulatedVotingPower())
+		})
+	}
+}
+
+func TestTransition_AcceptState_ImplicitProposerPrepare_Proposer(t *testing.T) {
+	cases := []struct {
+		name     string
+		implicit bool
+		outgoing uint64
+		prepared uint64
+	}{
+		{"explicit", false, 2, 0}, // preprepare and prepare
+		{"implicit", true, 1, 1},  // preprepare
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+			if c.implicit {
+				WithImplicitProposerPrepare()(m.config)
+			}
+			m.setState(AcceptState)
+			m.setProposal(&Proposal{Data: mockProposal, Hash: digest})
+
+			m.runCycle(context.Background())
+
+			m.expect(expectResult{
+				sequence:               1,
+				state:                  ValidateState,
+				prepareMsgs:            c.prepared,
+				prepareMsgsVotingPower: c.prepared,
+				outgoing:               c.outgoing,
+			})
+		})
+	}
+}
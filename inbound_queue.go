@@ -0,0 +1,132 @@
+package pbft
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// msgPriority returns the priority of the message type used when the inbound queue is full.
+// RoundChange and Commit messages are required to make progress (or recover) and are the last to be dropped.
+func msgPriority(typ MsgType) int {
+	switch typ {
+	case MessageReq_RoundChange, MessageReq_Commit:
+		return 2
+	case MessageReq_Prepare:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// inboundQueue is a bounded ring buffer for the messages received from the transport.
+// When the queue is full, the oldest message of the lowest priority is dropped.
+type inboundQueue struct {
+	lock sync.Mutex
+
+	// buf is the ring buffer storage
+	buf []*MessageReq
+
+	// head is the index of the oldest message
+	head int
+
+	// size is the number of queued messages
+	size int
+
+	// dropped is the number of dropped messages
+	dropped uint64
+}
+
+// newInboundQueue creates a new inbound queue with the given capacity
+func newInboundQueue(capacity int) *inboundQueue {
+	return &inboundQueue{
+		buf: make([]*MessageReq, capacity),
+	}
+}
+
+// push enqueues the message. It returns the message dropped to make room for it (or the message itself
+// if every queued message has a higher priority), or nil if nothing was dropped.
+func (q *inboundQueue) push(msg *MessageReq) *MessageReq {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.buf) == 0 {
+		atomic.AddUint64(&q.dropped, 1)
+		return msg
+	}
+
+	var dropped *MessageReq
+	if q.size == len(q.buf) {
+		victim := q.lowestPriority()
+		if msgPriority(msg.Type) < msgPriority(q.at(victim).Type) {
+			atomic.AddUint64(&q.dropped, 1)
+			return msg
+		}
+		dropped = q.remove(victim)
+		atomic.AddUint64(&q.dropped, 1)
+	}
+
+	q.buf[(q.head+q.size)%len(q.buf)] = msg
+	q.size++
+	return dropped
+}
+
+// pop dequeues the oldest message, it returns nil if the queue is empty
+func (q *inboundQueue) pop() *MessageReq {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.size == 0 {
+		return nil
+	}
+	msg := q.buf[q.head]
+	q.buf[q.head] = nil
+	q.head = (q.head + 1) % len(q.buf)
+	q.size--
+	return msg
+}
+
+// len returns the number of queued messages
+func (q *inboundQueue) len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.size
+}
+
+// droppedCount returns the number of messages dropped since the queue was created
+func (q *inboundQueue) droppedCount() uint64 {
+	return atomic.LoadUint64(&q.dropped)
+}
+
+// at returns the i-th queued message, starting from the oldest one
+func (q *inboundQueue) at(i int) *MessageReq {
+	return q.buf[(q.head+i)%len(q.buf)]
+}
+
+// lowestPriority returns the position of the oldest message with the lowest priority
+func (q *inboundQueue) lowestPriority() int {
+	victim := 0
+	for i := 1; i < q.size && msgPriority(q.at(victim).Type) > 0; i++ {
+		if msgPriority(q.at(i).Type) < msgPriority(q.at(victim).Type) {
+			victim = i
+		}
+	}
+	return victim
+}
+
+// remove removes the i-th queued message, shifting the newer ones to keep the insertion order
+func (q *inboundQueue) remove(i int) *MessageReq {
+	msg := q.at(i)
+	if i == 0 {
+		q.buf[q.head] = nil
+		q.head = (q.head + 1) % len(q.buf)
+		q.size--
+		return msg
+	}
+	for ; i < q.size-1; i++ {
+		q.buf[(q.head+i)%len(q.buf)] = q.at(i + 1)
+	}
+	q.buf[(q.head+q.size-1)%len(q.buf)] = nil
+	q.size--
+	return msg
+}
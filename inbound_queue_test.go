@@ -0,0 +1,96 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInboundQueue_Order(t *testing.T) {
+	q := newInboundQueue(3)
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, q.push(createMessage("A", MessageReq_Prepare, ViewMsg(1, uint64(i)))))
+		msg := q.pop()
+		require.NotNil(t, msg)
+		assert.Equal(t, uint64(i), msg.View.Round)
+	}
+	assert.Nil(t, q.pop())
+	assert.Zero(t, q.droppedCount())
+}
+
+func TestInboundQueue_DropLowestPriority(t *testing.T) {
+	q := newInboundQueue(3)
+
+	q.push(createMessage("A", MessageReq_Commit, ViewMsg(1, 0)))
+	q.push(createMessage("B", MessageReq_Preprepare, ViewMsg(1, 0)))
+	q.push(createMessage("C", MessageReq_Prepare, ViewMsg(1, 0)))
+
+	// the preprepare message is dropped to make room for the round change
+	dropped := q.push(createMessage("D", MessageReq_RoundChange, ViewMsg(1, 0)))
+	require.NotNil(t, dropped)
+	assert.Equal(t, NodeID("B"), dropped.From)
+
+	// the prepare message is dropped to make room for the commit
+	dropped = q.push(createMessage("E", MessageReq_Commit, ViewMsg(1, 0)))
+	require.NotNil(t, dropped)
+	assert.Equal(t, NodeID("C"), dropped.From)
+
+	// every queued message has a higher priority, the incoming preprepare is dropped
+	msg := createMessage("F", MessageReq_Preprepare, ViewMsg(1, 0))
+	assert.Equal(t, msg, q.push(msg))
+
+	assert.Equal(t, uint64(3), q.droppedCount())
+	require.Equal(t, 3, q.len())
+	for _, from := range []NodeID{"A", "D", "E"} {
+		assert.Equal(t, from, q.pop().From)
+	}
+}
+
+func TestPbft_PushMessage_InboundQueue(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.inbound = newInboundQueue(2)
+
+	for _, msg := range []*MessageReq{
+		createMessage("B", MessageReq_Preprepare, ViewMsg(1, 0)),
+		createMessage("C", MessageReq_Commit, ViewMsg(1, 0)),
+		createMessage("D", MessageReq_Commit, ViewMsg(1, 0)),
+	} {
+		msg.Hash = digest
+		m.PushMessage(msg)
+	}
+
+	assert.Equal(t, uint64(1), m.DroppedMessages())
+	assert.Equal(t, uint64(1), m.stats.DroppedMsgCount(MessageReq_Preprepare.String()))
+
+	m.drainInbound()
+	assert.Zero(t, m.inbound.len())
+	assert.Equal(t, 2, m.msgQueue.validateStateQueue.Len())
+}
+
+func BenchmarkInboundQueue_PushPop(b *testing.B) {
+	q := newInboundQueue(1024)
+	msg := createMessage("A", MessageReq_Prepare, ViewMsg(1, 0))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.push(msg)
+		q.pop()
+	}
+}
+
+func BenchmarkInboundQueue_Full(b *testing.B) {
+	q := newInboundQueue(1024)
+	for i := 0; i < 1024; i++ {
+		q.push(createMessage("A", MessageReq_Commit, ViewMsg(1, 0)))
+	}
+	msg := createMessage("A", MessageReq_Commit, ViewMsg(1, 0))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q.push(msg)
+	}
+}
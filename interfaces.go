@@ -8,6 +8,36 @@ type ValidatorSet interface {
 	VotingPower() map[NodeID]uint64
 }
 
+// ProposerSelector represents the proposer rotation behavior
+type ProposerSelector interface {
+	// CalcProposer returns the proposer of the given round. The seed is a per-sequence value
+	// agreed by all the nodes (e.g. previous proposal hash), so the result must be deterministic.
+	CalcProposer(validators ValidatorSet, seed []byte, round uint64) NodeID
+}
+
+// ProposerSeedBackend is an optional Backend extension which provides the per-sequence
+// seed used by the ProposerSelector
+type ProposerSeedBackend interface {
+	// ProposerSeed returns the seed for the current sequence
+	ProposerSeed() []byte
+}
+
+// ScoredProposerSelector is an optional ProposerSelector extension which weights the proposers with per-sequence
+// performance scores (see PerformanceProposerSelector)
+type ScoredProposerSelector interface {
+	// CalcScoredProposer returns the proposer of the given round out of the scores of the validators.
+	// Like the seed, the scores are agreed by all the nodes, so the result must be deterministic.
+	CalcScoredProposer(validators ValidatorSet, scores map[NodeID]uint64, seed []byte, round uint64) NodeID
+}
+
+// ProposerScoresBackend is an optional Backend extension which provides the per-sequence performance scores
+// of the validators used by a ScoredProposerSelector. The scores must be agreed on chain (e.g. derived from the
+// committed seals of the finalized proposals), not observed locally, so that all the nodes select the same proposer.
+type ProposerScoresBackend interface {
+	// ProposerScores returns the performance scores of the validators for the current sequence
+	ProposerScores() map[NodeID]uint64
+}
+
 // Logger represents logger behavior
 type Logger interface {
 	Printf(format string, args ...interface{})
@@ -18,6 +48,10 @@ type Logger interface {
 type Transport interface {
 	// Gossip broadcast the message to the network
 	Gossip(msg *MessageReq) error
+
+	// Send delivers the message only to the given node (i.e. sync requests and responses).
+	// The consensus votes are always gossiped.
+	Send(to NodeID, msg *MessageReq) error
 }
 
 // SignKey represents the behavior of the signing key
@@ -61,3 +95,13 @@ type Backend interface {
 	// ValidateCommit is used to validate that a given commit is valid
 	ValidateCommit(from NodeID, seal []byte) error
 }
+
+// ProposalHandler represents the application callbacks for a specific ProposalType.
+// It allows the same consensus instance to agree on payloads other than blocks (e.g. epoch changes).
+type ProposalHandler interface {
+	// Validate validates a raw proposal of the handled type (used if non-proposer)
+	Validate(*Proposal) error
+
+	// Insert inserts the sealed proposal of the handled type
+	Insert(p *SealedProposal) error
+}
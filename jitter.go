@@ -0,0 +1,30 @@
+package pbft
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand"
+	"time"
+)
+
+// newRandSource returns a random source seeded from crypto/rand, the default RandSource of the config
+func newRandSource() *rand.Rand {
+	var seed [8]byte
+	if _, err := crand.Read(seed[:]); err != nil {
+		seed = [8]byte{}
+		binary.BigEndian.PutUint64(seed[:], uint64(time.Now().UnixNano()))
+	}
+	return rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(seed[:]))))
+}
+
+// jitter extends the delay by a random amount of up to TimeoutJitter times the delay, drawn from the RandSource
+func (c *Config) jitter(delay time.Duration) time.Duration {
+	if c.TimeoutJitter <= 0 || delay <= 0 {
+		return delay
+	}
+	maxJitter := int64(float64(delay) * c.TimeoutJitter)
+	if maxJitter <= 0 {
+		return delay
+	}
+	return delay + time.Duration(c.RandSource.Int63n(maxJitter+1))
+}
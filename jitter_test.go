@@ -0,0 +1,31 @@
+package pbft
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_TimeoutJitter(t *testing.T) {
+	jittered := func(seed int64) []time.Duration {
+		config := DefaultConfig()
+		config.ApplyOps(WithRandSource(rand.New(rand.NewSource(seed))), WithTimeoutJitter(0.5))
+
+		delays := make([]time.Duration, 10)
+		for i := range delays {
+			delays[i] = config.jitter(time.Second)
+			assert.GreaterOrEqual(t, delays[i], time.Second)
+			assert.LessOrEqual(t, delays[i], 1500*time.Millisecond)
+		}
+		return delays
+	}
+
+	// the same seed draws the same jitter
+	assert.Equal(t, jittered(1), jittered(1))
+	assert.NotEqual(t, jittered(1), jittered(2))
+
+	// no jitter by default
+	assert.Equal(t, time.Second, DefaultConfig().jitter(time.Second))
+}
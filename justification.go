@@ -0,0 +1,71 @@
+package pbft
+
+import (
+	"bytes"
+	"errors"
+)
+
+var errMalformedJustification = errors.New("justification without proposal hash")
+
+// Justification is the proposal a node prepared (and locked) in a previous round.
+// It is carried by round change messages so that the next round adopts the prepared proposal.
+type Justification struct {
+	// Round is the round in which the proposal was prepared
+	Round uint64 `json:"round"`
+
+	// Proposal is the prepared proposal
+	Proposal *Proposal `json:"proposal"`
+
+	// Certificate proves that the proposal was prepared (see WithPreparedCertificates)
+	Certificate *PreparedCertificate `json:"certificate,omitempty"`
+}
+
+// Copy makes a copy of the Justification
+func (j *Justification) Copy() *Justification {
+	jj := &Justification{Round: j.Round}
+	if j.Proposal != nil {
+		jj.Proposal = j.Proposal.Copy()
+	}
+	if j.Certificate != nil {
+		jj.Certificate = j.Certificate.Copy()
+	}
+	return jj
+}
+
+// Equal compares whether two justifications refer to the same prepared proposal in the same round
+func (j *Justification) Equal(other *Justification) bool {
+	if j == nil || other == nil {
+		return j == other
+	}
+	if j.Proposal == nil || other.Proposal == nil {
+		return j.Round == other.Round && j.Proposal == other.Proposal
+	}
+	return j.Round == other.Round && j.Proposal.Equal(other.Proposal)
+}
+
+// SelectJustifiedProposal selects the proposal the new round has to adopt out of the given round change votes.
+// It picks the proposal prepared in the highest round, ties are broken by the lowest proposal hash,
+// so all the nodes calling it with the same votes agree regardless of the votes order.
+// It returns nil if none of the votes carries a justification, meaning that a fresh proposal can be built.
+func SelectJustifiedProposal(votes []*MessageReq) (*Proposal, error) {
+	var selected *Justification
+	for _, vote := range votes {
+		if vote.Type != MessageReq_RoundChange || vote.Justification == nil {
+			continue
+		}
+		justification := vote.Justification
+		if justification.Proposal == nil || len(justification.Proposal.Hash) == 0 {
+			return nil, errMalformedJustification
+		}
+		if selected == nil ||
+			justification.Round > selected.Round ||
+			(justification.Round == selected.Round && bytes.Compare(justification.Proposal.Hash, selected.Proposal.Hash) < 0) {
+			selected = justification
+		}
+	}
+
+	if selected == nil {
+		return nil, nil
+	}
+	return selected.Proposal.Copy(), nil
+}
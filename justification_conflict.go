@@ -0,0 +1,130 @@
+package pbft
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrConflictingJustification is returned when a node sends two round change messages for the same round
+// with different justifications
+var ErrConflictingJustification = errors.New("conflicting round change justifications")
+
+// ConflictingJustificationPolicy is the handling of a node sending two round change messages for the same round
+// with different justifications
+type ConflictingJustificationPolicy uint8
+
+const (
+	// ConflictingJustification_KeepFirst counts the first round change message and ignores the conflicting ones
+	ConflictingJustification_KeepFirst ConflictingJustificationPolicy = iota
+
+	// ConflictingJustification_DropBoth counts none of the round change messages of the node for the round,
+	// so that the justification selection does not depend on the message the node chose to send first
+	ConflictingJustification_DropBoth
+)
+
+func (c ConflictingJustificationPolicy) String() string {
+	switch c {
+	case ConflictingJustification_KeepFirst:
+		return "KeepFirst"
+	case ConflictingJustification_DropBoth:
+		return "DropBoth"
+	default:
+		return fmt.Sprintf("ConflictingJustificationPolicy(%d)", uint8(c))
+	}
+}
+
+// ConflictingJustificationProof is the evidence of a node sending two round change messages for the same view
+// with different justifications
+type ConflictingJustificationProof struct {
+	// Sender is the node that sent both round change messages
+	Sender NodeID
+
+	// First is the first round change message received from the sender
+	First *MessageReq
+
+	// Second is the conflicting round change message received from the sender
+	Second *MessageReq
+}
+
+// justificationConflicts keeps the evidences of conflicting round change justifications
+type justificationConflicts struct {
+	lock   sync.Mutex
+	proofs []*ConflictingJustificationProof
+}
+
+func (j *justificationConflicts) add(proof *ConflictingJustificationProof) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.proofs = append(j.proofs, proof)
+}
+
+func (j *justificationConflicts) getProofs() []*ConflictingJustificationProof {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	return append([]*ConflictingJustificationProof{}, j.proofs...)
+}
+
+// ConflictingJustifications returns the evidences of nodes that sent two round change messages for the same view
+// with different justifications
+func (p *Pbft) ConflictingJustifications() []*ConflictingJustificationProof {
+	return p.justificationConflicts.getProofs()
+}
+
+// checkRoundChangeConflict returns the round change message already received from the sender for the same round,
+// if its justification differs. A conflicting sender is excluded from the round: with dropBoth its first message
+// is removed too. It returns excluded if the sender already sent conflicting messages for the round.
+func (s *state) checkRoundChangeConflict(msg *MessageReq, dropBoth bool) (first *MessageReq, excluded bool) {
+	if msg.View == nil || !s.validators.Includes(msg.From) {
+		return nil, false
+	}
+
+	s.msgLock.Lock()
+	defer s.msgLock.Unlock()
+
+	round := msg.View.Round
+	if _, ok := s.conflictingRoundChanges[round][msg.From]; ok {
+		return nil, true
+	}
+	msgs, ok := s.roundMessages[round]
+	if !ok {
+		return nil, false
+	}
+	first, ok = msgs.messageMap[msg.From]
+	if !ok || first.Justification.Equal(msg.Justification) {
+		return nil, false
+	}
+
+	senders, ok := s.conflictingRoundChanges[round]
+	if !ok {
+		senders = map[NodeID]struct{}{}
+		s.conflictingRoundChanges[round] = senders
+	}
+	senders[msg.From] = struct{}{}
+	if dropBoth {
+		msgs.removeMessage(msg.From, s.validators.VotingPower()[msg.From])
+	}
+	return first, false
+}
+
+// checkConflictingJustification rejects a round change message whose justification conflicts with the one of
+// a message of the same sender and round, recording the evidence (see WithConflictingJustificationPolicy).
+// It returns the evidence recorded for the message, if any.
+func (p *Pbft) checkConflictingJustification(msg *MessageReq) (*ConflictingJustificationProof, error) {
+	dropBoth := p.config.ConflictingJustificationPolicy == ConflictingJustification_DropBoth
+	first, excluded := p.state.checkRoundChangeConflict(msg, dropBoth)
+	if excluded {
+		return nil, fmt.Errorf("%w: node %s in round %d", ErrConflictingJustification, msg.From, msg.View.Round)
+	}
+	if first == nil {
+		return nil, nil
+	}
+
+	proof := &ConflictingJustificationProof{Sender: msg.From, First: first.Copy(), Second: msg.Copy()}
+	p.justificationConflicts.add(proof)
+	p.logger.Printf("[WARN] conflicting round change justifications: sender=%s, view=%s", msg.From, msg.View)
+	p.checkSafetyThreshold()
+	return proof, fmt.Errorf("%w: node %s in round %d", ErrConflictingJustification, msg.From, msg.View.Round)
+}
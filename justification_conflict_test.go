@@ -0,0 +1,52 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundChange_ConflictingJustifications(t *testing.T) {
+	roundChange := func(from NodeID, hash []byte) *MessageReq {
+		msg := createMessage(from, MessageReq_RoundChange, ViewMsg(1, 1))
+		msg.Justification = &Justification{Round: 0, Proposal: &Proposal{Data: mockProposal, Hash: hash}}
+		return msg
+	}
+
+	run := func(policy ConflictingJustificationPolicy) *mockPbft {
+		m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+		WithConflictingJustificationPolicy(policy)(m.config)
+
+		require.NoError(t, m.addRoundChangeMsg(roundChange("B", digest)))
+		require.NoError(t, m.addRoundChangeMsg(roundChange("C", digest1)))
+
+		// the same justification sent again is a plain duplicate
+		assert.ErrorIs(t, m.addRoundChangeMsg(roundChange("B", digest)), ErrDuplicate)
+		assert.Empty(t, m.ConflictingJustifications())
+
+		// B justifies the round with another proposal
+		assert.ErrorIs(t, m.addRoundChangeMsg(roundChange("B", digest1)), ErrConflictingJustification)
+		proofs := m.ConflictingJustifications()
+		require.Len(t, proofs, 1)
+		assert.Equal(t, NodeID("B"), proofs[0].Sender)
+		assert.Equal(t, digest, proofs[0].First.Justification.Proposal.Hash)
+		assert.Equal(t, digest1, proofs[0].Second.Justification.Proposal.Hash)
+		assert.Equal(t, []NodeID{"B"}, m.FaultyValidators())
+		return m
+	}
+
+	// the first justification is kept
+	m := run(ConflictingJustification_KeepFirst)
+	assert.Equal(t, uint64(2), m.state.roundMessages[1].getAccumulatedVotingPower())
+	votes, err := m.SelectRoundChangeJustification(1, 2)
+	require.NoError(t, err)
+	assert.Len(t, votes, 2)
+
+	// neither justification is counted, nor any later message of B in the round
+	m = run(ConflictingJustification_DropBoth)
+	assert.Equal(t, uint64(1), m.state.roundMessages[1].getAccumulatedVotingPower())
+	assert.ErrorIs(t, m.addRoundChangeMsg(roundChange("B", digest)), ErrConflictingJustification)
+	assert.Len(t, m.ConflictingJustifications(), 1)
+	assert.Equal(t, uint64(1), m.state.roundMessages[1].getAccumulatedVotingPower())
+}
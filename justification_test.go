@@ -0,0 +1,81 @@
+package pbft
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func roundChangeWithJustification(from NodeID, round uint64, hash []byte) *MessageReq {
+	msg := createMessage(from, MessageReq_RoundChange, ViewMsg(1, 5))
+	if hash != nil {
+		msg.Justification = &Justification{
+			Round:    round,
+			Proposal: &Proposal{Data: hash, Hash: hash},
+		}
+	}
+	return msg
+}
+
+func TestSelectJustifiedProposal(t *testing.T) {
+	t.Run("No justification", func(t *testing.T) {
+		votes := []*MessageReq{
+			roundChangeWithJustification("A", 0, nil),
+			roundChangeWithJustification("B", 0, nil),
+		}
+		proposal, err := SelectJustifiedProposal(votes)
+		require.NoError(t, err)
+		assert.Nil(t, proposal)
+	})
+
+	t.Run("Highest prepared round wins", func(t *testing.T) {
+		votes := []*MessageReq{
+			roundChangeWithJustification("A", 1, []byte{0x1}),
+			roundChangeWithJustification("B", 3, []byte{0x3}),
+			roundChangeWithJustification("C", 0, nil),
+			roundChangeWithJustification("D", 2, []byte{0x2}),
+		}
+		proposal, err := SelectJustifiedProposal(votes)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0x3}, proposal.Hash)
+	})
+
+	t.Run("Ties are broken deterministically", func(t *testing.T) {
+		votes := []*MessageReq{
+			roundChangeWithJustification("A", 2, []byte{0x9}),
+			roundChangeWithJustification("B", 2, []byte{0x4}),
+			roundChangeWithJustification("C", 2, []byte{0x7}),
+			roundChangeWithJustification("D", 1, []byte{0x1}),
+		}
+		for i := 0; i < 10; i++ {
+			rand.Shuffle(len(votes), func(i, j int) { votes[i], votes[j] = votes[j], votes[i] })
+			proposal, err := SelectJustifiedProposal(votes)
+			require.NoError(t, err)
+			assert.Equal(t, []byte{0x4}, proposal.Hash)
+		}
+	})
+
+	t.Run("Malformed justification", func(t *testing.T) {
+		vote := roundChangeWithJustification("A", 1, []byte{0x1})
+		vote.Justification.Proposal.Hash = nil
+		_, err := SelectJustifiedProposal([]*MessageReq{vote})
+		assert.ErrorIs(t, err, errMalformedJustification)
+	})
+}
+
+func TestGossip_RoundChangeCarriesLockedProposal(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.setRound(2)
+	m.state.lock()
+	m.setRound(3)
+
+	m.sendRoundChange()
+
+	require.Len(t, m.respMsg, 1)
+	justification := m.respMsg[0].Justification
+	require.NotNil(t, justification)
+	assert.Equal(t, uint64(2), justification.Round)
+	assert.Equal(t, digest, justification.Proposal.Hash)
+}
@@ -0,0 +1,63 @@
+package pbft
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SequenceLeakHandler is invoked with the error describing the messages of another sequence
+// found in the prepared or committed messages of the current sequence
+type SequenceLeakHandler func(err error)
+
+// ErrSequenceLeak is reported when a message of another sequence is counted toward the quorum of the current one
+var ErrSequenceLeak = errors.New("message of another sequence counted toward the quorum")
+
+// sequenceLeaks returns the prepared and committed messages whose sequence differs from the current one,
+// sorted by type and sender
+func (s *state) sequenceLeaks() []*MessageReq {
+	sequence := s.GetSequence()
+
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	leaks := []*MessageReq{}
+	for _, msgs := range []*messages{s.prepared, s.committed} {
+		for _, msg := range msgs.messageMap {
+			if msg.View == nil || msg.View.Sequence != sequence {
+				leaks = append(leaks, msg)
+			}
+		}
+	}
+	sort.Slice(leaks, func(i, j int) bool {
+		if leaks[i].Type != leaks[j].Type {
+			return leaks[i].Type < leaks[j].Type
+		}
+		return leaks[i].From < leaks[j].From
+	})
+	return leaks
+}
+
+// checkSequenceLeaks verifies, when the detection is enabled, that every prepared and committed message
+// belongs to the current sequence. The leaks are reported to the configured handler, or panic without one.
+func (p *Pbft) checkSequenceLeaks() {
+	if !p.config.SequenceLeakDetection {
+		return
+	}
+	leaks := p.state.sequenceLeaks()
+	if len(leaks) == 0 {
+		return
+	}
+
+	descriptions := make([]string, 0, len(leaks))
+	for _, msg := range leaks {
+		descriptions = append(descriptions, fmt.Sprintf("%s from %s (view %v)", msg.Type, msg.From, msg.View))
+	}
+	err := fmt.Errorf("%w: sequence %d: %s", ErrSequenceLeak, p.state.GetSequence(), strings.Join(descriptions, ", "))
+
+	if p.config.SequenceLeakHandler == nil {
+		panic(fmt.Errorf("BUG: %w", err))
+	}
+	p.config.SequenceLeakHandler(err)
+}
@@ -0,0 +1,71 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLeakingPbft returns a node in the validate state of the sequence 2, with a prepare of the sequence 1
+// injected in the prepared messages, bypassing the view check of the state
+func newLeakingPbft(t *testing.T) *mockPbft {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.setSequence(2)
+	m.state.proposer = "A"
+	m.state.proposal = &Proposal{Data: mockProposal, Hash: digest}
+	m.setState(ValidateState)
+
+	stale := createMessage("C", MessageReq_Prepare, ViewMsg(1, 0))
+	stale.Hash = digest
+	m.state.prepared.addMessage(stale, 1)
+
+	prepare := createMessage("B", MessageReq_Prepare, ViewMsg(2, 0))
+	prepare.Hash = digest
+	m.emitMsg(prepare)
+	return m
+}
+
+func TestPbft_SequenceLeakDetection(t *testing.T) {
+	m := newLeakingPbft(t)
+	var leaks []error
+	WithSequenceLeakDetection(func(err error) { leaks = append(leaks, err) })(m.config)
+
+	m.runCycle(context.Background())
+
+	require.NotEmpty(t, leaks)
+	assert.ErrorIs(t, leaks[0], ErrSequenceLeak)
+	assert.Contains(t, leaks[0].Error(), "Prepare from C")
+	assert.NotContains(t, leaks[0].Error(), "from B")
+}
+
+func TestPbft_SequenceLeakDetection_Panics(t *testing.T) {
+	m := newLeakingPbft(t)
+	WithSequenceLeakDetection(nil)(m.config)
+
+	assert.Panics(t, func() { m.runCycle(context.Background()) })
+}
+
+func TestPbft_SequenceLeakDetection_Disabled(t *testing.T) {
+	m := newLeakingPbft(t)
+
+	assert.NotPanics(t, func() { m.runCycle(context.Background()) })
+}
+
+func TestState_SequenceLeaks(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+	s, err := initState(pool)
+	require.NoError(t, err)
+	s.setView(ViewMsg(1, 0))
+	sequence := s.GetSequence()
+
+	require.NoError(t, s.addCommitMsg(createMessage("A", MessageReq_Commit, ViewMsg(sequence, 0))))
+	assert.Empty(t, s.sequenceLeaks())
+
+	s.committed.addMessage(createMessage("B", MessageReq_Commit, ViewMsg(sequence+1, 0)), 1)
+	leaks := s.sequenceLeaks()
+	require.Len(t, leaks, 1)
+	assert.Equal(t, NodeID("B"), leaks[0].From)
+}
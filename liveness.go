@@ -0,0 +1,67 @@
+package pbft
+
+import (
+	"sort"
+	"sync"
+)
+
+// livenessTracker tracks the last sequence in which each validator sent a message,
+// so that the validators silent for longer than the inactivity window are considered offline
+type livenessTracker struct {
+	lock sync.Mutex
+
+	// lastSeen is the highest sequence of the messages received from each validator
+	lastSeen map[NodeID]uint64
+
+	// window is the number of sequences without messages after which a validator is inactive
+	window uint64
+}
+
+// newLivenessTracker creates a new liveness tracker with the given inactivity window
+func newLivenessTracker(window uint64) *livenessTracker {
+	return &livenessTracker{
+		lastSeen: map[NodeID]uint64{},
+		window:   window,
+	}
+}
+
+// observe records a message of the sequence from the node, which reactivates it if it was inactive
+func (l *livenessTracker) observe(from NodeID, sequence uint64) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if sequence > l.lastSeen[from] {
+		l.lastSeen[from] = sequence
+	}
+}
+
+// inactive returns the validators that did not send any message in the last window sequences before the given one.
+// The validators tracked for the first time are given a full window from the given sequence.
+func (l *livenessTracker) inactive(validators ValidatorSet, sequence uint64) map[NodeID]struct{} {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	inactive := map[NodeID]struct{}{}
+	for id := range validators.VotingPower() {
+		lastSeen, ok := l.lastSeen[id]
+		if !ok {
+			l.lastSeen[id] = sequence
+			continue
+		}
+		if sequence > lastSeen+l.window {
+			inactive[id] = struct{}{}
+		}
+	}
+	return inactive
+}
+
+// InactiveValidators returns the validators considered offline in the current sequence, sorted by id.
+// It is always empty unless the inactivity tracking is enabled (see WithInactivityTracking).
+func (p *Pbft) InactiveValidators() []NodeID {
+	ids := make([]NodeID, 0, len(p.inactive))
+	for id := range p.inactive {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,52 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLivenessTracker_Inactive(t *testing.T) {
+	validators := NewValStringStub([]NodeID{"A", "B", "C"}, CreateEqualVotingPowerMap([]NodeID{"A", "B", "C"}))
+	l := newLivenessTracker(2)
+
+	// validators tracked for the first time are given a full window
+	assert.Empty(t, l.inactive(validators, 1))
+
+	l.observe("A", 3)
+	l.observe("B", 2)
+	assert.Empty(t, l.inactive(validators, 3))
+	assert.Equal(t, map[NodeID]struct{}{"C": {}}, l.inactive(validators, 4))
+	assert.Equal(t, map[NodeID]struct{}{"B": {}, "C": {}}, l.inactive(validators, 5))
+
+	// older messages do not move the last seen sequence back
+	l.observe("A", 1)
+	assert.Equal(t, map[NodeID]struct{}{"A": {}, "B": {}, "C": {}}, l.inactive(validators, 6))
+
+	// a message reactivates the validator
+	l.observe("C", 6)
+	assert.Equal(t, map[NodeID]struct{}{"A": {}, "B": {}}, l.inactive(validators, 6))
+}
+
+func TestPbft_InactiveValidators(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D", "E", "F", "G"}, nil, "A")
+	m.liveness = newLivenessTracker(2)
+	require.NoError(t, m.SetBackend(m.backend))
+	assert.Equal(t, uint64(5), m.state.getQuorumSize())
+
+	for _, from := range []NodeID{"B", "C", "D", "E"} {
+		m.PushMessageInternal(createMessage(from, MessageReq_RoundChange, ViewMsg(4, 0)))
+	}
+	m.sequence = 4
+	require.NoError(t, m.SetBackend(m.backend))
+	assert.Equal(t, []NodeID{"F", "G"}, m.InactiveValidators())
+	// the offline validators are only reported, the quorum is still the one of the whole validator set
+	assert.Equal(t, uint64(5), m.state.getQuorumSize())
+
+	// the first message from F reactivates it
+	m.PushMessageInternal(createMessage("F", MessageReq_RoundChange, ViewMsg(4, 0)))
+	m.sequence = 5
+	require.NoError(t, m.SetBackend(m.backend))
+	assert.Equal(t, []NodeID{"G"}, m.InactiveValidators())
+}
@@ -0,0 +1,40 @@
+package pbft
+
+import "fmt"
+
+// LockConflictPolicy is the handling of a pre-prepare for a proposal different from the one the node is locked on.
+// In any case, the node never prepares on the conflicting proposal.
+type LockConflictPolicy uint8
+
+const (
+	// LockConflict_Ignore drops the conflicting pre-prepare, the node stays locked and keeps waiting
+	// for the locked proposal until the round times out
+	LockConflict_Ignore LockConflictPolicy = iota
+
+	// LockConflict_RoundChange treats the conflicting pre-prepare as the evidence of a faulty proposer
+	// and starts a round change right away
+	LockConflict_RoundChange
+)
+
+func (l LockConflictPolicy) String() string {
+	switch l {
+	case LockConflict_Ignore:
+		return "Ignore"
+	case LockConflict_RoundChange:
+		return "RoundChange"
+	default:
+		return fmt.Sprintf("LockConflictPolicy(%d)", uint8(l))
+	}
+}
+
+// handleLockConflict applies the lock conflict policy to a pre-prepare whose proposal differs from the locked one.
+// It returns true if the node leaves the accept state.
+func (p *Pbft) handleLockConflict(msg *MessageReq) bool {
+	if p.config.LockConflictPolicy == LockConflict_RoundChange {
+		p.handleStateErr(errIncorrectLockedProposal, RoundChange_LockConflict)
+		return true
+	}
+	p.logDrop(DropReasonLockConflict, "[WARN] pre-prepare conflicting with the locked proposal dropped: proposer=%s, hash=%x", msg.From, msg.Hash)
+	p.dropMessage(msg, DropReasonLockConflict)
+	return false
+}
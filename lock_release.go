@@ -0,0 +1,52 @@
+package pbft
+
+import "bytes"
+
+// roundChangeJustification returns the voting power of the round change messages of the round sent by the other nodes,
+// the received ones along with the queued ones, and whether any of them justifies the proposal with the given hash
+func (s *state) roundChangeJustification(round uint64, hash []byte, queued []*MessageReq) (votingPower uint64, justified bool) {
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	senders := map[NodeID]*MessageReq{}
+	if msgs, ok := s.roundMessages[round]; ok {
+		for from, msg := range msgs.messageMap {
+			senders[from] = msg
+		}
+	}
+	for _, msg := range queued {
+		if _, ok := senders[msg.From]; !ok {
+			senders[msg.From] = msg
+		}
+	}
+
+	power := s.validators.VotingPower()
+	for from, msg := range senders {
+		if from == s.selfID {
+			continue
+		}
+		votingPower += power[from]
+		if j := msg.Justification; j != nil && j.Proposal != nil && bytes.Equal(j.Proposal.Hash, hash) {
+			justified = true
+		}
+	}
+	return votingPower, justified
+}
+
+// releaseStaleLock unlocks the node, when MaxLockedRounds is set, once it stayed locked for MaxLockedRounds rounds and
+// the round change messages of the round prove that the locked proposal can not reach a commit quorum: the messages
+// of the other nodes reach the commit quorum size and none of them justifies the locked proposal.
+// Had the proposal reached a commit quorum, those messages would include an honest node locked on it, which sends
+// the justification of its locked proposal with the round change.
+func (p *Pbft) releaseStaleLock(round uint64) {
+	if p.config.MaxLockedRounds == 0 || !p.state.IsLocked() || round < p.state.lockedRound+p.config.MaxLockedRounds {
+		return
+	}
+	queued := p.msgQueue.roundChangesOf(&View{Sequence: p.state.view.Sequence, Round: round})
+	votingPower, justified := p.state.roundChangeJustification(round, p.state.proposal.Hash, queued)
+	if justified || votingPower < p.state.getCommitQuorumSize() {
+		return
+	}
+	p.logger.Printf("[INFO] released the lock on a proposal abandoned by the network: locked round=%d, round=%d", p.state.lockedRound, round)
+	p.state.unlock()
+}
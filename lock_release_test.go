@@ -0,0 +1,73 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransition_RoundChangeState_ReleaseStaleLock(t *testing.T) {
+	run := func(maxLockedRounds uint64, msgs ...*MessageReq) *mockPbft {
+		m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+		WithMaxLockedRounds(maxLockedRounds)(m.config)
+		m.setProposal(&Proposal{Data: mockProposal, Hash: digest})
+		m.state.lock()
+		m.setState(RoundChangeState)
+
+		for _, msg := range msgs {
+			m.emitMsg(msg)
+		}
+		m.runCycle(context.Background())
+		assert.Equal(t, AcceptState, m.getState())
+		assert.Equal(t, uint64(2), m.state.GetCurrentRound())
+		return m
+	}
+	roundChange := func(from NodeID, justification *Justification) *MessageReq {
+		msg := createMessage(from, MessageReq_RoundChange, ViewMsg(1, 2))
+		msg.Justification = justification
+		return msg
+	}
+	justified := &Justification{Round: 0, Proposal: &Proposal{Data: mockProposal, Hash: digest}}
+
+	// locked for 2 rounds and a quorum of the other nodes does not justify the proposal
+	m := run(2, roundChange("B", nil), roundChange("C", nil), roundChange("D", nil))
+	assert.False(t, m.IsLocked())
+	assert.Nil(t, m.state.proposal)
+
+	// not locked for long enough
+	m = run(3, roundChange("B", nil), roundChange("C", nil), roundChange("D", nil))
+	assert.True(t, m.IsLocked())
+
+	// a node justifies the locked proposal, it may have reached a commit quorum
+	m = run(2, roundChange("B", nil), roundChange("C", justified), roundChange("D", nil))
+	assert.True(t, m.IsLocked())
+
+	// the round change quorum is reached without the commit quorum size
+	m = run(2, roundChange("C", nil), roundChange("D", nil))
+	assert.True(t, m.IsLocked())
+
+	// disabled
+	m = run(0, roundChange("B", nil), roundChange("C", nil), roundChange("D", nil))
+	assert.True(t, m.IsLocked())
+}
+
+// Test that the round change messages received during the round release the stale lock once the round times out.
+func TestTransition_RoundChangeState_ReleaseStaleLock_Timeout(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.Close()
+	WithMaxLockedRounds(2)(m.config)
+	m.setProposal(&Proposal{Data: mockProposal, Hash: digest})
+	m.state.lock()
+	m.setRound(2)
+
+	messages := newMessages()
+	for _, from := range []NodeID{"B", "C", "D"} {
+		messages.addMessage(createMessage(from, MessageReq_RoundChange, ViewMsg(1, 2)), 1)
+	}
+	m.state.roundMessages[2] = messages
+
+	m.setState(RoundChangeState)
+	m.runCycle(context.Background())
+	assert.False(t, m.IsLocked())
+}
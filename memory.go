@@ -0,0 +1,183 @@
+package pbft
+
+import "sort"
+
+// msgOverhead is the approximate size of a message besides its variable length fields
+// (the struct itself, the view and the bookkeeping of the buffer holding it)
+const msgOverhead = 256
+
+// estimateMsgSize returns the approximate memory used by the message
+func estimateMsgSize(msg *MessageReq) int {
+	size := msgOverhead + len(msg.From) + len(msg.Seal) + len(msg.Hash) + len(msg.Proposal)
+	if j := msg.Justification; j != nil && j.Proposal != nil {
+		size += len(j.Proposal.Data) + len(j.Proposal.Hash)
+	}
+	return size
+}
+
+func estimateMsgsSize(msgs []*MessageReq) int {
+	size := 0
+	for _, msg := range msgs {
+		size += estimateMsgSize(msg)
+	}
+	return size
+}
+
+func (m *messages) estimatedSize() int {
+	size := 0
+	for _, msg := range m.messageMap {
+		size += estimateMsgSize(msg)
+	}
+	return size
+}
+
+// estimatedSize returns the approximate memory used by the prepared, committed and round change messages
+func (s *state) estimatedSize() int {
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	size := s.prepared.estimatedSize() + s.committed.estimatedSize()
+	for _, msgs := range s.roundMessages {
+		size += msgs.estimatedSize()
+	}
+	return size
+}
+
+// shedRoundMsgs removes the round change messages of the rounds below the given one, which are not needed
+// to change round anymore. It returns the approximate memory released.
+func (s *state) shedRoundMsgs(below uint64) int {
+	s.msgLock.Lock()
+	defer s.msgLock.Unlock()
+
+	released := 0
+	for round, msgs := range s.roundMessages {
+		if round < below {
+			released += msgs.estimatedSize()
+			delete(s.roundMessages, round)
+		}
+	}
+	return released
+}
+
+func (m *msgQueue) estimatedSize() int {
+	m.queueLock.Lock()
+	defer m.queueLock.Unlock()
+
+	return estimateMsgsSize(m.roundChangeStateQueue) +
+		estimateMsgsSize(m.acceptStateQueue) +
+		estimateMsgsSize(m.validateStateQueue)
+}
+
+func (f *futureMessages) estimatedSize() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return estimateMsgsSize(f.msgs)
+}
+
+// shed removes buffered messages, the ones of the furthest sequences first, until the given amount of memory
+// is released or the buffer is empty. It returns the approximate memory released and the number of removed messages.
+func (f *futureMessages) shed(size int) (released, count int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	// the oldest message of the furthest sequence comes first
+	order := make([]int, len(f.msgs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return f.msgs[order[i]].View.Sequence > f.msgs[order[j]].View.Sequence
+	})
+
+	removed := map[int]struct{}{}
+	for _, i := range order {
+		if released >= size {
+			break
+		}
+		released += estimateMsgSize(f.msgs[i])
+		f.decrement(f.msgs[i].View.Sequence)
+		f.dropped(f.msgs[i], FutureDropReasonMemoryBudget)
+		removed[i] = struct{}{}
+	}
+
+	remaining := make([]*MessageReq, 0, len(f.msgs)-len(removed))
+	for i, msg := range f.msgs {
+		if _, ok := removed[i]; !ok {
+			remaining = append(remaining, msg)
+		}
+	}
+	f.msgs = remaining
+	return released, len(removed)
+}
+
+func (s *syncMessages) estimatedSize() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return estimateMsgsSize(s.msgs)
+}
+
+// shed removes the oldest buffered messages until the given amount of memory is released or the buffer is empty.
+// It returns the approximate memory released and the number of removed messages.
+func (s *syncMessages) shed(size int) (released, count int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for count < len(s.msgs) && released < size {
+		released += estimateMsgSize(s.msgs[count])
+		s.msgs[count] = nil
+		count++
+	}
+	s.msgs = s.msgs[count:]
+	return released, count
+}
+
+func (q *inboundQueue) estimatedSize() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	size := 0
+	for i := 0; i < q.size; i++ {
+		size += estimateMsgSize(q.at(i))
+	}
+	return size
+}
+
+// EstimatedMemoryUsage returns the approximate memory, in bytes, used by the messages held by the node:
+// the prepared, committed and round change messages of the state, the queued messages and the buffered
+// messages of future sequences and of the sync.
+func (p *Pbft) EstimatedMemoryUsage() int {
+	size := p.state.estimatedSize() + p.msgQueue.estimatedSize() + p.futureMsgs.estimatedSize() + p.syncMsgs.estimatedSize()
+	if p.inbound != nil {
+		size += p.inbound.estimatedSize()
+	}
+	return size
+}
+
+// enforceMemoryBudget sheds buffered messages when the estimated memory usage exceeds the MemoryBudget.
+// The least important data goes first: the messages of future sequences, then the ones buffered during
+// the sync and finally the round change messages of the past rounds. The prepared, committed and round change
+// messages of the current view are never shed.
+func (p *Pbft) enforceMemoryBudget() {
+	budget := p.config.MemoryBudget
+	if budget <= 0 {
+		return
+	}
+	usage := p.EstimatedMemoryUsage()
+	if usage <= budget {
+		return
+	}
+
+	excess := usage - budget
+	released, count := p.futureMsgs.shed(excess)
+	if released < excess {
+		r, c := p.syncMsgs.shed(excess - released)
+		released, count = released+r, count+c
+	}
+	if released < excess && p.state.view != nil {
+		released += p.state.shedRoundMsgs(p.state.GetCurrentRound())
+	}
+
+	p.logger.Printf("[WARN] memory budget exceeded: usage=%d, budget=%d, released=%d bytes (%d buffered messages)", usage, budget, released, count)
+}
@@ -0,0 +1,85 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPbft_EstimatedMemoryUsage(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	assert.Zero(t, m.EstimatedMemoryUsage())
+
+	prepare := createMessage("B", MessageReq_Prepare, ViewMsg(1, 0))
+	prepare.Hash = digest
+	m.emitMsg(prepare)
+	future := createMessage("C", MessageReq_Prepare, ViewMsg(3, 0))
+	future.Hash = digest
+	m.emitMsg(future)
+	commit := createMessage("D", MessageReq_Commit, ViewMsg(1, 0))
+	require.NoError(t, m.state.addCommitMsg(commit))
+
+	expected := estimateMsgSize(prepare) + estimateMsgSize(future) + estimateMsgSize(commit)
+	assert.Equal(t, expected, m.EstimatedMemoryUsage())
+}
+
+func TestPbft_MemoryBudget_Shedding(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.state.SetCurrentRound(2)
+
+	// live messages of the current view
+	for _, from := range []NodeID{"B", "C"} {
+		require.NoError(t, m.state.addCommitMsg(createMessage(from, MessageReq_Commit, ViewMsg(1, 2))))
+		require.NoError(t, m.state.addRoundChangeMsg(createMessage(from, MessageReq_RoundChange, ViewMsg(1, 2))))
+	}
+	live := m.EstimatedMemoryUsage()
+
+	// round change messages of a past round
+	require.NoError(t, m.state.addRoundChangeMsg(createMessage("B", MessageReq_RoundChange, ViewMsg(1, 1))))
+
+	// messages of future sequences, the furthest ones are shed first
+	for _, seq := range []uint64{2, 5, 3} {
+		msg := createMessage("D", MessageReq_Prepare, ViewMsg(seq, 0))
+		msg.Hash = digest
+		m.emitMsg(msg)
+	}
+	require.Equal(t, 3, m.futureMsgs.len())
+	msgSize := estimateMsgSize(m.futureMsgs.msgs[0])
+
+	t.Run("future messages are shed first", func(t *testing.T) {
+		m.config.MemoryBudget = m.EstimatedMemoryUsage() - msgSize
+		m.enforceMemoryBudget()
+
+		require.Equal(t, 2, m.futureMsgs.len())
+		for _, msg := range m.futureMsgs.msgs {
+			assert.NotEqual(t, uint64(5), msg.View.Sequence)
+		}
+		assert.LessOrEqual(t, m.EstimatedMemoryUsage(), m.config.MemoryBudget)
+	})
+
+	t.Run("live messages are never shed", func(t *testing.T) {
+		m.config.MemoryBudget = 1
+		m.enforceMemoryBudget()
+
+		assert.Zero(t, m.futureMsgs.len())
+		assert.NotContains(t, m.state.roundMessages, uint64(1))
+		assert.Equal(t, 2, m.state.numCommitted())
+		assert.Equal(t, 2, m.state.roundMessages[2].length())
+		assert.Equal(t, live, m.EstimatedMemoryUsage())
+	})
+}
+
+func TestSyncMessages_Shed(t *testing.T) {
+	s := newSyncMessages(10)
+	for _, from := range []NodeID{"A", "B", "C"} {
+		s.add(createMessage(from, MessageReq_RoundChange, ViewMsg(1, 0)))
+	}
+	size := estimateMsgSize(s.msgs[0])
+
+	released, count := s.shed(size + 1)
+	assert.Equal(t, 2*size, released)
+	assert.Equal(t, 2, count)
+	require.Len(t, s.msgs, 1)
+	assert.Equal(t, NodeID("C"), s.msgs[0].From)
+}
@@ -3,6 +3,7 @@ package pbft
 import (
 	"bytes"
 	"fmt"
+	"time"
 )
 
 type MsgType int32
@@ -12,6 +13,20 @@ const (
 	MessageReq_Preprepare  MsgType = 1
 	MessageReq_Commit      MsgType = 2
 	MessageReq_Prepare     MsgType = 3
+
+	// MessageReq_ProposalRequest asks a peer for the proposal body of a Preprepare (unicast)
+	MessageReq_ProposalRequest MsgType = 4
+
+	// MessageReq_ProposalResponse carries the proposal body requested by a ProposalRequest (unicast)
+	MessageReq_ProposalResponse MsgType = 5
+
+	// MessageReq_PrepareCommit carries both the Prepare and the Commit of the sender, split apart on receipt
+	// (see WithCombinedVotes)
+	MessageReq_PrepareCommit MsgType = 6
+
+	// MessageReq_ViewSync advertises the current view and state of the sender to help the lagging nodes
+	// (see WithViewSync)
+	MessageReq_ViewSync MsgType = 7
 )
 
 func (m MsgType) String() string {
@@ -24,6 +39,14 @@ func (m MsgType) String() string {
 		return "Commit"
 	case MessageReq_Prepare:
 		return "Prepare"
+	case MessageReq_ProposalRequest:
+		return "ProposalRequest"
+	case MessageReq_ProposalResponse:
+		return "ProposalResponse"
+	case MessageReq_PrepareCommit:
+		return "PrepareCommit"
+	case MessageReq_ViewSync:
+		return "ViewSync"
 	default:
 		panic(fmt.Sprintf("BUG: Bad msgtype %d", m))
 	}
@@ -39,6 +62,9 @@ type MessageReq struct {
 	// seal is the committed seal for the proposal (only for commit messages)
 	Seal []byte `json:"seal"`
 
+	// prepareSeal is the prepare seal of the sender (only for prepare commit messages with the prepared certificates)
+	PrepareSeal []byte `json:"prepareSeal,omitempty"`
+
 	// view is the view assigned to the message
 	View *View `json:"view"`
 
@@ -47,6 +73,24 @@ type MessageReq struct {
 
 	// proposal is the arbitrary data proposal (only for preprepare messages)
 	Proposal []byte `json:"proposal"`
+
+	// proposalType is the type of the proposal (only for preprepare messages)
+	ProposalType ProposalType `json:"proposalType"`
+
+	// proposalTime is the creation time of the proposal (only for preprepare messages)
+	ProposalTime time.Time `json:"proposalTime"`
+
+	// proposalParentHash is the hash of the parent of the proposal (only for preprepare messages)
+	ProposalParentHash []byte `json:"proposalParentHash,omitempty"`
+
+	// proposalMetadata is the metadata of the proposal (only for preprepare messages)
+	ProposalMetadata map[string][]byte `json:"proposalMetadata,omitempty"`
+
+	// justification is the proposal the sender prepared in a previous round (only for round change messages)
+	Justification *Justification `json:"justification,omitempty"`
+
+	// nodeState is the state of the sender in its view (only for view sync messages)
+	NodeState State `json:"nodeState,omitempty"`
 }
 
 func (m MessageReq) String() string {
@@ -54,8 +98,8 @@ func (m MessageReq) String() string {
 }
 
 func (m *MessageReq) Validate() error {
-	// Hash field has to exist for state != RoundStateChange
-	if m.Type != MessageReq_RoundChange {
+	// Hash field has to exist for state != RoundStateChange (and the view sync messages)
+	if m.Type != MessageReq_RoundChange && m.Type != MessageReq_ViewSync {
 		if m.Hash == nil {
 			return fmt.Errorf("hash is empty for type %s", m.Type.String())
 		}
@@ -65,6 +109,21 @@ func (m *MessageReq) Validate() error {
 	return nil
 }
 
+// ValidateView performs the strict validation of the message view. It rejects messages without a view,
+// with a zero sequence (sequences start at 1) or with a round above maxRound.
+func (m *MessageReq) ValidateView(maxRound uint64) error {
+	if m.View == nil {
+		return fmt.Errorf("%w: view is missing", ErrWrongView)
+	}
+	if m.View.Sequence == 0 {
+		return fmt.Errorf("%w: sequence is zero", ErrWrongView)
+	}
+	if m.View.Round > maxRound {
+		return fmt.Errorf("%w: round %d is above the maximum round %d", ErrWrongView, m.View.Round, maxRound)
+	}
+	return nil
+}
+
 func (m *MessageReq) SetProposal(proposal []byte) {
 	m.Proposal = append([]byte{}, proposal...)
 }
@@ -84,6 +143,20 @@ func (m *MessageReq) Copy() *MessageReq {
 		mm.Seal = append([]byte{}, m.Seal...)
 	}
 
+	if m.PrepareSeal != nil {
+		mm.PrepareSeal = append([]byte{}, m.PrepareSeal...)
+	}
+
+	if m.ProposalParentHash != nil {
+		mm.ProposalParentHash = append([]byte{}, m.ProposalParentHash...)
+	}
+
+	mm.ProposalMetadata = copyMetadata(m.ProposalMetadata)
+
+	if m.Justification != nil {
+		mm.Justification = m.Justification.Copy()
+	}
+
 	return mm
 }
 
@@ -92,8 +165,15 @@ func (m *MessageReq) Equal(other *MessageReq) bool {
 	return other != nil &&
 		m.Type == other.Type && m.From == other.From &&
 		bytes.Equal(m.Proposal, other.Proposal) &&
+		m.ProposalType == other.ProposalType &&
+		m.ProposalTime.Equal(other.ProposalTime) &&
+		bytes.Equal(m.ProposalParentHash, other.ProposalParentHash) &&
+		equalMetadata(m.ProposalMetadata, other.ProposalMetadata) &&
 		bytes.Equal(m.Hash, other.Hash) &&
 		bytes.Equal(m.Seal, other.Seal) &&
+		bytes.Equal(m.PrepareSeal, other.PrepareSeal) &&
+		m.Justification.Equal(other.Justification) &&
+		m.NodeState == other.NodeState &&
 		m.View.Round == other.View.Round &&
 		m.View.Sequence == other.View.Sequence
 }
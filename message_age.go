@@ -0,0 +1,11 @@
+package pbft
+
+// exceedsMaxMessageAge returns whether the proposal carried by the message is older than MaxMessageAge,
+// according to the Clock. The messages without a proposal time are not checked, nor any message when
+// MaxMessageAge is zero.
+func (p *Pbft) exceedsMaxMessageAge(msg *MessageReq) bool {
+	if p.config.MaxMessageAge == 0 || msg.ProposalTime.IsZero() {
+		return false
+	}
+	return p.config.Clock.Now().Sub(msg.ProposalTime) > p.config.MaxMessageAge
+}
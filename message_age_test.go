@@ -0,0 +1,36 @@
+package pbft
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the messages with a proposal older than the max message age are dropped on ingestion.
+func TestPbft_PushMessage_MaxMessageAge(t *testing.T) {
+	now := time.Now()
+	metrics := &recordingMetrics{}
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+	WithClock(NewManualClock(now))(m.config)
+	WithMaxMessageAge(time.Minute)(m.config)
+	WithMetrics(metrics)(m.config)
+	m.state.view = ViewMsg(1, 0)
+
+	stale := createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0))
+	stale.ProposalTime = now.Add(-time.Minute - time.Millisecond)
+	m.emitMsg(stale)
+	assert.Equal(t, []string{"dropped Preprepare too_old"}, metrics.records)
+	assert.Empty(t, m.msgQueue.acceptStateQueue)
+
+	fresh := createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0))
+	fresh.ProposalTime = now.Add(-time.Minute)
+	m.emitMsg(fresh)
+	assert.Len(t, metrics.records, 1)
+	assert.Len(t, m.msgQueue.acceptStateQueue, 1)
+
+	// the messages without a proposal time are not checked
+	m.emitMsg(createMessage("C", MessageReq_Prepare, ViewMsg(1, 0)))
+	assert.Len(t, metrics.records, 1)
+	assert.Len(t, m.msgQueue.validateStateQueue, 1)
+}
@@ -0,0 +1,68 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageReq_ValidateView(t *testing.T) {
+	cases := []struct {
+		name  string
+		view  *View
+		valid bool
+	}{
+		{"nil view", nil, false},
+		{"zero sequence", ViewMsg(0, 0), false},
+		{"round above the maximum", ViewMsg(1, 11), false},
+		{"maximum round", ViewMsg(1, 10), true},
+		{"valid view", ViewMsg(5, 2), true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			msg := createMessage("A", MessageReq_Prepare, ViewMsg(1, 0))
+			msg.View = c.view
+
+			err := msg.ValidateView(10)
+			if c.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrWrongView)
+			}
+		})
+	}
+}
+
+func TestPbft_PushMessage_StrictValidation(t *testing.T) {
+	malformed := func() []*MessageReq {
+		nilView := createMessage("B", MessageReq_Prepare, ViewMsg(1, 0))
+		nilView.View = nil
+		return []*MessageReq{
+			nilView,
+			createMessage("B", MessageReq_Prepare, ViewMsg(0, 0)),
+			createMessage("B", MessageReq_Prepare, ViewMsg(1, defaultMaxRound+1)),
+		}
+	}
+
+	t.Run("Strict", func(t *testing.T) {
+		m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
+		WithStrictValidation(defaultMaxRound)(m.config)
+
+		for _, msg := range malformed() {
+			msg.Hash = digest
+			m.PushMessage(msg)
+		}
+		assert.Zero(t, m.msgQueue.validateStateQueue.Len())
+	})
+
+	t.Run("Lenient", func(t *testing.T) {
+		m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
+
+		// messages with a view are queued as before
+		for _, msg := range malformed()[1:] {
+			msg.Hash = digest
+			m.PushMessage(msg)
+		}
+		assert.Equal(t, 2, m.msgQueue.validateStateQueue.Len())
+	})
+}
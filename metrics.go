@@ -0,0 +1,136 @@
+package pbft
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Reasons of the dropped messages reported to the Metrics
+const (
+	// DropReasonStale is a message of a past view
+	DropReasonStale = "stale"
+
+	// DropReasonDuplicate is a message already received (see WithSequenceDedup)
+	DropReasonDuplicate = "duplicate"
+
+	// DropReasonMalformedSeal is a commit message whose seal does not have the length of the configured SealFormat
+	DropReasonMalformedSeal = "malformed_seal"
+
+	// DropReasonRoundTooFar is a round change message too far ahead of the current round (see WithMaxRoundJump)
+	DropReasonRoundTooFar = "round_too_far"
+
+	// DropReasonWrongProposer is a Preprepare message not sent by the proposer of the round it claims
+	DropReasonWrongProposer = "wrong_proposer"
+
+	// DropReasonInboundQueueFull is a message dropped to make room in the inbound queue (see WithInboundQueueSize)
+	DropReasonInboundQueueFull = "inbound_queue_full"
+
+	// DropReasonLockConflict is a Preprepare message for a proposal different from the locked one (see WithLockConflictPolicy)
+	DropReasonLockConflict = "lock_conflict"
+
+	// DropReasonTooOld is a message whose proposal is older than the configured MaxMessageAge
+	DropReasonTooOld = "too_old"
+
+	// DropReasonFutureRound is a Preprepare message for a future round of the current sequence dropped because
+	// MaxFuturePreprepares are already buffered
+	DropReasonFutureRound = "future_round"
+
+	// DropReasonOutOfPhase is a commit message received before the prepare quorum (see EarlyCommit_Reject)
+	DropReasonOutOfPhase = "out_of_phase"
+
+	// DropReasonNotValidator is a message from a node outside of the validator set
+	DropReasonNotValidator = "not_validator"
+
+	// DropReasonBodyMismatch is a proposal response whose body does not match the hash of the pre-prepare
+	DropReasonBodyMismatch = "body_mismatch"
+)
+
+// Metrics receives the measurements of the state machine. The methods are invoked synchronously
+// from the state machine and must not block.
+type Metrics interface {
+	// StateTransition is invoked every time the state machine moves from a state to another one
+	StateTransition(from, to State)
+
+	// RoundDuration is invoked when a round ends, either because a new round starts or the sequence is finalized
+	RoundDuration(round uint64, duration time.Duration)
+
+	// MessageDropped is invoked for every message dropped without being processed, with one of the DropReason values
+	MessageDropped(typ MsgType, reason string)
+
+	// QuorumReached is invoked when the prepare (MessageReq_Prepare) or commit (MessageReq_Commit) quorum
+	// of a round is reached, with the time elapsed since the round started
+	QuorumReached(typ MsgType, latency time.Duration)
+
+	// SequenceLatency is invoked when the commit quorum of a sequence is reached, with the time elapsed
+	// since the first proposal of the sequence was built or received, across the round changes
+	SequenceLatency(sequence uint64, latency time.Duration)
+}
+
+// noopMetrics is the default Metrics implementation, it discards the measurements
+type noopMetrics struct{}
+
+// StateTransition implements Metrics interface
+func (noopMetrics) StateTransition(State, State) {}
+
+// RoundDuration implements Metrics interface
+func (noopMetrics) RoundDuration(uint64, time.Duration) {}
+
+// MessageDropped implements Metrics interface
+func (noopMetrics) MessageDropped(MsgType, string) {}
+
+// QuorumReached implements Metrics interface
+func (noopMetrics) QuorumReached(MsgType, time.Duration) {}
+
+// SequenceLatency implements Metrics interface
+func (noopMetrics) SequenceLatency(uint64, time.Duration) {}
+
+// dropMessage records a message dropped without being processed
+func (p *Pbft) dropMessage(msg *MessageReq, reason string) {
+	p.stats.IncrDroppedMsgCount(msg.Type.String())
+	p.config.Metrics.MessageDropped(msg.Type, reason)
+}
+
+// startRound records the start of the current round
+func (p *Pbft) startRound() {
+	p.roundStart = p.config.Clock.Now()
+}
+
+// endRound reports the duration of the current round, if started
+func (p *Pbft) endRound() {
+	if p.roundStart.IsZero() {
+		return
+	}
+	p.config.Metrics.RoundDuration(p.state.GetCurrentRound(), p.config.Clock.Now().Sub(p.roundStart))
+	p.roundStart = time.Time{}
+}
+
+// quorumReached reports the latency of the quorum of the given type in the current round
+func (p *Pbft) quorumReached(typ MsgType) {
+	if p.roundStart.IsZero() {
+		return
+	}
+	p.config.Metrics.QuorumReached(typ, p.config.Clock.Now().Sub(p.roundStart))
+}
+
+// proposalObtained records the time the first proposal of the sequence was built or received
+func (p *Pbft) proposalObtained() {
+	if p.sequenceStart.IsZero() {
+		p.sequenceStart = p.config.Clock.Now()
+	}
+}
+
+// sequenceCommitted reports the latency from the first proposal of the sequence to the commit quorum
+func (p *Pbft) sequenceCommitted() {
+	if p.sequenceStart.IsZero() {
+		return
+	}
+	latency := p.config.Clock.Now().Sub(p.sequenceStart)
+	p.sequenceStart = time.Time{}
+	atomic.StoreInt64(&p.lastSequenceLatency, int64(latency))
+	p.config.Metrics.SequenceLatency(p.state.GetSequence(), latency)
+}
+
+// LastSequenceLatency returns the time from the first proposal to the commit quorum of the last committed sequence
+func (p *Pbft) LastSequenceLatency() time.Duration {
+	return time.Duration(atomic.LoadInt64(&p.lastSequenceLatency))
+}
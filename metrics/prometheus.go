@@ -0,0 +1,109 @@
+// Package metrics provides ready to use implementations of the pbft.Metrics interface.
+// It is a separate module, so that the users not exporting Prometheus metrics do not depend on the client.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/0xPolygon/pbft-consensus"
+)
+
+// The names and labels of the metrics are stable, the dashboards and alerts may rely on them:
+//
+//	pbft_state_transitions_total{from, to}      counter of the state machine transitions
+//	pbft_round_duration_seconds                 histogram of the duration of the rounds
+//	pbft_messages_dropped_total{type, reason}   counter of the messages dropped without being processed
+//	pbft_quorum_latency_seconds{type}           histogram of the time from the round start to the prepare and commit quorums
+//	pbft_sequence_latency_seconds               histogram of the time from the first proposal of a sequence to its commit quorum
+const (
+	namespace = "pbft"
+
+	stateTransitionsName = "state_transitions_total"
+	roundDurationName    = "round_duration_seconds"
+	messagesDroppedName  = "messages_dropped_total"
+	quorumLatencyName    = "quorum_latency_seconds"
+	sequenceLatencyName  = "sequence_latency_seconds"
+)
+
+// PrometheusMetrics is a pbft.Metrics implementation exporting the measurements as Prometheus metrics
+type PrometheusMetrics struct {
+	stateTransitions *prometheus.CounterVec
+	roundDuration    prometheus.Histogram
+	messagesDropped  *prometheus.CounterVec
+	quorumLatency    *prometheus.HistogramVec
+	sequenceLatency  prometheus.Histogram
+}
+
+// NewPrometheusMetrics creates the metrics and registers them with the given registerer.
+// The constant labels (i.e. the node id) are added to every metric.
+func NewPrometheusMetrics(registerer prometheus.Registerer, constLabels prometheus.Labels) (*PrometheusMetrics, error) {
+	m := &PrometheusMetrics{
+		stateTransitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        stateTransitionsName,
+			Help:        "Number of transitions of the state machine.",
+			ConstLabels: constLabels,
+		}, []string{"from", "to"}),
+		roundDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Name:        roundDurationName,
+			Help:        "Duration of the rounds, until a new round starts or the sequence is finalized.",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.ExponentialBuckets(0.05, 2, 12),
+		}),
+		messagesDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        messagesDroppedName,
+			Help:        "Number of messages dropped without being processed, by message type and reason.",
+			ConstLabels: constLabels,
+		}, []string{"type", "reason"}),
+		quorumLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Name:        quorumLatencyName,
+			Help:        "Time from the start of the round to the prepare and commit quorums.",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.ExponentialBuckets(0.01, 2, 12),
+		}, []string{"type"}),
+		sequenceLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   namespace,
+			Name:        sequenceLatencyName,
+			Help:        "Time from the first proposal of a sequence to its commit quorum, across the round changes.",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.ExponentialBuckets(0.05, 2, 12),
+		}),
+	}
+
+	for _, collector := range []prometheus.Collector{m.stateTransitions, m.roundDuration, m.messagesDropped, m.quorumLatency, m.sequenceLatency} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// StateTransition implements pbft.Metrics interface
+func (m *PrometheusMetrics) StateTransition(from, to pbft.State) {
+	m.stateTransitions.WithLabelValues(from.String(), to.String()).Inc()
+}
+
+// RoundDuration implements pbft.Metrics interface
+func (m *PrometheusMetrics) RoundDuration(_ uint64, duration time.Duration) {
+	m.roundDuration.Observe(duration.Seconds())
+}
+
+// MessageDropped implements pbft.Metrics interface
+func (m *PrometheusMetrics) MessageDropped(typ pbft.MsgType, reason string) {
+	m.messagesDropped.WithLabelValues(typ.String(), reason).Inc()
+}
+
+// QuorumReached implements pbft.Metrics interface
+func (m *PrometheusMetrics) QuorumReached(typ pbft.MsgType, latency time.Duration) {
+	m.quorumLatency.WithLabelValues(typ.String()).Observe(latency.Seconds())
+}
+
+// SequenceLatency implements pbft.Metrics interface
+func (m *PrometheusMetrics) SequenceLatency(_ uint64, latency time.Duration) {
+	m.sequenceLatency.Observe(latency.Seconds())
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/0xPolygon/pbft-consensus"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m, err := NewPrometheusMetrics(registry, prometheus.Labels{"node": "A"})
+	require.NoError(t, err)
+
+	var _ pbft.Metrics = m
+
+	m.StateTransition(pbft.AcceptState, pbft.ValidateState)
+	m.StateTransition(pbft.AcceptState, pbft.ValidateState)
+	m.RoundDuration(0, 2*time.Second)
+	m.MessageDropped(pbft.MessageReq_Prepare, pbft.DropReasonStale)
+	m.QuorumReached(pbft.MessageReq_Commit, time.Second)
+	m.SequenceLatency(1, 3*time.Second)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.stateTransitions.WithLabelValues("AcceptState", "ValidateState")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.messagesDropped.WithLabelValues("Prepare", "stale")))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, family := range families {
+		names = append(names, family.GetName())
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			assert.Equal(t, "A", labels["node"])
+		}
+	}
+	assert.Equal(t, []string{
+		"pbft_messages_dropped_total",
+		"pbft_quorum_latency_seconds",
+		"pbft_round_duration_seconds",
+		"pbft_sequence_latency_seconds",
+		"pbft_state_transitions_total",
+	}, names)
+
+	// the metrics can be registered only once
+	_, err = NewPrometheusMetrics(registry, prometheus.Labels{"node": "A"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package pbft
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMetrics is a Metrics implementation recording the measurements as strings
+type recordingMetrics struct {
+	records []string
+}
+
+func (r *recordingMetrics) StateTransition(from, to State) {
+	r.records = append(r.records, fmt.Sprintf("transition %s->%s", from, to))
+}
+
+func (r *recordingMetrics) RoundDuration(round uint64, duration time.Duration) {
+	r.records = append(r.records, fmt.Sprintf("round %d %s", round, duration))
+}
+
+func (r *recordingMetrics) MessageDropped(typ MsgType, reason string) {
+	r.records = append(r.records, fmt.Sprintf("dropped %s %s", typ, reason))
+}
+
+func (r *recordingMetrics) QuorumReached(typ MsgType, latency time.Duration) {
+	r.records = append(r.records, fmt.Sprintf("quorum %s %s", typ, latency))
+}
+
+func (r *recordingMetrics) SequenceLatency(sequence uint64, latency time.Duration) {
+	r.records = append(r.records, fmt.Sprintf("sequence %d %s", sequence, latency))
+}
+
+func TestPbft_Metrics(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	metrics := &recordingMetrics{}
+
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithClock(clock)(m.config)
+	m.dedup = newSequenceDedup()
+	m.setSequence(1)
+	WithMetrics(metrics)(m.config)
+	m.state.proposer = "A"
+	m.state.proposal = &Proposal{Data: mockProposal, Hash: digest}
+	m.setState(ValidateState)
+
+	message := func(from NodeID, typ MsgType) Event {
+		msg := createMessage(from, typ, ViewMsg(1, 0))
+		msg.Hash = digest
+		return MessageEvent(msg)
+	}
+	advance := func(d time.Duration) Event {
+		return CommandEvent(func(*Pbft) { clock.Advance(d) })
+	}
+	WithScheduler(NewDeterministicScheduler(
+		advance(time.Second),
+		message("B", MessageReq_Prepare),
+		message("C", MessageReq_Prepare),
+		advance(time.Second),
+		message("B", MessageReq_Commit),
+		message("C", MessageReq_Commit),
+		message("D", MessageReq_Commit),
+	))(m.config)
+
+	// a duplicate and a stale message are dropped
+	prepare := createMessage("D", MessageReq_Prepare, ViewMsg(1, 0))
+	prepare.Hash = digest
+	m.emitMsg(prepare)
+	m.emitMsg(prepare.Copy())
+	m.msgQueue.pushMessage(createMessage("D", MessageReq_Prepare, ViewMsg(0, 0)))
+
+	m.runCycle(context.Background())
+	clock.Advance(time.Second)
+	m.runCycle(context.Background())
+
+	assert.Equal(t, []string{
+		"transition AcceptState->ValidateState",
+		"dropped Prepare duplicate",
+		"dropped Prepare stale",
+		"quorum Prepare 1s",
+		"quorum Commit 2s",
+		"transition ValidateState->CommitState",
+		"round 0 3s",
+		"transition CommitState->DoneState",
+	}, metrics.records)
+}
+
+func TestPbft_LastSequenceLatency(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	metrics := &recordingMetrics{}
+
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithClock(clock)(m.config)
+	m.setSequence(1)
+	WithMetrics(metrics)(m.config)
+
+	// round 0: the node builds the first proposal of the sequence
+	m.setProposal(&Proposal{Data: mockProposal, Hash: digest, Time: time.Now()})
+	m.setState(AcceptState)
+	m.runCycle(context.Background())
+	require.Equal(t, ValidateState, m.getState())
+	assert.Zero(t, m.LastSequenceLatency())
+
+	// round 1: after a round change, the proposal of the new proposer is received
+	clock.Advance(2 * time.Second)
+	m.setRound(1)
+	m.state.resetRoundMsgs()
+	m.setState(AcceptState)
+	preprepare := createMessage("B", MessageReq_Preprepare, ViewMsg(1, 1))
+	preprepare.Hash = digest
+	m.emitMsg(preprepare)
+	m.runCycle(context.Background())
+	require.Equal(t, ValidateState, m.getState())
+
+	clock.Advance(time.Second)
+	for _, from := range []NodeID{"B", "C"} {
+		prepare := createMessage(from, MessageReq_Prepare, ViewMsg(1, 1))
+		prepare.Hash = digest
+		m.emitMsg(prepare)
+	}
+	for _, from := range []NodeID{"B", "C", "D"} {
+		commit := createMessage(from, MessageReq_Commit, ViewMsg(1, 1))
+		commit.Hash = digest
+		m.emitMsg(commit)
+	}
+	m.runCycle(context.Background())
+	require.Equal(t, CommitState, m.getState())
+
+	// the latency is measured from the first proposal of the sequence
+	assert.Equal(t, 3*time.Second, m.LastSequenceLatency())
+	assert.Contains(t, metrics.records, "sequence 1 3s")
+}
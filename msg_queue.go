@@ -78,6 +78,50 @@ func (m *msgQueue) readMessageWithDiscards(st State, current *View) (*MessageReq
 	}
 }
 
+// roundChangesAbove returns the queued round change messages of the current sequence with a round higher than the current one.
+// The messages are left in the queue.
+func (m *msgQueue) roundChangesAbove(current *View) []*MessageReq {
+	m.queueLock.Lock()
+	defer m.queueLock.Unlock()
+
+	msgs := []*MessageReq{}
+	for _, msg := range m.roundChangeStateQueue {
+		if msg.View.Sequence == current.Sequence && msg.View.Round > current.Round {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+// futurePreprepares returns the number of queued pre-prepare messages of the current sequence with a round higher
+// than the current one
+func (m *msgQueue) futurePreprepares(current *View) int {
+	m.queueLock.Lock()
+	defer m.queueLock.Unlock()
+
+	count := 0
+	for _, msg := range m.acceptStateQueue {
+		if msg.Type == MessageReq_Preprepare && msg.View.Sequence == current.Sequence && msg.View.Round > current.Round {
+			count++
+		}
+	}
+	return count
+}
+
+// roundChangesOf returns the queued round change messages of the given view. The messages are left in the queue.
+func (m *msgQueue) roundChangesOf(view *View) []*MessageReq {
+	m.queueLock.Lock()
+	defer m.queueLock.Unlock()
+
+	msgs := []*MessageReq{}
+	for _, msg := range m.roundChangeStateQueue {
+		if cmpView(msg.View, view) == 0 {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
 // getQueue checks the passed in state, and returns the corresponding message queue
 func (m *msgQueue) getQueue(st State) *msgQueueImpl {
 	if st == RoundChangeState {
@@ -106,8 +150,8 @@ func msgToState(msg MsgType) State {
 	if msg == MessageReq_RoundChange {
 		// round change
 		return RoundChangeState
-	} else if msg == MessageReq_Preprepare {
-		// preprepare
+	} else if msg == MessageReq_Preprepare || msg == MessageReq_ProposalResponse {
+		// preprepare and the proposal body of a preprepare
 		return AcceptState
 	} else if msg == MessageReq_Prepare || msg == MessageReq_Commit {
 		// prepare and commit
@@ -53,6 +53,21 @@ func TestMsgQueue_RoundChangeState(t *testing.T) {
 	}
 }
 
+func TestMsgQueue_RoundChangesAbove(t *testing.T) {
+	m := newMsgQueue()
+	m.pushMessage(createMessage("A", MessageReq_RoundChange, ViewMsg(1, 1)))
+	m.pushMessage(createMessage("B", MessageReq_RoundChange, ViewMsg(1, 2)))
+	m.pushMessage(createMessage("C", MessageReq_RoundChange, ViewMsg(2, 3)))
+	m.pushMessage(createMessage("D", MessageReq_Commit, ViewMsg(1, 3)))
+
+	msgs := m.roundChangesAbove(ViewMsg(1, 1))
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, NodeID("B"), msgs[0].From)
+
+	// the messages are left in the queue
+	assert.Equal(t, 3, m.roundChangeStateQueue.Len())
+}
+
 func Test_msgToState(t *testing.T) {
 	expectedResult := map[MsgType]State{
 		MessageReq_RoundChange: RoundChangeState,
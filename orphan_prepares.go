@@ -0,0 +1,123 @@
+package pbft
+
+// orphanPrepares is a bounded buffer of the prepare messages received for a proposal the node does not know yet,
+// keyed by proposal hash. They are counted once the pre-prepare of the proposal is accepted.
+type orphanPrepares struct {
+	// limit is the maximum number of buffered messages
+	limit int
+
+	// size is the number of buffered messages
+	size int
+
+	// byHash are the buffered messages keyed by proposal hash
+	byHash map[string][]*MessageReq
+}
+
+// newOrphanPrepares creates a new buffer holding at most limit messages
+func newOrphanPrepares(limit int) *orphanPrepares {
+	return &orphanPrepares{
+		limit:  limit,
+		byHash: map[string][]*MessageReq{},
+	}
+}
+
+// add buffers the message. It returns false if the buffer is full
+// or the sender already has a message buffered for the same proposal.
+func (o *orphanPrepares) add(msg *MessageReq) bool {
+	if o.size >= o.limit {
+		return false
+	}
+	key := string(msg.Hash)
+	for _, buffered := range o.byHash[key] {
+		if buffered.From == msg.From && cmpView(buffered.View, msg.View) == 0 {
+			return false
+		}
+	}
+	o.byHash[key] = append(o.byHash[key], msg)
+	o.size++
+	return true
+}
+
+// take removes and returns the messages buffered for the proposal hash
+func (o *orphanPrepares) take(hash []byte) []*MessageReq {
+	key := string(hash)
+	msgs := o.byHash[key]
+	delete(o.byHash, key)
+	o.size -= len(msgs)
+	return msgs
+}
+
+// prune drops the messages of the views before the given one
+func (o *orphanPrepares) prune(view *View) {
+	for key, msgs := range o.byHash {
+		kept := msgs[:0]
+		for _, msg := range msgs {
+			if cmpView(msg.View, view) >= 0 {
+				kept = append(kept, msg)
+			}
+		}
+		o.size -= len(msgs) - len(kept)
+		if len(kept) == 0 {
+			delete(o.byHash, key)
+		} else {
+			o.byHash[key] = kept
+		}
+	}
+}
+
+// bufferOrphanPrepare keeps a prepare message whose proposal is not known yet.
+// It returns false if the buffer is disabled, full or the message is not a prepare.
+func (s *state) bufferOrphanPrepare(msg *MessageReq) bool {
+	if s.orphans == nil || msg.Type != MessageReq_Prepare || msg.View == nil {
+		return false
+	}
+	if !s.validators.Includes(msg.From) {
+		return false
+	}
+
+	s.msgLock.Lock()
+	defer s.msgLock.Unlock()
+
+	return s.orphans.add(msg)
+}
+
+// attachOrphanPrepares adds to the prepared messages the buffered prepares of the current proposal and view.
+// The buffered messages of the previous views are dropped. It returns the number of messages added.
+func (s *state) attachOrphanPrepares() int {
+	if s.orphans == nil || s.proposal == nil || s.view == nil {
+		return 0
+	}
+
+	s.msgLock.Lock()
+	s.orphans.prune(s.view)
+	current := []*MessageReq{}
+	for _, msg := range s.orphans.take(s.proposal.Hash) {
+		if cmpView(msg.View, s.view) == 0 {
+			current = append(current, msg)
+		} else {
+			// the prepares of the next rounds are kept for their pre-prepare
+			s.orphans.add(msg)
+		}
+	}
+	s.msgLock.Unlock()
+
+	added := 0
+	for _, msg := range current {
+		if err := s.addPrepareMsg(msg); err == nil {
+			added++
+		}
+	}
+	return added
+}
+
+// numOrphanPrepares returns the number of buffered prepare messages
+func (s *state) numOrphanPrepares() int {
+	if s.orphans == nil {
+		return 0
+	}
+
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	return s.orphans.size
+}
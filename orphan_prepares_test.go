@@ -0,0 +1,109 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestState_OrphanPrepares(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+	s, err := initState(pool)
+	require.NoError(t, err)
+	s.view = ViewMsg(1, 0)
+
+	prepare := func(from NodeID, round uint64) *MessageReq {
+		msg := createMessage(from, MessageReq_Prepare, ViewMsg(1, round))
+		msg.Hash = digest
+		return msg
+	}
+
+	// disabled by default
+	assert.False(t, s.bufferOrphanPrepare(prepare("B", 0)))
+
+	s.orphans = newOrphanPrepares(4)
+	assert.True(t, s.bufferOrphanPrepare(prepare("B", 0)))
+	assert.True(t, s.bufferOrphanPrepare(prepare("C", 0)))
+	assert.True(t, s.bufferOrphanPrepare(prepare("C", 1)))
+	// duplicates, non validators and other message types are not buffered
+	assert.False(t, s.bufferOrphanPrepare(prepare("B", 0)))
+	assert.False(t, s.bufferOrphanPrepare(prepare("X", 0)))
+	assert.False(t, s.bufferOrphanPrepare(createMessage("D", MessageReq_Commit, ViewMsg(1, 0))))
+	assert.Equal(t, 3, s.numOrphanPrepares())
+	assert.Zero(t, s.numPrepared())
+
+	// the prepares are counted once the proposal is known
+	s.proposal = &Proposal{Data: mockProposal, Hash: digest}
+	assert.Equal(t, 2, s.attachOrphanPrepares())
+	assert.Equal(t, 2, s.numPrepared())
+
+	// the prepare of the next round is kept for the pre-prepare of that round
+	assert.Equal(t, 1, s.numOrphanPrepares())
+	s.view = ViewMsg(1, 2)
+	assert.Zero(t, s.attachOrphanPrepares())
+	assert.Zero(t, s.numOrphanPrepares())
+}
+
+func TestState_OrphanPrepares_Bounded(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+	s, err := initState(pool)
+	require.NoError(t, err)
+	s.orphans = newOrphanPrepares(2)
+
+	for _, from := range []NodeID{"A", "B", "C"} {
+		msg := createMessage(from, MessageReq_Prepare, ViewMsg(1, 0))
+		msg.Hash = []byte(from)
+		s.bufferOrphanPrepare(msg)
+	}
+	assert.Equal(t, 2, s.numOrphanPrepares())
+}
+
+// Test that the prepares for an unknown proposal are buffered in the validate state instead of being dropped.
+func TestTransition_ValidateState_BufferOrphanPrepare(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B", nil)
+	m.state.orphans = newOrphanPrepares(10)
+	m.setState(ValidateState)
+	m.state.view = ViewMsg(1, 0)
+	m.state.proposal = &Proposal{Data: mockProposal, Hash: []byte("other")}
+
+	prepare := createMessage("C", MessageReq_Prepare, ViewMsg(1, 0))
+	prepare.Hash = digest
+	WithScheduler(NewDeterministicScheduler(MessageEvent(prepare)))(m.config)
+
+	m.runCycle(context.Background())
+
+	assert.Zero(t, m.state.numPrepared())
+	assert.Equal(t, 1, m.state.numOrphanPrepares())
+}
+
+// Test that a prepare received before the pre-prepare is counted once the pre-prepare is accepted.
+func TestTransition_AcceptState_AttachOrphanPrepares(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B", nil)
+	m.state.orphans = newOrphanPrepares(10)
+	m.state.view = ViewMsg(1, 0)
+	m.setState(AcceptState)
+
+	prepare := createMessage("C", MessageReq_Prepare, ViewMsg(1, 0))
+	prepare.Hash = digest
+	require.True(t, m.state.bufferOrphanPrepare(prepare))
+
+	preprepare := createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0))
+	preprepare.ProposalTime = time.Now()
+	WithScheduler(NewDeterministicScheduler(MessageEvent(preprepare)))(m.config)
+
+	m.runCycle(context.Background())
+
+	m.expect(expectResult{
+		sequence:               1,
+		state:                  ValidateState,
+		prepareMsgs:            1,
+		prepareMsgsVotingPower: 1,
+		outgoing:               1, // prepare
+	})
+	assert.Zero(t, m.state.numOrphanPrepares())
+}
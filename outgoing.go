@@ -0,0 +1,28 @@
+package pbft
+
+// OutgoingInterceptor is invoked with every consensus message (pre-prepare, prepare, commit and round change)
+// before the node sends it, i.e. to attach an envelope or a signature made with externally managed keys.
+// It is invoked synchronously in the sending order. The returned message is sent in place of the given one,
+// while an error suppresses the send.
+type OutgoingInterceptor interface {
+	Before(msg *MessageReq) (*MessageReq, error)
+}
+
+// interceptOutgoing passes the message to the configured OutgoingInterceptor, if any.
+// It returns false when the message must not be sent.
+func (p *Pbft) interceptOutgoing(msg *MessageReq) (*MessageReq, bool) {
+	interceptor := p.config.OutgoingInterceptor
+	if interceptor == nil {
+		return msg, true
+	}
+	intercepted, err := interceptor.Before(msg)
+	if err != nil {
+		p.logger.Printf("[ERROR] outgoing %s message suppressed by the interceptor: %v", msg.Type, err)
+		return nil, false
+	}
+	if intercepted == nil {
+		p.logger.Printf("[ERROR] outgoing %s message suppressed, the interceptor returned no message", msg.Type)
+		return nil, false
+	}
+	return intercepted, true
+}
@@ -0,0 +1,57 @@
+package pbft
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type outgoingInterceptorFunc func(msg *MessageReq) (*MessageReq, error)
+
+func (f outgoingInterceptorFunc) Before(msg *MessageReq) (*MessageReq, error) {
+	return f(msg)
+}
+
+func TestPbft_OutgoingInterceptor(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.state.view = ViewMsg(1, 0)
+	m.state.proposal = &Proposal{Data: mockProposal, Hash: digest, Time: time.Now()}
+
+	seen := []MsgType{}
+	WithOutgoingInterceptor(outgoingInterceptorFunc(func(msg *MessageReq) (*MessageReq, error) {
+		seen = append(seen, msg.Type)
+		if msg.Type == MessageReq_Prepare {
+			return nil, errors.New("suppressed")
+		}
+		wrapped := msg.Copy()
+		wrapped.Hash = append([]byte("wrapped:"), msg.Hash...)
+		return wrapped, nil
+	}))(m.config)
+
+	m.sendPreprepareMsg()
+	m.sendPrepareMsg()
+	m.sendCommitMsg()
+	m.sendRoundChange()
+
+	// the interceptor sees every message type, in the sending order
+	assert.Equal(t, []MsgType{MessageReq_Preprepare, MessageReq_Prepare, MessageReq_Commit, MessageReq_RoundChange}, seen)
+
+	// the intercepted messages are sent, the prepare message is suppressed
+	require.Len(t, m.respMsg, 3)
+	assert.Equal(t, MessageReq_Preprepare, m.respMsg[0].Type)
+	assert.Equal(t, MessageReq_Commit, m.respMsg[1].Type)
+	assert.Equal(t, MessageReq_RoundChange, m.respMsg[2].Type)
+	for _, msg := range m.respMsg {
+		assert.True(t, bytes.HasPrefix(msg.Hash, []byte("wrapped:")))
+	}
+
+	// the node does not process the suppressed prepare message either
+	for _, msg := range m.msgQueue.validateStateQueue {
+		assert.NotEqual(t, MessageReq_Prepare, msg.Type)
+	}
+	assert.Len(t, m.msgQueue.validateStateQueue, 1)
+}
@@ -0,0 +1,51 @@
+package pbft
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrWrongParent is returned when the parent hash of the proposal is not the hash of the last finalized proposal
+	ErrWrongParent = errors.New("proposal parent is not the last finalized proposal")
+
+	// ErrUnknownParent is returned after the genesis sequence when the hash of the last finalized proposal is not known
+	ErrUnknownParent = errors.New("last finalized proposal is not known")
+)
+
+// checkParentHash validates, when enabled, that the proposal extends the last finalized proposal. The check is skipped
+// in the genesis sequence and for the nil proposals. Without a genesis sequence (see WithGenesisSequence), it is also
+// skipped while the hash of the last finalized proposal is not known.
+func (p *Pbft) checkParentHash(proposal *Proposal) error {
+	if !p.config.VerifyParentHash || proposal.IsNil() || p.isGenesisSequence() {
+		return nil
+	}
+	if p.lastFinalizedHash == nil {
+		if p.config.GenesisMode {
+			return fmt.Errorf("%w: sequence %d is after the genesis sequence %d", ErrUnknownParent, p.state.view.Sequence, p.config.GenesisSequence)
+		}
+		return nil
+	}
+	if !bytes.Equal(proposal.ParentHash, p.lastFinalizedHash) {
+		return fmt.Errorf("%w: parent %x, last finalized %x", ErrWrongParent, proposal.ParentHash, p.lastFinalizedHash)
+	}
+	return nil
+}
+
+// SetLastFinalizedHash sets the hash of the last finalized proposal, the parent expected from the next proposal.
+// It is meant to restore the parent on startup (i.e. from the head of the chain), the hash is then updated as
+// the sequences are finalized. Like SetBackend, it must not be called concurrently with the state machine.
+func (p *Pbft) SetLastFinalizedHash(hash []byte) {
+	p.lastFinalizedHash = append([]byte{}, hash...)
+}
+
+// LastFinalizedHash returns the hash of the last finalized proposal, nil if not known
+func (p *Pbft) LastFinalizedHash() []byte {
+	return p.lastFinalizedHash
+}
+
+// isGenesisSequence returns whether the current sequence is the explicit genesis sequence (see WithGenesisSequence)
+func (p *Pbft) isGenesisSequence() bool {
+	return p.config.GenesisMode && p.state.view != nil && p.state.view.Sequence == p.config.GenesisSequence
+}
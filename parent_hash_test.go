@@ -0,0 +1,92 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPbft_CheckParentHash(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithParentHashVerification()(m.config)
+
+	// genesis sequence, the parent is not known yet
+	assert.NoError(t, m.checkParentHash(&Proposal{Data: mockProposal, ParentHash: digest1}))
+
+	m.proposalFinalized(&Proposal{Data: mockProposal, Hash: digest})
+	assert.Equal(t, digest, m.LastFinalizedHash())
+	assert.NoError(t, m.checkParentHash(&Proposal{Data: mockProposal1, ParentHash: digest}))
+	assert.ErrorIs(t, m.checkParentHash(&Proposal{Data: mockProposal1, ParentHash: digest1}), ErrWrongParent)
+	assert.ErrorIs(t, m.checkParentHash(&Proposal{Data: mockProposal1}), ErrWrongParent)
+
+	// the nil proposals have no parent
+	m.proposalFinalized(NilProposal())
+	assert.Equal(t, digest, m.LastFinalizedHash())
+	assert.NoError(t, m.checkParentHash(NilProposal()))
+
+	// disabled by default
+	m.config.VerifyParentHash = false
+	assert.NoError(t, m.checkParentHash(&Proposal{Data: mockProposal1, ParentHash: digest1}))
+}
+
+// Test that the genesis proposal is accepted without a parent, and the linkage is enforced from the next sequence.
+func TestPbft_GenesisSequence(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithParentHashVerification()(m.config)
+	WithProposalTimePolicy(ProposalTime_Strict)(m.config)
+	WithGenesisSequence(0)(m.config)
+	now := time.Now()
+
+	m.state.view = ViewMsg(0, 0)
+	genesis := &Proposal{Data: mockProposal, Hash: digest, Time: now}
+	assert.NoError(t, m.precheckProposal(genesis))
+	m.proposalFinalized(genesis)
+
+	m.state.view = ViewMsg(1, 0)
+	assert.ErrorIs(t, m.precheckProposal(&Proposal{Data: mockProposal1, Time: now.Add(time.Second)}), ErrWrongParent)
+	assert.ErrorIs(t, m.precheckProposal(&Proposal{Data: mockProposal1, ParentHash: digest, Time: now}), ErrProposalTimestamp)
+	assert.NoError(t, m.precheckProposal(&Proposal{Data: mockProposal1, ParentHash: digest, Time: now.Add(time.Second)}))
+
+	// after the genesis sequence, the parent must be known (i.e. restored after a restart)
+	restarted := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithParentHashVerification()(restarted.config)
+	WithGenesisSequence(0)(restarted.config)
+	restarted.state.view = ViewMsg(5, 0)
+	assert.ErrorIs(t, restarted.precheckProposal(&Proposal{Data: mockProposal1, ParentHash: digest}), ErrUnknownParent)
+	restarted.SetLastFinalizedHash(digest)
+	assert.NoError(t, restarted.precheckProposal(&Proposal{Data: mockProposal1, ParentHash: digest}))
+}
+
+// Test that the follower starts a round change when the proposal does not extend the last finalized proposal.
+func TestTransition_AcceptState_WrongParentHash(t *testing.T) {
+	run := func(parentHash []byte) State {
+		m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+		WithParentHashVerification()(m.config)
+		m.SetLastFinalizedHash(digest1)
+		m.state.view = ViewMsg(1, 0)
+		m.setState(AcceptState)
+
+		preprepare := createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0))
+		preprepare.ProposalParentHash = parentHash
+		m.emitMsg(preprepare)
+		m.runCycle(context.Background())
+		return m.getState()
+	}
+
+	assert.Equal(t, ValidateState, run(digest1))
+	assert.Equal(t, RoundChangeState, run([]byte("fork")))
+}
+
+func TestHashProposal_ParentHash(t *testing.T) {
+	proposal := &Proposal{Data: mockProposal}
+	unlinked := HashProposal(proposal)
+
+	proposal.ParentHash = digest
+	linked := HashProposal(proposal)
+	assert.NotEqual(t, unlinked, linked)
+
+	proposal.ParentHash = digest1
+	assert.NotEqual(t, linked, HashProposal(proposal))
+}
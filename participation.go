@@ -0,0 +1,113 @@
+package pbft
+
+import "sync"
+
+const defaultParticipationHistory = 1024
+
+// ParticipationRecord is the participation of a validator in the finalized sequences
+type ParticipationRecord struct {
+	// Sequences is the number of finalized sequences the validator sent a Prepare or a Commit message for
+	Sequences uint64
+
+	// Prepares is the number of finalized sequences the validator sent a Prepare message for
+	Prepares uint64
+
+	// Commits is the number of finalized sequences the validator sent a Commit message for
+	Commits uint64
+}
+
+// sequenceParticipation are the validators that participated in a finalized sequence
+type sequenceParticipation struct {
+	sequence  uint64
+	prepared  []NodeID
+	committed []NodeID
+}
+
+// participationTracker keeps the participation of the validators in the last finalized sequences
+type participationTracker struct {
+	lock sync.Mutex
+
+	// history are the last finalized sequences, the oldest first
+	history []sequenceParticipation
+
+	// maxHistory is the maximum number of finalized sequences kept
+	maxHistory int
+}
+
+// newParticipationTracker creates a new tracker keeping up to maxHistory finalized sequences
+func newParticipationTracker(maxHistory int) *participationTracker {
+	return &participationTracker{
+		maxHistory: maxHistory,
+	}
+}
+
+// record stores the participation of the validators in the finalized sequence
+func (t *participationTracker) record(sequence uint64, prepared, committed []NodeID) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.maxHistory <= 0 {
+		return
+	}
+	if len(t.history) >= t.maxHistory {
+		t.history = append(t.history[:0], t.history[len(t.history)-t.maxHistory+1:]...)
+	}
+	t.history = append(t.history, sequenceParticipation{
+		sequence:  sequence,
+		prepared:  prepared,
+		committed: committed,
+	})
+}
+
+// stats aggregates the participation of the validators in the last window finalized sequences
+func (t *participationTracker) stats(window uint64) map[NodeID]ParticipationRecord {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	start := 0
+	if window < uint64(len(t.history)) {
+		start = len(t.history) - int(window)
+	}
+
+	records := map[NodeID]ParticipationRecord{}
+	for _, entry := range t.history[start:] {
+		participated := map[NodeID]struct{}{}
+		for _, id := range entry.prepared {
+			record := records[id]
+			record.Prepares++
+			records[id] = record
+			participated[id] = struct{}{}
+		}
+		for _, id := range entry.committed {
+			record := records[id]
+			record.Commits++
+			records[id] = record
+			participated[id] = struct{}{}
+		}
+		for id := range participated {
+			record := records[id]
+			record.Sequences++
+			records[id] = record
+		}
+	}
+	return records
+}
+
+// ParticipationStats returns, per validator, the participation in the last window finalized sequences.
+// Only the messages that counted toward the quorum of the finalized round are taken into account.
+func (p *Pbft) ParticipationStats(window uint64) map[NodeID]ParticipationRecord {
+	return p.participation.stats(window)
+}
+
+// recordParticipation records the senders of the prepared and committed messages of the finalized sequence
+func (p *Pbft) recordParticipation() {
+	collect := func(rangeFn func(func(NodeID, *MessageReq) bool)) []NodeID {
+		ids := []NodeID{}
+		rangeFn(func(id NodeID, _ *MessageReq) bool {
+			ids = append(ids, id)
+			return true
+		})
+		return ids
+	}
+	p.participation.record(p.state.view.Sequence, collect(p.state.rangePrepared), collect(p.state.rangeCommitted))
+}
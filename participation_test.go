@@ -0,0 +1,62 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParticipationTracker_Window(t *testing.T) {
+	tracker := newParticipationTracker(3)
+
+	tracker.record(1, []NodeID{"A", "B"}, []NodeID{"A", "B"})
+	tracker.record(2, []NodeID{"A"}, []NodeID{"C"})
+	tracker.record(3, []NodeID{"A", "B"}, []NodeID{"A"})
+	tracker.record(4, nil, []NodeID{"B"})
+
+	// the first sequence is out of the history
+	assert.Equal(t, map[NodeID]ParticipationRecord{
+		"A": {Sequences: 2, Prepares: 2, Commits: 1},
+		"B": {Sequences: 2, Prepares: 1, Commits: 1},
+		"C": {Sequences: 1, Commits: 1},
+	}, tracker.stats(10))
+
+	assert.Equal(t, map[NodeID]ParticipationRecord{
+		"B": {Sequences: 1, Commits: 1},
+	}, tracker.stats(1))
+
+	assert.Empty(t, tracker.stats(0))
+}
+
+func TestPbft_ParticipationStats(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.state.view = ViewMsg(1, 0)
+	m.state.proposer = "A"
+
+	for _, from := range []NodeID{"A", "B", "C"} {
+		require.NoError(t, m.state.addMessage(createMessage(from, MessageReq_Prepare, ViewMsg(1, 0))))
+	}
+	for _, from := range []NodeID{"A", "B", "D"} {
+		require.NoError(t, m.state.addMessage(createMessage(from, MessageReq_Commit, ViewMsg(1, 0))))
+	}
+	m.setState(CommitState)
+
+	m.runCycle(context.Background())
+
+	m.expect(expectResult{
+		sequence:               1,
+		state:                  DoneState,
+		prepareMsgs:            3,
+		prepareMsgsVotingPower: 3,
+		commitMsgs:             3,
+		commitMsgsVotingPower:  3,
+	})
+	assert.Equal(t, map[NodeID]ParticipationRecord{
+		"A": {Sequences: 1, Prepares: 1, Commits: 1},
+		"B": {Sequences: 1, Prepares: 1, Commits: 1},
+		"C": {Sequences: 1, Prepares: 1},
+		"D": {Sequences: 1, Commits: 1},
+	}, m.ParticipationStats(1))
+}
@@ -0,0 +1,204 @@
+package pbft
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+)
+
+// pipelinedRound holds the messages of a single round of a pipelined sequence
+type pipelinedRound struct {
+	// preprepare is the proposal message of the round
+	preprepare *MessageReq
+
+	// committed are the commit messages of the round, indexed by sender
+	committed map[NodeID]*MessageReq
+}
+
+// pipeline tracks the messages of the sequences following the one being finalized, each one with
+// its own quorum tracking. A pipelined sequence whose commit quorum is reached while the previous one
+// is still in progress gets finalized right after it, so that sequences are always finalized in order.
+// It assumes that the validator set does not change between the pipelined sequences.
+type pipeline struct {
+	lock sync.Mutex
+
+	// depth is the number of sequences ahead of the current one that are tracked
+	depth uint64
+
+	// sequence is the current sequence of the node
+	sequence uint64
+
+	// rounds are the tracked messages per sequence and round
+	rounds map[uint64]map[uint64]*pipelinedRound
+
+	// sealHash computes the hash sealed by the commit seals of a proposal, the proposal hash if nil
+	sealHash func(*Proposal) []byte
+
+	// roundSealHash binds the seal hash to the round of the commit seals, if not nil (see WithRoundBoundCommitSeals)
+	roundSealHash func(round uint64, sealHash []byte) []byte
+
+	// verifyHash verifies the hash of a proposal against its content, if not nil
+	verifyHash func(*Proposal) error
+}
+
+// newPipeline creates a new pipeline tracking up to depth sequences ahead of the current one
+func newPipeline(depth uint64) *pipeline {
+	return &pipeline{
+		depth:  depth,
+		rounds: map[uint64]map[uint64]*pipelinedRound{},
+	}
+}
+
+// track records the Preprepare and Commit messages of the pipelined sequences.
+// Messages of other types or outside of the pipeline window are ignored.
+func (p *pipeline) track(msg *MessageReq) {
+	if msg.View == nil || (msg.Type != MessageReq_Preprepare && msg.Type != MessageReq_Commit) {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	sequence := msg.View.Sequence
+	if sequence <= p.sequence || sequence > p.sequence+p.depth {
+		return
+	}
+
+	rounds, ok := p.rounds[sequence]
+	if !ok {
+		rounds = map[uint64]*pipelinedRound{}
+		p.rounds[sequence] = rounds
+	}
+	round, ok := rounds[msg.View.Round]
+	if !ok {
+		round = &pipelinedRound{committed: map[NodeID]*MessageReq{}}
+		rounds[msg.View.Round] = round
+	}
+
+	if msg.Type == MessageReq_Preprepare {
+		if round.preprepare == nil {
+			round.preprepare = msg.Copy()
+		}
+		return
+	}
+	if _, ok := round.committed[msg.From]; !ok {
+		round.committed[msg.From] = msg.Copy()
+	}
+}
+
+// advance sets the current sequence of the node and drops the messages of the sequences up to it
+func (p *pipeline) advance(sequence uint64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.sequence = sequence
+	for seq := range p.rounds {
+		if seq <= sequence {
+			delete(p.rounds, seq)
+		}
+	}
+}
+
+// committedSequence is a pipelined sequence that reached a commit quorum
+type committedSequence struct {
+	*SealedProposal
+
+	// round is the round in which the commit quorum was reached
+	round uint64
+
+	// sealHash is the hash sealed by the committed seals, when it is not the proposal hash
+	sealHash []byte
+}
+
+// proof returns the finalization proof of the pipelined sequence
+func (c *committedSequence) proof() *FinalizationProof {
+	return &FinalizationProof{
+		Hash:           append([]byte{}, c.Proposal.Hash...),
+		View:           &View{Sequence: c.Number, Round: c.round},
+		CommittedSeals: c.CommittedSeals,
+		SealHash:       c.sealHash,
+	}
+}
+
+// committed returns the sealed proposal of the given pipelined sequence if any of its rounds reached
+// a commit quorum of valid seals for the proposed hash, otherwise it returns nil
+func (p *pipeline) committed(sequence uint64, validators ValidatorSet, verifySeals func([]SealItem) []error) *committedSequence {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	votingPower := validators.VotingPower()
+	_, quorumSize, err := CalculateQuorum(votingPower)
+	if err != nil {
+		return nil
+	}
+
+	rounds := make([]uint64, 0, len(p.rounds[sequence]))
+	for round := range p.rounds[sequence] {
+		rounds = append(rounds, round)
+	}
+	sort.Slice(rounds, func(i, j int) bool { return rounds[i] < rounds[j] })
+
+	for _, r := range rounds {
+		round := p.rounds[sequence][r]
+		if round.preprepare == nil {
+			continue
+		}
+
+		proposal := &Proposal{
+			Type:       round.preprepare.ProposalType,
+			Time:       round.preprepare.ProposalTime,
+			Data:       append([]byte{}, round.preprepare.Proposal...),
+			Hash:       append([]byte{}, round.preprepare.Hash...),
+			ParentHash: round.preprepare.ProposalParentHash,
+			Metadata:   round.preprepare.ProposalMetadata,
+		}
+		if p.verifyHash != nil && p.verifyHash(proposal) != nil {
+			// the proposal content does not match the hash committed to
+			continue
+		}
+		sealHash := proposal.Hash
+		if p.sealHash != nil {
+			sealHash = p.sealHash(proposal)
+		}
+		if p.roundSealHash != nil {
+			sealHash = p.roundSealHash(r, sealHash)
+		}
+
+		items := []SealItem{}
+		for from, msg := range round.committed {
+			if validators.Includes(from) && bytes.Equal(round.preprepare.Hash, msg.Hash) {
+				items = append(items, SealItem{From: from, Hash: sealHash, Seal: msg.Seal})
+			}
+		}
+
+		power := uint64(0)
+		seals := []CommittedSeal{}
+		for i, err := range verifySeals(items) {
+			if err != nil {
+				continue
+			}
+			power += votingPower[items[i].From]
+			seals = append(seals, CommittedSeal{NodeID: items[i].From, Signature: items[i].Seal})
+		}
+		if power < quorumSize {
+			continue
+		}
+		sort.Slice(seals, func(i, j int) bool { return seals[i].NodeID < seals[j].NodeID })
+
+		committed := &committedSequence{
+			SealedProposal: &SealedProposal{
+				Proposal:       proposal,
+				CommittedSeals: seals,
+				Proposer:       round.preprepare.From,
+				Number:         sequence,
+				Round:          r,
+			},
+			round: r,
+		}
+		if p.sealHash != nil || p.roundSealHash != nil {
+			committed.sealHash = sealHash
+		}
+		return committed
+	}
+	return nil
+}
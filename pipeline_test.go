@@ -0,0 +1,90 @@
+package pbft
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func acceptSeal(items []SealItem) []error { return make([]error, len(items)) }
+
+func pipelinedCommit(from NodeID, view *View) *MessageReq {
+	msg := createMessage(from, MessageReq_Commit, view)
+	msg.Hash = digest
+	return msg
+}
+
+func TestPipeline_Window(t *testing.T) {
+	validators := NewValStringStub([]NodeID{"A", "B", "C", "D"}, CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+	p := newPipeline(1)
+	p.advance(1)
+
+	// only the messages of the sequence 2 are tracked
+	for _, sequence := range []uint64{1, 2, 3} {
+		p.track(createMessage("A", MessageReq_Preprepare, ViewMsg(sequence, 0)))
+		for _, from := range []NodeID{"A", "B", "C"} {
+			p.track(pipelinedCommit(from, ViewMsg(sequence, 0)))
+		}
+	}
+	assert.Nil(t, p.committed(1, validators, acceptSeal))
+	assert.NotNil(t, p.committed(2, validators, acceptSeal))
+	assert.Nil(t, p.committed(3, validators, acceptSeal))
+
+	// advancing drops the finalized sequences
+	p.advance(2)
+	assert.Nil(t, p.committed(2, validators, acceptSeal))
+	assert.Empty(t, p.rounds)
+}
+
+func TestPipeline_Committed(t *testing.T) {
+	validators := NewValStringStub([]NodeID{"A", "B", "C", "D"}, CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+
+	t.Run("No quorum", func(t *testing.T) {
+		p := newPipeline(1)
+		p.track(createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0)))
+		p.track(pipelinedCommit("A", ViewMsg(1, 0)))
+		p.track(pipelinedCommit("B", ViewMsg(1, 0)))
+		// a commit for a different hash does not count
+		commit := createMessage("C", MessageReq_Commit, ViewMsg(1, 0))
+		commit.Hash = digest1
+		p.track(commit)
+
+		assert.Nil(t, p.committed(1, validators, acceptSeal))
+	})
+
+	t.Run("Invalid seals", func(t *testing.T) {
+		p := newPipeline(1)
+		p.track(createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0)))
+		for _, from := range []NodeID{"A", "B", "C"} {
+			p.track(pipelinedCommit(from, ViewMsg(1, 0)))
+		}
+
+		assert.Nil(t, p.committed(1, validators, func(items []SealItem) []error {
+			return BatchVerifySeals(items, func(from NodeID, _ []byte, _ []byte) error {
+				if from == "C" {
+					return errors.New("invalid seal")
+				}
+				return nil
+			}, 1)
+		}))
+	})
+
+	t.Run("Quorum", func(t *testing.T) {
+		p := newPipeline(1)
+		p.track(createMessage("A", MessageReq_Preprepare, ViewMsg(1, 2)))
+		for _, from := range []NodeID{"C", "A", "B"} {
+			p.track(pipelinedCommit(from, ViewMsg(1, 2)))
+		}
+
+		pp := p.committed(1, validators, acceptSeal)
+		require.NotNil(t, pp)
+		assert.Equal(t, uint64(1), pp.Number)
+		assert.Equal(t, NodeID("A"), pp.Proposer)
+		assert.Equal(t, digest, pp.Proposal.Hash)
+		assert.Equal(t, mockProposal, pp.Proposal.Data)
+		require.Len(t, pp.CommittedSeals, 3)
+		assert.Equal(t, NodeID("A"), pp.CommittedSeals[0].NodeID)
+	})
+}
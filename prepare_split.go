@@ -0,0 +1,64 @@
+package pbft
+
+import "encoding/hex"
+
+// addConflictingPrepare records a prepare of the current view referencing another proposal than the accepted one.
+// It returns false if the message is not a prepare of the current view from a validator.
+func (s *state) addConflictingPrepare(msg *MessageReq) bool {
+	if msg.Type != MessageReq_Prepare || cmpView(msg.View, s.view) != 0 || !s.validators.Includes(msg.From) {
+		return false
+	}
+
+	s.msgLock.Lock()
+	defer s.msgLock.Unlock()
+
+	s.conflictingPrepares.addMessage(msg, s.validators.VotingPower()[msg.From])
+	return true
+}
+
+// preparesSplit returns whether the prepares of the current view are split across several proposals so that
+// none of them can reach the prepare quorum anymore, even with the voting power of the validators which did not
+// prepare yet. Waiting for the round timeout is then pointless.
+func (s *state) preparesSplit() bool {
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	if len(s.conflictingPrepares.messageMap) == 0 {
+		return false
+	}
+
+	votingPower := s.validators.VotingPower()
+	voted := map[NodeID]struct{}{}
+	proposals := map[string]uint64{}
+	for _, msgs := range []*messages{s.prepared, s.conflictingPrepares} {
+		for from, msg := range msgs.messageMap {
+			proposals[hex.EncodeToString(msg.Hash)] += votingPower[from]
+			voted[from] = struct{}{}
+		}
+	}
+
+	undecided := uint64(0)
+	for id, power := range votingPower {
+		if _, ok := voted[id]; !ok {
+			undecided += power
+		}
+	}
+	for _, power := range proposals {
+		if power+undecided >= s.getPrepareQuorumSize() {
+			return false
+		}
+	}
+	return true
+}
+
+// recoverPrepareSplit starts a round change if the prepares of the current view are split (see preparesSplit),
+// so that a single proposal can be proposed again in the next round. A locked node already reached the quorum.
+func (p *Pbft) recoverPrepareSplit() bool {
+	if p.state.IsLocked() || !p.state.preparesSplit() {
+		return false
+	}
+	p.logger.Printf("[WARN] prepares split across proposals, none can reach quorum: sequence=%d, round=%d",
+		p.state.view.Sequence, p.state.GetCurrentRound())
+	p.startRoundChange(RoundChange_PrepareSplit)
+	return true
+}
@@ -0,0 +1,56 @@
+package pbft
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newPrepareSplitNode(t *testing.T, prepares map[NodeID][]byte) *mockPbft {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.state.view = ViewMsg(1, 0)
+	m.state.proposal = &Proposal{Data: mockProposal, Hash: digest}
+	m.setState(ValidateState)
+
+	events := []Event{}
+	for _, from := range []NodeID{"A", "B", "C", "D"} {
+		if hash, ok := prepares[from]; ok {
+			msg := createMessage(from, MessageReq_Prepare, ViewMsg(1, 0))
+			msg.Hash = hash
+			events = append(events, MessageEvent(msg))
+		}
+	}
+	WithScheduler(NewDeterministicScheduler(events...))(m.config)
+	return m
+}
+
+// Test that prepares split half and half across two proposals start a round change right away.
+func TestTransition_ValidateState_PrepareSplit(t *testing.T) {
+	m := newPrepareSplitNode(t, map[NodeID][]byte{"A": digest, "B": digest, "C": digest1, "D": digest1})
+
+	// the split is reported by the vote summaries
+	m.runCycle(context.Background())
+	votes := m.ProposalsForCurrentSequence()
+	assert.Equal(t, uint64(2), votes[hex.EncodeToString(digest)].PrepareVotingPower)
+	assert.Equal(t, uint64(2), votes[hex.EncodeToString(digest1)].PrepareVotingPower)
+
+	assert.Equal(t, RoundChangeState, m.getState())
+	assert.Equal(t, RoundChange_PrepareSplit, m.LastRoundChangeReason())
+}
+
+// Test that a conflicting prepare does not start a round change while a proposal can still reach quorum.
+func TestTransition_ValidateState_PrepareSplit_Undecided(t *testing.T) {
+	m := newPrepareSplitNode(t, map[NodeID][]byte{"A": digest, "B": digest, "C": digest1})
+
+	m.runCycle(context.Background())
+
+	m.expect(expectResult{
+		sequence:               1,
+		state:                  ValidateState,
+		prepareMsgs:            2,
+		prepareMsgsVotingPower: 2,
+	})
+	assert.Equal(t, RoundChange_None, m.LastRoundChangeReason())
+}
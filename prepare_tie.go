@@ -0,0 +1,84 @@
+package pbft
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// PrepareTieResolution is the handling of a round in which another proposal reaches the prepare quorum as well as
+// the accepted one. It takes at least the voting power of a faulty proposer and of validators preparing both proposals.
+type PrepareTieResolution uint8
+
+const (
+	// PrepareTie_RoundChange commits none of the proposals and starts a round change right away
+	PrepareTie_RoundChange PrepareTieResolution = iota
+
+	// PrepareTie_LowerHash lets the proposal with the lower hash win: the node commits the accepted proposal
+	// if its hash is the lower one, and starts a round change otherwise, since it can not commit a proposal
+	// it did not accept
+	PrepareTie_LowerHash
+)
+
+func (r PrepareTieResolution) String() string {
+	switch r {
+	case PrepareTie_RoundChange:
+		return "RoundChange"
+	case PrepareTie_LowerHash:
+		return "LowerHash"
+	default:
+		return fmt.Sprintf("PrepareTieResolution(%d)", uint8(r))
+	}
+}
+
+// competingPrepareQuorum returns the lowest hash of the other proposals whose prepares of the current view reach
+// the prepare quorum, nil if there is none. A tie takes validators preparing both proposals: the other proposals
+// without such a validator, besides the node itself which only prepares the accepted proposal, are not considered.
+func (s *state) competingPrepareQuorum(self NodeID) []byte {
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	votingPower := s.validators.VotingPower()
+	proposals := map[string]uint64{}
+	equivocated := map[string]bool{}
+	for from, msg := range s.conflictingPrepares.messageMap {
+		if from == self {
+			continue
+		}
+		proposals[string(msg.Hash)] += votingPower[from]
+		if _, ok := s.prepared.messageMap[from]; ok {
+			equivocated[string(msg.Hash)] = true
+		}
+	}
+
+	var competing []byte
+	for hash, power := range proposals {
+		if power < s.getPrepareQuorumSize() || !equivocated[hash] {
+			continue
+		}
+		if competing == nil || bytes.Compare([]byte(hash), competing) < 0 {
+			competing = []byte(hash)
+		}
+	}
+	return competing
+}
+
+// resolvePrepareTie applies the PrepareTieResolution once the accepted proposal reaches the prepare quorum while
+// another one reached it as well. It returns true if the node leaves the validate state instead of committing.
+func (p *Pbft) resolvePrepareTie() bool {
+	if p.state.IsLocked() {
+		return false
+	}
+	competing := p.state.competingPrepareQuorum(p.validator.NodeID())
+	if competing == nil {
+		return false
+	}
+	if p.config.PrepareTieResolution == PrepareTie_LowerHash && bytes.Compare(p.state.proposal.Hash, competing) < 0 {
+		p.logger.Printf("[WARN] prepare quorum tie won by the accepted proposal: hash=%x, competing=%x",
+			p.state.proposal.Hash, competing)
+		return false
+	}
+	p.logger.Printf("[WARN] prepare quorum tie between proposals: sequence=%d, round=%d, hash=%x, competing=%x",
+		p.state.view.Sequence, p.state.GetCurrentRound(), p.state.proposal.Hash, competing)
+	p.startRoundChange(RoundChange_PrepareTie)
+	return true
+}
@@ -0,0 +1,69 @@
+package pbft
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that a tie on the prepare quorum power between two proposals is resolved as configured.
+// B and C prepare both proposals, so that each one reaches the prepare quorum.
+func TestTransition_ValidateState_PrepareTie(t *testing.T) {
+	lower, higher := digest, digest1
+	if bytes.Compare(lower, higher) > 0 {
+		lower, higher = higher, lower
+	}
+
+	cases := []struct {
+		name       string
+		resolution PrepareTieResolution
+		accepted   []byte
+		committed  bool
+	}{
+		{"round change", PrepareTie_RoundChange, lower, false},
+		{"lower hash accepted", PrepareTie_LowerHash, lower, true},
+		{"higher hash accepted", PrepareTie_LowerHash, higher, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			competing := lower
+			if bytes.Equal(c.accepted, lower) {
+				competing = higher
+			}
+
+			m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+			WithPrepareTieResolution(c.resolution)(m.config)
+			m.state.view = ViewMsg(1, 0)
+			m.state.proposal = &Proposal{Data: mockProposal, Hash: c.accepted}
+			m.setState(ValidateState)
+
+			events := []Event{}
+			prepare := func(from NodeID, hash []byte) {
+				msg := createMessage(from, MessageReq_Prepare, ViewMsg(1, 0))
+				msg.Hash = hash
+				events = append(events, MessageEvent(msg))
+			}
+			for _, from := range []NodeID{"B", "C", "D"} {
+				prepare(from, competing)
+			}
+			for _, from := range []NodeID{"A", "B", "C"} {
+				prepare(from, c.accepted)
+			}
+			WithScheduler(NewDeterministicScheduler(events...))(m.config)
+
+			m.runCycle(context.Background())
+
+			assert.Equal(t, c.committed, m.state.IsLocked())
+			if c.committed {
+				assert.Equal(t, RoundChange_None, m.LastRoundChangeReason())
+				assert.Len(t, m.respMsg, 1) // commit
+			} else {
+				assert.Equal(t, RoundChangeState, m.getState())
+				assert.Equal(t, RoundChange_PrepareTie, m.LastRoundChangeReason())
+				assert.Empty(t, m.respMsg)
+			}
+		})
+	}
+}
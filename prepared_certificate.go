@@ -0,0 +1,145 @@
+package pbft
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrInvalidCertificate is returned when a round change justification is not proven by a valid prepared certificate
+var ErrInvalidCertificate = errors.New("invalid prepared certificate")
+
+// prepareSealPrefix separates the content of the prepare seals from the one of the commit seals
+var prepareSealPrefix = []byte("pbft-prepare")
+
+// PreparedCertificate proves, with their prepare seals, that a quorum of validators prepared a proposal in a round.
+// It is carried by the justifications of the round change messages (see WithPreparedCertificates).
+type PreparedCertificate struct {
+	// Round is the round in which the proposal was prepared
+	Round uint64 `json:"round"`
+
+	// Hash is the hash of the prepared proposal
+	Hash []byte `json:"hash"`
+
+	// PrepareSeals are the seals of the prepare messages, sorted by NodeID
+	PrepareSeals []CommittedSeal `json:"prepareSeals"`
+}
+
+// Copy makes a copy of the PreparedCertificate
+func (c *PreparedCertificate) Copy() *PreparedCertificate {
+	cc := &PreparedCertificate{
+		Round:        c.Round,
+		Hash:         append([]byte{}, c.Hash...),
+		PrepareSeals: make([]CommittedSeal, 0, len(c.PrepareSeals)),
+	}
+	for _, seal := range c.PrepareSeals {
+		cc.PrepareSeals = append(cc.PrepareSeals, CommittedSeal{NodeID: seal.NodeID, Signature: append([]byte{}, seal.Signature...)})
+	}
+	return cc
+}
+
+// prepareSealHash returns the digest sealed by the prepare messages. It binds the round and the proposal hash
+// under a prefix of its own, so that a prepare seal can not be replayed as a commit seal.
+func prepareSealHash(round uint64, hash []byte) []byte {
+	var encodedRound [8]byte
+	binary.BigEndian.PutUint64(encodedRound[:], round)
+
+	h := sha256.New()
+	h.Write(prepareSealPrefix)
+	h.Write(encodedRound[:])
+	h.Write(hash)
+	return h.Sum(nil)
+}
+
+// sealPrepare seals the prepare message of the current proposal
+func (p *Pbft) sealPrepare(msg *MessageReq) error {
+	seal, err := p.validator.Sign(SignableContent(p.config.SealDomain, prepareSealHash(msg.View.Round, msg.Hash)))
+	if err != nil {
+		return err
+	}
+	msg.Seal, err = EncodeSeal(p.config.SealFormat, seal)
+	return err
+}
+
+// validatePrepareSeal validates the seal of the prepare message
+func (p *Pbft) validatePrepareSeal(msg *MessageReq) error {
+	return p.validateCommitSeal(msg.From, prepareSealHash(msg.View.Round, msg.Hash), msg.Seal)
+}
+
+// buildPreparedCertificate bundles the prepare seals of the prepare messages of the current proposal.
+// It fails if they do not reach the prepare quorum.
+func (p *Pbft) buildPreparedCertificate() (*PreparedCertificate, error) {
+	if p.state.proposal == nil {
+		return nil, errNoProposal
+	}
+	hash := p.state.proposal.Hash
+	votingPower := p.state.validators.VotingPower()
+
+	var seals []CommittedSeal
+	accumulatedVotingPower := uint64(0)
+	p.state.rangePrepared(func(from NodeID, msg *MessageReq) bool {
+		if bytes.Equal(msg.Hash, hash) && len(msg.Seal) != 0 {
+			seals = append(seals, CommittedSeal{NodeID: from, Signature: msg.Seal})
+			accumulatedVotingPower += votingPower[from]
+		}
+		return true
+	})
+	if accumulatedVotingPower < p.state.getPrepareQuorumSize() {
+		return nil, errInsufficientSeals
+	}
+	sort.Slice(seals, func(i, j int) bool { return seals[i].NodeID < seals[j].NodeID })
+
+	return &PreparedCertificate{
+		Round:        p.state.GetCurrentRound(),
+		Hash:         append([]byte{}, hash...),
+		PrepareSeals: seals,
+	}, nil
+}
+
+// verifyPreparedCertificate verifies that the justification is proven by its prepared certificate, namely that the
+// certificate prepare seals are valid seals of distinct validators reaching quorum voting power
+func (p *Pbft) verifyPreparedCertificate(justification *Justification) error {
+	certificate := justification.Certificate
+	if certificate == nil {
+		return fmt.Errorf("%w: missing", ErrInvalidCertificate)
+	}
+	if justification.Proposal == nil || certificate.Round != justification.Round || !bytes.Equal(certificate.Hash, justification.Proposal.Hash) {
+		return fmt.Errorf("%w: it does not match the justification", ErrInvalidCertificate)
+	}
+
+	// the prepare seals are verified as the seals of a finalization proof of the prepare seal digest
+	proof := &FinalizationProof{
+		Hash:           prepareSealHash(certificate.Round, certificate.Hash),
+		View:           ViewMsg(p.state.view.Sequence, certificate.Round),
+		CommittedSeals: certificate.PrepareSeals,
+	}
+	if err := VerifyFinalizationProof(proof, p.state.validators, p.validateCommitSeal, ProofSealing{}); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCertificate, err)
+	}
+	return nil
+}
+
+// addRoundChangeMsg adds the round change message to the state. With the prepared certificates enabled,
+// a justification must be proven by a valid prepared certificate, otherwise the message is rejected.
+// A message conflicting with the justification of a previous message of the sender for the same round is rejected.
+func (p *Pbft) addRoundChangeMsg(msg *MessageReq) error {
+	_, err := p.mergeRoundChangeMsg(msg)
+	return err
+}
+
+// mergeRoundChangeMsg adds the round change message to the state as addRoundChangeMsg does, and returns
+// the evidence recorded if the message conflicts with a previous message of the sender for the same round
+func (p *Pbft) mergeRoundChangeMsg(msg *MessageReq) (*ConflictingJustificationProof, error) {
+	if p.config.PreparedCertificates && msg.Justification != nil {
+		if err := p.verifyPreparedCertificate(msg.Justification); err != nil {
+			return nil, err
+		}
+	}
+	if proof, err := p.checkConflictingJustification(msg); err != nil {
+		return proof, err
+	}
+	return nil, p.state.addRoundChangeMsg(msg)
+}
@@ -0,0 +1,135 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCertificateMockPbft creates a node with the prepared certificates enabled, whose seals are verified
+// against the keys of the account pool
+func newCertificateMockPbft(t *testing.T) *mockPbft {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.pool.useMockSigners()
+	m.backend = &sealValidatorBackend{mockBackend: m.backend.(*mockBackend), verifier: m.pool.verifier()}
+	WithPreparedCertificates()(m.config)
+	m.state.view = ViewMsg(1, 0)
+	m.state.proposal = &Proposal{Data: mockProposal, Hash: digest}
+	return m
+}
+
+// sealedPrepare creates the prepare message of the node sealed with its key
+func sealedPrepare(t *testing.T, m *mockPbft, from NodeID, view *View) *MessageReq {
+	msg := createMessage(from, MessageReq_Prepare, view)
+	msg.Hash = digest
+	seal, err := m.pool.signer(from).Sign(prepareSealHash(view.Round, digest))
+	require.NoError(t, err)
+	msg.Seal = seal
+	return msg
+}
+
+func TestPbft_PreparedCertificate(t *testing.T) {
+	m := newCertificateMockPbft(t)
+	for _, from := range []NodeID{"A", "B"} {
+		require.NoError(t, m.state.addPrepareMsg(sealedPrepare(t, m, from, ViewMsg(1, 0))))
+	}
+	_, err := m.buildPreparedCertificate()
+	assert.ErrorIs(t, err, errInsufficientSeals)
+
+	require.NoError(t, m.state.addPrepareMsg(sealedPrepare(t, m, "C", ViewMsg(1, 0))))
+	certificate, err := m.buildPreparedCertificate()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), certificate.Round)
+	assert.Len(t, certificate.PrepareSeals, 3)
+
+	m.setRound(1)
+	roundChange := func(from NodeID, certificate *PreparedCertificate) *MessageReq {
+		msg := createMessage(from, MessageReq_RoundChange, ViewMsg(1, 1))
+		msg.Justification = &Justification{Round: 0, Proposal: m.state.proposal.Copy(), Certificate: certificate}
+		return msg
+	}
+
+	// a valid certificate proves the justification
+	assert.NoError(t, m.addRoundChangeMsg(roundChange("B", certificate)))
+
+	// a commit seal replayed as a prepare seal
+	forged := certificate.Copy()
+	commitSeal, err := m.pool.signer("C").Sign(digest)
+	require.NoError(t, err)
+	forged.PrepareSeals[2].Signature = commitSeal
+	assert.ErrorIs(t, m.addRoundChangeMsg(roundChange("C", forged)), ErrInvalidCertificate)
+
+	// prepare seals below the quorum
+	partial := certificate.Copy()
+	partial.PrepareSeals = partial.PrepareSeals[:2]
+	assert.ErrorIs(t, m.addRoundChangeMsg(roundChange("C", partial)), ErrInvalidCertificate)
+
+	// a certificate of another round
+	other := certificate.Copy()
+	other.Round = 1
+	assert.ErrorIs(t, m.addRoundChangeMsg(roundChange("C", other)), ErrInvalidCertificate)
+
+	// a justification without certificate
+	assert.ErrorIs(t, m.addRoundChangeMsg(roundChange("D", nil)), ErrInvalidCertificate)
+
+	// a round change without justification needs no certificate
+	assert.NoError(t, m.addRoundChangeMsg(createMessage("D", MessageReq_RoundChange, ViewMsg(1, 1))))
+	assert.Len(t, m.state.roundMessages[1].messageMap, 2)
+}
+
+func TestTransition_ValidateState_PreparedCertificate(t *testing.T) {
+	m := newCertificateMockPbft(t)
+	m.setState(ValidateState)
+
+	// an unsealed prepare is not counted
+	unsealed := createMessage("B", MessageReq_Prepare, ViewMsg(1, 0))
+	unsealed.Hash = digest
+	m.emitMsg(unsealed)
+	for _, from := range []NodeID{"B", "C", "D"} {
+		m.emitMsg(sealedPrepare(t, m, from, ViewMsg(1, 0)))
+	}
+
+	m.runCycle(context.Background())
+	require.True(t, m.state.IsLocked())
+	require.NotNil(t, m.preparedCertificate)
+
+	// the round change of the locked node carries the certificate, accepted by the other nodes
+	m.respMsg = nil
+	m.setRound(1)
+	m.sendRoundChange()
+	require.Len(t, m.respMsg, 1)
+	justification := m.respMsg[0].Justification
+	require.NotNil(t, justification)
+	require.NotNil(t, justification.Certificate)
+	assert.Len(t, justification.Certificate.PrepareSeals, 3)
+	assert.NoError(t, m.verifyPreparedCertificate(justification))
+}
+
+// Test that a round change rejected for its forged certificate does not stop the round change state.
+func TestTransition_RoundChangeState_ForgedCertificate(t *testing.T) {
+	m := newCertificateMockPbft(t)
+	m.setState(RoundChangeState)
+
+	// the first message of round 2 carries a certificate without seals
+	forged := createMessage("B", MessageReq_RoundChange, ViewMsg(1, 2))
+	forged.Justification = &Justification{
+		Round:       0,
+		Proposal:    m.state.proposal.Copy(),
+		Certificate: &PreparedCertificate{Round: 0, Hash: digest},
+	}
+	m.emitMsg(forged)
+	m.emitMsg(createMessage("C", MessageReq_RoundChange, ViewMsg(1, 2)))
+	m.emitMsg(createMessage("D", MessageReq_RoundChange, ViewMsg(1, 2)))
+
+	m.runCycle(context.Background())
+
+	m.expect(expectResult{
+		sequence: 1,
+		round:    2,
+		outgoing: 1, // our new round change
+		state:    AcceptState,
+	})
+	assert.NotContains(t, m.state.roundMessages[2].messageMap, NodeID("B"))
+}
@@ -0,0 +1,67 @@
+package pbft
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// preprepareRetransmitter flags the Preprepare of the proposer for retransmission at every interval
+type preprepareRetransmitter struct {
+	// due is set when a retransmission is due
+	due uint32
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// startPreprepareRetransmission starts retransmitting the Preprepare of the proposer, if enabled
+// (see WithPreprepareRetransmission). The ticks only wake up the state machine, which retransmits.
+func (p *Pbft) startPreprepareRetransmission() {
+	interval := p.config.PreprepareRetransmitInterval
+	if interval <= 0 || p.state.proposer != p.validator.NodeID() || p.state.proposal == nil {
+		return
+	}
+
+	r := &preprepareRetransmitter{stop: make(chan struct{}), done: make(chan struct{})}
+	p.retransmitter = r
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				atomic.StoreUint32(&r.due, 1)
+				select {
+				case p.updateCh <- struct{}{}:
+				default:
+				}
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// stopPreprepareRetransmission stops the retransmission of the Preprepare, if running
+func (p *Pbft) stopPreprepareRetransmission() {
+	r := p.retransmitter
+	if r == nil {
+		return
+	}
+	close(r.stop)
+	<-r.done
+	p.retransmitter = nil
+}
+
+// retransmitPreprepare gossips the Preprepare again if a retransmission is due. The message is the same
+// as the original one, so the receivers that got it already drop the copy (see WithSequenceDedup).
+func (p *Pbft) retransmitPreprepare() {
+	r := p.retransmitter
+	if r == nil || !atomic.CompareAndSwapUint32(&r.due, 1, 0) {
+		return
+	}
+	p.logger.Printf("[DEBUG] retransmitting preprepare: sequence=%d, round=%d", p.state.view.Sequence, p.state.view.Round)
+	p.sendPreprepareMsg()
+}
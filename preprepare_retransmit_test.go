@@ -0,0 +1,81 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that a lagging node that lost the Preprepare prepares on its retransmission
+// and that the proposer stops retransmitting once it observes the prepare quorum.
+func TestPbft_PreprepareRetransmission(t *testing.T) {
+	ids := []NodeID{"A", "B", "C", "D"}
+	proposer := newMockPbft(t, ids, nil, "A")
+	WithPreprepareRetransmission(5 * time.Millisecond)(proposer.config)
+	proposer.state.view = ViewMsg(1, 0)
+	proposer.state.proposer = "A"
+	proposer.state.timeoutChan = time.After(5 * time.Second)
+	proposer.setState(ValidateState)
+
+	sent := make(chan *MessageReq, 64)
+	proposer.gossipFn = func(msg *MessageReq) error {
+		sent <- msg
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		proposer.runCycle(context.Background())
+	}()
+	nextPreprepare := func() *MessageReq {
+		for {
+			select {
+			case msg := <-sent:
+				if msg.Type == MessageReq_Preprepare {
+					return msg
+				}
+			case <-time.After(time.Second):
+				require.FailNow(t, "no preprepare retransmitted")
+			}
+		}
+	}
+
+	// the first Preprepare is lost, the retransmission is identical
+	lost := nextPreprepare()
+	retransmitted := nextPreprepare()
+	assert.Equal(t, lost, retransmitted)
+
+	lagging := newMockPbft(t, ids, nil, "B")
+	lagging.state.view = ViewMsg(1, 0)
+	lagging.setState(AcceptState)
+	lagging.emitMsg(retransmitted.Copy())
+	lagging.runCycle(context.Background())
+	lagging.expect(expectResult{
+		sequence: 1,
+		state:    ValidateState,
+		outgoing: 1, // prepare
+	})
+
+	// the prepare quorum stops the retransmissions
+	for _, from := range []NodeID{"A", "C"} {
+		prepare := createMessage(from, MessageReq_Prepare, ViewMsg(1, 0))
+		prepare.Hash = retransmitted.Hash
+		proposer.emitMsg(prepare)
+	}
+	proposer.emitMsg(lagging.respMsg[0].Copy())
+	for msg := range sent {
+		if msg.Type == MessageReq_Commit {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	for len(sent) > 0 {
+		assert.NotEqual(t, MessageReq_Preprepare, (<-sent).Type)
+	}
+
+	proposer.Close()
+	<-done
+}
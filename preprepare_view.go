@@ -0,0 +1,38 @@
+package pbft
+
+// admitFuturePreprepare returns false if the message is a pre-prepare for a future round of the current sequence
+// while MaxFuturePreprepares of them are already buffered, the message is then dropped
+func (p *Pbft) admitFuturePreprepare(msg *MessageReq) bool {
+	if msg.Type != MessageReq_Preprepare || p.config.MaxFuturePreprepares <= 0 {
+		return true
+	}
+	current := p.state.CurrentView()
+	if msg.View.Sequence != current.Sequence || msg.View.Round <= current.Round {
+		return true
+	}
+	if p.msgQueue.futurePreprepares(&current) < p.config.MaxFuturePreprepares {
+		return true
+	}
+	p.logDrop(DropReasonFutureRound, "[DEBUG] pre-prepare for a future round dropped, buffer full: proposer=%s, view=%s",
+		msg.From, msg.View)
+	p.dropMessage(msg, DropReasonFutureRound)
+	return false
+}
+
+// checkPreprepareView returns whether the pre-prepare is for the current view and can be processed. A pre-prepare
+// of a past round is dropped, one of a future round is buffered again until the node reaches its round.
+func (p *Pbft) checkPreprepareView(msg *MessageReq) bool {
+	current := p.state.CurrentView()
+	switch cmpView(msg.View, &current) {
+	case -1:
+		p.logDrop(DropReasonStale, "[DEBUG] pre-prepare for a past view dropped: proposer=%s, view=%s, current=%s",
+			msg.From, msg.View, &current)
+		p.dropMessage(msg, DropReasonStale)
+		return false
+	case 1:
+		p.logger.Printf("[DEBUG] pre-prepare for a future view buffered: proposer=%s, view=%s", msg.From, msg.View)
+		p.msgQueue.pushMessage(msg)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPreprepareViewNode(t *testing.T, round uint64) *mockPbft {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.state.view = ViewMsg(1, round)
+	m.setState(AcceptState)
+	return m
+}
+
+func TestTransition_AcceptState_PreprepareView(t *testing.T) {
+	cases := []struct {
+		name     string
+		proposer NodeID
+		round    uint64
+		state    State
+	}{
+		{"stale", "A", 0, RoundChangeState},
+		{"current", "B", 1, ValidateState},
+		{"future", "C", 2, RoundChangeState},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := newPreprepareViewNode(t, 1)
+			m.emitMsg(createMessage(c.proposer, MessageReq_Preprepare, ViewMsg(1, c.round)))
+
+			m.runCycle(context.Background())
+
+			// only the pre-prepare of the current round is prepared
+			assert.Equal(t, c.state, m.getState())
+			if c.state == ValidateState {
+				require.Len(t, m.respMsg, 1)
+				assert.Equal(t, MessageReq_Prepare, m.respMsg[0].Type)
+			} else {
+				assert.Empty(t, m.respMsg)
+			}
+		})
+	}
+}
+
+// Test that a pre-prepare of a future round is processed once the node reaches the round.
+func TestTransition_AcceptState_FuturePreprepare(t *testing.T) {
+	m := newPreprepareViewNode(t, 1)
+	m.emitMsg(createMessage("C", MessageReq_Preprepare, ViewMsg(1, 2)))
+	m.runCycle(context.Background())
+	require.Equal(t, RoundChangeState, m.getState())
+
+	m.setRound(2)
+	m.setState(AcceptState)
+	m.runCycle(context.Background())
+
+	assert.Equal(t, ValidateState, m.getState())
+	require.Len(t, m.respMsg, 1)
+	assert.Equal(t, MessageReq_Prepare, m.respMsg[0].Type)
+}
+
+func TestPbft_MaxFuturePreprepares(t *testing.T) {
+	m := newPreprepareViewNode(t, 1)
+	WithMaxFuturePreprepares(1)(m.config)
+
+	m.emitMsg(createMessage("C", MessageReq_Preprepare, ViewMsg(1, 2)))
+	m.emitMsg(createMessage("D", MessageReq_Preprepare, ViewMsg(1, 3)))
+	// the pre-prepare of the current round is not bounded
+	m.emitMsg(createMessage("B", MessageReq_Preprepare, ViewMsg(1, 1)))
+
+	view := m.state.CurrentView()
+	assert.Equal(t, 1, m.msgQueue.futurePreprepares(&view))
+	assert.Len(t, m.msgQueue.acceptStateQueue, 2)
+}
+
+func TestPbft_CheckPreprepareView(t *testing.T) {
+	m := newPreprepareViewNode(t, 1)
+
+	assert.False(t, m.checkPreprepareView(createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0))))
+	assert.Empty(t, m.msgQueue.acceptStateQueue)
+
+	assert.True(t, m.checkPreprepareView(createMessage("B", MessageReq_Preprepare, ViewMsg(1, 1))))
+
+	// the pre-prepare of a future round is kept
+	assert.False(t, m.checkPreprepareView(createMessage("C", MessageReq_Preprepare, ViewMsg(1, 2))))
+	assert.Len(t, m.msgQueue.acceptStateQueue, 1)
+}
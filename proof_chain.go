@@ -0,0 +1,50 @@
+package pbft
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ErrBrokenProofChain is returned by VerifyProofChain when two consecutive proofs are not linked
+var ErrBrokenProofChain = errors.New("broken finalization proof chain")
+
+// VerifyProofChain verifies a chain of finalization proofs of consecutive sequences, as received during a bulk
+// sync from a trusted checkpoint. The proofs are verified with the given sealing, the first one against the given
+// validator set, the next ones against the NextValidators of the last proof setting them. The proofs must be linked:
+// the parent hash of every proof of a non nil proposal must be the hash of the last non nil proposal before it
+// in the chain.
+// It fails on the first invalid proof, the error carries its sequence. The parent hash and the next validators
+// are not sealed, so the application must bind them to the proposal hash (e.g. in the block header).
+func VerifyProofChain(proofs []*FinalizationProof, startValidators ValidatorSet, verifySeal SealVerifier, sealing ProofSealing) error {
+	if len(proofs) == 0 {
+		return errEmptyProof
+	}
+
+	validators := startValidators
+	var prevHash []byte
+	for i, proof := range proofs {
+		if proof == nil || proof.View == nil {
+			return fmt.Errorf("proof %d: %w", i, errEmptyProof)
+		}
+		sequence := proof.View.Sequence
+		if i > 0 && sequence != proofs[i-1].View.Sequence+1 {
+			return fmt.Errorf("proof of sequence %d: %w: follows sequence %d", sequence, ErrBrokenProofChain, proofs[i-1].View.Sequence)
+		}
+		nilProposal := bytes.Equal(proof.Hash, nilProposalHash[:])
+		if prevHash != nil && !nilProposal && !bytes.Equal(proof.ParentHash, prevHash) {
+			return fmt.Errorf("proof of sequence %d: %w: parent hash %x, previous hash %x", sequence, ErrBrokenProofChain, proof.ParentHash, prevHash)
+		}
+		if err := VerifyFinalizationProof(proof, validators, verifySeal, sealing); err != nil {
+			return fmt.Errorf("proof of sequence %d: %w", sequence, err)
+		}
+
+		if !nilProposal {
+			prevHash = proof.Hash
+		}
+		if proof.NextValidators != nil {
+			validators = proof.NextValidators
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package pbft
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildProofChain builds the proofs of the sequences 1 to n, sealed by the given signers
+func buildProofChain(t *testing.T, pool *testerAccountPool, n uint64, signers ...NodeID) []*FinalizationProof {
+	var proofs []*FinalizationProof
+	var parentHash []byte
+	for sequence := uint64(1); sequence <= n; sequence++ {
+		hash := sha256.Sum256([]byte{byte(sequence)})
+		proof := &FinalizationProof{Hash: hash[:], View: ViewMsg(sequence, 0), ParentHash: parentHash}
+		for _, id := range signers {
+			seal, err := pool.signer(id).Sign(hash[:])
+			require.NoError(t, err)
+			proof.CommittedSeals = append(proof.CommittedSeals, CommittedSeal{NodeID: id, Signature: seal})
+		}
+		proofs = append(proofs, proof)
+		parentHash = proof.Hash
+	}
+	return proofs
+}
+
+func TestVerifyProofChain(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D", "E", "F", "G"}))
+	pool.useMockSigners()
+	verifier := pool.verifier()
+	start := NewValStringStub([]NodeID{"A", "B", "C", "D"}, CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+
+	proofs := buildProofChain(t, pool, 3, "A", "B", "C")
+	require.NoError(t, VerifyProofChain(proofs, start, verifier.Verify, ProofSealing{}))
+
+	// the validator set changes after the second sequence
+	next := NewStaticValidatorSet(NewValStringStub([]NodeID{"E", "F", "G"}, CreateEqualVotingPowerMap([]NodeID{"E", "F", "G"})))
+	changed := buildProofChain(t, pool, 3, "A", "B", "C")
+	changed[1].NextValidators = next
+	third := buildProofChain(t, pool, 3, "E", "F", "G")[2]
+	changed[2].CommittedSeals = third.CommittedSeals
+	assert.NoError(t, VerifyProofChain(changed, start, verifier.Verify, ProofSealing{}))
+	// the old validators can not seal past the change
+	proofs[1].NextValidators = next
+	err := VerifyProofChain(proofs, start, verifier.Verify, ProofSealing{})
+	assert.ErrorIs(t, err, ErrNotValidator)
+	assert.Contains(t, err.Error(), "sequence 3")
+
+	// the next validators survive the binary encoding
+	data, err := changed[1].MarshalBinary()
+	require.NoError(t, err)
+	decoded := &FinalizationProof{}
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, changed[1], decoded)
+}
+
+func TestVerifyProofChain_BrokenLinkage(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+	pool.useMockSigners()
+	verifier := pool.verifier()
+
+	proofs := buildProofChain(t, pool, 4, "A", "B", "C")
+	proofs[2].ParentHash = digest
+	err := VerifyProofChain(proofs, pool.validatorSet(), verifier.Verify, ProofSealing{})
+	assert.ErrorIs(t, err, ErrBrokenProofChain)
+	assert.Contains(t, err.Error(), "sequence 3")
+
+	// a gap in the sequences breaks the chain
+	proofs = buildProofChain(t, pool, 4, "A", "B", "C")
+	err = VerifyProofChain(append(proofs[:1:1], proofs[2:]...), pool.validatorSet(), verifier.Verify, ProofSealing{})
+	assert.ErrorIs(t, err, ErrBrokenProofChain)
+	assert.Contains(t, err.Error(), "sequence 3")
+
+	// a nil proposal does not carry the parent hash
+	proofs = buildProofChain(t, pool, 3, "A", "B", "C")
+	proofs[1].Hash = nilProposalHash[:]
+	proofs[1].ParentHash = nil
+	for i, id := range []NodeID{"A", "B", "C"} {
+		seal, err := pool.signer(id).Sign(nilProposalHash[:])
+		require.NoError(t, err)
+		proofs[1].CommittedSeals[i].Signature = seal
+	}
+	proofs[2].ParentHash = proofs[0].Hash
+	assert.NoError(t, VerifyProofChain(proofs, pool.validatorSet(), verifier.Verify, ProofSealing{}))
+}
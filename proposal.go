@@ -2,11 +2,45 @@ package pbft
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"fmt"
 	"time"
 )
 
+type ProposalType uint32
+
+const (
+	// ProposalType_Block is the default proposal type for regular blocks
+	ProposalType_Block ProposalType = 0
+
+	// ProposalType_EpochChange is a proposal that changes the validator set for the next epoch
+	ProposalType_EpochChange ProposalType = 1
+
+	// ProposalType_Nil is the sentinel empty proposal committed to make progress when no valid proposal exists
+	ProposalType_Nil ProposalType = 2
+)
+
+// nilProposalHash is the well known hash of the nil proposal
+var nilProposalHash = sha256.Sum256([]byte("pbft-nil-proposal"))
+
+func (t ProposalType) String() string {
+	switch t {
+	case ProposalType_Block:
+		return "Block"
+	case ProposalType_EpochChange:
+		return "EpochChange"
+	case ProposalType_Nil:
+		return "Nil"
+	default:
+		return fmt.Sprintf("ProposalType(%d)", uint32(t))
+	}
+}
+
 // Proposal is the default proposal
 type Proposal struct {
+	// Type discriminates the payload carried in Data (block, epoch change...)
+	Type ProposalType
+
 	// Data is an arbitrary set of data to approve in consensus
 	Data []byte
 
@@ -15,6 +49,26 @@ type Proposal struct {
 
 	// Hash is the digest of the data to seal
 	Hash []byte
+
+	// ParentHash is the hash of the proposal finalized in the previous sequence (see WithParentHashVerification)
+	ParentHash []byte
+
+	// Metadata is the application context attached to the proposal (e.g. a block number, extra-data).
+	// It is covered by HashProposal, hence by the committed seals, and the ProposalValidator can inspect it.
+	Metadata map[string][]byte
+}
+
+// NilProposal creates the sentinel nil proposal, its Time is left for the caller to set
+func NilProposal() *Proposal {
+	return &Proposal{
+		Type: ProposalType_Nil,
+		Hash: append([]byte{}, nilProposalHash[:]...),
+	}
+}
+
+// IsNil returns whether the proposal is the sentinel nil proposal
+func (p *Proposal) IsNil() bool {
+	return p.Type == ProposalType_Nil
 }
 
 // Equal compares whether two proposals have the same hash
@@ -29,6 +83,10 @@ func (p *Proposal) Copy() *Proposal {
 
 	pp.Data = append([]byte{}, p.Data...)
 	pp.Hash = append([]byte{}, p.Hash...)
+	if p.ParentHash != nil {
+		pp.ParentHash = append([]byte{}, p.ParentHash...)
+	}
+	pp.Metadata = copyMetadata(p.Metadata)
 
 	return pp
 }
@@ -0,0 +1,106 @@
+package pbft
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ProposalBodyMismatchPolicy is the handling of a requested proposal body that does not match the pre-prepare hash
+type ProposalBodyMismatchPolicy uint8
+
+const (
+	// BodyMismatch_Drop drops the mismatched body and keeps waiting for the body requested to the proposer
+	// until the round times out
+	BodyMismatch_Drop ProposalBodyMismatchPolicy = iota
+
+	// BodyMismatch_Rerequest drops the mismatched body and requests a fresh copy to the next validator not asked yet,
+	// in the order of the validator set
+	BodyMismatch_Rerequest
+)
+
+func (b ProposalBodyMismatchPolicy) String() string {
+	switch b {
+	case BodyMismatch_Drop:
+		return "Drop"
+	case BodyMismatch_Rerequest:
+		return "Rerequest"
+	default:
+		return fmt.Sprintf("ProposalBodyMismatchPolicy(%d)", uint8(b))
+	}
+}
+
+// ProposalHashVerifier is an optional interface the Backend can implement to verify that the body of a proposal
+// hashes to its declared hash. It verifies the bodies received through the proposal relay when the engine has
+// no proposal hasher (see WithProposalHash).
+type ProposalHashVerifier interface {
+	VerifyProposalHash(proposal *Proposal) error
+}
+
+var (
+	errOtherProposal            = errors.New("proposal response for another proposal")
+	errMissingProposalBody      = errors.New("proposal response without the proposal body")
+	errUnverifiableProposalBody = errors.New("proposal body can not be verified without a proposal hasher or a ProposalHashVerifier")
+)
+
+// verifyProposalBody verifies that a proposal body received through the relay hashes to its declared hash, with
+// the proposal hasher or else the ProposalHashVerifier of the backend. A body that can not be verified is refused.
+func (p *Pbft) verifyProposalBody(proposal *Proposal) error {
+	if p.proposalHasher() != nil {
+		return p.verifyProposalHash(proposal)
+	}
+	if verifier, ok := p.backend.(ProposalHashVerifier); ok {
+		if err := verifier.VerifyProposalHash(proposal); err != nil {
+			return fmt.Errorf("%w: %v", ErrProposalHashMismatch, err)
+		}
+		return nil
+	}
+	return errUnverifiableProposalBody
+}
+
+// rejectProposalBody handles a proposal response that does not complete the pending pre-prepare. A response for
+// another proposal is ignored and a response without the body means that the peer lacks it, so the body is requested
+// to another peer. A body under the pre-prepare hash that does not hash to it has been tampered with: it is dropped
+// and, with BodyMismatch_Rerequest, the body is requested to another peer. The pre-prepare stays pending,
+// so the node never prepares on a body it could not verify.
+func (p *Pbft) rejectProposalBody(preprepare, resp *MessageReq, err error) {
+	switch {
+	case errors.Is(err, errOtherProposal):
+		p.logger.Printf("[DEBUG] proposal response from %s for another proposal ignored", resp.From)
+		return
+	case errors.Is(err, errMissingProposalBody):
+		p.logger.Printf("[DEBUG] proposal response from %s without the proposal body", resp.From)
+		p.rerequestProposalBody(preprepare)
+		return
+	case errors.Is(err, errUnverifiableProposalBody):
+		p.logger.Printf("[ERROR] proposal body from %s dropped: %v", resp.From, err)
+		return
+	}
+
+	p.logDrop(DropReasonBodyMismatch, "[WARN] proposal body from %s does not match the pre-prepare hash %x, potential attack",
+		resp.From, preprepare.Hash)
+	p.dropMessage(resp, DropReasonBodyMismatch)
+
+	if p.config.ProposalBodyMismatchPolicy == BodyMismatch_Rerequest {
+		p.rerequestProposalBody(preprepare)
+	}
+}
+
+// rerequestProposalBody requests the proposal body of the pre-prepare to the next validator not asked yet
+func (p *Pbft) rerequestProposalBody(preprepare *MessageReq) {
+	if peer, ok := p.nextBodyPeer(); ok {
+		p.requestProposalFrom(preprepare, peer)
+	} else {
+		p.logger.Printf("[WARN] no peer left to request the proposal body %x", preprepare.Hash)
+	}
+}
+
+// nextBodyPeer returns the first validator, in the order of the validator set, not asked yet for the proposal body
+func (p *Pbft) nextBodyPeer() (NodeID, bool) {
+	self := p.validator.NodeID()
+	for _, id := range validatorOrder(p.state.validators) {
+		if _, asked := p.bodyRequests[id]; !asked && id != self {
+			return id, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,177 @@
+package pbft
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that a proposal body not matching the pre-prepare hash is rejected and, with BodyMismatch_Rerequest,
+// requested to another peer before the node prepares on the verified body.
+func TestPbft_ProposalBodyMismatch(t *testing.T) {
+	run := func(policy ProposalBodyMismatchPolicy, events ...func(m *mockPbft) Event) (*mockPbft, *recordingMetrics) {
+		metrics := &recordingMetrics{}
+		m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+		WithProposalHash(HashProposal)(m.config)
+		WithProposalBodyMismatchPolicy(policy)(m.config)
+		WithMetrics(metrics)(m.config)
+		m.state.view = ViewMsg(1, 0)
+		m.setState(AcceptState)
+
+		schedule := []Event{}
+		for _, event := range events {
+			schedule = append(schedule, event(m))
+		}
+		WithScheduler(NewDeterministicScheduler(schedule...))(m.config)
+		m.runCycle(context.Background())
+		return m, metrics
+	}
+
+	hash := HashProposal(&Proposal{Data: mockProposal})
+	preprepare := func(*mockPbft) Event {
+		msg := createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0))
+		msg.ProposalTime = time.Now()
+		msg.Proposal = nil
+		msg.Hash = hash
+		return MessageEvent(msg)
+	}
+	response := func(from NodeID, body []byte) func(*mockPbft) Event {
+		return func(*mockPbft) Event {
+			msg := createMessage(from, MessageReq_ProposalResponse, ViewMsg(1, 0))
+			msg.Hash = hash
+			msg.Proposal = body
+			return MessageEvent(msg)
+		}
+	}
+	requestedTo := func(peers ...NodeID) func(m *mockPbft) Event {
+		return func(m *mockPbft) Event {
+			return CommandEvent(func(*Pbft) {
+				require.Len(t, m.respMsg, len(peers))
+				for _, msg := range m.respMsg {
+					assert.Equal(t, MessageReq_ProposalRequest, msg.Type)
+					assert.Equal(t, hash, msg.Hash)
+				}
+				assert.Equal(t, peers, m.sentTo)
+			})
+		}
+	}
+
+	t.Run("rerequest", func(t *testing.T) {
+		m, metrics := run(BodyMismatch_Rerequest,
+			preprepare,
+			// the tampered body of the proposer is rejected and requested to C (B is the local node)
+			response("A", mockProposal1),
+			requestedTo("A", "C"),
+			response("C", mockProposal),
+		)
+
+		m.expect(expectResult{
+			sequence: 1,
+			state:    ValidateState,
+			outgoing: 3, // two proposal requests and prepare
+		})
+		assert.Equal(t, MessageReq_Prepare, m.respMsg[2].Type)
+		assert.Equal(t, mockProposal, m.state.proposal.Data)
+		assert.Contains(t, metrics.records, "dropped ProposalResponse body_mismatch")
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		m, metrics := run(BodyMismatch_Drop,
+			preprepare,
+			response("A", mockProposal1),
+			requestedTo("A"),
+		)
+
+		// the pre-prepare stays pending, the node does not prepare
+		m.expect(expectResult{
+			sequence: 1,
+			state:    AcceptState,
+			outgoing: 1, // proposal request
+		})
+		assert.Equal(t, []string{"dropped ProposalResponse body_mismatch"}, metrics.records)
+	})
+
+	t.Run("missing body", func(t *testing.T) {
+		m, metrics := run(BodyMismatch_Drop,
+			preprepare,
+			// the proposer lacks the body, it is requested to C without counting a mismatch
+			response("A", nil),
+			requestedTo("A", "C"),
+			response("C", mockProposal),
+		)
+
+		m.expect(expectResult{
+			sequence: 1,
+			state:    ValidateState,
+			outgoing: 3, // two proposal requests and prepare
+		})
+		assert.Equal(t, mockProposal, m.state.proposal.Data)
+		assert.NotContains(t, metrics.records, "dropped ProposalResponse body_mismatch")
+	})
+}
+
+// hashVerifierBackend is a backend verifying the proposal hashes with the given function
+type hashVerifierBackend struct {
+	*mockBackend
+	verify func(proposal *Proposal) error
+}
+
+func (b *hashVerifierBackend) VerifyProposalHash(proposal *Proposal) error {
+	return b.verify(proposal)
+}
+
+// Test that a relayed proposal body is only accepted once verified by the proposal hasher or the backend.
+func TestPbft_ProposalBodyVerification(t *testing.T) {
+	run := func(setup func(m *mockPbft), body []byte) *mockPbft {
+		m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+		setup(m)
+		m.state.view = ViewMsg(1, 0)
+		m.setState(AcceptState)
+
+		preprepare := createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0))
+		preprepare.ProposalTime = time.Now()
+		preprepare.Proposal = nil
+		response := createMessage("A", MessageReq_ProposalResponse, ViewMsg(1, 0))
+		response.Hash = digest
+		response.Proposal = body
+		WithScheduler(NewDeterministicScheduler(MessageEvent(preprepare), MessageEvent(response)))(m.config)
+		m.runCycle(context.Background())
+		return m
+	}
+	verifyData := func(m *mockPbft) {
+		m.backend = &hashVerifierBackend{mockBackend: m.backend.(*mockBackend), verify: func(proposal *Proposal) error {
+			if !bytes.Equal(proposal.Data, mockProposal) {
+				return errVerificationFailed
+			}
+			return nil
+		}}
+	}
+
+	// without a proposal hasher nor a ProposalHashVerifier, the body can not be verified
+	m := run(func(*mockPbft) {}, mockProposal)
+	m.expect(expectResult{
+		sequence: 1,
+		state:    AcceptState,
+		outgoing: 1, // proposal request
+	})
+
+	// the backend rejects a tampered body
+	m = run(verifyData, mockProposal1)
+	m.expect(expectResult{
+		sequence: 1,
+		state:    AcceptState,
+		outgoing: 1, // proposal request
+	})
+
+	m = run(verifyData, mockProposal)
+	m.expect(expectResult{
+		sequence: 1,
+		state:    ValidateState,
+		outgoing: 2, // proposal request and prepare
+	})
+	assert.Equal(t, mockProposal, m.state.proposal.Data)
+}
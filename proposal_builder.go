@@ -0,0 +1,89 @@
+package pbft
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+)
+
+// errProposalBuildTimeout is the state error when the ProposalBuilder does not return before the round timeout
+var errProposalBuildTimeout = errors.New("proposal building exceeded the round timeout")
+
+// ProposalBuilder builds the proposals of the node when it is the proposer, in place of Backend.BuildProposal.
+// The context is cancelled when the round times out, so a slow builder leads to a round change instead of
+// stalling the round. The engine sets the Time and the Hash (see HashProposal and WithProposalHash) of the returned proposal.
+type ProposalBuilder interface {
+	Build(ctx context.Context, view View) (*Proposal, error)
+}
+
+// HashProposal returns the hash the engine sets on the proposals of a ProposalBuilder, the sha256 digest of the data.
+// When the proposal has a parent, it is the sha256 digest of the parent hash followed by the digest of the data.
+// When the proposal has metadata, the result is then hashed again followed by the digest of the metadata.
+// A proposal of another type than ProposalType_Block is finally hashed again followed by its type.
+func HashProposal(proposal *Proposal) []byte {
+	digest := sha256.Sum256(proposal.Data)
+	hash := digest[:]
+	if len(proposal.ParentHash) != 0 {
+		linked := sha256.Sum256(append(append([]byte{}, proposal.ParentHash...), hash...))
+		hash = linked[:]
+	}
+	if metadata := metadataDigest(proposal.Metadata); metadata != nil {
+		bound := sha256.Sum256(append(append([]byte{}, hash...), metadata...))
+		hash = bound[:]
+	}
+	if proposal.Type != ProposalType_Block {
+		typed := make([]byte, len(hash)+4)
+		copy(typed, hash)
+		binary.BigEndian.PutUint32(typed[len(hash):], uint32(proposal.Type))
+		bound := sha256.Sum256(typed)
+		hash = bound[:]
+	}
+	return hash
+}
+
+// buildProposal obtains the proposal for the current view from the ProposalBuilder, if configured, otherwise from the backend.
+// The builder runs until the round times out, then it is cancelled and abandoned, even if it ignores the cancellation.
+func (p *Pbft) buildProposal(ctx context.Context) (*Proposal, error) {
+	builder := p.config.ProposalBuilder
+	if builder == nil {
+		return p.backend.BuildProposal()
+	}
+
+	buildCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type buildResult struct {
+		proposal *Proposal
+		err      error
+	}
+	resultCh := make(chan buildResult, 1)
+	view := p.state.CurrentView()
+	go func() {
+		proposal, err := builder.Build(buildCtx, view)
+		resultCh <- buildResult{proposal, err}
+	}()
+
+	var result buildResult
+	select {
+	case result = <-resultCh:
+	case <-p.state.timeoutChan:
+		return nil, errProposalBuildTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+	if result.proposal == nil {
+		return nil, errors.New("proposal builder returned no proposal")
+	}
+
+	proposal := result.proposal
+	proposal.Time = p.config.Clock.Now()
+	if proposal.ParentHash == nil && p.lastFinalizedHash != nil {
+		proposal.ParentHash = append([]byte{}, p.lastFinalizedHash...)
+	}
+	proposal.Hash = p.proposalHasher()(proposal)
+	return proposal, nil
+}
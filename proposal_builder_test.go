@@ -0,0 +1,86 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type proposalBuilderFunc func(ctx context.Context, view View) (*Proposal, error)
+
+func (f proposalBuilderFunc) Build(ctx context.Context, view View) (*Proposal, error) {
+	return f(ctx, view)
+}
+
+func TestTransition_AcceptState_ProposalBuilder(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+
+	var builtView View
+	WithProposalBuilder(proposalBuilderFunc(func(ctx context.Context, view View) (*Proposal, error) {
+		builtView = view
+		return &Proposal{Data: mockProposal}, nil
+	}))(m.config)
+	m.setState(AcceptState)
+	m.runCycle(context.Background())
+
+	m.expect(expectResult{
+		sequence: 1,
+		outgoing: 2, // preprepare and prepare
+		state:    ValidateState,
+	})
+	assert.Equal(t, uint64(1), builtView.Sequence)
+
+	proposal := m.state.proposal
+	require.NotNil(t, proposal)
+	assert.False(t, proposal.Time.IsZero())
+	assert.Equal(t, HashProposal(proposal), proposal.Hash)
+}
+
+func TestTransition_AcceptState_ProposalBuilder_Timeout(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+
+	cancelled := make(chan error, 1)
+	WithProposalBuilder(proposalBuilderFunc(func(ctx context.Context, view View) (*Proposal, error) {
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+		return nil, ctx.Err()
+	}))(m.config)
+	m.setState(AcceptState)
+	m.state.timeoutChan = time.After(20 * time.Millisecond)
+	m.runCycle(context.Background())
+
+	// the slow builder leads to a round change and its context is cancelled
+	m.expect(expectResult{
+		sequence: 1,
+		state:    RoundChangeState,
+	})
+	select {
+	case err := <-cancelled:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("the builder context was not cancelled")
+	}
+}
+
+func TestTransition_AcceptState_ProposalBuilder_IgnoresCancellation(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+
+	release := make(chan struct{})
+	defer close(release)
+	WithProposalBuilder(proposalBuilderFunc(func(ctx context.Context, view View) (*Proposal, error) {
+		<-release
+		return &Proposal{Data: mockProposal}, nil
+	}))(m.config)
+	m.setState(AcceptState)
+	m.state.timeoutChan = time.After(20 * time.Millisecond)
+	m.runCycle(context.Background())
+
+	// the builder is abandoned, it does not stall the round
+	m.expect(expectResult{
+		sequence: 1,
+		state:    RoundChangeState,
+	})
+}
@@ -0,0 +1,40 @@
+package pbft
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ErrProposalHashMismatch is returned when the hash declared by a proposal is not the hash of its content
+var ErrProposalHashMismatch = errors.New("proposal hash does not match the proposal content")
+
+// ProposalHashFunc computes the hash of a proposal out of its content (see WithProposalHash)
+type ProposalHashFunc func(proposal *Proposal) []byte
+
+// proposalHasher returns the function computing the hash of the proposals: the configured ProposalHash, otherwise
+// HashProposal when the engine hashes the proposals of a ProposalBuilder. It returns nil when the backend owns
+// the proposal hash, which Backend.Validate must then verify.
+func (p *Pbft) proposalHasher() ProposalHashFunc {
+	if p.config.ProposalHash != nil {
+		return p.config.ProposalHash
+	}
+	if p.config.ProposalBuilder != nil {
+		return HashProposal
+	}
+	return nil
+}
+
+// verifyProposalHash recomputes the hash of the received proposal, so that the fields covered by the hash
+// (the data, the type, the parent and the metadata with HashProposal) can not be swapped under the hash the
+// validators seal. The nil proposal has its own well known hash (see validateNilProposal).
+func (p *Pbft) verifyProposalHash(proposal *Proposal) error {
+	hasher := p.proposalHasher()
+	if hasher == nil || proposal.IsNil() {
+		return nil
+	}
+	if hash := hasher(proposal); !bytes.Equal(hash, proposal.Hash) {
+		return fmt.Errorf("%w: declared %x, computed %x", ErrProposalHashMismatch, proposal.Hash, hash)
+	}
+	return nil
+}
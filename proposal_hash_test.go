@@ -0,0 +1,74 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashProposal_Type(t *testing.T) {
+	block := &Proposal{Data: mockProposal}
+	epochChange := &Proposal{Data: mockProposal, Type: ProposalType_EpochChange}
+
+	assert.NotEqual(t, HashProposal(block), HashProposal(epochChange))
+}
+
+func TestTransition_AcceptState_ProposalHashMismatch(t *testing.T) {
+	metadata := map[string][]byte{"number": {1}}
+	preprepare := func(tamper func(msg *MessageReq)) *MessageReq {
+		msg := createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0))
+		msg.ProposalMetadata = metadata
+		msg.Hash = HashProposal(&Proposal{Data: msg.Proposal, Metadata: metadata})
+		tamper(msg)
+		return msg
+	}
+
+	cases := []struct {
+		name   string
+		tamper func(msg *MessageReq)
+		state  State
+	}{
+		{"untampered", func(*MessageReq) {}, ValidateState},
+		{"metadata", func(msg *MessageReq) { msg.ProposalMetadata = map[string][]byte{"number": {2}} }, RoundChangeState},
+		{"type", func(msg *MessageReq) { msg.ProposalType = ProposalType_EpochChange }, RoundChangeState},
+		{"parent", func(msg *MessageReq) { msg.ProposalParentHash = digest1 }, RoundChangeState},
+		{"data", func(msg *MessageReq) { msg.Proposal = mockProposal1 }, RoundChangeState},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			validated := false
+			validatorIds := []NodeID{"A", "B", "C", "D"}
+			backend := newMockBackend(validatorIds, CreateEqualVotingPowerMap(validatorIds), nil).HookValidateHandler(func(*Proposal) error {
+				validated = true
+				return nil
+			})
+			m := newMockPbft(t, validatorIds, nil, "B", backend)
+			WithProposalHash(HashProposal)(m.config)
+			m.setState(AcceptState)
+
+			m.emitMsg(preprepare(c.tamper))
+			m.runCycle(context.Background())
+
+			assert.Equal(t, c.state, m.getState())
+			if c.state == RoundChangeState {
+				// the tampered proposal never reaches the backend, nor gets a vote
+				assert.False(t, validated)
+				assert.Equal(t, RoundChange_InvalidProposal, m.LastRoundChangeReason())
+			}
+		})
+	}
+}
+
+func TestPipeline_Committed_ProposalHashMismatch(t *testing.T) {
+	validators := NewValStringStub([]NodeID{"A", "B", "C", "D"}, CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+	p := newPipeline(1)
+	p.verifyHash = (&Pbft{config: &Config{ProposalHash: HashProposal}}).verifyProposalHash
+
+	// the proposal data does not match the committed hash
+	p.track(createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0)))
+	for _, from := range []NodeID{"A", "B", "C"} {
+		p.track(pipelinedCommit(from, ViewMsg(1, 0)))
+	}
+	assert.Nil(t, p.committed(1, validators, acceptSeal))
+}
@@ -0,0 +1,59 @@
+package pbft
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+)
+
+// metadataDigest returns the sha256 digest of the canonical encoding of the proposal metadata: the entries sorted
+// by key, each key and value prefixed with its length, so that distinct metadata can not encode to the same bytes.
+// It returns nil for empty metadata.
+func metadataDigest(metadata map[string][]byte) []byte {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	buf := make([]byte, binary.MaxVarintLen64)
+	for _, key := range keys {
+		value := metadata[key]
+		h.Write(buf[:binary.PutUvarint(buf, uint64(len(key)))])
+		h.Write([]byte(key))
+		h.Write(buf[:binary.PutUvarint(buf, uint64(len(value)))])
+		h.Write(value)
+	}
+	return h.Sum(nil)
+}
+
+// copyMetadata makes a deep copy of the proposal metadata
+func copyMetadata(metadata map[string][]byte) map[string][]byte {
+	if metadata == nil {
+		return nil
+	}
+	cp := make(map[string][]byte, len(metadata))
+	for key, value := range metadata {
+		cp[key] = append([]byte{}, value...)
+	}
+	return cp
+}
+
+// equalMetadata compares whether two proposal metadata have the same entries
+func equalMetadata(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		other, ok := b[key]
+		if !ok || string(value) != string(other) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashProposal_Metadata(t *testing.T) {
+	proposal := func(metadata map[string][]byte) *Proposal {
+		return &Proposal{Data: mockProposal, ParentHash: digest1, Metadata: metadata}
+	}
+
+	plain := HashProposal(proposal(nil))
+	assert.Equal(t, plain, HashProposal(proposal(map[string][]byte{})))
+
+	number1 := HashProposal(proposal(map[string][]byte{"number": {1}}))
+	number2 := HashProposal(proposal(map[string][]byte{"number": {2}}))
+	assert.NotEqual(t, plain, number1)
+	assert.NotEqual(t, number1, number2)
+	assert.Equal(t, number1, HashProposal(proposal(map[string][]byte{"number": {1}})))
+
+	// the entries are length prefixed, moving bytes between a key and its value changes the hash
+	assert.NotEqual(t,
+		HashProposal(proposal(map[string][]byte{"ab": []byte("c")})),
+		HashProposal(proposal(map[string][]byte{"a": []byte("bc")})))
+}
+
+func TestPbft_ProposalMetadata_Seal(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.pool.get("A").signFn = func(b []byte) ([]byte, error) {
+		return append([]byte("sig:"), b...), nil
+	}
+
+	seal := func(metadata map[string][]byte) []byte {
+		proposal := &Proposal{Data: mockProposal, Metadata: metadata}
+		proposal.Hash = HashProposal(proposal)
+		m.state.proposal = proposal
+		m.respMsg = nil
+		m.sendCommitMsg()
+		require.Len(t, m.respMsg, 1)
+		return m.respMsg[0].Seal
+	}
+
+	assert.NotEqual(t, seal(map[string][]byte{"number": {1}}), seal(map[string][]byte{"number": {2}}))
+}
+
+func TestTransition_AcceptState_ProposalMetadata(t *testing.T) {
+	metadata := map[string][]byte{"number": {7}, "extra": []byte("data")}
+
+	// the metadata of the built proposal is sent with the preprepare
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithProposalBuilder(proposalBuilderFunc(func(ctx context.Context, view View) (*Proposal, error) {
+		return &Proposal{Data: mockProposal, Metadata: metadata}, nil
+	}))(m.config)
+	m.setState(AcceptState)
+	m.runCycle(context.Background())
+
+	require.NotEmpty(t, m.respMsg)
+	preprepare := m.respMsg[0]
+	assert.Equal(t, MessageReq_Preprepare, preprepare.Type)
+	assert.Equal(t, metadata, preprepare.ProposalMetadata)
+	assert.Equal(t, HashProposal(&Proposal{Data: mockProposal, Metadata: metadata}), preprepare.Hash)
+
+	// the receiving validator can inspect it
+	var validated map[string][]byte
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	backend := newMockBackend(validatorIds, CreateEqualVotingPowerMap(validatorIds), nil).HookValidateHandler(func(p *Proposal) error {
+		validated = p.Metadata
+		return nil
+	})
+	r := newMockPbft(t, validatorIds, nil, "B", backend)
+	r.setState(AcceptState)
+	r.emitMsg(preprepare.Copy())
+	r.runCycle(context.Background())
+
+	assert.Equal(t, ValidateState, r.getState())
+	assert.Equal(t, metadata, validated)
+}
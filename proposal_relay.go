@@ -0,0 +1,128 @@
+package pbft
+
+import (
+	"bytes"
+	"sync"
+)
+
+// proposalRelay keeps the proposals known by the node for the current sequence,
+// so that it can serve them to the peers that received a Preprepare without the proposal body
+type proposalRelay struct {
+	lock sync.Mutex
+
+	// sequence is the sequence of the stored proposals
+	sequence uint64
+
+	// proposals are the known proposals of the sequence
+	proposals []*Proposal
+}
+
+// store records the proposal of the sequence, the proposals of the previous sequences are dropped
+func (r *proposalRelay) store(sequence uint64, proposal *Proposal) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if sequence != r.sequence {
+		r.sequence = sequence
+		r.proposals = nil
+	}
+	for _, known := range r.proposals {
+		if bytes.Equal(known.Hash, proposal.Hash) {
+			return
+		}
+	}
+	r.proposals = append(r.proposals, proposal.Copy())
+}
+
+// get returns the proposal of the sequence with the given hash, if known
+func (r *proposalRelay) get(sequence uint64, hash []byte) *Proposal {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if sequence != r.sequence {
+		return nil
+	}
+	for _, proposal := range r.proposals {
+		if bytes.Equal(proposal.Hash, hash) {
+			return proposal.Copy()
+		}
+	}
+	return nil
+}
+
+// missingProposal returns whether the Preprepare came without the proposal body
+func missingProposal(msg *MessageReq) bool {
+	return msg.Type == MessageReq_Preprepare && len(msg.Proposal) == 0 && msg.ProposalType != ProposalType_Nil
+}
+
+// requestProposal asks the sender of the Preprepare for the missing proposal body
+func (p *Pbft) requestProposal(preprepare *MessageReq) {
+	p.bodyRequests = map[NodeID]struct{}{}
+	p.requestProposalFrom(preprepare, preprepare.From)
+}
+
+// requestProposalFrom asks the given peer for the proposal body of the Preprepare
+func (p *Pbft) requestProposalFrom(preprepare *MessageReq, to NodeID) {
+	p.bodyRequests[to] = struct{}{}
+	req := &MessageReq{
+		Type: MessageReq_ProposalRequest,
+		From: p.validator.NodeID(),
+		View: preprepare.View.Copy(),
+		Hash: append([]byte{}, preprepare.Hash...),
+	}
+	if err := p.transport.Send(to, req); err != nil {
+		p.logger.Printf("[ERROR] failed to request proposal to %s: %v", to, err)
+	}
+}
+
+// serveProposal responds to a proposal request with the proposal of the requested hash, if known
+func (p *Pbft) serveProposal(req *MessageReq) {
+	if req.View == nil {
+		return
+	}
+	proposal := p.relay.get(req.View.Sequence, req.Hash)
+	if proposal == nil {
+		p.logger.Printf("[DEBUG] proposal requested by %s is unknown", req.From)
+		return
+	}
+
+	resp := &MessageReq{
+		Type:               MessageReq_ProposalResponse,
+		From:               p.validator.NodeID(),
+		View:               req.View.Copy(),
+		Hash:               proposal.Hash,
+		Proposal:           proposal.Data,
+		ProposalType:       proposal.Type,
+		ProposalTime:       proposal.Time,
+		ProposalParentHash: proposal.ParentHash,
+		ProposalMetadata:   proposal.Metadata,
+	}
+	if err := p.transport.Send(req.From, resp); err != nil {
+		p.logger.Printf("[ERROR] failed to send proposal to %s: %v", req.From, err)
+	}
+}
+
+// completePreprepare returns a copy of the Preprepare with the proposal body of the response.
+// It fails if the response does not carry the proposal of the Preprepare, including when the body
+// is not verified to hash to the Preprepare hash, so that a forged response does not discard the pending Preprepare.
+func (p *Pbft) completePreprepare(preprepare, resp *MessageReq) (*MessageReq, error) {
+	if !bytes.Equal(resp.Hash, preprepare.Hash) {
+		return nil, errOtherProposal
+	}
+	if len(resp.Proposal) == 0 {
+		return nil, errMissingProposalBody
+	}
+	msg := preprepare.Copy()
+	msg.SetProposal(resp.Proposal)
+	proposal := &Proposal{
+		Type:       msg.ProposalType,
+		Data:       msg.Proposal,
+		Hash:       msg.Hash,
+		ParentHash: msg.ProposalParentHash,
+		Metadata:   msg.ProposalMetadata,
+	}
+	if err := p.verifyProposalBody(proposal); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
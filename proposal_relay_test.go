@@ -0,0 +1,121 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that a proposal response whose body does not hash to the pre-prepare hash is dropped, keeping the pre-prepare pending.
+func TestPbft_ProposalRelay_ForgedProposalBody(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+	WithProposalHash(HashProposal)(m.config)
+	m.state.view = ViewMsg(1, 0)
+	m.setState(AcceptState)
+
+	hash := HashProposal(&Proposal{Data: mockProposal})
+	preprepare := createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0))
+	preprepare.ProposalTime = time.Now()
+	preprepare.Proposal = nil
+	preprepare.Hash = hash
+
+	response := func(body []byte) Event {
+		msg := createMessage("C", MessageReq_ProposalResponse, ViewMsg(1, 0))
+		msg.Hash = hash
+		msg.Proposal = body
+		return MessageEvent(msg)
+	}
+	WithScheduler(NewDeterministicScheduler(
+		MessageEvent(preprepare),
+		// a forged body under the pre-prepare hash is dropped
+		response(mockProposal1),
+		response(mockProposal),
+	))(m.config)
+
+	m.runCycle(context.Background())
+
+	m.expect(expectResult{
+		sequence: 1,
+		state:    ValidateState,
+		outgoing: 2, // proposal request and prepare
+	})
+	assert.Equal(t, mockProposal, m.state.proposal.Data)
+	assert.Equal(t, hash, m.state.proposal.Hash)
+}
+
+// Test that a node receiving a pre-prepare without the proposal body requests it to the proposer and votes once it arrives.
+func TestPbft_ProposalRelay_RecoverMissingProposal(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+	WithProposalHash(HashProposal)(m.config)
+	m.state.view = ViewMsg(1, 0)
+	m.setState(AcceptState)
+
+	hash := HashProposal(&Proposal{Data: mockProposal})
+	preprepare := createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0))
+	preprepare.ProposalTime = time.Now()
+	preprepare.Proposal = nil
+	preprepare.Hash = hash
+
+	response := func(from NodeID, hash []byte) Event {
+		msg := createMessage(from, MessageReq_ProposalResponse, ViewMsg(1, 0))
+		msg.Hash = hash
+		msg.Proposal = mockProposal
+		return MessageEvent(msg)
+	}
+	requested := CommandEvent(func(*Pbft) {
+		require.Len(t, m.respMsg, 1)
+		assert.Equal(t, MessageReq_ProposalRequest, m.respMsg[0].Type)
+		assert.Equal(t, hash, m.respMsg[0].Hash)
+		assert.Equal(t, []NodeID{"A"}, m.sentTo)
+	})
+	WithScheduler(NewDeterministicScheduler(
+		MessageEvent(preprepare),
+		requested,
+		// a response for another proposal is ignored
+		response("C", []byte("other")),
+		response("C", hash),
+	))(m.config)
+
+	m.runCycle(context.Background())
+
+	m.expect(expectResult{
+		sequence: 1,
+		state:    ValidateState,
+		outgoing: 2, // proposal request and prepare
+	})
+	assert.Equal(t, MessageReq_Prepare, m.respMsg[1].Type)
+	assert.Equal(t, mockProposal, m.state.proposal.Data)
+	assert.Equal(t, hash, m.state.proposal.Hash)
+}
+
+func TestPbft_ProposalRelay_ServeProposal(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.relay.store(1, &Proposal{Data: mockProposal, Hash: digest})
+
+	request := createMessage("B", MessageReq_ProposalRequest, ViewMsg(1, 0))
+	request.Hash = digest
+	m.PushMessage(request)
+
+	require.Len(t, m.respMsg, 1)
+	assert.Equal(t, []NodeID{"B"}, m.sentTo)
+	resp := m.respMsg[0]
+	assert.Equal(t, MessageReq_ProposalResponse, resp.Type)
+	assert.Equal(t, NodeID("A"), resp.From)
+	assert.Equal(t, mockProposal, resp.Proposal)
+	assert.Equal(t, digest, resp.Hash)
+
+	// unknown proposals and other sequences are not served
+	request = createMessage("B", MessageReq_ProposalRequest, ViewMsg(1, 0))
+	request.Hash = []byte("other")
+	m.PushMessage(request)
+	request = createMessage("B", MessageReq_ProposalRequest, ViewMsg(2, 0))
+	request.Hash = digest
+	m.PushMessage(request)
+	assert.Len(t, m.respMsg, 1)
+
+	// requests never reach the message queues
+	assert.Zero(t, m.msgQueue.acceptStateQueue.Len())
+}
@@ -0,0 +1,67 @@
+package pbft
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrProposalTimestamp is returned when the proposal time does not follow the time of the last finalized proposal
+var ErrProposalTimestamp = errors.New("proposal time does not follow the last finalized proposal")
+
+// ProposalTimePolicy is the validation of the proposal time against the time of the last finalized proposal
+type ProposalTimePolicy uint8
+
+const (
+	// ProposalTime_Unchecked does not compare the proposal time with the last finalized proposal
+	ProposalTime_Unchecked ProposalTimePolicy = iota
+
+	// ProposalTime_Lenient rejects the proposals older than the last finalized proposal, the equal times are allowed
+	ProposalTime_Lenient
+
+	// ProposalTime_Strict rejects the proposals whose time is not strictly greater than the last finalized proposal
+	ProposalTime_Strict
+)
+
+func (t ProposalTimePolicy) String() string {
+	switch t {
+	case ProposalTime_Unchecked:
+		return "Unchecked"
+	case ProposalTime_Lenient:
+		return "Lenient"
+	case ProposalTime_Strict:
+		return "Strict"
+	default:
+		return fmt.Sprintf("ProposalTimePolicy(%d)", uint8(t))
+	}
+}
+
+// checkProposalTime validates the proposal time against the time of the last finalized proposal, as set by the policy.
+// The nil proposals, the zero times, sent by older peers, and the proposals of the genesis sequence are not validated.
+func (p *Pbft) checkProposalTime(proposal *Proposal) error {
+	last := p.lastProposalTime
+	if proposal.IsNil() || proposal.Time.IsZero() || last.IsZero() || p.isGenesisSequence() {
+		return nil
+	}
+
+	switch p.config.ProposalTimePolicy {
+	case ProposalTime_Lenient:
+		if proposal.Time.Before(last) {
+			return fmt.Errorf("%w: %s is before %s", ErrProposalTimestamp, proposal.Time.Format(time.RFC3339Nano), last.Format(time.RFC3339Nano))
+		}
+	case ProposalTime_Strict:
+		if !proposal.Time.After(last) {
+			return fmt.Errorf("%w: %s is not after %s", ErrProposalTimestamp, proposal.Time.Format(time.RFC3339Nano), last.Format(time.RFC3339Nano))
+		}
+	}
+	return nil
+}
+
+// proposalFinalized stores the time and the hash of the finalized proposal, the nil proposals are skipped
+func (p *Pbft) proposalFinalized(proposal *Proposal) {
+	if proposal == nil || proposal.IsNil() {
+		return
+	}
+	p.lastProposalTime = proposal.Time
+	p.lastFinalizedHash = append([]byte{}, proposal.Hash...)
+}
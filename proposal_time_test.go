@@ -0,0 +1,91 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPbft_CheckProposalTime(t *testing.T) {
+	last := time.Now()
+
+	cases := []struct {
+		policy     ProposalTimePolicy
+		offset     time.Duration
+		shouldFail bool
+	}{
+		{ProposalTime_Unchecked, 0, false},
+		{ProposalTime_Unchecked, -time.Second, false},
+		{ProposalTime_Lenient, 0, false},
+		{ProposalTime_Lenient, -time.Second, true},
+		{ProposalTime_Lenient, time.Second, false},
+		{ProposalTime_Strict, 0, true},
+		{ProposalTime_Strict, -time.Second, true},
+		{ProposalTime_Strict, time.Second, false},
+	}
+	for _, c := range cases {
+		m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+		WithProposalTimePolicy(c.policy)(m.config)
+		m.proposalFinalized(&Proposal{Data: mockProposal, Time: last})
+
+		err := m.checkProposalTime(&Proposal{Data: mockProposal, Time: last.Add(c.offset)})
+		if c.shouldFail {
+			assert.ErrorIs(t, err, ErrProposalTimestamp, "%s %s", c.policy, c.offset)
+		} else {
+			assert.NoError(t, err, "%s %s", c.policy, c.offset)
+		}
+	}
+}
+
+func TestPbft_CheckProposalTime_Skipped(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithProposalTimePolicy(ProposalTime_Strict)(m.config)
+	now := time.Now()
+
+	// nothing finalized yet
+	assert.NoError(t, m.checkProposalTime(&Proposal{Data: mockProposal, Time: now}))
+
+	// the nil proposals are neither stored nor validated
+	m.proposalFinalized(&Proposal{Data: mockProposal, Time: now})
+	m.proposalFinalized(&Proposal{Type: ProposalType_Nil, Time: now.Add(time.Hour)})
+	assert.Equal(t, now, m.lastProposalTime)
+	assert.NoError(t, m.checkProposalTime(&Proposal{Type: ProposalType_Nil, Time: now}))
+
+	// zero times of older peers are not validated
+	assert.NoError(t, m.checkProposalTime(&Proposal{Data: mockProposal}))
+}
+
+// Test that the follower starts a round change when the proposal has the same time of the last finalized one under the strict policy.
+func TestTransition_AcceptState_ProposalTime_Strict(t *testing.T) {
+	now := time.Now()
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+	WithProposalTimePolicy(ProposalTime_Strict)(m.config)
+	m.proposalFinalized(&Proposal{Data: mockProposal, Time: now})
+	m.state.view = ViewMsg(1, 0)
+	m.setState(AcceptState)
+
+	preprepare := createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0))
+	preprepare.ProposalTime = now
+	m.emitMsg(preprepare)
+
+	m.runCycle(context.Background())
+
+	assert.Equal(t, RoundChangeState, m.getState())
+}
+
+// Test that the time of the proposal finalized in the commit state is stored.
+func TestTransition_CommitState_StoresProposalTime(t *testing.T) {
+	now := time.Now()
+	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
+	m.state.view = ViewMsg(1, 0)
+	m.state.proposer = "A"
+	m.state.proposal = &Proposal{Data: mockProposal, Hash: digest, Time: now}
+	m.setState(CommitState)
+
+	m.runCycle(context.Background())
+
+	assert.Equal(t, DoneState, m.getState())
+	assert.True(t, now.Equal(m.lastProposalTime))
+}
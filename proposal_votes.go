@@ -0,0 +1,75 @@
+package pbft
+
+import (
+	"encoding/hex"
+	"sort"
+)
+
+// ProposalVoteSummary is the prepare and commit voting power backing a proposal of the current sequence
+type ProposalVoteSummary struct {
+	// Hash is the hash of the proposal
+	Hash []byte
+
+	// PrepareVotingPower is the accumulated voting power of the prepare messages referencing the proposal
+	PrepareVotingPower uint64
+
+	// CommitVotingPower is the accumulated voting power of the commit messages referencing the proposal
+	CommitVotingPower uint64
+
+	// Prepares are the senders of the prepare messages, sorted
+	Prepares []NodeID
+
+	// Commits are the senders of the commit messages, sorted
+	Commits []NodeID
+}
+
+// proposalVotes summarizes the prepared and committed messages by the proposal hash they reference.
+// The accepted proposal is reported even if no message references it yet.
+func (s *state) proposalVotes() map[string]ProposalVoteSummary {
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	summaries := map[string]*ProposalVoteSummary{}
+	summary := func(hash []byte) *ProposalVoteSummary {
+		key := hex.EncodeToString(hash)
+		if _, ok := summaries[key]; !ok {
+			summaries[key] = &ProposalVoteSummary{Hash: append([]byte{}, hash...)}
+		}
+		return summaries[key]
+	}
+
+	if s.proposal != nil && s.proposal.Hash != nil {
+		summary(s.proposal.Hash)
+	}
+
+	votingPower := s.validators.VotingPower()
+	for nodeId, msg := range s.prepared.messageMap {
+		vote := summary(msg.Hash)
+		vote.PrepareVotingPower += votingPower[nodeId]
+		vote.Prepares = append(vote.Prepares, nodeId)
+	}
+	for nodeId, msg := range s.conflictingPrepares.messageMap {
+		vote := summary(msg.Hash)
+		vote.PrepareVotingPower += votingPower[nodeId]
+		vote.Prepares = append(vote.Prepares, nodeId)
+	}
+	for nodeId, msg := range s.committed.messageMap {
+		vote := summary(msg.Hash)
+		vote.CommitVotingPower += votingPower[nodeId]
+		vote.Commits = append(vote.Commits, nodeId)
+	}
+
+	result := make(map[string]ProposalVoteSummary, len(summaries))
+	for key, vote := range summaries {
+		sort.Slice(vote.Prepares, func(i, j int) bool { return vote.Prepares[i] < vote.Prepares[j] })
+		sort.Slice(vote.Commits, func(i, j int) bool { return vote.Commits[i] < vote.Commits[j] })
+		result[key] = *vote
+	}
+	return result
+}
+
+// ProposalsForCurrentSequence returns the proposals seen in the current sequence, keyed by the hex encoded hash,
+// with the voting power backing each of them. More than one entry reveals competing proposals of a faulty proposer.
+func (p *Pbft) ProposalsForCurrentSequence() map[string]ProposalVoteSummary {
+	return p.state.proposalVotes()
+}
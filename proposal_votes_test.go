@@ -0,0 +1,54 @@
+package pbft
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPbft_ProposalsForCurrentSequence(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.state.view = ViewMsg(1, 0)
+
+	// the accepted proposal is reported before any vote
+	assert.Equal(t, map[string]ProposalVoteSummary{
+		hex.EncodeToString(digest): {Hash: digest},
+	}, m.ProposalsForCurrentSequence())
+
+	otherDigest := []byte{0x2}
+	add := func(from NodeID, typ MsgType, hash []byte) {
+		msg := createMessage(from, typ, ViewMsg(1, 0))
+		msg.Hash = hash
+		require.NoError(t, m.state.addMessage(msg))
+	}
+	add("A", MessageReq_Prepare, digest)
+	add("B", MessageReq_Prepare, digest)
+	add("C", MessageReq_Prepare, otherDigest)
+	add("A", MessageReq_Commit, digest)
+	add("D", MessageReq_Commit, otherDigest)
+	add("C", MessageReq_Commit, otherDigest)
+
+	proposals := m.ProposalsForCurrentSequence()
+	require.Len(t, proposals, 2)
+	assert.Equal(t, ProposalVoteSummary{
+		Hash:               digest,
+		PrepareVotingPower: 2,
+		CommitVotingPower:  1,
+		Prepares:           []NodeID{"A", "B"},
+		Commits:            []NodeID{"A"},
+	}, proposals[hex.EncodeToString(digest)])
+	assert.Equal(t, ProposalVoteSummary{
+		Hash:               otherDigest,
+		PrepareVotingPower: 1,
+		CommitVotingPower:  2,
+		Prepares:           []NodeID{"C"},
+		Commits:            []NodeID{"C", "D"},
+	}, proposals[hex.EncodeToString(otherDigest)])
+
+	// the summaries are copies
+	proposals[hex.EncodeToString(digest)].Hash[0] ^= 0xff
+	_, ok := m.ProposalsForCurrentSequence()[hex.EncodeToString(digest)]
+	assert.True(t, ok)
+}
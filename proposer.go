@@ -0,0 +1,230 @@
+package pbft
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"sync"
+)
+
+// ValidatorSetProposerSelector is the default ProposerSelector which delegates to the ValidatorSet round robin
+type ValidatorSetProposerSelector struct{}
+
+// CalcProposer implements ProposerSelector interface
+func (ValidatorSetProposerSelector) CalcProposer(validators ValidatorSet, _ []byte, round uint64) NodeID {
+	return validators.CalcProposer(round)
+}
+
+// SeededProposerSelector is a round robin ProposerSelector whose base index is derived from the per-sequence seed,
+// so the round 0 proposer shifts at every sequence while staying deterministic across nodes
+type SeededProposerSelector struct{}
+
+// CalcProposer implements ProposerSelector interface
+func (SeededProposerSelector) CalcProposer(validators ValidatorSet, seed []byte, round uint64) NodeID {
+	ids := sortedValidatorIds(validators)
+	if len(ids) == 0 {
+		return ""
+	}
+	digest := sha256.Sum256(seed)
+	offset := binary.BigEndian.Uint64(digest[:8])
+
+	return ids[(offset+round)%uint64(len(ids))]
+}
+
+// WeightedProposerSelector picks the proposer with a probability proportional to its voting power.
+// The pick is a pure function of the seed and the round, so it is verifiable by all the nodes.
+// In order to be resistant to stake grinding, the seed should be derived with NextProposerSeed,
+// which chains the previous seed with the committed seals of a quorum of validators.
+type WeightedProposerSelector struct{}
+
+// CalcProposer implements ProposerSelector interface
+func (WeightedProposerSelector) CalcProposer(validators ValidatorSet, seed []byte, round uint64) NodeID {
+	ids := sortedValidatorIds(validators)
+	votingPower := validators.VotingPower()
+
+	totalVotingPower := uint64(0)
+	for _, id := range ids {
+		totalVotingPower += votingPower[id]
+	}
+	if totalVotingPower == 0 {
+		return ""
+	}
+
+	roundBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(roundBytes, round)
+	digest := sha256.Sum256(append(append([]byte{}, seed...), roundBytes...))
+	return pickWeighted(ids, votingPower, totalVotingPower, digest[:])
+}
+
+// ChainSeededProposerSelector picks the proposer with a probability proportional to its voting power, out of a seed
+// derived from the chain (see ChainSeed), the round and the validator set. Since the pick is a pure function of
+// on-chain data, anyone can verify the proposer afterwards.
+type ChainSeededProposerSelector struct{}
+
+// CalcProposer implements ProposerSelector interface
+func (ChainSeededProposerSelector) CalcProposer(validators ValidatorSet, seed []byte, round uint64) NodeID {
+	ids := sortedValidatorIds(validators)
+	votingPower := validators.VotingPower()
+
+	h := sha256.New()
+	h.Write(seed)
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, round)
+	h.Write(buf)
+
+	totalVotingPower := uint64(0)
+	for _, id := range ids {
+		totalVotingPower += votingPower[id]
+		binary.BigEndian.PutUint64(buf, uint64(len(id)))
+		h.Write(buf)
+		h.Write([]byte(id))
+		binary.BigEndian.PutUint64(buf, votingPower[id])
+		h.Write(buf)
+	}
+	if totalVotingPower == 0 {
+		return ""
+	}
+	return pickWeighted(ids, votingPower, totalVotingPower, h.Sum(nil))
+}
+
+// ChainSeed returns the seed of the ChainSeededProposerSelector for the given height out of the hash of the previous block
+func ChainSeed(prevBlockHash []byte, height uint64) []byte {
+	heightBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(heightBytes, height)
+	digest := sha256.Sum256(append(append([]byte{}, prevBlockHash...), heightBytes...))
+	return digest[:]
+}
+
+// pickWeighted maps the digest to one of the ids, with a probability proportional to its voting power
+func pickWeighted(ids []NodeID, votingPower map[NodeID]uint64, totalVotingPower uint64, digest []byte) NodeID {
+	pick := binary.BigEndian.Uint64(digest[:8]) % totalVotingPower
+	for _, id := range ids {
+		if pick < votingPower[id] {
+			return id
+		}
+		pick -= votingPower[id]
+	}
+	return ids[len(ids)-1]
+}
+
+// NextProposerSeed derives the proposer seed of the next sequence from the previous seed and the finalization proof.
+// Since the committed seals are produced by a quorum of validators, no single validator controls the resulting seed.
+func NextProposerSeed(prevSeed []byte, proof *FinalizationProof) []byte {
+	seals := append([]CommittedSeal{}, proof.CommittedSeals...)
+	sort.Slice(seals, func(i, j int) bool {
+		return seals[i].NodeID < seals[j].NodeID
+	})
+
+	h := sha256.New()
+	h.Write(prevSeed)
+	h.Write(proof.Hash)
+	for _, seal := range seals {
+		h.Write([]byte(seal.NodeID))
+		h.Write(seal.Signature)
+	}
+	return h.Sum(nil)
+}
+
+// sortedValidatorIds returns the validator ids in a deterministic (lexicographical) order
+func sortedValidatorIds(validators ValidatorSet) []NodeID {
+	votingPower := validators.VotingPower()
+	ids := make([]NodeID, 0, len(votingPower))
+	for id := range votingPower {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// proposerPenalties counts the rounds each proposer failed to get its proposal committed
+type proposerPenalties struct {
+	lock     sync.Mutex
+	failures map[NodeID]uint64
+}
+
+func newProposerPenalties() *proposerPenalties {
+	return &proposerPenalties{
+		failures: map[NodeID]uint64{},
+	}
+}
+
+// penalize increments the failure counter of the given proposer
+func (pp *proposerPenalties) penalize(proposer NodeID) {
+	pp.lock.Lock()
+	defer pp.lock.Unlock()
+	pp.failures[proposer]++
+}
+
+// reset clears all the failure counters
+func (pp *proposerPenalties) reset() {
+	pp.lock.Lock()
+	defer pp.lock.Unlock()
+	pp.failures = map[NodeID]uint64{}
+}
+
+// snapshot returns a copy of the failure counters
+func (pp *proposerPenalties) snapshot() map[NodeID]uint64 {
+	pp.lock.Lock()
+	defer pp.lock.Unlock()
+
+	failures := make(map[NodeID]uint64, len(pp.failures))
+	for id, count := range pp.failures {
+		failures[id] = count
+	}
+	return failures
+}
+
+// FailedProposers returns, per proposer, the number of rounds that were abandoned
+// without committing its proposal in the current sequence
+func (p *Pbft) FailedProposers() map[NodeID]uint64 {
+	return p.penalties.snapshot()
+}
+
+// penalizeRoundProposer records as failed the proposers of the rounds the node moves past, from the current round
+// up to nextRound (excluded), so that the proposers of the rounds skipped by a round jump are penalized too.
+// At most one rotation (the size of the validator set) of rounds is penalized: the rounds beyond it can not be
+// attributed to their proposers, and a round far ahead does not turn into as many iterations.
+func (p *Pbft) penalizeRoundProposer(nextRound uint64) {
+	currentRound := p.state.GetCurrentRound()
+	if nextRound <= currentRound || p.state.validators == nil {
+		return
+	}
+	rounds := nextRound - currentRound
+	if size := uint64(p.state.validators.Len()); rounds > size {
+		rounds = size
+	}
+	for round := currentRound; round < currentRound+rounds; round++ {
+		p.penalties.penalize(p.calcProposer(round))
+	}
+}
+
+// calcProposer calculates the proposer of the given round in the current sequence, skipping the evicted proposers
+func (p *Pbft) calcProposer(round uint64) NodeID {
+	if p.proposerOverride != nil {
+		return p.proposerOverride(&View{Sequence: p.state.view.Sequence, Round: round})
+	}
+	validators := p.state.validators
+	if p.eviction != nil {
+		validators = p.eviction.eligible(validators, p.state.view.Sequence)
+	}
+	return selectProposer(p.config.ProposerSelector, validators, p.proposerScores, p.proposerSeed, round)
+}
+
+// selectProposer calculates the proposer with the selector, weighted by the scores if the selector supports them
+func selectProposer(selector ProposerSelector, validators ValidatorSet, scores map[NodeID]uint64, seed []byte, round uint64) NodeID {
+	if scored, ok := selector.(ScoredProposerSelector); ok && scores != nil {
+		return scored.CalcScoredProposer(validators, scores, seed, round)
+	}
+	return selector.CalcProposer(validators, seed, round)
+}
+
+// ProposerFor returns the node expected to propose in the given view.
+// Only views of the current sequence can be calculated, an empty NodeID is returned otherwise.
+func (p *Pbft) ProposerFor(view *View) NodeID {
+	if view == nil || p.state.view == nil || view.Sequence != p.state.view.Sequence {
+		return ""
+	}
+	return p.calcProposer(view.Round)
+}
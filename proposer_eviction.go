@@ -0,0 +1,120 @@
+package pbft
+
+import (
+	"sort"
+	"sync"
+)
+
+// proposerEviction tracks the consecutive rounds each proposer failed to get its proposal committed and evicts
+// from the proposer rotation the ones reaching the threshold, for a cooldown of sequences. It is fed with the rounds
+// of the finalized sequences only, so that the nodes finalizing the same sequences agree on the evicted proposers.
+type proposerEviction struct {
+	lock sync.Mutex
+
+	threshold uint64
+	cooldown  uint64
+
+	// failures are the consecutive failures of each proposer
+	failures map[NodeID]uint64
+
+	// evicted are the evicted proposers with the last sequence of their eviction
+	evicted map[NodeID]uint64
+}
+
+func newProposerEviction(threshold, cooldown uint64) *proposerEviction {
+	return &proposerEviction{
+		threshold: threshold,
+		cooldown:  cooldown,
+		failures:  map[NodeID]uint64{},
+		evicted:   map[NodeID]uint64{},
+	}
+}
+
+// finalized records the proposers of the rounds of the finalized sequence: the ones of the rounds before
+// the finalized round failed, the one of the finalized round succeeded
+func (e *proposerEviction) finalized(sequence uint64, failed []NodeID, succeeded NodeID) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	for id, until := range e.evicted {
+		if until <= sequence {
+			delete(e.evicted, id)
+		}
+	}
+	for _, id := range failed {
+		e.failures[id]++
+		if e.failures[id] >= e.threshold {
+			e.evicted[id] = sequence + e.cooldown
+			delete(e.failures, id)
+		}
+	}
+	delete(e.failures, succeeded)
+}
+
+// isEvicted returns whether the proposer is evicted in the given sequence
+func (e *proposerEviction) isEvicted(id NodeID, sequence uint64) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	until, ok := e.evicted[id]
+	return ok && sequence <= until
+}
+
+// eligible returns the validator set without the proposers evicted in the given sequence.
+// The whole set is returned if no proposer is evicted, or if all of them are.
+func (e *proposerEviction) eligible(validators ValidatorSet, sequence uint64) ValidatorSet {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	evicted := 0
+	for id, until := range e.evicted {
+		if sequence <= until && validators.Includes(id) {
+			evicted++
+		}
+	}
+	if evicted == 0 || evicted == validators.Len() {
+		return validators
+	}
+
+	set := NewStaticValidatorSet(validators)
+	nodes := make([]NodeID, 0, len(set.Nodes)-evicted)
+	for _, id := range set.Nodes {
+		if until, ok := e.evicted[id]; ok && sequence <= until {
+			delete(set.VotingPowerMap, id)
+			continue
+		}
+		nodes = append(nodes, id)
+	}
+	set.Nodes = nodes
+	return set
+}
+
+// recordProposerEviction feeds the proposer eviction, if enabled, with the proposers of the sequence finalized
+// in the given round
+func (p *Pbft) recordProposerEviction(sequence, round uint64) {
+	if p.eviction == nil || p.state.validators == nil {
+		return
+	}
+	failed := make([]NodeID, 0, round)
+	for r := uint64(0); r < round; r++ {
+		failed = append(failed, p.calcProposer(r))
+	}
+	p.eviction.finalized(sequence, failed, p.calcProposer(round))
+}
+
+// EvictedProposers returns the proposers skipped in the proposer rotation of the current sequence, sorted by id.
+// It is always empty unless the proposer eviction is enabled (see WithProposerEviction).
+func (p *Pbft) EvictedProposers() []NodeID {
+	ids := []NodeID{}
+	if p.eviction == nil || p.state.validators == nil {
+		return ids
+	}
+	sequence := p.state.GetSequence()
+	for id := range p.state.validators.VotingPower() {
+		if p.eviction.isEvicted(id, sequence) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,49 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPbft_ProposerEviction(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithProposerEviction(2, 3)(m.config)
+	m.eviction = newProposerEviction(2, 3)
+
+	// A, the round 0 proposer, fails and the sequence is finalized in round 1
+	m.state.view = ViewMsg(1, 0)
+	failing := m.calcProposer(0)
+	assert.Equal(t, NodeID("A"), failing)
+	m.recordProposerEviction(1, 1)
+	assert.Empty(t, m.EvictedProposers())
+
+	// a success of the proposer resets its consecutive failures
+	m.state.view = ViewMsg(2, 0)
+	m.recordProposerEviction(2, 0)
+	m.state.view = ViewMsg(3, 0)
+	m.recordProposerEviction(3, 1)
+	assert.Empty(t, m.EvictedProposers())
+
+	// the second consecutive failure evicts A, the rotation skips it
+	m.state.view = ViewMsg(4, 0)
+	m.recordProposerEviction(4, 1)
+	m.state.view = ViewMsg(5, 0)
+	assert.Equal(t, []NodeID{"A"}, m.EvictedProposers())
+	for round := uint64(0); round < 6; round++ {
+		assert.NotEqual(t, failing, m.calcProposer(round))
+	}
+	assert.Equal(t, []NodeID{"B", "C", "D"}, []NodeID{m.calcProposer(0), m.calcProposer(1), m.calcProposer(2)})
+
+	// the votes of the evicted proposer still count
+	assert.True(t, m.state.validators.Includes(failing))
+	assert.Equal(t, uint64(3), m.state.getQuorumSize())
+
+	// the eviction ends with the cooldown
+	m.recordProposerEviction(5, 0)
+	m.state.view = ViewMsg(7, 0)
+	m.recordProposerEviction(7, 0)
+	m.state.view = ViewMsg(8, 0)
+	assert.Empty(t, m.EvictedProposers())
+	assert.Equal(t, failing, m.calcProposer(0))
+}
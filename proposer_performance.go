@@ -0,0 +1,64 @@
+package pbft
+
+import "crypto/sha256"
+
+// PerformanceProposerSelector favors the higher performing validators as proposers: the round 0 proposer is
+// picked with a probability proportional to the voting power times the performance score of the validator.
+// The scores are provided by a ProposerScoresBackend, without them the pick is weighted by the voting power only.
+//
+// In order to keep the liveness, every validator keeps at least the MinScore, so that it still gets proposer
+// slots, and the later rounds rotate in a round robin from the round 0 proposer, so that a failing high score
+// proposer is replaced and every validator gets a turn within a full rotation.
+type PerformanceProposerSelector struct {
+	// MinScore is the score of the validators without a score or with a lower one. It defaults to 1.
+	MinScore uint64
+}
+
+// CalcProposer implements ProposerSelector interface
+func (s PerformanceProposerSelector) CalcProposer(validators ValidatorSet, seed []byte, round uint64) NodeID {
+	return s.CalcScoredProposer(validators, map[NodeID]uint64{}, seed, round)
+}
+
+// CalcScoredProposer implements ScoredProposerSelector interface
+func (s PerformanceProposerSelector) CalcScoredProposer(validators ValidatorSet, scores map[NodeID]uint64, seed []byte, round uint64) NodeID {
+	ids := sortedValidatorIds(validators)
+	votingPower := validators.VotingPower()
+
+	minScore := s.MinScore
+	if minScore == 0 {
+		minScore = 1
+	}
+	weights := make(map[NodeID]uint64, len(ids))
+	totalWeight := uint64(0)
+	for _, id := range ids {
+		score := scores[id]
+		if score < minScore {
+			score = minScore
+		}
+		weights[id] = votingPower[id] * score
+		totalWeight += weights[id]
+	}
+	if totalWeight == 0 {
+		return ""
+	}
+
+	digest := sha256.Sum256(seed)
+	first := pickWeighted(ids, weights, totalWeight, digest[:])
+	for i, id := range ids {
+		if id == first {
+			return ids[(uint64(i)+round)%uint64(len(ids))]
+		}
+	}
+	return first
+}
+
+// ParticipationScores derives the performance scores of the validators out of their participation records,
+// namely the number of finalized sequences they committed. The records must be derived from on-chain data
+// (e.g. the committed seals of the finalized proposals) for the scores to be agreed by all the nodes.
+func ParticipationScores(records map[NodeID]ParticipationRecord) map[NodeID]uint64 {
+	scores := make(map[NodeID]uint64, len(records))
+	for id, record := range records {
+		scores[id] = record.Commits
+	}
+	return scores
+}
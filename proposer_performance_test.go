@@ -0,0 +1,88 @@
+package pbft
+
+import (
+	"crypto/sha256"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPerformanceProposerSelector_FavorsHigherScores(t *testing.T) {
+	ids := []NodeID{"A", "B", "C", "D"}
+	validators := NewValStringStub(ids, CreateEqualVotingPowerMap(ids))
+	scores := map[NodeID]uint64{"A": 1, "B": 1, "C": 2, "D": 6}
+	selector := PerformanceProposerSelector{}
+
+	const sequences = 10000
+	selected := map[NodeID]int{}
+	for i := 0; i < sequences; i++ {
+		seed := sha256.Sum256([]byte(strconv.Itoa(i)))
+		proposer := selector.CalcScoredProposer(validators, scores, seed[:], 0)
+		// the selection is deterministic for the same scores, seed and round
+		require.Equal(t, proposer, selector.CalcScoredProposer(validators, scores, seed[:], 0))
+		selected[proposer]++
+	}
+	for id, score := range scores {
+		assert.InDelta(t, float64(score)/10, float64(selected[id])/sequences, 0.02, "validator %s", id)
+	}
+
+	// the later rounds rotate over every validator
+	seen := map[NodeID]struct{}{}
+	for round := uint64(0); round < uint64(len(ids)); round++ {
+		seen[selector.CalcScoredProposer(validators, scores, []byte{0x1}, round)] = struct{}{}
+	}
+	assert.Len(t, seen, len(ids))
+
+	// the validators below the minimum score keep proposing
+	selected = map[NodeID]int{}
+	for i := 0; i < 1000; i++ {
+		seed := sha256.Sum256([]byte(strconv.Itoa(i)))
+		selected[PerformanceProposerSelector{MinScore: 2}.CalcScoredProposer(validators, map[NodeID]uint64{"D": 6}, seed[:], 0)]++
+	}
+	assert.Len(t, selected, 4)
+}
+
+// scoresBackend provides the performance scores of the validators
+type scoresBackend struct {
+	*mockBackend
+	scores map[NodeID]uint64
+}
+
+func (b *scoresBackend) ProposerScores() map[NodeID]uint64 {
+	return b.scores
+}
+
+func TestPbft_ProposerScores(t *testing.T) {
+	ids := []NodeID{"A", "B", "C", "D"}
+	votingPowerMap := CreateEqualVotingPowerMap(ids)
+	// only D performs, A, B and C are at the minimum score
+	scores := ParticipationScores(map[NodeID]ParticipationRecord{"D": {Sequences: 100, Commits: 100}})
+
+	newNode := func(account NodeID) (*mockPbft, *scoresBackend) {
+		backend := newMockBackend(ids, votingPowerMap, nil)
+		m := newMockPbft(t, ids, votingPowerMap, account, backend)
+		WithProposerSelector(PerformanceProposerSelector{})(m.config)
+		return m, &scoresBackend{mockBackend: backend, scores: scores}
+	}
+	nodeA, backendA := newNode("A")
+	nodeB, backendB := newNode("B")
+
+	selected := map[NodeID]int{}
+	for sequence := uint64(1); sequence <= 50; sequence++ {
+		seed := sha256.Sum256([]byte(strconv.Itoa(int(sequence))))
+		var proposers []NodeID
+		for i, node := range []*mockPbft{nodeA, nodeB} {
+			backend := []*scoresBackend{backendA, backendB}[i]
+			backend.seed = seed[:]
+			node.sequence = sequence
+			require.NoError(t, node.SetBackend(backend))
+			proposers = append(proposers, node.ProposerFor(ViewMsg(sequence, 0)))
+		}
+		// all nodes agree on the proposer
+		assert.Equal(t, proposers[0], proposers[1])
+		selected[proposers[0]]++
+	}
+	assert.Greater(t, selected["D"], 40)
+}
@@ -0,0 +1,31 @@
+package pbft
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// EpochProposerSeed returns the proposer seed of the sequence out of the seed of its epoch. A backend providing it
+// as ProposerSeed (see ProposerSeedBackend) gets a proposer schedule that can be computed ahead with ProposerSchedule.
+func EpochProposerSeed(epochSeed []byte, sequence uint64) []byte {
+	sequenceBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(sequenceBytes, sequence)
+	digest := sha256.Sum256(append(append([]byte{}, epochSeed...), sequenceBytes...))
+	return digest[:]
+}
+
+// ProposerSchedule returns the round 0 proposer of the count sequences from startSeq, as picked by the configured
+// ProposerSelector with the seeds derived by EpochProposerSeed out of the epoch seed. A nil epoch seed stands for
+// a backend without a ProposerSeedBackend. The performance scores and the proposer overrides are not known ahead,
+// so the schedule only matches a live engine not using them.
+func (p *Pbft) ProposerSchedule(epochSeed []byte, startSeq, count uint64, validators ValidatorSet) []NodeID {
+	schedule := make([]NodeID, 0, count)
+	for sequence := startSeq; sequence < startSeq+count; sequence++ {
+		var seed []byte
+		if epochSeed != nil {
+			seed = EpochProposerSeed(epochSeed, sequence)
+		}
+		schedule = append(schedule, selectProposer(p.config.ProposerSelector, validators, nil, seed, 0))
+	}
+	return schedule
+}
@@ -0,0 +1,41 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProposerSchedule_MatchesEngine(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D", "E"}
+	votingPowerMap := map[NodeID]uint64{"A": 10, "B": 20, "C": 30, "D": 25, "E": 15}
+	epochSeed := []byte("epoch 3")
+
+	for _, selector := range []ProposerSelector{SeededProposerSelector{}, WeightedProposerSelector{}, ChainSeededProposerSelector{}} {
+		backend := newMockBackend(validatorIds, votingPowerMap, nil)
+		m := newMockPbft(t, validatorIds, votingPowerMap, "A", backend)
+		WithProposerSelector(selector)(m.config)
+
+		schedule := m.ProposerSchedule(epochSeed, 30, 10, NewValStringStub(validatorIds, votingPowerMap))
+		require.Len(t, schedule, 10)
+
+		for i, proposer := range schedule {
+			sequence := uint64(30 + i)
+			backend.seed = EpochProposerSeed(epochSeed, sequence)
+			m.sequence = sequence
+			require.NoError(t, m.SetBackend(backend))
+			assert.Equal(t, m.ProposerFor(ViewMsg(sequence, 0)), proposer, "%T at sequence %d", selector, sequence)
+		}
+	}
+}
+
+func TestProposerSchedule_NoEpochSeed(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	m := newMockPbft(t, validatorIds, nil, "A")
+
+	// without a seed, the default selector picks the round 0 proposer of the validator set at every sequence
+	schedule := m.ProposerSchedule(nil, 1, 3, NewValStringStub(validatorIds, CreateEqualVotingPowerMap(validatorIds)))
+	assert.Equal(t, []NodeID{"A", "A", "A"}, schedule)
+	assert.Equal(t, m.ProposerFor(ViewMsg(1, 0)), schedule[0])
+}
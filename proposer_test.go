@@ -0,0 +1,161 @@
+package pbft
+
+import (
+	"context"
+	"crypto/sha256"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeededProposerSelector_RotatesAcrossSequences(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D", "E"}
+	votingPowerMap := CreateEqualVotingPowerMap(validatorIds)
+
+	newNode := func(account NodeID) *mockPbft {
+		backend := newMockBackend(validatorIds, votingPowerMap, nil)
+		m := newMockPbft(t, validatorIds, votingPowerMap, account, backend)
+		WithProposerSelector(SeededProposerSelector{})(m.config)
+		return m
+	}
+	nodeA, nodeB := newNode("A"), newNode("B")
+
+	proposers := map[NodeID]struct{}{}
+	for sequence := uint64(1); sequence <= 20; sequence++ {
+		seed := sha256.Sum256([]byte(strconv.Itoa(int(sequence))))
+		var selected []NodeID
+		for _, node := range []*mockPbft{nodeA, nodeB} {
+			backend := node.backend.(*mockBackend)
+			backend.seed = seed[:]
+			node.sequence = sequence
+			require.NoError(t, node.SetBackend(backend))
+			selected = append(selected, node.ProposerFor(ViewMsg(sequence, 0)))
+		}
+		// all nodes agree on the proposer
+		assert.Equal(t, selected[0], selected[1])
+		proposers[selected[0]] = struct{}{}
+	}
+	// round 0 proposer differs across sequences
+	assert.Greater(t, len(proposers), 1)
+}
+
+func TestSeededProposerSelector_RoundRobin(t *testing.T) {
+	validators := NewValStringStub([]NodeID{"C", "A", "B"}, CreateEqualVotingPowerMap([]NodeID{"C", "A", "B"}))
+	selector := SeededProposerSelector{}
+	seed := []byte{0x1}
+
+	first := selector.CalcProposer(validators, seed, 0)
+	seen := map[NodeID]struct{}{first: {}}
+	for round := uint64(1); round < 3; round++ {
+		seen[selector.CalcProposer(validators, seed, round)] = struct{}{}
+	}
+	// every validator gets a turn within a full rotation
+	assert.Len(t, seen, 3)
+	assert.Equal(t, first, selector.CalcProposer(validators, seed, 3))
+}
+
+func TestWeightedProposerSelector_TracksVotingPower(t *testing.T) {
+	votingPowerMap := map[NodeID]uint64{"A": 10, "B": 20, "C": 30, "D": 40}
+	validators := NewValStringStub([]NodeID{"A", "B", "C", "D"}, votingPowerMap)
+	selector := WeightedProposerSelector{}
+
+	const sequences = 20000
+	seed := []byte("genesis")
+	selected := map[NodeID]int{}
+	for i := 0; i < sequences; i++ {
+		proposer := selector.CalcProposer(validators, seed, 0)
+		// the selection is deterministic for the same seed and round
+		assert.Equal(t, proposer, selector.CalcProposer(validators, seed, 0))
+		selected[proposer]++
+
+		seed = NextProposerSeed(seed, &FinalizationProof{
+			Hash:           seed,
+			CommittedSeals: []CommittedSeal{{NodeID: proposer, Signature: seed}},
+		})
+	}
+
+	for id, votingPower := range votingPowerMap {
+		expected := float64(votingPower) / 100
+		actual := float64(selected[id]) / sequences
+		assert.InDelta(t, expected, actual, 0.02, "validator %s", id)
+	}
+}
+
+func TestChainSeededProposerSelector_Deterministic(t *testing.T) {
+	votingPowerMap := map[NodeID]uint64{"A": 10, "B": 20, "C": 30, "D": 40}
+	ids := []NodeID{"A", "B", "C", "D"}
+	reversedIds := []NodeID{"D", "C", "B", "A"}
+
+	// independent selectors and validator sets agree on every proposer
+	selected := map[NodeID]int{}
+	for height := uint64(1); height <= 1000; height++ {
+		seed := ChainSeed(digest, height)
+		for round := uint64(0); round < 3; round++ {
+			proposer := ChainSeededProposerSelector{}.CalcProposer(NewValStringStub(ids, votingPowerMap), seed, round)
+			other := ChainSeededProposerSelector{}.CalcProposer(NewValStringStub(reversedIds, votingPowerMap), ChainSeed(digest, height), round)
+			require.Equal(t, proposer, other)
+			selected[proposer]++
+		}
+	}
+	// every validator gets picked, the heavier ones more often
+	assert.Len(t, selected, 4)
+	assert.Greater(t, selected["D"], selected["A"])
+
+	// the selection depends on the validator set
+	seed := ChainSeed(digest, 1)
+	changed := false
+	for round := uint64(0); round < 20 && !changed; round++ {
+		changed = ChainSeededProposerSelector{}.CalcProposer(NewValStringStub(ids, votingPowerMap), seed, round) !=
+			ChainSeededProposerSelector{}.CalcProposer(NewValStringStub(ids, map[NodeID]uint64{"A": 40, "B": 30, "C": 20, "D": 10}), seed, round)
+	}
+	assert.True(t, changed)
+	assert.NotEqual(t, ChainSeed(digest, 1), ChainSeed(digest, 2))
+}
+
+func TestNextProposerSeed_IndependentOfSealsOrder(t *testing.T) {
+	seals := []CommittedSeal{{NodeID: "A", Signature: []byte{0x1}}, {NodeID: "B", Signature: []byte{0x2}}}
+	reversed := []CommittedSeal{seals[1], seals[0]}
+
+	seed := NextProposerSeed([]byte{0x0}, &FinalizationProof{Hash: digest, CommittedSeals: seals})
+	assert.Equal(t, seed, NextProposerSeed([]byte{0x0}, &FinalizationProof{Hash: digest, CommittedSeals: reversed}))
+	assert.NotEqual(t, seed, NextProposerSeed([]byte{0x1}, &FinalizationProof{Hash: digest, CommittedSeals: seals}))
+}
+
+func TestPbft_ProposerFor(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
+
+	assert.Equal(t, NodeID("A"), m.ProposerFor(ViewMsg(1, 0)))
+	assert.Equal(t, NodeID("C"), m.ProposerFor(ViewMsg(1, 2)))
+
+	// views of other sequences can not be calculated
+	assert.Equal(t, NodeID(""), m.ProposerFor(ViewMsg(2, 0)))
+	assert.Equal(t, NodeID(""), m.ProposerFor(nil))
+}
+
+func TestTransition_AcceptState_ProposerOverride(t *testing.T) {
+	newNode := func() *mockPbft {
+		m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "B")
+		m.state.view = ViewMsg(1, 0)
+		m.setState(AcceptState)
+		return m
+	}
+
+	// without override, A is the proposer and the preprepare from C is ignored
+	m := newNode()
+	m.emitMsg(createMessage("C", MessageReq_Preprepare, ViewMsg(1, 0)))
+	m.runCycle(context.Background())
+	assert.Equal(t, RoundChangeState, m.getState())
+
+	// the override forces C as proposer and its preprepare is accepted
+	m = newNode()
+	m.proposerOverride = func(view *View) NodeID {
+		return "C"
+	}
+	assert.Equal(t, NodeID("C"), m.ProposerFor(ViewMsg(1, 0)))
+	m.emitMsg(createMessage("C", MessageReq_Preprepare, ViewMsg(1, 0)))
+	m.runCycle(context.Background())
+	assert.Equal(t, ValidateState, m.getState())
+	assert.Equal(t, NodeID("C"), m.state.proposer)
+}
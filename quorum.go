@@ -0,0 +1,55 @@
+package pbft
+
+import (
+	"sort"
+	"strconv"
+)
+
+// ComputeQuorum returns the quorum size and the max faulty voting power of a hypothetical validator set, without
+// constructing an engine. Without a power map, the validatorsCount validators have the same voting power.
+// When the config sets a CommitQuorum, the quorum is the commit quorum it calculates (a nil config is allowed).
+// An invalid validator set (i.e. no voting power) yields zero values.
+func ComputeQuorum(config *Config, validatorsCount uint, powerMap map[NodeID]uint64) (quorum, maxFaulty uint64) {
+	if powerMap == nil {
+		powerMap = make(map[NodeID]uint64, validatorsCount)
+		for i := uint(0); i < validatorsCount; i++ {
+			powerMap[NodeID(strconv.FormatUint(uint64(i), 10))] = 1
+		}
+	}
+	ids := make([]NodeID, 0, len(powerMap))
+	for id := range powerMap {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	s := newState()
+	s.validators = NewValStringStub(ids, powerMap)
+	if err := s.initializeVotingInfo(); err != nil {
+		return 0, 0
+	}
+	if config != nil {
+		if err := s.initializePhaseQuorums(config.PrepareQuorum, config.CommitQuorum); err != nil {
+			return 0, 0
+		}
+	}
+	return s.getCommitQuorumSize(), s.getMaxFaultyVotingPower()
+}
+
+// QuorumRow is the max faulty nodes and the quorum size of a validator set of equal voting power
+type QuorumRow struct {
+	Nodes      uint
+	MaxFaulty  uint64
+	QuorumSize uint64
+}
+
+// QuorumTable returns the max faulty nodes and the quorum size of the validator sets of equal voting power
+// from 1 up to maxNodes validators. Since the max faulty nodes is floor((n-1)/3), the quorum only changes
+// every three validators (i.e. 4, 5 and 6 validators tolerate 1 faulty node).
+func QuorumTable(maxNodes uint) []QuorumRow {
+	rows := make([]QuorumRow, 0, maxNodes)
+	for nodes := uint(1); nodes <= maxNodes; nodes++ {
+		quorum, maxFaulty := ComputeQuorum(nil, nodes, nil)
+		rows = append(rows, QuorumRow{Nodes: nodes, MaxFaulty: maxFaulty, QuorumSize: quorum})
+	}
+	return rows
+}
@@ -0,0 +1,83 @@
+package pbft
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeQuorum(t *testing.T) {
+	cases := []map[NodeID]uint64{
+		{"A": 1},
+		{"A": 1, "B": 1, "C": 1, "D": 1},
+		{"A": 1, "B": 1, "C": 1, "D": 1, "E": 1, "F": 1, "G": 1},
+		{"A": 10, "B": 20, "C": 30, "D": 40},
+		{"A": 1, "B": 100},
+	}
+	for _, powerMap := range cases {
+		maxFaulty, quorum, err := CalculateQuorum(powerMap)
+		assert.NoError(t, err)
+
+		actualQuorum, actualMaxFaulty := ComputeQuorum(DefaultConfig(), uint(len(powerMap)), powerMap)
+		assert.Equal(t, quorum, actualQuorum, "%v", powerMap)
+		assert.Equal(t, maxFaulty, actualMaxFaulty, "%v", powerMap)
+
+		// the quorum matches the one of an engine with the same validators
+		ids := []NodeID{}
+		for id := range powerMap {
+			ids = append(ids, id)
+		}
+		m := newMockPbft(t, ids, powerMap, ids[0])
+		assert.Equal(t, m.QuorumSize(), actualQuorum)
+		assert.Equal(t, m.MaxFaultyVotingPower(), actualMaxFaulty)
+	}
+}
+
+func TestComputeQuorum_EqualVotingPower(t *testing.T) {
+	for count := uint(1); count <= 20; count++ {
+		ids := []NodeID{}
+		for i := uint(0); i < count; i++ {
+			ids = append(ids, NodeID(strconv.Itoa(int(i))))
+		}
+		maxFaulty, quorum, _ := CalculateQuorum(CreateEqualVotingPowerMap(ids))
+
+		actualQuorum, actualMaxFaulty := ComputeQuorum(nil, count, nil)
+		assert.Equal(t, quorum, actualQuorum, "%d validators", count)
+		assert.Equal(t, maxFaulty, actualMaxFaulty, "%d validators", count)
+	}
+
+	// no voting power
+	quorum, maxFaulty := ComputeQuorum(nil, 0, nil)
+	assert.Zero(t, quorum)
+	assert.Zero(t, maxFaulty)
+}
+
+func TestComputeQuorum_CommitQuorum(t *testing.T) {
+	config := DefaultConfig()
+	config.ApplyOps(WithPhaseQuorums(nil, func(totalVotingPower uint64) uint64 {
+		return totalVotingPower
+	}))
+
+	quorum, maxFaulty := ComputeQuorum(config, 7, nil)
+	assert.Equal(t, uint64(7), quorum)
+	assert.Equal(t, uint64(2), maxFaulty)
+}
+
+func TestQuorumTable(t *testing.T) {
+	assert.Empty(t, QuorumTable(0))
+
+	rows := QuorumTable(10)
+	assert.Len(t, rows, 10)
+	for i, row := range rows {
+		assert.Equal(t, uint(i+1), row.Nodes)
+	}
+
+	// 4, 5 and 6 validators tolerate the same single faulty node
+	assert.Equal(t, QuorumRow{Nodes: 4, MaxFaulty: 1, QuorumSize: 3}, rows[3])
+	assert.Equal(t, QuorumRow{Nodes: 5, MaxFaulty: 1, QuorumSize: 3}, rows[4])
+	assert.Equal(t, QuorumRow{Nodes: 6, MaxFaulty: 1, QuorumSize: 3}, rows[5])
+	assert.Equal(t, QuorumRow{Nodes: 7, MaxFaulty: 2, QuorumSize: 5}, rows[6])
+	assert.Equal(t, QuorumRow{Nodes: 10, MaxFaulty: 3, QuorumSize: 7}, rows[9])
+	assert.Equal(t, QuorumRow{Nodes: 1, MaxFaulty: 0, QuorumSize: 1}, rows[0])
+}
@@ -0,0 +1,78 @@
+package pbft
+
+import (
+	"bytes"
+	"sort"
+)
+
+// canonicalLess orders two messages by sequence, round, type and sender. The messages without a view go first,
+// and the hash and the seal break the ties between the equivocating messages of a sender so the order is total.
+func canonicalLess(a, b *MessageReq) bool {
+	if (a.View == nil) != (b.View == nil) {
+		return a.View == nil
+	}
+	if a.View != nil {
+		if a.View.Sequence != b.View.Sequence {
+			return a.View.Sequence < b.View.Sequence
+		}
+		if a.View.Round != b.View.Round {
+			return a.View.Round < b.View.Round
+		}
+	}
+	if a.Type != b.Type {
+		return a.Type < b.Type
+	}
+	if a.From != b.From {
+		return a.From < b.From
+	}
+	if c := bytes.Compare(a.Hash, b.Hash); c != 0 {
+		return c < 0
+	}
+	return bytes.Compare(a.Seal, b.Seal) < 0
+}
+
+// CanonicalOrder returns the messages sorted in the canonical total order (sequence, round, type, then sender),
+// which does not depend on the order they were received in. The given slice is left untouched.
+func CanonicalOrder(msgs []*MessageReq) []*MessageReq {
+	ordered := append([]*MessageReq{}, msgs...)
+	sort.SliceStable(ordered, func(i, j int) bool { return canonicalLess(ordered[i], ordered[j]) })
+	return ordered
+}
+
+// ReplayCanonical applies a copy of the messages in the canonical order to a fresh state of the given validator set,
+// to compare the outcome of the same message set on diverging nodes. The state takes the sequence of the first message,
+// the first pre-prepare sets the proposal and its round, the combined votes are split and the messages the state
+// rejects (non validators, other sequences, duplicates) are skipped as a live node would.
+func ReplayCanonical(validators ValidatorSet, msgs []*MessageReq) *state {
+	s := newState()
+	s.validators = validators
+	if err := s.initializeVotingInfo(); err != nil {
+		s.err = err
+		return s
+	}
+
+	for _, msg := range CanonicalOrder(msgs) {
+		if msg.View == nil {
+			continue
+		}
+		msg = msg.Copy()
+		if s.view == nil {
+			s.view = &View{Sequence: msg.View.Sequence}
+		}
+
+		switch msg.Type {
+		case MessageReq_Preprepare:
+			if s.proposal == nil && msg.View.Sequence == s.view.Sequence {
+				s.proposal = &Proposal{Data: msg.Proposal, Hash: msg.Hash}
+				s.view.Round = msg.View.Round
+			}
+		case MessageReq_PrepareCommit:
+			prepare, commit := splitPrepareCommit(msg)
+			_ = s.addMessage(prepare)
+			_ = s.addMessage(commit)
+		default:
+			_ = s.addMessage(msg)
+		}
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+package pbft
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func replayMessages() []*MessageReq {
+	msgs := []*MessageReq{
+		createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0)),
+		createMessage("B", MessageReq_Preprepare, ViewMsg(1, 1)),
+		createMessage("X", MessageReq_Prepare, ViewMsg(1, 0)),
+		createMessage("C", MessageReq_Prepare, ViewMsg(2, 0)),
+		createMessage("D", MessageReq_PrepareCommit, ViewMsg(1, 0)),
+	}
+	for _, from := range []NodeID{"A", "B", "C"} {
+		msgs = append(msgs,
+			createMessage(from, MessageReq_Prepare, ViewMsg(1, 0)),
+			createMessage(from, MessageReq_Commit, ViewMsg(1, 0)),
+			createMessage(from, MessageReq_RoundChange, ViewMsg(1, 1)),
+		)
+	}
+	for _, msg := range msgs {
+		msg.Hash = digest
+	}
+	// B equivocates on its prepare
+	equivocation := createMessage("B", MessageReq_Prepare, ViewMsg(1, 0))
+	equivocation.Hash = []byte{0xff}
+	return append(msgs, equivocation)
+}
+
+func TestCanonicalOrder(t *testing.T) {
+	msgs := replayMessages()
+	ordered := CanonicalOrder(msgs)
+
+	require.Len(t, ordered, len(msgs))
+	for i := 1; i < len(ordered); i++ {
+		assert.False(t, canonicalLess(ordered[i], ordered[i-1]), "message %d out of order", i)
+	}
+	assert.Equal(t, MessageReq_Preprepare, ordered[0].Type)
+	assert.Equal(t, uint64(2), ordered[len(ordered)-1].View.Sequence)
+}
+
+func TestReplayCanonical_OrderIndependent(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+
+	msgs := replayMessages()
+	expected := ReplayCanonical(pool.validatorSet(), msgs)
+	require.NoError(t, expected.getErr())
+	require.NoError(t, expected.checkInvariants())
+
+	assert.Equal(t, uint64(1), expected.GetSequence())
+	assert.Equal(t, uint64(0), expected.GetCurrentRound())
+	assert.Equal(t, digest, expected.proposal.Hash)
+	assert.Equal(t, 4, expected.numPrepared())
+	assert.Equal(t, 4, expected.numCommitted())
+	assert.Equal(t, 3, expected.roundMessages[1].length())
+	assert.Equal(t, digest, expected.prepared.messageMap["B"].Hash)
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		shuffled := append([]*MessageReq{}, msgs...)
+		rnd.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		assert.Equal(t, expected, ReplayCanonical(pool.validatorSet(), shuffled))
+	}
+}
@@ -0,0 +1,16 @@
+package pbft
+
+// RoleChangeCallback is invoked when the node becomes, or stops being, the proposer of the current view
+type RoleChangeCallback func(isProposer bool, view View)
+
+// updateRole records whether the node is the proposer of the current view and notifies
+// the OnRoleChange callback when the role differs from the previous view
+func (p *Pbft) updateRole(isProposer bool) {
+	if isProposer == p.isProposer {
+		return
+	}
+	p.isProposer = isProposer
+	if p.config.OnRoleChange != nil {
+		p.config.OnRoleChange(isProposer, p.state.CurrentView())
+	}
+}
@@ -0,0 +1,52 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransition_AcceptState_OnRoleChange(t *testing.T) {
+	type roleChange struct {
+		isProposer bool
+		view       View
+	}
+	changes := []roleChange{}
+
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+	WithOnRoleChange(func(isProposer bool, view View) {
+		changes = append(changes, roleChange{isProposer, view})
+	})(m.config)
+	m.setProposal(&Proposal{
+		Data: mockProposal,
+		Time: time.Now(),
+	})
+	m.state.view = ViewMsg(1, 0)
+
+	runRound := func(round uint64, proposer NodeID) {
+		m.setRound(round)
+		m.setState(AcceptState)
+		if proposer != "B" {
+			m.emitMsg(createMessage(proposer, MessageReq_Preprepare, ViewMsg(1, round)))
+		}
+		m.runCycle(context.Background())
+		assert.Equal(t, proposer, m.state.proposer)
+	}
+
+	// not the proposer of the first round, the role did not change
+	runRound(0, "A")
+	assert.Empty(t, changes)
+
+	// the round change makes the node the proposer
+	runRound(1, "B")
+	assert.Equal(t, []roleChange{{true, View{Sequence: 1, Round: 1}}}, changes)
+
+	// and the next one moves the role to another node
+	runRound(2, "C")
+	assert.Equal(t, []roleChange{
+		{true, View{Sequence: 1, Round: 1}},
+		{false, View{Sequence: 1, Round: 2}},
+	}, changes)
+}
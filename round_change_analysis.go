@@ -0,0 +1,84 @@
+package pbft
+
+import "sort"
+
+// RoundChangeAnalysis describes the view change decisions taken out of a sequence of round change messages
+type RoundChangeAnalysis struct {
+	// Rounds are the rounds with at least one accepted round change message, in ascending order
+	Rounds []RoundChangeRound
+
+	// MaxRound is the round the node would fast-track to (see maxRound), if MaxRoundFound
+	MaxRound uint64
+
+	// MaxRoundFound reports whether any round reached the fast-track threshold
+	MaxRoundFound bool
+
+	// Ignored is the number of messages not counted: not round changes, from non validators or duplicates
+	Ignored int
+}
+
+// RoundChangeRound describes the round change messages accumulated for a round
+type RoundChangeRound struct {
+	Round uint64
+
+	// Senders are the validators that sent a round change for the round, sorted by NodeID
+	Senders []NodeID
+
+	// VotingPower is the accumulated voting power of the senders
+	VotingPower uint64
+
+	// FastTrackIndex is the index of the message which made the round exceed the max faulty voting power,
+	// namely the threshold to fast-track to the round, or -1 if not reached
+	FastTrackIndex int
+
+	// QuorumIndex is the index of the message which made the round reach the quorum, or -1 if not reached
+	QuorumIndex int
+}
+
+// AnalyzeRoundChanges replays the given round change messages, in order, against the validator set and reports
+// for every round the accumulated voting power, when the fast-track threshold and the quorum were reached,
+// and the round the node would move to. The messages are handled like the state machine does, regardless of their sequence.
+func AnalyzeRoundChanges(msgs []*MessageReq, validators ValidatorSet) (RoundChangeAnalysis, error) {
+	analysis := RoundChangeAnalysis{}
+
+	s := newState()
+	s.validators = validators
+	if err := s.initializeVotingInfo(); err != nil {
+		return analysis, err
+	}
+
+	rounds := map[uint64]*RoundChangeRound{}
+	for i, msg := range msgs {
+		if msg == nil || msg.View == nil {
+			analysis.Ignored++
+			continue
+		}
+		if err := s.addRoundChangeMsg(msg); err != nil {
+			analysis.Ignored++
+			continue
+		}
+
+		round, exists := rounds[msg.View.Round]
+		if !exists {
+			round = &RoundChangeRound{Round: msg.View.Round, FastTrackIndex: -1, QuorumIndex: -1}
+			rounds[msg.View.Round] = round
+		}
+		round.Senders = append(round.Senders, msg.From)
+		round.VotingPower = s.roundMessages[msg.View.Round].getAccumulatedVotingPower()
+		if round.FastTrackIndex < 0 && round.VotingPower > s.getMaxFaultyVotingPower() {
+			round.FastTrackIndex = i
+		}
+		if round.QuorumIndex < 0 && round.VotingPower >= s.getQuorumSize() {
+			round.QuorumIndex = i
+		}
+	}
+
+	for _, round := range rounds {
+		sort.Slice(round.Senders, func(i, j int) bool { return round.Senders[i] < round.Senders[j] })
+		analysis.Rounds = append(analysis.Rounds, *round)
+	}
+	sort.Slice(analysis.Rounds, func(i, j int) bool { return analysis.Rounds[i].Round < analysis.Rounds[j].Round })
+
+	analysis.MaxRound, analysis.MaxRoundFound = s.maxRound()
+	return analysis, nil
+}
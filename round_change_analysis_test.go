@@ -0,0 +1,57 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeRoundChanges(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	validators := NewValStringStub(validatorIds, CreateEqualVotingPowerMap(validatorIds))
+
+	roundChange := func(from NodeID, round uint64) *MessageReq {
+		return createMessage(from, MessageReq_RoundChange, ViewMsg(1, round))
+	}
+	msgs := []*MessageReq{
+		roundChange("A", 1),
+		roundChange("B", 1), // round 1 can be fast-tracked
+		roundChange("A", 1), // duplicate
+		roundChange("C", 3),
+		roundChange("E", 3), // not a validator
+		roundChange("C", 1), // round 1 reaches the quorum
+		createMessage("D", MessageReq_Prepare, ViewMsg(1, 3)),
+		roundChange("D", 3), // round 3 can be fast-tracked
+	}
+
+	analysis, err := AnalyzeRoundChanges(msgs, validators)
+	require.NoError(t, err)
+
+	assert.Equal(t, RoundChangeAnalysis{
+		Rounds: []RoundChangeRound{
+			{Round: 1, Senders: []NodeID{"A", "B", "C"}, VotingPower: 3, FastTrackIndex: 1, QuorumIndex: 5},
+			{Round: 3, Senders: []NodeID{"C", "D"}, VotingPower: 2, FastTrackIndex: 7, QuorumIndex: -1},
+		},
+		MaxRound:      3,
+		MaxRoundFound: true,
+		Ignored:       3,
+	}, analysis)
+}
+
+func TestAnalyzeRoundChanges_NoFastTrack(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	validators := NewValStringStub(validatorIds, CreateEqualVotingPowerMap(validatorIds))
+
+	analysis, err := AnalyzeRoundChanges([]*MessageReq{
+		createMessage("A", MessageReq_RoundChange, ViewMsg(1, 2)),
+	}, validators)
+	require.NoError(t, err)
+
+	assert.False(t, analysis.MaxRoundFound)
+	require.Len(t, analysis.Rounds, 1)
+	assert.Equal(t, -1, analysis.Rounds[0].FastTrackIndex)
+
+	_, err = AnalyzeRoundChanges(nil, NewValStringStub(validatorIds, map[NodeID]uint64{}))
+	assert.ErrorIs(t, err, errInvalidTotalVotingPower)
+}
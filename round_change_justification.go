@@ -0,0 +1,42 @@
+package pbft
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrInsufficientRoundChanges is returned when the round change messages of a round do not reach the requested voting power
+var ErrInsufficientRoundChanges = errors.New("round change messages do not reach the voting power")
+
+// SelectRoundChangeJustification selects the minimal bundle of round change messages of the round whose voting power
+// reaches the given one, namely MaxFaultyVotingPower()+1 to prove that an honest node wants the round change or
+// QuorumSize() to prove a round change quorum. The messages are picked by decreasing voting power, ties broken by
+// NodeID, so that the nodes holding the same messages select the same bundle. The bundle is sorted by NodeID.
+func (p *Pbft) SelectRoundChangeJustification(round uint64, power uint64) ([]*MessageReq, error) {
+	return p.state.selectRoundChanges(round, power)
+}
+
+// selectRoundChanges returns copies of the minimal set of round change messages of the round reaching the voting power
+func (s *state) selectRoundChanges(round uint64, power uint64) ([]*MessageReq, error) {
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	msgs, ok := s.roundMessages[round]
+	if !ok || msgs.getAccumulatedVotingPower() < power {
+		return nil, fmt.Errorf("%w: round %d, voting power %d", ErrInsufficientRoundChanges, round, power)
+	}
+
+	senders := make([]NodeID, 0, len(msgs.messageMap))
+	for from := range msgs.messageMap {
+		senders = append(senders, from)
+	}
+	sort.Slice(senders, func(i, j int) bool { return senders[i] < senders[j] })
+
+	selected := selectMinimalSigners(s.validators.VotingPower(), senders, power)
+	bundle := make([]*MessageReq, 0, len(selected))
+	for _, from := range selected {
+		bundle = append(bundle, msgs.messageMap[from].Copy())
+	}
+	return bundle, nil
+}
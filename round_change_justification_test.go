@@ -0,0 +1,66 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPbft_SelectRoundChangeJustification(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D", "E"}
+	votingPowerMap := map[NodeID]uint64{"A": 1, "B": 3, "C": 3, "D": 2, "E": 1}
+
+	newNode := func(order []NodeID) *mockPbft {
+		m := newMockPbft(t, validatorIds, votingPowerMap, "A")
+		m.state.view = ViewMsg(1, 0)
+		for _, from := range order {
+			require.NoError(t, m.state.addRoundChangeMsg(createMessage(from, MessageReq_RoundChange, ViewMsg(1, 1))))
+		}
+		return m
+	}
+	senders := func(bundle []*MessageReq) []NodeID {
+		ids := make([]NodeID, 0, len(bundle))
+		for _, msg := range bundle {
+			ids = append(ids, msg.From)
+		}
+		return ids
+	}
+
+	// the nodes received the round changes in different orders
+	first := newNode([]NodeID{"A", "B", "C", "D", "E"})
+	second := newNode([]NodeID{"E", "D", "C", "B", "A"})
+	require.Equal(t, uint64(3), first.MaxFaultyVotingPower())
+	require.Equal(t, uint64(7), first.QuorumSize())
+
+	for _, power := range []uint64{first.MaxFaultyVotingPower() + 1, first.QuorumSize()} {
+		bundle, err := first.SelectRoundChangeJustification(1, power)
+		require.NoError(t, err)
+		other, err := second.SelectRoundChangeJustification(1, power)
+		require.NoError(t, err)
+		assert.Equal(t, bundle, other)
+	}
+
+	// the highest voting powers are picked first, ties broken by NodeID
+	bundle, err := first.SelectRoundChangeJustification(1, first.MaxFaultyVotingPower()+1)
+	require.NoError(t, err)
+	assert.Equal(t, []NodeID{"B", "C"}, senders(bundle))
+
+	bundle, err = first.SelectRoundChangeJustification(1, first.QuorumSize())
+	require.NoError(t, err)
+	assert.Equal(t, []NodeID{"B", "C", "D"}, senders(bundle))
+
+	// a tie between equal voting powers is broken by NodeID
+	bundle, err = first.SelectRoundChangeJustification(1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []NodeID{"B"}, senders(bundle))
+
+	// round changes below the voting power
+	partial := newNode([]NodeID{"A", "D", "E"})
+	_, err = partial.SelectRoundChangeJustification(1, partial.MaxFaultyVotingPower()+1)
+	require.NoError(t, err)
+	_, err = partial.SelectRoundChangeJustification(1, partial.QuorumSize())
+	assert.ErrorIs(t, err, ErrInsufficientRoundChanges)
+	_, err = partial.SelectRoundChangeJustification(2, 1)
+	assert.ErrorIs(t, err, ErrInsufficientRoundChanges)
+}
@@ -0,0 +1,23 @@
+package pbft
+
+// MergeRoundChanges folds the round change messages received from another path (i.e. while syncing or from
+// the gossip of a peer) into the round change messages of the current sequence, as if they were received
+// from the transport. It returns the number of messages newly added and the evidences of the senders whose
+// message conflicts with a known one for the same round (see ConflictingJustifications). The duplicates,
+// the messages of other sequences and the ones of nodes outside the validator set are skipped.
+// The merged messages count towards the round change quorum the next time the node checks it.
+func (p *Pbft) MergeRoundChanges(incoming []*MessageReq) (added int, conflicts []*ConflictingJustificationProof) {
+	for _, msg := range incoming {
+		if msg == nil || msg.Type != MessageReq_RoundChange || msg.View == nil {
+			continue
+		}
+		proof, err := p.mergeRoundChangeMsg(msg.Copy())
+		if proof != nil {
+			conflicts = append(conflicts, proof)
+		}
+		if err == nil {
+			added++
+		}
+	}
+	return added, conflicts
+}
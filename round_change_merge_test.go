@@ -0,0 +1,49 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPbft_MergeRoundChanges(t *testing.T) {
+	roundChange := func(from NodeID, view *View, hash []byte) *MessageReq {
+		msg := createMessage(from, MessageReq_RoundChange, view)
+		msg.Justification = &Justification{Round: 0, Proposal: &Proposal{Data: mockProposal, Hash: hash}}
+		return msg
+	}
+
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.state.view = ViewMsg(1, 0)
+
+	added, conflicts := m.MergeRoundChanges([]*MessageReq{
+		roundChange("B", ViewMsg(1, 1), digest),
+		roundChange("C", ViewMsg(1, 1), digest),
+	})
+	assert.Equal(t, 2, added)
+	assert.Empty(t, conflicts)
+
+	// the overlapping set from another peer
+	added, conflicts = m.MergeRoundChanges([]*MessageReq{
+		roundChange("B", ViewMsg(1, 1), digest),
+		// C justifies the round with another proposal
+		roundChange("C", ViewMsg(1, 1), digest1),
+		roundChange("D", ViewMsg(1, 1), digest),
+		roundChange("C", ViewMsg(1, 2), digest1),
+		// skipped: outside the validator set, another sequence, another type
+		roundChange("E", ViewMsg(1, 1), digest),
+		roundChange("B", ViewMsg(2, 1), digest),
+		createMessage("B", MessageReq_Commit, ViewMsg(1, 1)),
+		nil,
+	})
+	assert.Equal(t, 2, added)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, NodeID("C"), conflicts[0].Sender)
+	assert.Equal(t, digest, conflicts[0].First.Justification.Proposal.Hash)
+	assert.Equal(t, digest1, conflicts[0].Second.Justification.Proposal.Hash)
+	assert.Equal(t, conflicts, m.ConflictingJustifications())
+
+	assert.Equal(t, uint64(3), m.state.roundMessages[1].getAccumulatedVotingPower())
+	assert.Equal(t, uint64(1), m.state.roundMessages[2].getAccumulatedVotingPower())
+}
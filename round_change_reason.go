@@ -0,0 +1,92 @@
+package pbft
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// RoundChangeReason is the trigger of a round change
+type RoundChangeReason uint8
+
+const (
+	// RoundChange_None is reported before the first round change
+	RoundChange_None RoundChangeReason = iota
+
+	// RoundChange_Timeout is a round which timed out, waiting for the proposal, the quorums or the round changes
+	RoundChange_Timeout
+
+	// RoundChange_InvalidProposal is a proposal, built or received, which failed the validation
+	RoundChange_InvalidProposal
+
+	// RoundChange_MissingProposal is a proposer unable to build its proposal
+	RoundChange_MissingProposal
+
+	// RoundChange_LockConflict is a proposal conflicting with the locked one (see LockConflict_RoundChange)
+	RoundChange_LockConflict
+
+	// RoundChange_CommitFailed is a committed proposal which could not be finalized
+	RoundChange_CommitFailed
+
+	// RoundChange_CatchUp is a round change of at least MaxFaulty+1 voting power for a higher round
+	RoundChange_CatchUp
+
+	// RoundChange_PrepareSplit is a round whose prepares are split across proposals, so that none can reach quorum
+	RoundChange_PrepareSplit
+
+	// RoundChange_PrepareTie is a round in which two proposals reach the prepare quorum (see PrepareTieResolution)
+	RoundChange_PrepareTie
+)
+
+func (r RoundChangeReason) String() string {
+	switch r {
+	case RoundChange_None:
+		return "None"
+	case RoundChange_Timeout:
+		return "Timeout"
+	case RoundChange_InvalidProposal:
+		return "InvalidProposal"
+	case RoundChange_MissingProposal:
+		return "MissingProposal"
+	case RoundChange_LockConflict:
+		return "LockConflict"
+	case RoundChange_CommitFailed:
+		return "CommitFailed"
+	case RoundChange_CatchUp:
+		return "CatchUp"
+	case RoundChange_PrepareSplit:
+		return "PrepareSplit"
+	case RoundChange_PrepareTie:
+		return "PrepareTie"
+	default:
+		return fmt.Sprintf("RoundChangeReason(%d)", uint8(r))
+	}
+}
+
+// RoundChangeNotifier is an optional StateNotifier extension notified of the round changes with their reason
+type RoundChangeNotifier interface {
+	// HandleRoundChange notifies that the round of the view is abandoned for the given reason
+	HandleRoundChange(reason RoundChangeReason, view *View)
+}
+
+// startRoundChange moves to the RoundChangeState for the given reason
+func (p *Pbft) startRoundChange(reason RoundChangeReason) {
+	p.recordRoundChange(reason)
+	p.setState(RoundChangeState)
+}
+
+// recordRoundChange records the reason of a round change and notifies it
+func (p *Pbft) recordRoundChange(reason RoundChangeReason) {
+	atomic.StoreUint32(&p.roundChangeReason, uint32(reason))
+	view := p.state.CurrentView()
+	p.logger.Printf("[DEBUG] round change: reason=%s, sequence=%d, round=%d", reason, view.Sequence, view.Round)
+	p.roundChangeHistory.add(view, reason)
+	if notifier, ok := p.notifier.(RoundChangeNotifier); ok {
+		notifier.HandleRoundChange(reason, &view)
+	}
+	p.events.publish(ConsensusEvent{Type: ConsensusEvent_RoundChange, View: view, Reason: reason})
+}
+
+// LastRoundChangeReason returns the reason of the last round change, RoundChange_None if the node did not change round
+func (p *Pbft) LastRoundChangeReason() RoundChangeReason {
+	return RoundChangeReason(atomic.LoadUint32(&p.roundChangeReason))
+}
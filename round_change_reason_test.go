@@ -0,0 +1,55 @@
+package pbft
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundChangeRecorder is a StateNotifier recording the notified round changes
+type roundChangeRecorder struct {
+	StateNotifier
+	reasons []RoundChangeReason
+	views   []View
+}
+
+func (r *roundChangeRecorder) HandleRoundChange(reason RoundChangeReason, view *View) {
+	r.reasons = append(r.reasons, reason)
+	r.views = append(r.views, *view)
+}
+
+func TestPbft_LastRoundChangeReason(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C"}
+	votingPowerMap := CreateEqualVotingPowerMap(validatorIds)
+	run := func(validate func(*Proposal) error, msgs ...*MessageReq) (*mockPbft, *roundChangeRecorder) {
+		backend := newMockBackend(validatorIds, votingPowerMap, nil).HookValidateHandler(validate)
+		m := newMockPbft(t, validatorIds, votingPowerMap, "C", backend)
+		recorder := &roundChangeRecorder{StateNotifier: m.notifier}
+		m.notifier = recorder
+		m.state.view = ViewMsg(1, 0)
+		m.setState(AcceptState)
+		require.Equal(t, RoundChange_None, m.LastRoundChangeReason())
+
+		for _, msg := range msgs {
+			m.emitMsg(msg)
+		}
+		m.runCycle(m.ctx)
+		require.True(t, m.IsState(RoundChangeState))
+		return m, recorder
+	}
+
+	// no pre-prepare before the timeout
+	m, recorder := run(nil)
+	assert.Equal(t, RoundChange_Timeout, m.LastRoundChangeReason())
+	assert.Equal(t, []RoundChangeReason{RoundChange_Timeout}, recorder.reasons)
+	assert.Equal(t, []View{{Sequence: 1, Round: 0}}, recorder.views)
+
+	// invalid pre-prepare
+	m, recorder = run(func(*Proposal) error {
+		return errors.New("failed to validate a proposal")
+	}, createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0)))
+	assert.Equal(t, RoundChange_InvalidProposal, m.LastRoundChangeReason())
+	assert.Equal(t, []RoundChangeReason{RoundChange_InvalidProposal}, recorder.reasons)
+}
@@ -0,0 +1,20 @@
+package pbft
+
+// exceedsMaxRoundJump reports whether the round change message claims a round further than MaxRoundJump rounds ahead
+// of the current round. The rounds of the future sequences are counted from round 0.
+func (p *Pbft) exceedsMaxRoundJump(msg *MessageReq) bool {
+	if p.config.MaxRoundJump == 0 || msg.Type != MessageReq_RoundChange || msg.View == nil {
+		return false
+	}
+
+	current := p.state.CurrentView()
+	base := uint64(0)
+	switch {
+	case msg.View.Sequence < current.Sequence:
+		// stale messages are dropped later on
+		return false
+	case msg.View.Sequence == current.Sequence:
+		base = current.Round
+	}
+	return msg.View.Round > base && msg.View.Round-base > p.config.MaxRoundJump
+}
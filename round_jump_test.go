@@ -0,0 +1,38 @@
+package pbft
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the round change messages too far ahead of the current round are dropped on ingestion.
+func TestPbft_PushMessage_MaxRoundJump(t *testing.T) {
+	metrics := &recordingMetrics{}
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithMaxRoundJump(10)(m.config)
+	WithMetrics(metrics)(m.config)
+	m.state.view = ViewMsg(1, 5)
+
+	for _, view := range []*View{
+		ViewMsg(1, math.MaxUint64), // absurdly high round
+		ViewMsg(1, 16),             // one round too far
+		ViewMsg(2, 11),             // future sequences count from round 0
+		ViewMsg(1, 15),
+		ViewMsg(2, 10),
+	} {
+		m.PushMessage(createMessage("B", MessageReq_RoundChange, view))
+	}
+
+	assert.Equal(t, []string{
+		"dropped RoundChange round_too_far",
+		"dropped RoundChange round_too_far",
+		"dropped RoundChange round_too_far",
+	}, metrics.records)
+	assert.Len(t, m.msgQueue.roundChangeStateQueue, 1)
+
+	// other message types are not bounded
+	m.PushMessage(createMessage("B", MessageReq_Prepare, ViewMsg(1, 100)))
+	assert.Len(t, metrics.records, 3)
+}
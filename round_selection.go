@@ -0,0 +1,88 @@
+package pbft
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RoundSelection is the rule picking the round to move to, and whose justification wins, when the round change
+// messages of several rounds reach the fast-track threshold (F+1)
+type RoundSelection uint8
+
+const (
+	// RoundSelection_Highest picks the highest round, the votes of the lower rounds are superseded by it
+	RoundSelection_Highest RoundSelection = iota
+
+	// RoundSelection_Lowest picks the lowest round, so the node moves by the smallest step
+	RoundSelection_Lowest
+)
+
+func (r RoundSelection) String() string {
+	switch r {
+	case RoundSelection_Highest:
+		return "Highest"
+	case RoundSelection_Lowest:
+		return "Lowest"
+	default:
+		return fmt.Sprintf("RoundSelection(%d)", uint8(r))
+	}
+}
+
+// selectRound picks, with the given rule, one of the rounds whose round change messages reach the fast-track threshold.
+// Round 0 is never selected, since there is nothing to fast-track to.
+func (s *state) selectRound(selection RoundSelection) (selected uint64, found bool) {
+	for round, messages := range s.roundMessages {
+		if round == 0 || messages.getAccumulatedVotingPower() < s.getMaxFaultyVotingPower()+1 {
+			continue
+		}
+		if !found ||
+			(selection == RoundSelection_Highest && round > selected) ||
+			(selection == RoundSelection_Lowest && round < selected) {
+			selected = round
+			found = true
+		}
+	}
+	return
+}
+
+// JustifiedProposal returns the proposal the node has to adopt out of the round change messages of the round
+// picked by the configured RoundSelection (see SelectJustifiedProposal). It returns nil if no round reaches
+// the fast-track threshold or none of its messages carries a justification.
+func (p *Pbft) JustifiedProposal() (*Proposal, error) {
+	round, found := p.state.selectRound(p.config.RoundSelection)
+	if !found {
+		return nil, nil
+	}
+	votes := []*MessageReq{}
+	p.state.RangeRoundMessages(func(r uint64, msgs map[NodeID]*MessageReq) bool {
+		if r != round {
+			return true
+		}
+		for _, msg := range msgs {
+			votes = append(votes, msg)
+		}
+		return false
+	})
+	return SelectJustifiedProposal(votes)
+}
+
+// roundsWithQuorum returns, in ascending order, the rounds whose round change messages reach the quorum
+func (s *state) roundsWithQuorum() []uint64 {
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	rounds := []uint64{}
+	for round, messages := range s.roundMessages {
+		if messages.getAccumulatedVotingPower() >= s.getQuorumSize() {
+			rounds = append(rounds, round)
+		}
+	}
+	sort.Slice(rounds, func(i, j int) bool { return rounds[i] < rounds[j] })
+	return rounds
+}
+
+// RoundsWithChangeQuorum returns, in ascending order, all the rounds of the current sequence whose round change
+// messages reach the quorum voting power, unlike the round change state which only moves to the highest one.
+func (p *Pbft) RoundsWithChangeQuorum() []uint64 {
+	return p.state.roundsWithQuorum()
+}
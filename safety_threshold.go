@@ -0,0 +1,87 @@
+package pbft
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrSafetyThresholdBreached is returned when the provably faulty validators exceed the max faulty voting power,
+// so that neither the safety nor the liveness of the consensus hold anymore
+var ErrSafetyThresholdBreached = errors.New("faulty validators exceed the max faulty voting power")
+
+// FaultyValidators returns the validators of the current set with evidence of a fault, sorted by id:
+// the proposers that sent two different proposals for the same view, the nodes that sent conflicting round change
+// justifications for the same view and the nodes that prepared and committed different proposals in the same view.
+// Only verified equivocation evidence counts: an offline validator (see InactiveValidators) proves no fault.
+func (p *Pbft) FaultyValidators() []NodeID {
+	faulty := map[NodeID]struct{}{}
+	for _, proof := range p.doubleProposals.getProofs() {
+		if p.state.validators.Includes(proof.Proposer) {
+			faulty[proof.Proposer] = struct{}{}
+		}
+	}
+	for _, proof := range p.justificationConflicts.getProofs() {
+		if p.state.validators.Includes(proof.Sender) {
+			faulty[proof.Sender] = struct{}{}
+		}
+	}
+	for _, proof := range p.crossPhase.getProofs() {
+		if p.state.validators.Includes(proof.Sender) {
+			faulty[proof.Sender] = struct{}{}
+		}
+	}
+
+	ids := make([]NodeID, 0, len(faulty))
+	for id := range faulty {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// checkSafetyThreshold halts the node, when enabled, if the voting power of the faulty validators
+// exceeds the max faulty voting power. It returns the halt error, if any.
+func (p *Pbft) checkSafetyThreshold() error {
+	if !p.config.HaltOnSafetyBreach {
+		return nil
+	}
+	if err := p.Halted(); err != nil {
+		return err
+	}
+
+	faulty := p.FaultyValidators()
+	votingPower := p.state.validators.VotingPower()
+	faultyVotingPower := uint64(0)
+	names := make([]string, 0, len(faulty))
+	for _, id := range faulty {
+		faultyVotingPower += votingPower[id]
+		names = append(names, string(id))
+	}
+	if faultyVotingPower <= p.state.getMaxFaultyVotingPower() {
+		return nil
+	}
+
+	err := fmt.Errorf("%w: faulty voting power %d, max %d, faulty validators: %s",
+		ErrSafetyThresholdBreached, faultyVotingPower, p.state.getMaxFaultyVotingPower(), strings.Join(names, ", "))
+	p.logger.Printf("[ERROR] halting the consensus: %v", err)
+
+	p.haltLock.Lock()
+	p.haltErr = err
+	p.haltLock.Unlock()
+
+	select {
+	case p.updateCh <- struct{}{}:
+	default:
+	}
+	return err
+}
+
+// Halted returns the reason why the node halted the consensus, or nil if it did not
+func (p *Pbft) Halted() error {
+	p.haltLock.Lock()
+	defer p.haltLock.Unlock()
+
+	return p.haltErr
+}
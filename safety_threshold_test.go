@@ -0,0 +1,71 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// equivocate makes the proposer of the round send two different proposals
+func equivocate(m *mockPbft, proposer NodeID, round uint64) {
+	first := createMessage(proposer, MessageReq_Preprepare, ViewMsg(1, round))
+	m.emitMsg(first)
+	second := createMessage(proposer, MessageReq_Preprepare, ViewMsg(1, round))
+	second.Proposal = mockProposal1
+	second.Hash = digest1
+	m.emitMsg(second)
+}
+
+func TestPbft_HaltOnSafetyBreach(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "C")
+	WithHaltOnSafetyBreach()(m.config)
+	require.Equal(t, NodeID("B"), m.calcProposer(1))
+
+	// up to f faulty validators are tolerated
+	equivocate(m, "A", 0)
+	assert.Equal(t, []NodeID{"A"}, m.FaultyValidators())
+	assert.NoError(t, m.Halted())
+
+	// f+1 faulty validators halt the consensus
+	equivocate(m, "B", 1)
+	assert.Equal(t, []NodeID{"A", "B"}, m.FaultyValidators())
+	assert.ErrorIs(t, m.Halted(), ErrSafetyThresholdBreached)
+
+	// the state machine stops instead of waiting for the round timeout
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	m.Run(ctx)
+	assert.NoError(t, ctx.Err())
+
+	assert.ErrorIs(t, m.SetBackend(m.backend), ErrSafetyThresholdBreached)
+}
+
+func TestPbft_HaltOnSafetyBreach_Disabled(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "C")
+
+	equivocate(m, "A", 0)
+	equivocate(m, "B", 1)
+
+	assert.Equal(t, []NodeID{"A", "B"}, m.FaultyValidators())
+	assert.NoError(t, m.Halted())
+	assert.NoError(t, m.SetBackend(m.backend))
+}
+
+// Test that the offline validators do not count as faulty, silence is no evidence of equivocation.
+func TestPbft_HaltOnSafetyBreach_Offline(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithHaltOnSafetyBreach()(m.config)
+	m.liveness = newLivenessTracker(2)
+	require.NoError(t, m.SetBackend(m.backend))
+
+	m.PushMessageInternal(createMessage("B", MessageReq_RoundChange, ViewMsg(4, 0)))
+	m.sequence = 4
+	require.NoError(t, m.SetBackend(m.backend))
+
+	assert.Equal(t, []NodeID{"C", "D"}, m.InactiveValidators())
+	assert.Empty(t, m.FaultyValidators())
+	assert.NoError(t, m.Halted())
+}
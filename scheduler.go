@@ -0,0 +1,54 @@
+package pbft
+
+import (
+	"context"
+	"time"
+)
+
+// EventType is the type of an event processed by the run loop
+type EventType int
+
+const (
+	// EventMessage signals a new message. The message is pushed before being processed,
+	// unless it is nil (i.e. the message is already queued).
+	EventMessage EventType = iota
+
+	// EventTimeout signals the timeout of the current round
+	EventTimeout
+
+	// EventCommand runs a command on the run loop
+	EventCommand
+)
+
+// Event is a single input of the run loop
+type Event struct {
+	Type EventType
+
+	// Msg is the message of an EventMessage
+	Msg *MessageReq
+
+	// Command is the function run by an EventCommand
+	Command func(p *Pbft)
+}
+
+// Scheduler feeds the run loop with the events to process, one at a time
+type Scheduler interface {
+	// Next blocks until the next event is available. It returns false when the run loop has to stop.
+	// The round timeout and the channel signaling queued messages are the ones of the state machine.
+	Next(ctx context.Context, timeout <-chan time.Time, update <-chan struct{}) (Event, bool)
+}
+
+// realScheduler is the default Scheduler, driven by the round timers and the messages from the transport
+type realScheduler struct{}
+
+// Next implements Scheduler interface
+func (realScheduler) Next(ctx context.Context, timeout <-chan time.Time, update <-chan struct{}) (Event, bool) {
+	select {
+	case <-timeout:
+		return Event{Type: EventTimeout}, true
+	case <-ctx.Done():
+		return Event{}, false
+	case <-update:
+		return Event{Type: EventMessage}, true
+	}
+}
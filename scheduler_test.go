@@ -0,0 +1,66 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the ordering of a round timeout racing the last commit determines the transition.
+func TestDeterministicScheduler_RoundChangeRacingCommit(t *testing.T) {
+	message := func(from NodeID, typ MsgType) Event {
+		msg := createMessage(from, typ, ViewMsg(1, 0))
+		msg.Hash = digest
+		return MessageEvent(msg)
+	}
+	prepared := []Event{
+		message("A", MessageReq_Prepare),
+		message("B", MessageReq_Prepare),
+		message("C", MessageReq_Prepare),
+		message("B", MessageReq_Commit),
+	}
+
+	cases := []struct {
+		name    string
+		events  []Event
+		state   State
+		commits int
+	}{
+		{"commit before timeout", append(prepared[:4:4], message("C", MessageReq_Commit), TimeoutEvent()), CommitState, 3},
+		{"timeout before commit", append(prepared[:4:4], TimeoutEvent(), message("C", MessageReq_Commit)), RoundChangeState, 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			scheduler := NewDeterministicScheduler(c.events...)
+			m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+			WithScheduler(scheduler)(m.config)
+			m.setState(ValidateState)
+
+			m.runCycle(context.Background())
+
+			assert.Equal(t, c.state, m.getState())
+			assert.Equal(t, c.commits, m.state.numCommitted())
+			// the transition happened right at the deciding event, the rest is left unprocessed
+			assert.Equal(t, 1, scheduler.Pending())
+		})
+	}
+}
+
+func TestDeterministicScheduler_Command(t *testing.T) {
+	var seen []int
+	record := CommandEvent(func(p *Pbft) { seen = append(seen, p.state.numPrepared()) })
+
+	prepare := createMessage("B", MessageReq_Prepare, ViewMsg(1, 0))
+	prepare.Hash = digest
+
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithScheduler(NewDeterministicScheduler(record, MessageEvent(prepare), record))(m.config)
+	m.setState(ValidateState)
+
+	m.runCycle(context.Background())
+
+	// the loop stops in the same state once every event is processed
+	assert.Equal(t, ValidateState, m.getState())
+	assert.Equal(t, []int{0, 1}, seen)
+}
@@ -0,0 +1,51 @@
+package pbft
+
+import "encoding/binary"
+
+// SignableContent returns the content a committed seal is produced over: the proposal hash prefixed with
+// the domain separation tag and its length, so that a seal can not be replayed into a protocol signing similar bytes.
+// Without a tag, the content is the bare proposal hash.
+func SignableContent(domain, hash []byte) []byte {
+	if len(domain) == 0 {
+		return hash
+	}
+
+	content := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(domain)+len(hash))
+	n := binary.PutUvarint(content, uint64(len(domain)))
+	content = append(content[:n], domain...)
+	return append(content, hash...)
+}
+
+// SignableViewContent returns the content of a seal bound to a view (i.e. a header embedding the view): the canonical
+// encoding of the view (see View.MarshalBinary) followed by the hash, prefixed as SignableContent does. Every node
+// encodes the view to the same bytes, so that such a seal verifies on any node.
+func SignableViewContent(domain []byte, view *View, hash []byte) []byte {
+	encoded, _ := view.MarshalBinary()
+	return SignableContent(domain, append(encoded, hash...))
+}
+
+// SealValidator is an optional interface the Backend can implement to validate the committed seals against
+// the content they were produced over (see SignableContent). When implemented, it is used in place of ValidateCommit,
+// so that the seals produced under another domain separation tag are rejected.
+type SealValidator interface {
+	ValidateSeal(from NodeID, content []byte, seal []byte) error
+}
+
+// DomainSealVerifier wraps a SealVerifier of the signed content, so that it verifies the seals produced under the domain
+// separation tag (i.e. when verifying a FinalizationProof)
+func DomainSealVerifier(domain []byte, verify SealVerifier) SealVerifier {
+	return func(from NodeID, hash []byte, seal []byte) error {
+		return verify(from, SignableContent(domain, hash), seal)
+	}
+}
+
+// validateCommitSeal validates the committed seal of the proposal hash with the backend
+func (p *Pbft) validateCommitSeal(from NodeID, hash []byte, seal []byte) error {
+	if p.config.SignatureAggregator != nil {
+		return p.config.SignatureAggregator.VerifyPartial(from, SignableContent(p.config.SealDomain, hash), seal)
+	}
+	if validator, ok := p.backend.(SealValidator); ok {
+		return validator.ValidateSeal(from, SignableContent(p.config.SealDomain, hash), seal)
+	}
+	return p.backend.ValidateCommit(from, seal)
+}
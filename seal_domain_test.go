@@ -0,0 +1,82 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignableContent(t *testing.T) {
+	hash := []byte("hash")
+
+	// without a tag the bare hash is signed
+	assert.Equal(t, hash, SignableContent(nil, hash))
+
+	assert.NotEqual(t, SignableContent([]byte("a"), hash), SignableContent([]byte("b"), hash))
+	// the tag length prevents ambiguous contents
+	assert.NotEqual(t, SignableContent([]byte("ab"), []byte("c")), SignableContent([]byte("a"), []byte("bc")))
+	assert.Equal(t, SignableContent([]byte("a"), hash), SignableContent([]byte("a"), hash))
+}
+
+// Test that a seal signed under the domain A fails the verification under the domain B.
+func TestPbft_SealDomain(t *testing.T) {
+	domainA, domainB := []byte("domain-a"), []byte("domain-b")
+
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithSealDomain(domainA)(m.config)
+	m.pool.useMockSigners()
+	verifier := m.pool.verifier()
+
+	m.sendCommitMsg()
+	require.Len(t, m.respMsg, 1)
+	seal := m.respMsg[0].Seal
+	hash := m.state.proposal.Hash
+
+	assert.NoError(t, DomainSealVerifier(domainA, verifier.Verify)("A", hash, seal))
+	assert.ErrorIs(t, DomainSealVerifier(domainB, verifier.Verify)("A", hash, seal), ErrBadSignature)
+	assert.ErrorIs(t, verifier.Verify("A", hash, seal), ErrBadSignature)
+}
+
+// sealValidatorBackend validates the committed seals against the signed content
+type sealValidatorBackend struct {
+	*mockBackend
+	verifier *MockVerifier
+}
+
+func (b *sealValidatorBackend) ValidateSeal(from NodeID, content []byte, seal []byte) error {
+	return b.verifier.Verify(from, content, seal)
+}
+
+// Test that the commits sealed under another domain are rejected by the validate state.
+func TestTransition_ValidateState_SealDomain(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithSealDomain([]byte("domain-b"))(m.config)
+	m.backend = &sealValidatorBackend{mockBackend: m.backend.(*mockBackend), verifier: m.pool.verifier()}
+	m.state.view = ViewMsg(1, 0)
+	m.setState(ValidateState)
+
+	commit := func(from NodeID, domain string) Event {
+		seal, err := m.pool.signer(from).Sign(SignableContent([]byte(domain), digest))
+		require.NoError(t, err)
+		msg := createMessage(from, MessageReq_Commit, ViewMsg(1, 0))
+		msg.Hash = digest
+		msg.Seal = seal
+		return MessageEvent(msg)
+	}
+	WithScheduler(NewDeterministicScheduler(
+		commit("B", "domain-a"),
+		commit("C", "domain-b"),
+	))(m.config)
+
+	m.runCycle(context.Background())
+
+	m.expect(expectResult{
+		sequence:              1,
+		state:                 ValidateState,
+		commitMsgs:            1,
+		commitMsgsVotingPower: 1,
+	})
+	assert.Contains(t, m.state.committed.messageMap, NodeID("C"))
+}
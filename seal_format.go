@@ -0,0 +1,149 @@
+package pbft
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// SealFormat is the encoding of the committed seals
+type SealFormat uint8
+
+const (
+	// SealFormat_Native keeps the seal as produced by the SignKey
+	SealFormat_Native SealFormat = iota
+
+	// SealFormat_Raw encodes the seal as the 64 bytes r||s
+	SealFormat_Raw
+
+	// SealFormat_DER encodes the seal as the ASN.1 DER sequence of r and s
+	SealFormat_DER
+
+	// SealFormat_CompactRecoverable encodes the seal as the 65 bytes r||s||v
+	SealFormat_CompactRecoverable
+)
+
+const (
+	sealScalarSize      = 32
+	rawSealSize         = 2 * sealScalarSize
+	recoverableSealSize = rawSealSize + 1
+
+	// minDERSealSize is the size of the DER sequence of two one byte integers
+	minDERSealSize = 8
+	// maxDERSealSize is the size of the DER sequence of two 33 bytes integers (a scalar with the sign byte)
+	maxDERSealSize = 72
+)
+
+// ErrSealFormat is returned when a seal is not encoded in the configured format
+var ErrSealFormat = errors.New("invalid seal format")
+
+func (f SealFormat) String() string {
+	switch f {
+	case SealFormat_Native:
+		return "Native"
+	case SealFormat_Raw:
+		return "Raw"
+	case SealFormat_DER:
+		return "DER"
+	case SealFormat_CompactRecoverable:
+		return "CompactRecoverable"
+	default:
+		return fmt.Sprintf("SealFormat(%d)", uint8(f))
+	}
+}
+
+// derSeal is the ASN.1 structure of a DER encoded seal
+type derSeal struct {
+	R, S *big.Int
+}
+
+// EncodeSeal encodes the signature produced by the SignKey in the given format.
+// The signature is expected to be either r||s or r||s||v. The recoverable format requires the latter.
+func EncodeSeal(format SealFormat, signature []byte) ([]byte, error) {
+	if format == SealFormat_Native {
+		return signature, nil
+	}
+	if len(signature) != rawSealSize && len(signature) != recoverableSealSize {
+		return nil, fmt.Errorf("%w: signature length %d", ErrSealFormat, len(signature))
+	}
+
+	switch format {
+	case SealFormat_Raw:
+		return append([]byte{}, signature[:rawSealSize]...), nil
+	case SealFormat_DER:
+		return asn1.Marshal(derSeal{
+			R: new(big.Int).SetBytes(signature[:sealScalarSize]),
+			S: new(big.Int).SetBytes(signature[sealScalarSize:rawSealSize]),
+		})
+	case SealFormat_CompactRecoverable:
+		if len(signature) != recoverableSealSize {
+			return nil, fmt.Errorf("%w: signature is not recoverable", ErrSealFormat)
+		}
+		return append([]byte{}, signature...), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown format %s", ErrSealFormat, format)
+	}
+}
+
+// CheckSealLength is a cheap structural check of the seal length for the given format, meant to reject
+// the malformed seals before the full decoding and verification. The native seals only need to be non empty.
+func CheckSealLength(format SealFormat, seal []byte) error {
+	size := len(seal)
+	if size == 0 {
+		return fmt.Errorf("%w: empty seal", ErrSealFormat)
+	}
+
+	switch format {
+	case SealFormat_Raw:
+		if size != rawSealSize {
+			return fmt.Errorf("%w: raw seal length %d", ErrSealFormat, size)
+		}
+	case SealFormat_CompactRecoverable:
+		if size != recoverableSealSize {
+			return fmt.Errorf("%w: recoverable seal length %d", ErrSealFormat, size)
+		}
+	case SealFormat_DER:
+		if size < minDERSealSize || size > maxDERSealSize {
+			return fmt.Errorf("%w: DER seal length %d out of range [%d, %d]", ErrSealFormat, size, minDERSealSize, maxDERSealSize)
+		}
+	}
+	return nil
+}
+
+// DecodeSeal validates that the seal is encoded in the given format and returns its r||s form
+// (r||s||v for the recoverable format). Native seals are returned unchanged.
+func DecodeSeal(format SealFormat, seal []byte) ([]byte, error) {
+	switch format {
+	case SealFormat_Native:
+		return seal, nil
+	case SealFormat_Raw:
+		if len(seal) != rawSealSize {
+			return nil, fmt.Errorf("%w: raw seal length %d", ErrSealFormat, len(seal))
+		}
+		return seal, nil
+	case SealFormat_CompactRecoverable:
+		if len(seal) != recoverableSealSize {
+			return nil, fmt.Errorf("%w: recoverable seal length %d", ErrSealFormat, len(seal))
+		}
+		return seal, nil
+	case SealFormat_DER:
+		var sig derSeal
+		rest, err := asn1.Unmarshal(seal, &sig)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSealFormat, err)
+		}
+		if len(rest) != 0 {
+			return nil, fmt.Errorf("%w: trailing data after DER seal", ErrSealFormat)
+		}
+		if sig.R.Sign() <= 0 || sig.S.Sign() <= 0 || sig.R.BitLen() > 8*sealScalarSize || sig.S.BitLen() > 8*sealScalarSize {
+			return nil, fmt.Errorf("%w: DER seal scalars out of range", ErrSealFormat)
+		}
+		raw := make([]byte, rawSealSize)
+		sig.R.FillBytes(raw[:sealScalarSize])
+		sig.S.FillBytes(raw[sealScalarSize:])
+		return raw, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown format %s", ErrSealFormat, format)
+	}
+}
@@ -0,0 +1,166 @@
+package pbft
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recoverableSignature() []byte {
+	sig := make([]byte, recoverableSealSize)
+	for i := range sig {
+		sig[i] = byte(i + 1)
+	}
+	return sig
+}
+
+func TestSealFormat_EncodeDecode(t *testing.T) {
+	sig := recoverableSignature()
+
+	cases := []struct {
+		format  SealFormat
+		decoded []byte
+	}{
+		{SealFormat_Native, sig},
+		{SealFormat_Raw, sig[:rawSealSize]},
+		{SealFormat_DER, sig[:rawSealSize]},
+		{SealFormat_CompactRecoverable, sig},
+	}
+	for _, c := range cases {
+		t.Run(c.format.String(), func(t *testing.T) {
+			seal, err := EncodeSeal(c.format, sig)
+			require.NoError(t, err)
+
+			decoded, err := DecodeSeal(c.format, seal)
+			require.NoError(t, err)
+			assert.Equal(t, c.decoded, decoded)
+		})
+	}
+}
+
+func TestSealFormat_Invalid(t *testing.T) {
+	sig := recoverableSignature()
+
+	// the recoverable format requires the recovery id
+	_, err := EncodeSeal(SealFormat_CompactRecoverable, sig[:rawSealSize])
+	assert.ErrorIs(t, err, ErrSealFormat)
+
+	_, err = EncodeSeal(SealFormat_Raw, sig[:10])
+	assert.ErrorIs(t, err, ErrSealFormat)
+
+	// seals encoded in a different format are rejected
+	der, err := EncodeSeal(SealFormat_DER, sig)
+	require.NoError(t, err)
+	for _, format := range []SealFormat{SealFormat_Raw, SealFormat_CompactRecoverable} {
+		_, err = DecodeSeal(format, der)
+		assert.ErrorIs(t, err, ErrSealFormat)
+	}
+	_, err = DecodeSeal(SealFormat_DER, sig[:rawSealSize])
+	assert.ErrorIs(t, err, ErrSealFormat)
+	_, err = DecodeSeal(SealFormat_DER, append(der, 0x1))
+	assert.ErrorIs(t, err, ErrSealFormat)
+}
+
+func TestGossip_SealFormat(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
+	WithSealFormat(SealFormat_DER)(m.config)
+	m.pool.get("A").signFn = func(b []byte) ([]byte, error) {
+		return recoverableSignature(), nil
+	}
+
+	m.gossip(MessageReq_Commit)
+
+	require.Len(t, m.respMsg, 1)
+	decoded, err := DecodeSeal(SealFormat_DER, m.respMsg[0].Seal)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(recoverableSignature()[:rawSealSize], decoded))
+}
+
+func TestTransition_ValidateState_SealFormat(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D", "E"}, nil, "A")
+	WithSealFormat(SealFormat_Raw)(m.config)
+	m.pool.get("A").signFn = func(b []byte) ([]byte, error) {
+		return recoverableSignature(), nil
+	}
+	m.setState(ValidateState)
+
+	// the commit seal of D is not encoded in the raw format
+	for _, from := range []NodeID{"D", "B", "C", "E"} {
+		commit := createMessage(from, MessageReq_Commit, nil)
+		commit.Seal = recoverableSignature()[:rawSealSize]
+		if from == "D" {
+			commit.Seal = recoverableSignature()
+		}
+		m.emitMsg(commit)
+	}
+
+	m.runCycle(context.Background())
+
+	m.expect(expectResult{
+		sequence:              1,
+		state:                 CommitState,
+		commitMsgs:            3,
+		commitMsgsVotingPower: 3,
+		locked:                true,
+		outgoing:              1, // A commit message
+	})
+	m.RangeCommitted(func(from NodeID, _ *MessageReq) bool {
+		assert.NotEqual(t, NodeID("D"), from)
+		return true
+	})
+}
+
+func TestCheckSealLength(t *testing.T) {
+	der, err := EncodeSeal(SealFormat_DER, recoverableSignature())
+	require.NoError(t, err)
+
+	cases := []struct {
+		format SealFormat
+		seal   []byte
+		valid  bool
+	}{
+		{SealFormat_Native, []byte{0x1}, true},
+		{SealFormat_Native, nil, false},
+		{SealFormat_Raw, recoverableSignature()[:rawSealSize], true},
+		{SealFormat_Raw, recoverableSignature()[:rawSealSize-1], false},
+		{SealFormat_Raw, recoverableSignature(), false},
+		{SealFormat_CompactRecoverable, recoverableSignature(), true},
+		{SealFormat_CompactRecoverable, recoverableSignature()[:rawSealSize], false},
+		{SealFormat_CompactRecoverable, append(recoverableSignature(), 0x0), false},
+		{SealFormat_DER, der, true},
+		{SealFormat_DER, der[:minDERSealSize-1], false},
+		{SealFormat_DER, make([]byte, maxDERSealSize+1), false},
+	}
+	for _, c := range cases {
+		err := CheckSealLength(c.format, c.seal)
+		if c.valid {
+			assert.NoError(t, err, "%s seal of %d bytes", c.format, len(c.seal))
+		} else {
+			assert.ErrorIs(t, err, ErrSealFormat, "%s seal of %d bytes", c.format, len(c.seal))
+		}
+	}
+}
+
+// Test that the commit messages with a seal too short or too long for the seal format are dropped on ingestion.
+func TestPbft_PushMessage_MalformedSeal(t *testing.T) {
+	metrics := &recordingMetrics{}
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithSealFormat(SealFormat_Raw)(m.config)
+	WithMetrics(metrics)(m.config)
+
+	for _, seal := range [][]byte{recoverableSignature()[:rawSealSize-1], recoverableSignature(), recoverableSignature()[:rawSealSize]} {
+		commit := createMessage("B", MessageReq_Commit, ViewMsg(1, 0))
+		commit.Hash = digest
+		commit.Seal = seal
+		m.PushMessage(commit)
+	}
+
+	assert.Equal(t, []string{
+		"dropped Commit malformed_seal",
+		"dropped Commit malformed_seal",
+	}, metrics.records)
+	assert.Len(t, m.msgQueue.validateStateQueue, 1)
+}
@@ -0,0 +1,63 @@
+package pbft
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// SealHashFunc computes the hash sealed by the commit seals of a proposal (see WithSealHash)
+type SealHashFunc func(proposal *Proposal) []byte
+
+// SealHash returns the hash sealed by the commit seals of the proposal, computed by the configured SealHashFunc.
+// Unlike the proposal hash, which is the identity of the proposal, the seal hash can exclude some fields of
+// the proposal (e.g. the seals of a block header). It defaults to the proposal hash.
+func (p *Pbft) SealHash(proposal *Proposal) []byte {
+	if p.config.SealHash == nil {
+		return proposal.Hash
+	}
+	return p.config.SealHash(proposal)
+}
+
+// ErrInvalidSealHash is returned when the seal hash carried by a finalization proof is not the one rebuilt by the verifier
+var ErrInvalidSealHash = errors.New("invalid seal hash")
+
+// ProofSealHashFunc rebuilds the hash sealed by the commit seals of a finalized proposal out of the proposal hash
+// (e.g. from the synced block), for the verifiers of the finalization proofs (see WithProofSealHash)
+type ProofSealHashFunc func(hash []byte) ([]byte, error)
+
+// ProofSealing describes the hash sealed by the committed seals of a FinalizationProof. The verifier rebuilds it out
+// of the proof hash, so that the seals can not vouch for a hash other than the one of the finalized proposal.
+// The zero value seals the proposal hash.
+type ProofSealing struct {
+	// SealHash rebuilds the seal hash of the finalized proposal, when the commits seal it (see WithSealHash)
+	SealHash ProofSealHashFunc
+
+	// RoundBound binds the sealed hash to the round of the proof view (see WithRoundBoundCommitSeals)
+	RoundBound bool
+}
+
+// sealedHash rebuilds the hash sealed by the committed seals of the proof. A proof carrying a seal hash other
+// than the rebuilt one is rejected.
+func (s ProofSealing) sealedHash(proof *FinalizationProof) ([]byte, error) {
+	hash := proof.Hash
+	if s.SealHash != nil {
+		sealHash, err := s.SealHash(proof.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSealHash, err)
+		}
+		hash = sealHash
+	}
+	if s.RoundBound {
+		hash = CommitSealHash(proof.View.Round, hash)
+	}
+	if len(proof.SealHash) != 0 && !bytes.Equal(proof.SealHash, hash) {
+		return nil, fmt.Errorf("%w: %x, expected %x", ErrInvalidSealHash, proof.SealHash, hash)
+	}
+	return hash, nil
+}
+
+// proofSealing returns the sealing of the finalization proofs of the node
+func (p *Pbft) proofSealing() ProofSealing {
+	return ProofSealing{SealHash: p.config.ProofSealHash, RoundBound: p.config.RoundBoundCommitSeals}
+}
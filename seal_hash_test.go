@@ -0,0 +1,155 @@
+package pbft
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dataSealHash seals the proposal data only, regardless of the proposal hash
+func dataSealHash(proposal *Proposal) []byte {
+	hash := sha256.Sum256(proposal.Data)
+	return hash[:]
+}
+
+func TestPbft_SealHash(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	proposal := &Proposal{Data: mockProposal, Hash: digest}
+
+	// without a SealHashFunc the proposal hash is sealed
+	assert.Equal(t, digest, m.SealHash(proposal))
+
+	WithSealHash(dataSealHash)(m.config)
+	sealHash := m.SealHash(proposal)
+	assert.Equal(t, sealHash, m.SealHash(&Proposal{Data: mockProposal, Hash: digest1}))
+	assert.NotEqual(t, proposal.Hash, sealHash)
+}
+
+// Test that the commits are sealed over the seal hash and that the seals over the proposal hash are rejected,
+// while the proposal hash remains the identity of the finalized proposal.
+func TestTransition_ValidateState_SealHash(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithSealHash(dataSealHash)(m.config)
+	m.pool.useMockSigners()
+	m.backend = &sealValidatorBackend{mockBackend: m.backend.(*mockBackend), verifier: m.pool.verifier()}
+	m.state.view = ViewMsg(1, 0)
+	m.setProposal(&Proposal{Data: mockProposal, Hash: digest})
+	sealHash := m.SealHash(m.state.proposal)
+
+	// the own commit is sealed over the seal hash
+	m.sendCommitMsg()
+	require.Len(t, m.respMsg, 1)
+	assert.NoError(t, m.pool.verifier().Verify("A", sealHash, m.respMsg[0].Seal))
+
+	m.setState(ValidateState)
+	commit := func(from NodeID, content []byte) Event {
+		seal, err := m.pool.signer(from).Sign(content)
+		require.NoError(t, err)
+		msg := createMessage(from, MessageReq_Commit, ViewMsg(1, 0))
+		msg.Hash = digest
+		msg.Seal = seal
+		return MessageEvent(msg)
+	}
+	WithScheduler(NewDeterministicScheduler(
+		commit("B", digest),
+		commit("C", sealHash),
+	))(m.config)
+
+	m.runCycle(context.Background())
+
+	m.expect(expectResult{
+		sequence:              1,
+		state:                 ValidateState,
+		commitMsgs:            2,
+		commitMsgsVotingPower: 2,
+		outgoing:              1, // commit
+	})
+	assert.Contains(t, m.state.committed.messageMap, NodeID("C"))
+	assert.NotContains(t, m.state.committed.messageMap, NodeID("B"))
+}
+
+func TestFinalizationProof_SealHash(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+	pool.useMockSigners()
+	verifier := pool.verifier()
+	validators := pool.validatorSet()
+
+	sealHash := dataSealHash(&Proposal{Data: mockProposal, Hash: digest})
+	sealing := ProofSealing{SealHash: proofSealHash(map[string][]byte{string(digest): sealHash})}
+	proof := &FinalizationProof{Hash: digest, SealHash: sealHash, View: ViewMsg(1, 0)}
+	for _, id := range []NodeID{"A", "B", "C"} {
+		seal, err := pool.signer(id).Sign(sealHash)
+		require.NoError(t, err)
+		proof.CommittedSeals = append(proof.CommittedSeals, CommittedSeal{NodeID: id, Signature: seal})
+	}
+	assert.NoError(t, VerifyFinalizationProof(proof, validators, verifier.Verify, sealing))
+
+	// the seals do not verify against the proposal hash
+	plain := *proof
+	plain.SealHash = nil
+	assert.Error(t, VerifyFinalizationProof(&plain, validators, verifier.Verify, ProofSealing{}))
+
+	// the seal hash is rebuilt by the verifier, a proof without it still verifies
+	assert.NoError(t, VerifyFinalizationProof(&plain, validators, verifier.Verify, sealing))
+
+	// a seal hash is rejected when the verifier seals the proposal hash
+	assert.ErrorIs(t, VerifyFinalizationProof(proof, validators, verifier.Verify, ProofSealing{}), ErrInvalidSealHash)
+
+	// the seals of a proposal do not vouch for the swapped hash of another one
+	swapped := *proof
+	swapped.Hash = digest1
+	assert.ErrorIs(t, VerifyFinalizationProof(&swapped, validators, verifier.Verify, sealing), ErrInvalidSealHash)
+
+	// the seal hash survives the binary encoding
+	data, err := proof.MarshalBinary()
+	require.NoError(t, err)
+	decoded := &FinalizationProof{}
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, proof.SealHash, decoded.SealHash)
+	assert.Nil(t, decoded.AggregatedSignature)
+	assert.NoError(t, VerifyFinalizationProof(decoded, validators, verifier.Verify, sealing))
+}
+
+// proofSealHash rebuilds the seal hashes of the known proposals, by hash
+func proofSealHash(sealHashes map[string][]byte) ProofSealHashFunc {
+	return func(hash []byte) ([]byte, error) {
+		sealHash, ok := sealHashes[string(hash)]
+		if !ok {
+			return nil, fmt.Errorf("unknown proposal %x", hash)
+		}
+		return sealHash, nil
+	}
+}
+
+// Test that a finalization proof whose hash is swapped is not applied, so that the node never trusts the forged
+// hash as the last finalized one.
+func TestPbft_ApplyFinalizationProof_SwappedHash(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.pool.useMockSigners()
+	m.backend = &sealValidatorBackend{mockBackend: m.backend.(*mockBackend), verifier: m.pool.verifier()}
+	WithSealHash(dataSealHash)(m.config)
+	sealHash := dataSealHash(&Proposal{Data: mockProposal, Hash: digest})
+	WithProofSealHash(proofSealHash(map[string][]byte{string(digest): sealHash}))(m.config)
+	m.setSequence(1)
+
+	proof := &FinalizationProof{Hash: digest, SealHash: sealHash, View: ViewMsg(1, 0)}
+	for _, id := range []NodeID{"A", "B", "C"} {
+		seal, err := m.pool.signer(id).Sign(sealHash)
+		require.NoError(t, err)
+		proof.CommittedSeals = append(proof.CommittedSeals, CommittedSeal{NodeID: id, Signature: seal})
+	}
+
+	swapped := *proof
+	swapped.Hash = digest1
+	assert.ErrorIs(t, m.ApplyFinalizationProof(&swapped), ErrInvalidSealHash)
+	assert.Equal(t, uint64(1), m.state.GetSequence())
+	assert.Nil(t, m.lastFinalizedHash)
+
+	require.NoError(t, m.ApplyFinalizationProof(proof))
+	assert.Equal(t, digest, m.lastFinalizedHash)
+}
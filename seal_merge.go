@@ -0,0 +1,73 @@
+package pbft
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrConflictingSeals is returned when a node has two different seals in the merged sets, namely it equivocated
+	ErrConflictingSeals = errors.New("conflicting seals from the same node")
+
+	// ErrProofMismatch is returned when the merged finalization proofs do not refer to the same proposal and view
+	ErrProofMismatch = errors.New("finalization proofs refer to different proposals")
+)
+
+// MergeCommittedSeals returns the union by NodeID of the two seal sets, sorted by NodeID. A node with two
+// different seals is rejected, since only one of them can be valid for the proposal. The seals are expected
+// to be produced over the same proposal (see MergeFinalizationProofs), the merged set still has to be verified.
+func MergeCommittedSeals(a, b []CommittedSeal) ([]CommittedSeal, error) {
+	merged := make(map[NodeID]CommittedSeal, len(a)+len(b))
+	for _, seals := range [][]CommittedSeal{a, b} {
+		for _, seal := range seals {
+			if existing, ok := merged[seal.NodeID]; ok {
+				if !bytes.Equal(existing.Signature, seal.Signature) {
+					return nil, fmt.Errorf("%w: node %s", ErrConflictingSeals, seal.NodeID)
+				}
+				continue
+			}
+			merged[seal.NodeID] = CommittedSeal{NodeID: seal.NodeID, Signature: append([]byte{}, seal.Signature...)}
+		}
+	}
+
+	seals := make([]CommittedSeal, 0, len(merged))
+	for _, seal := range merged {
+		seals = append(seals, seal)
+	}
+	sort.Slice(seals, func(i, j int) bool { return seals[i].NodeID < seals[j].NodeID })
+	return seals, nil
+}
+
+// MergeFinalizationProofs merges two partial finalization proofs of the same proposal and view (i.e. collected
+// from different peers while syncing). The merged proof can then be checked with VerifyFinalizationProof.
+func MergeFinalizationProofs(a, b *FinalizationProof) (*FinalizationProof, error) {
+	if a == nil || b == nil || a.View == nil || b.View == nil {
+		return nil, errEmptyProof
+	}
+	if !bytes.Equal(a.Hash, b.Hash) || !bytes.Equal(a.SealHash, b.SealHash) || !bytes.Equal(a.ParentHash, b.ParentHash) || *a.View != *b.View {
+		return nil, fmt.Errorf("%w: %x in %v and %x in %v", ErrProofMismatch, a.Hash, a.View, b.Hash, b.View)
+	}
+
+	seals, err := MergeCommittedSeals(a.CommittedSeals, b.CommittedSeals)
+	if err != nil {
+		return nil, err
+	}
+	merged := &FinalizationProof{
+		Hash:           append([]byte{}, a.Hash...),
+		View:           a.View.Copy(),
+		CommittedSeals: seals,
+	}
+	if len(a.SealHash) != 0 {
+		merged.SealHash = append([]byte{}, a.SealHash...)
+	}
+	if len(a.ParentHash) != 0 {
+		merged.ParentHash = append([]byte{}, a.ParentHash...)
+	}
+	merged.NextValidators = a.NextValidators
+	if merged.NextValidators == nil {
+		merged.NextValidators = b.NextValidators
+	}
+	return merged, nil
+}
@@ -0,0 +1,52 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeCommittedSeals(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	validators := NewValStringStub(validatorIds, CreateEqualVotingPowerMap(validatorIds))
+
+	// two sub-quorum proofs of the same proposal, overlapping on B
+	a := echoProof(1, "A", "B")
+	b := echoProof(1, "D", "B")
+	require.ErrorIs(t, VerifyFinalizationProof(a, validators, verifyEchoSeal, ProofSealing{}), errInsufficientSeals)
+	require.ErrorIs(t, VerifyFinalizationProof(b, validators, verifyEchoSeal, ProofSealing{}), errInsufficientSeals)
+
+	seals, err := MergeCommittedSeals(a.CommittedSeals, b.CommittedSeals)
+	require.NoError(t, err)
+	signers := []NodeID{}
+	for _, seal := range seals {
+		signers = append(signers, seal.NodeID)
+	}
+	assert.Equal(t, []NodeID{"A", "B", "D"}, signers)
+
+	merged, err := MergeFinalizationProofs(a, b)
+	require.NoError(t, err)
+	assert.Equal(t, seals, merged.CommittedSeals)
+	assert.NoError(t, VerifyFinalizationProof(merged, validators, verifyEchoSeal, ProofSealing{}))
+}
+
+func TestMergeCommittedSeals_Invalid(t *testing.T) {
+	// B equivocates
+	_, err := MergeCommittedSeals(
+		[]CommittedSeal{{NodeID: "A", Signature: []byte{0x1}}, {NodeID: "B", Signature: []byte{0x1}}},
+		[]CommittedSeal{{NodeID: "B", Signature: []byte{0x2}}},
+	)
+	assert.ErrorIs(t, err, ErrConflictingSeals)
+
+	// proofs of different proposals or views
+	_, err = MergeFinalizationProofs(echoProof(1, "A"), echoProof(2, "B"))
+	assert.ErrorIs(t, err, ErrProofMismatch)
+	otherRound := echoProof(1, "B")
+	otherRound.View.Round = 1
+	_, err = MergeFinalizationProofs(echoProof(1, "A"), otherRound)
+	assert.ErrorIs(t, err, ErrProofMismatch)
+
+	_, err = MergeFinalizationProofs(echoProof(1, "A"), nil)
+	assert.ErrorIs(t, err, errEmptyProof)
+}
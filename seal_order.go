@@ -0,0 +1,125 @@
+package pbft
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SealOrdering is the order of the committed seals of a sealed proposal
+type SealOrdering uint8
+
+const (
+	// SealOrdering_NodeID orders the seals by the NodeID of the signer
+	SealOrdering_NodeID SealOrdering = iota
+
+	// SealOrdering_ValidatorIndex orders the seals by the index of the signer in the validator set,
+	// namely the order of the proposer rotation of the set
+	SealOrdering_ValidatorIndex
+
+	// SealOrdering_SigningTime orders the seals by the time their commit messages were received
+	SealOrdering_SigningTime
+)
+
+func (o SealOrdering) String() string {
+	switch o {
+	case SealOrdering_NodeID:
+		return "NodeID"
+	case SealOrdering_ValidatorIndex:
+		return "ValidatorIndex"
+	case SealOrdering_SigningTime:
+		return "SigningTime"
+	default:
+		return fmt.Sprintf("SealOrdering(%d)", uint8(o))
+	}
+}
+
+// SealSelection is the subset of the committed seals included in a sealed proposal
+type SealSelection uint8
+
+const (
+	// SealSelection_All includes the seals of all the commit messages received, for the maximum provability
+	SealSelection_All SealSelection = iota
+
+	// SealSelection_Minimal includes only the seals needed to reach the commit quorum, to keep the sealed proposals compact.
+	// The signers are picked deterministically, by descending voting power and then by NodeID, never by arrival,
+	// so that the nodes receiving the same commit messages build the same seal set. For the same reason,
+	// the SealOrdering_SigningTime ordering is replaced by the selection order.
+	SealSelection_Minimal
+)
+
+func (s SealSelection) String() string {
+	switch s {
+	case SealSelection_All:
+		return "All"
+	case SealSelection_Minimal:
+		return "Minimal"
+	default:
+		return fmt.Sprintf("SealSelection(%d)", uint8(s))
+	}
+}
+
+// selectMinimalSigners returns the signers, in their original order, that are needed to reach the quorum
+// picking them by descending voting power and then by NodeID. All the signers are returned if they do not reach it.
+func selectMinimalSigners(votingPower map[NodeID]uint64, signers []NodeID, quorum uint64) []NodeID {
+	candidates := orderByVotingPower(votingPower, signers)
+
+	selected := make(map[NodeID]struct{}, len(candidates))
+	accumulated := uint64(0)
+	for _, id := range candidates {
+		if accumulated >= quorum {
+			break
+		}
+		selected[id] = struct{}{}
+		accumulated += votingPower[id]
+	}
+
+	minimal := make([]NodeID, 0, len(selected))
+	for _, id := range signers {
+		if _, ok := selected[id]; ok {
+			minimal = append(minimal, id)
+		}
+	}
+	return minimal
+}
+
+// orderByVotingPower returns the signers ordered by descending voting power and then by NodeID
+func orderByVotingPower(votingPower map[NodeID]uint64, signers []NodeID) []NodeID {
+	ordered := append([]NodeID{}, signers...)
+	sort.Slice(ordered, func(i, j int) bool {
+		if votingPower[ordered[i]] != votingPower[ordered[j]] {
+			return votingPower[ordered[i]] > votingPower[ordered[j]]
+		}
+		return ordered[i] < ordered[j]
+	})
+	return ordered
+}
+
+// validatorIndices returns the index of each validator in the validator set, namely in the order of the proposer
+// rotation of the set (see validatorOrder)
+func validatorIndices(validators ValidatorSet) map[NodeID]int {
+	order := validatorOrder(validators)
+	index := make(map[NodeID]int, len(order))
+	for i, id := range order {
+		index[id] = i
+	}
+	return index
+}
+
+// orderByValidatorIndex returns the signers ordered by their index in the validator set. Each signer is placed
+// in the slot of its index, so the order is built in linear time rather than with a comparison sort.
+func orderByValidatorIndex(index map[NodeID]int, signers []NodeID) []NodeID {
+	slots := make([]NodeID, len(index))
+	for _, id := range signers {
+		if i, ok := index[id]; ok {
+			slots[i] = id
+		}
+	}
+
+	ordered := make([]NodeID, 0, len(signers))
+	for _, id := range slots {
+		if id != "" {
+			ordered = append(ordered, id)
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,30 @@
+package pbft
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrDuplicateSigner is returned when a node has more than one seal in a seal set
+var ErrDuplicateSigner = errors.New("duplicate seal signer")
+
+// CommittedSealsSet is a set of committed seals, i.e. the CommittedSeals of a SealedProposal or of a FinalizationProof
+type CommittedSealsSet []CommittedSeal
+
+// Signers returns the validators that contributed the seals, sorted by NodeID. A node with more than one seal
+// is rejected, so that the voting power of the signers can be summed without counting a node twice.
+// The seals are not verified.
+func (s CommittedSealsSet) Signers() ([]NodeID, error) {
+	seen := make(map[NodeID]struct{}, len(s))
+	signers := make([]NodeID, 0, len(s))
+	for _, seal := range s {
+		if _, ok := seen[seal.NodeID]; ok {
+			return nil, fmt.Errorf("%w: node %s", ErrDuplicateSigner, seal.NodeID)
+		}
+		seen[seal.NodeID] = struct{}{}
+		signers = append(signers, seal.NodeID)
+	}
+	sort.Slice(signers, func(i, j int) bool { return signers[i] < signers[j] })
+	return signers, nil
+}
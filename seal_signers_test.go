@@ -0,0 +1,33 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommittedSealsSet_Signers(t *testing.T) {
+	seals := CommittedSealsSet{
+		{NodeID: "C", Signature: []byte{0x3}},
+		{NodeID: "A", Signature: []byte{0x1}},
+		{NodeID: "B", Signature: []byte{0x2}},
+	}
+	signers, err := seals.Signers()
+	require.NoError(t, err)
+	assert.Equal(t, []NodeID{"A", "B", "C"}, signers)
+
+	// the signers of a sealed proposal
+	sealed := &SealedProposal{CommittedSeals: seals[:2]}
+	signers, err = CommittedSealsSet(sealed.CommittedSeals).Signers()
+	require.NoError(t, err)
+	assert.Equal(t, []NodeID{"A", "C"}, signers)
+
+	empty, err := CommittedSealsSet{}.Signers()
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	// a node with two seals, even the same one, is rejected
+	_, err = append(seals, CommittedSeal{NodeID: "A", Signature: []byte{0x1}}).Signers()
+	assert.ErrorIs(t, err, ErrDuplicateSigner)
+}
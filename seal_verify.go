@@ -0,0 +1,66 @@
+package pbft
+
+import (
+	"runtime"
+	"sync"
+)
+
+// SealItem is a committed seal to verify
+type SealItem struct {
+	// From is the node that produced the seal
+	From NodeID
+
+	// Hash is the sealed proposal hash
+	Hash []byte
+
+	// Seal is the committed seal
+	Seal []byte
+}
+
+// BatchVerifier is an optional interface the Backend can implement to verify many committed seals together
+// (i.e. with aggregated signatures). The returned slice holds the verification result of each item.
+type BatchVerifier interface {
+	BatchVerify(items []SealItem) []error
+}
+
+// BatchVerifySeals verifies the items in parallel with a pool of workers using the given SealVerifier.
+// The number of workers defaults to GOMAXPROCS when it is not positive.
+// The returned slice holds the verification result of each item.
+func BatchVerifySeals(items []SealItem, verifySeal SealVerifier, workers int) []error {
+	errs := make([]error, len(items))
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				item := items[index]
+				errs[index] = verifySeal(item.From, item.Hash, item.Seal)
+			}
+		}()
+	}
+	for index := range items {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errs
+}
+
+// batchVerifySeals verifies the committed seals with the backend, in a single batch if the backend
+// implements BatchVerifier, otherwise in parallel with the backend ValidateSeal (or ValidateCommit)
+func (p *Pbft) batchVerifySeals(items []SealItem) []error {
+	if verifier, ok := p.backend.(BatchVerifier); ok {
+		return verifier.BatchVerify(items)
+	}
+	return BatchVerifySeals(items, p.validateCommitSeal, 0)
+}
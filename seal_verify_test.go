@@ -0,0 +1,114 @@
+package pbft
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchVerifySeals(t *testing.T) {
+	items := []SealItem{}
+	for i := 0; i < 20; i++ {
+		items = append(items, SealItem{From: NodeID(fmt.Sprintf("node%d", i)), Hash: digest, Seal: []byte{byte(i)}})
+	}
+	errBadSeal := errors.New("bad seal")
+	verifyEven := func(_ NodeID, _ []byte, seal []byte) error {
+		if seal[0]%2 == 1 {
+			return errBadSeal
+		}
+		return nil
+	}
+
+	for _, workers := range []int{0, 1, 3, 100} {
+		errs := BatchVerifySeals(items, verifyEven, workers)
+		require.Len(t, errs, len(items))
+		for i, err := range errs {
+			if i%2 == 1 {
+				assert.ErrorIs(t, err, errBadSeal)
+			} else {
+				assert.NoError(t, err)
+			}
+		}
+	}
+
+	assert.Empty(t, BatchVerifySeals(nil, verifyEven, 0))
+}
+
+type mockBatchBackend struct {
+	*mockBackend
+	batches int
+}
+
+func (m *mockBatchBackend) BatchVerify(items []SealItem) []error {
+	m.batches++
+	return make([]error, len(items))
+}
+
+func TestPbft_BatchVerifySeals(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
+	items := []SealItem{{From: "A", Hash: digest}, {From: "B", Hash: digest}}
+
+	// the backend does not batch, seals are verified one by one with ValidateCommit
+	assert.Equal(t, []error{nil, nil}, m.batchVerifySeals(items))
+
+	backend := &mockBatchBackend{mockBackend: m.backend.(*mockBackend)}
+	m.backend = backend
+	assert.Equal(t, []error{nil, nil}, m.batchVerifySeals(items))
+	assert.Equal(t, 1, backend.batches)
+}
+
+func ecdsaSealItems(b *testing.B, n int) ([]SealItem, SealVerifier) {
+	hash := sha256.Sum256([]byte("proposal"))
+	keys := map[NodeID]*ecdsa.PublicKey{}
+	items := make([]SealItem, n)
+	for i := 0; i < n; i++ {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(b, err)
+		seal, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+		require.NoError(b, err)
+
+		id := NodeID(fmt.Sprintf("node%d", i))
+		keys[id] = &key.PublicKey
+		items[i] = SealItem{From: id, Hash: hash[:], Seal: seal}
+	}
+	verify := func(from NodeID, hash []byte, seal []byte) error {
+		if !ecdsa.VerifyASN1(keys[from], hash, seal) {
+			return errors.New("invalid seal")
+		}
+		return nil
+	}
+	return items, verify
+}
+
+func BenchmarkVerifySeals_Sequential(b *testing.B) {
+	items, verify := ecdsaSealItems(b, 100)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, item := range items {
+			if err := verify(item.From, item.Hash, item.Seal); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkVerifySeals_Batched(b *testing.B) {
+	items, verify := ecdsaSealItems(b, 100)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, err := range BatchVerifySeals(items, verify, 0) {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
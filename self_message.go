@@ -0,0 +1,61 @@
+package pbft
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// SelfMessagePolicy is the handling of the messages received from the transport with the NodeID of the node,
+// namely the echoes of the messages gossiped by the node itself
+type SelfMessagePolicy uint8
+
+const (
+	// SelfMessage_Accept processes the echoes like any other message
+	SelfMessage_Accept SelfMessagePolicy = iota
+
+	// SelfMessage_Drop discards the echoes, the node only processes the local copy of its messages
+	SelfMessage_Drop
+
+	// SelfMessage_Confirm discards the echoes and counts them as the confirmation that the transport
+	// delivered the messages of the node
+	SelfMessage_Confirm
+)
+
+func (s SelfMessagePolicy) String() string {
+	switch s {
+	case SelfMessage_Accept:
+		return "Accept"
+	case SelfMessage_Drop:
+		return "Drop"
+	case SelfMessage_Confirm:
+		return "Confirm"
+	default:
+		return fmt.Sprintf("SelfMessagePolicy(%d)", uint8(s))
+	}
+}
+
+// handleSelfMessage applies the self message policy to a message received from the transport.
+// It returns true if the message has been consumed and must not be queued.
+func (p *Pbft) handleSelfMessage(msg *MessageReq) bool {
+	if msg.From != p.state.selfID {
+		return false
+	}
+
+	switch p.config.SelfMessagePolicy {
+	case SelfMessage_Drop:
+		p.logger.Printf("[TRACE] dropped self message %s", msg)
+		return true
+	case SelfMessage_Confirm:
+		atomic.AddUint64(&p.selfConfirmations, 1)
+		p.logger.Printf("[TRACE] self message %s confirmed by the transport", msg)
+		return true
+	default:
+		return false
+	}
+}
+
+// SelfConfirmations returns the number of echoes of its own messages received by the node
+// with the SelfMessage_Confirm policy
+func (p *Pbft) SelfConfirmations() uint64 {
+	return atomic.LoadUint64(&p.selfConfirmations)
+}
@@ -0,0 +1,50 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPbft_SelfMessagePolicy(t *testing.T) {
+	cases := []struct {
+		policy        SelfMessagePolicy
+		queued        int
+		confirmations uint64
+	}{
+		{SelfMessage_Accept, 2, 0},
+		{SelfMessage_Drop, 1, 0},
+		{SelfMessage_Confirm, 1, 1},
+	}
+	for _, c := range cases {
+		t.Run(c.policy.String(), func(t *testing.T) {
+			m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+			WithSelfMessagePolicy(c.policy)(m.config)
+			assert.Equal(t, NodeID("A"), m.state.selfID)
+
+			m.state.view = ViewMsg(1, 0)
+			m.setProposal(&Proposal{Data: mockProposal})
+
+			// the local copy of the gossiped prepare is always processed
+			m.gossip(MessageReq_Prepare)
+			assert.Len(t, m.respMsg, 1)
+
+			// the transport echoes the prepare back to the node
+			m.emitMsg(m.respMsg[0].Copy())
+
+			assert.Equal(t, c.queued, m.msgQueue.validateStateQueue.Len())
+			assert.Equal(t, c.confirmations, m.SelfConfirmations())
+		})
+	}
+}
+
+// Test that the messages of the other validators are not affected by the self message policy.
+func TestPbft_SelfMessagePolicy_OtherSenders(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithSelfMessagePolicy(SelfMessage_Drop)(m.config)
+
+	m.emitMsg(createMessage("B", MessageReq_Prepare, ViewMsg(1, 0)))
+
+	assert.Equal(t, 1, m.msgQueue.validateStateQueue.Len())
+	assert.Zero(t, m.SelfConfirmations())
+}
@@ -0,0 +1,11 @@
+package pbft
+
+// sequenceDeadlineExceeded returns whether the node has been on the current sequence for longer than the
+// SequenceDeadline, regardless of the round. It is checked, with the Clock, every time the node waits for a message,
+// so the round timeouts bound the delay of the check.
+func (p *Pbft) sequenceDeadlineExceeded() bool {
+	if p.config.SequenceDeadline <= 0 || p.sequenceEntered.IsZero() {
+		return false
+	}
+	return p.config.Clock.Now().Sub(p.sequenceEntered) >= p.config.SequenceDeadline
+}
@@ -0,0 +1,34 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that a node exceeding the sequence deadline moves to the sync state, whatever its round.
+func TestTransition_RoundChangeState_SequenceDeadline(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+	WithClock(clock)(m.config)
+	WithSequenceDeadline(time.Minute)(m.config)
+	m.setSequence(1)
+
+	// the round changes do not reset the deadline
+	clock.Advance(50 * time.Second)
+	m.setRound(5)
+	assert.False(t, m.sequenceDeadlineExceeded())
+
+	clock.Advance(10 * time.Second)
+	assert.True(t, m.sequenceDeadlineExceeded())
+	m.setState(RoundChangeState)
+	m.runCycle(context.Background())
+
+	assert.Equal(t, SyncState, m.getState())
+
+	// the deadline restarts with the next sequence
+	m.setSequence(2)
+	assert.False(t, m.sequenceDeadlineExceeded())
+}
@@ -0,0 +1,66 @@
+package pbft
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrValidatorSetTooSmall is returned when the validator set is smaller than the configured minimum
+// and the SmallValidatorSet_Halt policy is set
+var ErrValidatorSetTooSmall = errors.New("validator set is too small to tolerate faults")
+
+// ErrValidatorSetTooLarge is returned when the validator set is larger than the configured maximum (see WithMaxValidators)
+var ErrValidatorSetTooLarge = errors.New("validator set is too large")
+
+// SmallValidatorSetPolicy is the handling of a validator set smaller than the configured minimum (see WithMinValidators),
+// namely a set that can not tolerate any faulty validator with the default minimum of 4
+type SmallValidatorSetPolicy uint8
+
+const (
+	// SmallValidatorSet_Degraded keeps running without fault tolerance, warning at every validator set update
+	SmallValidatorSet_Degraded SmallValidatorSetPolicy = iota
+
+	// SmallValidatorSet_Halt refuses the validator set, the state machine does not start the sequence
+	SmallValidatorSet_Halt
+)
+
+func (s SmallValidatorSetPolicy) String() string {
+	switch s {
+	case SmallValidatorSet_Degraded:
+		return "Degraded"
+	case SmallValidatorSet_Halt:
+		return "Halt"
+	default:
+		return fmt.Sprintf("SmallValidatorSetPolicy(%d)", uint8(s))
+	}
+}
+
+// checkValidatorSetSize applies the small validator set policy to the current validator set
+func (p *Pbft) checkValidatorSetSize() error {
+	size := p.state.validators.Len()
+	p.degraded = size < p.config.MinValidators
+	if !p.degraded {
+		return nil
+	}
+
+	if p.config.SmallValidatorSetPolicy == SmallValidatorSet_Halt {
+		return fmt.Errorf("%w: %d validators, at least %d required", ErrValidatorSetTooSmall, size, p.config.MinValidators)
+	}
+	p.logger.Printf("[WARN] running in degraded mode without fault tolerance: %d validators, at least %d required, max faulty voting power=%d",
+		size, p.config.MinValidators, p.state.getMaxFaultyVotingPower())
+	return nil
+}
+
+// checkMaxValidators refuses a validator set larger than the configured maximum, before it replaces the current one
+func (p *Pbft) checkMaxValidators(validators ValidatorSet) error {
+	if max := p.config.MaxValidators; max > 0 && validators.Len() > max {
+		return fmt.Errorf("%w: %d validators, at most %d allowed", ErrValidatorSetTooLarge, validators.Len(), max)
+	}
+	return nil
+}
+
+// IsDegraded returns whether the current validator set is smaller than the configured minimum,
+// namely the node runs without fault tolerance
+func (p *Pbft) IsDegraded() bool {
+	return p.degraded
+}
@@ -0,0 +1,65 @@
+package pbft
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPbft_SmallValidatorSet(t *testing.T) {
+	cases := []struct {
+		validators []NodeID
+		degraded   bool
+	}{
+		{[]NodeID{"A", "B", "C"}, true},
+		{[]NodeID{"A", "B", "C", "D"}, false},
+		{[]NodeID{"A", "B", "C", "D", "E"}, false},
+	}
+	for _, c := range cases {
+		for _, policy := range []SmallValidatorSetPolicy{SmallValidatorSet_Degraded, SmallValidatorSet_Halt} {
+			t.Run(fmt.Sprintf("%d validators %s", len(c.validators), policy), func(t *testing.T) {
+				m := newMockPbft(t, c.validators, nil, "A")
+				WithMinValidators(defaultMinValidators, policy)(m.config)
+
+				err := m.SetBackend(m.backend)
+
+				if c.degraded && policy == SmallValidatorSet_Halt {
+					assert.ErrorIs(t, err, ErrValidatorSetTooSmall)
+				} else {
+					assert.NoError(t, err)
+				}
+				assert.Equal(t, c.degraded, m.IsDegraded())
+			})
+		}
+	}
+}
+
+func TestPbft_MaxValidators(t *testing.T) {
+	cases := []struct {
+		validators []NodeID
+		err        bool
+	}{
+		{[]NodeID{"A", "B", "C", "D"}, false},
+		{[]NodeID{"A", "B", "C", "D", "E"}, false},
+		{[]NodeID{"A", "B", "C", "D", "E", "F"}, true},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%d validators", len(c.validators)), func(t *testing.T) {
+			m := newMockPbft(t, c.validators, nil, "A")
+			WithMaxValidators(5)(m.config)
+			previous := m.state.validators
+
+			err := m.SetBackend(m.backend)
+
+			if c.err {
+				assert.ErrorIs(t, err, ErrValidatorSetTooLarge)
+				// the oversized set is not applied
+				assert.Equal(t, previous, m.state.validators)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, len(c.validators), m.state.validators.Len())
+			}
+		})
+	}
+}
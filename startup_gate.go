@@ -0,0 +1,113 @@
+package pbft
+
+import (
+	"context"
+	"sync"
+)
+
+// startupGate holds back the first proposal of the node until enough peers are known to be online
+// (see WithStartupPeers and WithStartupQuorum). Once open, it stays open.
+type startupGate struct {
+	lock sync.Mutex
+
+	// peers are the nodes observed online, from their messages or ObservePeer
+	peers map[NodeID]struct{}
+
+	// open is whether the threshold was met
+	open bool
+
+	// notifyCh is signaled when a new peer is observed
+	notifyCh chan struct{}
+}
+
+func newStartupGate() *startupGate {
+	return &startupGate{
+		peers:    map[NodeID]struct{}{},
+		notifyCh: make(chan struct{}, 1),
+	}
+}
+
+// observe records the peer as online
+func (g *startupGate) observe(peer NodeID) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.open {
+		return
+	}
+	if _, ok := g.peers[peer]; ok {
+		return
+	}
+	g.peers[peer] = struct{}{}
+
+	select {
+	case g.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// ObservePeer reports a peer as online to the startup gate (see WithStartupPeers), e.g. from the peer discovery
+// of the transport. The peers sending consensus messages are observed without it.
+func (p *Pbft) ObservePeer(peer NodeID) {
+	if p.startup != nil {
+		p.startup.observe(peer)
+	}
+}
+
+// startupReady returns whether the peers observed online meet the configured threshold, and opens the gate if so.
+// Only the validators of the current set are counted.
+func (p *Pbft) startupReady() bool {
+	g := p.startup
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.open {
+		return true
+	}
+
+	self := p.validator.NodeID()
+	votingPower := p.state.validators.VotingPower()
+	peers, power := 0, votingPower[self]
+	for peer := range g.peers {
+		if peer != self && p.state.validators.Includes(peer) {
+			peers++
+			power += votingPower[peer]
+		}
+	}
+	if peers < p.config.StartupPeers {
+		return false
+	}
+	if p.config.StartupQuorum && power < p.state.getQuorumSize() {
+		return false
+	}
+
+	g.open = true
+	p.logger.Printf("[INFO] startup threshold met: peers=%d, voting power=%d", peers, power)
+	return true
+}
+
+// waitStartupPeers holds back the proposal until the startup threshold is met. If the round times out in the meantime,
+// it starts a round change and returns false, as it does if the context is done.
+func (p *Pbft) waitStartupPeers(ctx context.Context) bool {
+	if p.startup == nil {
+		return true
+	}
+
+	logged := false
+	for !p.startupReady() {
+		if !logged {
+			p.logger.Printf("[INFO] waiting for the peers to come online before proposing")
+			logged = true
+		}
+		select {
+		case <-p.startup.notifyCh:
+		case <-p.state.timeoutChan:
+			p.logger.Printf("[INFO] round timed out waiting for the peers to come online")
+			p.startRoundChange(RoundChange_Timeout)
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStartupMockPbft creates the proposer of the first round, waiting for the startup peers
+func newStartupMockPbft(t *testing.T, opts ...ConfigOption) *mockPbft {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	for _, opt := range opts {
+		opt(m.config)
+	}
+	m.startup = newStartupGate()
+	m.setState(AcceptState)
+	m.setProposal(&Proposal{
+		Data: mockProposal,
+		Time: time.Now(),
+	})
+	return m
+}
+
+func (m *mockPbft) startupOpen() bool {
+	m.startup.lock.Lock()
+	defer m.startup.lock.Unlock()
+
+	return m.startup.open
+}
+
+func TestPbft_StartupReady(t *testing.T) {
+	m := newStartupMockPbft(t, WithStartupQuorum())
+
+	// the node holds a quarter of the voting power, the quorum is three quarters
+	m.ObservePeer("B")
+	assert.False(t, m.startupReady())
+
+	// the node itself and the nodes outside the validator set are not counted
+	m.ObservePeer("A")
+	m.ObservePeer("E")
+	assert.False(t, m.startupReady())
+
+	m.ObservePeer("C")
+	assert.True(t, m.startupReady())
+
+	// once open, the gate stays open
+	m.startup.peers = map[NodeID]struct{}{}
+	assert.True(t, m.startupReady())
+}
+
+// Test that the proposer withholds its first proposal until the peers it received messages from meet the threshold.
+func TestTransition_AcceptState_StartupPeers(t *testing.T) {
+	m := newStartupMockPbft(t, WithStartupPeers(2), WithInboundQueueSize(16))
+	m.state.timeoutChan = time.After(5 * time.Second)
+
+	go func() {
+		// the messages wait in the inbound queue, the node waiting to propose does not read it
+		m.PushMessage(createMessage("B", MessageReq_RoundChange, ViewMsg(1, 0)))
+		time.Sleep(50 * time.Millisecond)
+		assert.False(t, m.startupOpen())
+
+		m.PushMessage(createMessage("C", MessageReq_RoundChange, ViewMsg(1, 0)))
+	}()
+	m.runCycle(context.Background())
+
+	assert.True(t, m.startupOpen())
+	m.expect(expectResult{
+		sequence: 1,
+		outgoing: 2, // preprepare and prepare
+		state:    ValidateState,
+	})
+}
+
+// Test that the round times out while the proposer waits for the startup peers.
+func TestTransition_AcceptState_StartupPeers_Timeout(t *testing.T) {
+	m := newStartupMockPbft(t, WithStartupPeers(2))
+	m.state.timeoutChan = time.After(100 * time.Millisecond)
+
+	m.PushMessage(createMessage("B", MessageReq_RoundChange, ViewMsg(1, 0)))
+	m.runCycle(context.Background())
+
+	assert.False(t, m.startupOpen())
+	assert.Equal(t, RoundChangeState, m.getState())
+	assert.Equal(t, RoundChange_Timeout, m.LastRoundChangeReason())
+	for _, msg := range m.respMsg {
+		require.NotEqual(t, MessageReq_Preprepare, msg.Type)
+	}
+}
@@ -1,15 +1,27 @@
 package pbft
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
 // state defines the current state object in PBFT
 type state struct {
+	// selfID is the NodeID of the node
+	selfID NodeID
+
 	// validators represent the current validator set
 	validators ValidatorSet
 
+	// validatorIndex is the index of each validator in the validator set, set along with the voting info
+	validatorIndex map[NodeID]int
+
 	// state is the current state
 	state uint64
 
@@ -22,6 +34,9 @@ type state struct {
 	// Current view
 	view *View
 
+	// viewLock guards the replacement of the current view, the round is updated atomically
+	viewLock sync.RWMutex
+
 	// List of prepared messages
 	prepared *messages
 
@@ -31,15 +46,43 @@ type state struct {
 	// List of round change messages
 	roundMessages map[uint64]*messages
 
+	// orphans are the prepare messages received before the pre-prepare of their proposal (nil if disabled)
+	orphans *orphanPrepares
+
+	// conflictingPrepares are the prepare messages of the current view referencing another proposal
+	conflictingPrepares *messages
+
+	// conflictingRoundChanges are the senders of round change messages with conflicting justifications, by round
+	conflictingRoundChanges map[uint64]map[NodeID]struct{}
+
+	// roundCommits holds the commit messages of the current sequence (only with CommitRound_Lenient)
+	roundCommits *roundCommits
+
+	// lastSeen is the time of the last message received from each node, it is kept across sequences
+	lastSeen map[NodeID]time.Time
+
+	// msgLock guards the prepared, committed, conflicting, round change and orphan message lists,
+	// the conflicting round change senders and the last seen times against concurrent access
+	msgLock sync.RWMutex
+
 	// maxFaultyVotingPower represents max tolerable faulty voting power in order to have Byzantine fault tollerance property satisfied
 	maxFaultyVotingPower uint64
 
 	// quorumSize represents minimum accumulated voting power needed to proceed to next PBFT state
 	quorumSize uint64
 
+	// prepareQuorumSize is the accumulated voting power of prepare messages needed to lock and commit
+	prepareQuorumSize uint64
+
+	// commitQuorumSize is the accumulated voting power of commit messages needed to finalize
+	commitQuorumSize uint64
+
 	// Locked signals whether the proposal is locked
 	locked uint64
 
+	// lockedRound is the round in which the proposal got locked
+	lockedRound uint64
+
 	// timeout tracks the time left for this round
 	timeoutChan <-chan time.Time
 
@@ -52,7 +95,9 @@ func newState() *state {
 	c := &state{
 		// this is a default value, it will get reset
 		// at every iteration
-		timeoutChan: nil,
+		timeoutChan:  nil,
+		roundCommits: newRoundCommits(),
+		lastSeen:     map[NodeID]time.Time{},
 	}
 
 	c.resetRoundMsgs()
@@ -69,6 +114,46 @@ func (s *state) initializeVotingInfo() error {
 	}
 	s.maxFaultyVotingPower = maxFaultyVotingPower
 	s.quorumSize = quorumSize
+	s.validatorIndex = validatorIndices(s.validators)
+	s.prepareQuorumSize = quorumSize
+	s.commitQuorumSize = quorumSize
+	return nil
+}
+
+// initializePhaseQuorums sets the prepare and commit quorum sizes out of the given calculators (nil keeps the quorum size).
+// The phase quorums can not be lower than the quorum size, since it would break safety, nor higher than the total voting power.
+// The commit quorum can not be lower than the prepare quorum.
+func (s *state) initializePhaseQuorums(prepareQuorum, commitQuorum QuorumCalculator) error {
+	totalVotingPower := uint64(0)
+	for _, power := range s.validators.VotingPower() {
+		totalVotingPower += power
+	}
+
+	calculate := func(calculator QuorumCalculator) (uint64, error) {
+		if calculator == nil {
+			return s.quorumSize, nil
+		}
+		size := calculator(totalVotingPower)
+		if size < s.quorumSize || size > totalVotingPower {
+			return 0, fmt.Errorf("%w: %d is out of range [%d, %d]", errInvalidPhaseQuorum, size, s.quorumSize, totalVotingPower)
+		}
+		return size, nil
+	}
+
+	prepareQuorumSize, err := calculate(prepareQuorum)
+	if err != nil {
+		return err
+	}
+	commitQuorumSize, err := calculate(commitQuorum)
+	if err != nil {
+		return err
+	}
+	if commitQuorumSize < prepareQuorumSize {
+		return fmt.Errorf("%w: commit quorum %d is lower than prepare quorum %d", errInvalidPhaseQuorum, commitQuorumSize, prepareQuorumSize)
+	}
+
+	s.prepareQuorumSize = prepareQuorumSize
+	s.commitQuorumSize = commitQuorumSize
 	return nil
 }
 
@@ -79,6 +164,16 @@ func (s *state) getQuorumSize() uint64 {
 	return s.quorumSize
 }
 
+// getPrepareQuorumSize returns the accumulated voting power of prepare messages needed to lock and commit
+func (s *state) getPrepareQuorumSize() uint64 {
+	return s.prepareQuorumSize
+}
+
+// getCommitQuorumSize returns the accumulated voting power of commit messages needed to finalize
+func (s *state) getCommitQuorumSize() uint64 {
+	return s.commitQuorumSize
+}
+
 // getMaxFaultyVotingPower is calculated as at most 1/3 of total voting power of the entire validator set.
 func (s *state) getMaxFaultyVotingPower() uint64 {
 	return s.maxFaultyVotingPower
@@ -89,18 +184,116 @@ func (s *state) IsLocked() bool {
 }
 
 func (s *state) GetSequence() uint64 {
+	s.viewLock.RLock()
+	defer s.viewLock.RUnlock()
+
 	return s.view.Sequence
 }
 
-func (s *state) getCommittedSeals() []CommittedSeal {
-	committedSeals := make([]CommittedSeal, 0, len(s.committed.messageMap))
-	for nodeId, commit := range s.committed.messageMap {
-		committedSeals = append(committedSeals, CommittedSeal{Signature: commit.Seal, NodeID: nodeId})
+// CurrentView returns a copy of the current view, it is safe to call concurrently with the state machine
+func (s *state) CurrentView() View {
+	s.viewLock.RLock()
+	defer s.viewLock.RUnlock()
+
+	if s.view == nil {
+		return View{}
+	}
+	return View{
+		Sequence: s.view.Sequence,
+		Round:    atomic.LoadUint64(&s.view.Round),
+	}
+}
+
+// setView replaces the current view
+func (s *state) setView(view *View) {
+	s.viewLock.Lock()
+	defer s.viewLock.Unlock()
+
+	s.view = view
+}
+
+// getCommittedSeals returns the selected seals of the committed messages in the given order
+func (s *state) getCommittedSeals(ordering SealOrdering, selection SealSelection) []CommittedSeal {
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	arrival := s.committed.arrival
+	if selection == SealSelection_Minimal {
+		arrival = selectMinimalSigners(s.validators.VotingPower(), arrival, s.getCommitQuorumSize())
+	}
+
+	var signers []NodeID
+	switch ordering {
+	case SealOrdering_ValidatorIndex:
+		index := s.validatorIndex
+		if index == nil {
+			index = validatorIndices(s.validators)
+		}
+		signers = orderByValidatorIndex(index, arrival)
+	case SealOrdering_SigningTime:
+		if selection == SealSelection_Minimal {
+			// the arrival order is not agreed across the nodes
+			signers = orderByVotingPower(s.validators.VotingPower(), arrival)
+			break
+		}
+		signers = append(signers, arrival...)
+	default:
+		signers = append(signers, arrival...)
+		sort.Slice(signers, func(i, j int) bool { return signers[i] < signers[j] })
+	}
+
+	committedSeals := make([]CommittedSeal, 0, len(signers))
+	for _, nodeId := range signers {
+		committedSeals = append(committedSeals, CommittedSeal{Signature: s.committed.messageMap[nodeId].Seal, NodeID: nodeId})
 	}
 
 	return committedSeals
 }
 
+// assertCommittedAgreement verifies that all the committed messages reference the same proposal hash.
+// The dissenters are the senders not referencing the most common hash (the earliest one on a tie).
+func (s *state) assertCommittedAgreement() error {
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	counts := map[string]int{}
+	var expected []byte
+	for _, nodeId := range s.committed.arrival {
+		hash := s.committed.messageMap[nodeId].Hash
+		counts[string(hash)]++
+		if counts[string(hash)] > counts[string(expected)] {
+			expected = hash
+		}
+	}
+	if len(counts) <= 1 {
+		return nil
+	}
+
+	dissenters := []string{}
+	for _, nodeId := range s.committed.arrival {
+		if !bytes.Equal(s.committed.messageMap[nodeId].Hash, expected) {
+			dissenters = append(dissenters, string(nodeId))
+		}
+	}
+	sort.Strings(dissenters)
+	return fmt.Errorf("%w: expected hash %x, dissenters: %s", ErrCommittedDisagreement, expected, strings.Join(dissenters, ", "))
+}
+
+// committedVotingPowerFor returns the accumulated voting power of the committed messages referencing the given proposal hash
+func (s *state) committedVotingPowerFor(hash []byte) uint64 {
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	votingPower := s.validators.VotingPower()
+	accumulated := uint64(0)
+	for nodeId, msg := range s.committed.messageMap {
+		if bytes.Equal(msg.Hash, hash) {
+			accumulated += votingPower[nodeId]
+		}
+	}
+	return accumulated
+}
+
 // getState returns the current state
 func (s *state) getState() State {
 	stateAddr := &s.state
@@ -124,96 +317,210 @@ func (s *state) getErr() error {
 }
 
 // maxRound tries to resolve the round node should fast-track, based on round change messages.
-// Quorum size for fast-track higher round is F+1 round change messages (where F denotes max faulty voting power)
+// Quorum size for fast-track higher round is F+1 round change messages (where F denotes max faulty voting power).
+// When several rounds reach it, the highest one is returned (see RoundSelection).
 func (s *state) maxRound() (maxRound uint64, found bool) {
-	for currentRound, messages := range s.roundMessages {
-		if messages.getAccumulatedVotingPower() < s.getMaxFaultyVotingPower()+1 {
-			continue
+	return s.selectRound(RoundSelection_Highest)
+}
+
+// highestObservedRound returns the highest round of the round change, prepared and committed messages.
+// Unlike maxRound, it does not require a quorum of round change messages.
+func (s *state) highestObservedRound() uint64 {
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	highest := uint64(0)
+	for round := range s.roundMessages {
+		if round > highest {
+			highest = round
 		}
-		if maxRound < currentRound {
-			maxRound = currentRound
-			found = true
+	}
+	for _, msgs := range []*messages{s.prepared, s.committed} {
+		for _, msg := range msgs.messageMap {
+			if msg.View != nil && msg.View.Round > highest {
+				highest = msg.View.Round
+			}
 		}
 	}
+	return highest
+}
 
-	return
+// RangeRoundMessages calls fn with the round change messages of each round, in ascending round order.
+// The messages are copies taken under the state lock, so fn can keep them and must not call back into the state.
+// The iteration stops when fn returns false.
+func (s *state) RangeRoundMessages(fn func(round uint64, msgs map[NodeID]*MessageReq) bool) {
+	s.msgLock.RLock()
+	rounds := make([]uint64, 0, len(s.roundMessages))
+	copies := make(map[uint64]map[NodeID]*MessageReq, len(s.roundMessages))
+	for round, msgs := range s.roundMessages {
+		rounds = append(rounds, round)
+		copied := make(map[NodeID]*MessageReq, len(msgs.messageMap))
+		for from, msg := range msgs.messageMap {
+			copied[from] = msg.Copy()
+		}
+		copies[round] = copied
+	}
+	s.msgLock.RUnlock()
+
+	sort.Slice(rounds, func(i, j int) bool {
+		return rounds[i] < rounds[j]
+	})
+	for _, round := range rounds {
+		if !fn(round, copies[round]) {
+			return
+		}
+	}
 }
 
 // resetRoundMsgs resets the prepared, committed and round messages in the current state
 func (s *state) resetRoundMsgs() {
+	s.msgLock.Lock()
+	defer s.msgLock.Unlock()
+
 	s.prepared = newMessages()
 	s.committed = newMessages()
+	s.conflictingPrepares = newMessages()
 	s.roundMessages = map[uint64]*messages{}
+	s.conflictingRoundChanges = map[uint64]map[NodeID]struct{}{}
 }
 
-// CalcProposer calculates the proposer and sets it to the state
-func (s *state) CalcProposer() {
-	s.proposer = s.validators.CalcProposer(s.view.Round)
+// resetForSequence moves the state to round 0 of the given sequence. Moving to another sequence clears the prepared,
+// committed, round change and held commit messages and releases the lock, since a lock only binds the proposals
+// of its sequence. Re-entering the current sequence (i.e. a validator set update) keeps the messages and the lock,
+// so that a locked node can not be tricked into preparing another proposal in the same sequence.
+func (s *state) resetForSequence(sequence uint64) {
+	if s.view != nil && s.view.Sequence == sequence {
+		s.SetCurrentRound(0)
+		return
+	}
+	s.resetRoundMsgs()
+	s.roundCommits.reset(sequence)
+	s.setView(&View{Sequence: sequence})
+	s.unlock()
 }
 
 func (s *state) lock() {
+	if !s.IsLocked() && s.view != nil {
+		s.lockedRound = s.GetCurrentRound()
+	}
 	atomic.StoreUint64(&s.locked, 1)
 }
 
 func (s *state) unlock() {
 	s.proposal = nil
+	s.lockedRound = 0
 	atomic.StoreUint64(&s.locked, 0)
 }
 
 // cleanRound deletes the specific round messages
 func (s *state) cleanRound(round uint64) {
+	s.msgLock.Lock()
+	defer s.msgLock.Unlock()
+
 	delete(s.roundMessages, round)
 }
 
-// addRoundChangeMsg adds a ROUND-CHANGE message to the round, and returns the round message size
-func (s *state) addRoundChangeMsg(msg *MessageReq) {
+var (
+	// ErrNotValidator is returned when the message sender is not part of the validator set
+	ErrNotValidator = errors.New("message sender is not a validator")
+
+	// ErrDuplicate is returned when the sender already has a message of the same type in the list
+	ErrDuplicate = errors.New("duplicate message")
+
+	// ErrWrongView is returned when the message view is missing or belongs to another sequence
+	ErrWrongView = errors.New("message view does not match the current sequence")
+
+	// ErrBadSignature is returned when the message seal fails the validation
+	ErrBadSignature = errors.New("bad message signature")
+
+	// ErrWrongType is returned when the message type is not expected by the message list
+	ErrWrongType = errors.New("unexpected message type")
+
+	// ErrCommittedDisagreement is returned when the committed messages do not all reference the same proposal
+	ErrCommittedDisagreement = errors.New("committed messages reference different proposals")
+)
+
+// addRoundChangeMsg adds a ROUND-CHANGE message to the round
+func (s *state) addRoundChangeMsg(msg *MessageReq) error {
 	if msg.Type != MessageReq_RoundChange {
-		return
+		return ErrWrongType
 	}
 
-	s.addMessage(msg)
+	return s.addMessage(msg)
 }
 
 // addPrepareMsg adds a PREPARE message
-func (s *state) addPrepareMsg(msg *MessageReq) {
+func (s *state) addPrepareMsg(msg *MessageReq) error {
 	if msg.Type != MessageReq_Prepare {
-		return
+		return ErrWrongType
 	}
 
-	s.addMessage(msg)
+	return s.addMessage(msg)
 }
 
 // addCommitMsg adds a COMMIT message
-func (s *state) addCommitMsg(msg *MessageReq) {
+func (s *state) addCommitMsg(msg *MessageReq) error {
 	if msg.Type != MessageReq_Commit {
-		return
+		return ErrWrongType
 	}
 
-	s.addMessage(msg)
+	return s.addMessage(msg)
 }
 
-// addMessage adds a new message to one of the following message lists: committed, prepared, roundMessages
-func (s *state) addMessage(msg *MessageReq) {
+// addMessage adds a new message to one of the following message lists: committed, prepared, roundMessages.
+// It returns the reason why the message was dropped, if any.
+func (s *state) addMessage(msg *MessageReq) error {
 	addr := msg.From
 	if !s.validators.Includes(addr) {
 		// only include messages from validators
-		return
+		return ErrNotValidator
+	}
+	if msg.View == nil || (s.view != nil && msg.View.Sequence != s.view.Sequence) {
+		return ErrWrongView
 	}
 
+	s.msgLock.Lock()
+	defer s.msgLock.Unlock()
+
+	var added bool
 	votingPower := s.validators.VotingPower()[msg.From]
-	if msg.Type == MessageReq_Commit {
-		s.committed.addMessage(msg, votingPower)
-	} else if msg.Type == MessageReq_Prepare {
-		s.prepared.addMessage(msg, votingPower)
-	} else if msg.Type == MessageReq_RoundChange {
+	switch msg.Type {
+	case MessageReq_Commit:
+		added = s.committed.addMessage(msg, votingPower)
+	case MessageReq_Prepare:
+		added = s.prepared.addMessage(msg, votingPower)
+	case MessageReq_RoundChange:
 		view := msg.View
 		roundChangeMessages, exists := s.roundMessages[view.Round]
 		if !exists {
 			roundChangeMessages = newMessages()
 			s.roundMessages[view.Round] = roundChangeMessages
 		}
-		roundChangeMessages.addMessage(msg, votingPower)
+		added = roundChangeMessages.addMessage(msg, votingPower)
+	default:
+		return ErrWrongType
+	}
+
+	if !added {
+		return ErrDuplicate
 	}
+	return nil
+}
+
+// rangeCommitted calls fn with a copy of every committed message until fn returns false
+func (s *state) rangeCommitted(fn func(NodeID, *MessageReq) bool) {
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	s.committed.rangeMessages(fn)
+}
+
+// rangePrepared calls fn with a copy of every prepared message until fn returns false
+func (s *state) rangePrepared(fn func(NodeID, *MessageReq) bool) {
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	s.prepared.rangeMessages(fn)
 }
 
 // numPrepared returns the number of messages in the prepared message list
@@ -227,16 +534,25 @@ func (s *state) numCommitted() int {
 }
 
 func (s *state) GetCurrentRound() uint64 {
+	s.viewLock.RLock()
+	defer s.viewLock.RUnlock()
+
 	return atomic.LoadUint64(&s.view.Round)
 }
 
 func (s *state) SetCurrentRound(round uint64) {
+	s.viewLock.RLock()
+	defer s.viewLock.RUnlock()
+
 	atomic.StoreUint64(&s.view.Round, round)
 }
 
 type messages struct {
 	messageMap             map[NodeID]*MessageReq
 	accumulatedVotingPower uint64
+
+	// arrival holds the senders in the order their messages were added
+	arrival []NodeID
 }
 
 func newMessages() *messages {
@@ -246,12 +562,41 @@ func newMessages() *messages {
 	}
 }
 
-func (m *messages) addMessage(message *MessageReq, votingPower uint64) {
+// addMessage adds the message to the list and returns false if the sender already has a message in it
+func (m *messages) addMessage(message *MessageReq, votingPower uint64) bool {
 	if _, exists := m.messageMap[message.From]; exists {
-		return
+		return false
 	}
 	m.messageMap[message.From] = message
 	m.accumulatedVotingPower += votingPower
+	m.arrival = append(m.arrival, message.From)
+	return true
+}
+
+// removeMessage removes the message of the sender and subtracts the given voting power from the accumulated one.
+// It returns false if the sender has no message in the list.
+func (m *messages) removeMessage(from NodeID, votingPower uint64) bool {
+	if _, exists := m.messageMap[from]; !exists {
+		return false
+	}
+	delete(m.messageMap, from)
+	m.accumulatedVotingPower -= votingPower
+	for i, id := range m.arrival {
+		if id == from {
+			m.arrival = append(m.arrival[:i], m.arrival[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// rangeMessages calls fn with a copy of every message until fn returns false
+func (m *messages) rangeMessages(fn func(NodeID, *MessageReq) bool) {
+	for from, msg := range m.messageMap {
+		if !fn(from, msg.Copy()) {
+			return
+		}
+	}
 }
 
 func (m messages) getAccumulatedVotingPower() uint64 {
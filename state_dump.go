@@ -0,0 +1,123 @@
+package pbft
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// roundChangeHistorySize is the number of the most recent round changes kept for DumpState
+const roundChangeHistorySize = 16
+
+// StateDump is the debug snapshot of the consensus state produced by DumpState. The proposals are
+// reported by their hex encoded hash only, never with their data.
+type StateDump struct {
+	View  View   `json:"view"`
+	State string `json:"state"`
+
+	// Proposer is the proposer of the current round
+	Proposer NodeID `json:"proposer"`
+
+	// ProposalHash is the hash of the proposal of the current round, if any
+	ProposalHash string `json:"proposalHash,omitempty"`
+
+	Locked      bool   `json:"locked"`
+	LockedRound uint64 `json:"lockedRound,omitempty"`
+
+	// LockedHash is the hash of the locked proposal, if locked
+	LockedHash string `json:"lockedHash,omitempty"`
+
+	Prepares MessageTally `json:"prepares"`
+	Commits  MessageTally `json:"commits"`
+
+	// RoundChanges are the round change messages of the current sequence, by round
+	RoundChanges map[uint64]MessageTally `json:"roundChanges"`
+
+	// RecentRoundChanges are the most recent round changes of the node, the oldest first
+	RecentRoundChanges []RoundChangeRecord `json:"recentRoundChanges"`
+}
+
+// MessageTally is the accumulated voting power of a list of messages with their senders
+type MessageTally struct {
+	VotingPower uint64 `json:"votingPower"`
+
+	// Signers are the senders of the messages, sorted
+	Signers []NodeID `json:"signers"`
+}
+
+// RoundChangeRecord is a round abandoned by the node
+type RoundChangeRecord struct {
+	View   View   `json:"view"`
+	Reason string `json:"reason"`
+}
+
+// roundChangeHistory keeps the most recent round changes of the node
+type roundChangeHistory struct {
+	lock    sync.Mutex
+	records []RoundChangeRecord
+}
+
+func (r *roundChangeHistory) add(view View, reason RoundChangeReason) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.records = append(r.records, RoundChangeRecord{View: view, Reason: reason.String()})
+	if len(r.records) > roundChangeHistorySize {
+		r.records = r.records[len(r.records)-roundChangeHistorySize:]
+	}
+}
+
+func (r *roundChangeHistory) get() []RoundChangeRecord {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]RoundChangeRecord{}, r.records...)
+}
+
+func newMessageTally(msgs *messages) MessageTally {
+	tally := MessageTally{VotingPower: msgs.getAccumulatedVotingPower(), Signers: []NodeID{}}
+	for from := range msgs.messageMap {
+		tally.Signers = append(tally.Signers, from)
+	}
+	sort.Slice(tally.Signers, func(i, j int) bool { return tally.Signers[i] < tally.Signers[j] })
+	return tally
+}
+
+// dump takes a snapshot of the state, holding the message lock
+func (s *state) dump() *StateDump {
+	dump := &StateDump{
+		View:     s.CurrentView(),
+		State:    s.getState().String(),
+		Proposer: s.proposer,
+		Locked:   s.IsLocked(),
+	}
+
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	if s.proposal != nil && s.proposal.Hash != nil {
+		dump.ProposalHash = hex.EncodeToString(s.proposal.Hash)
+		if dump.Locked {
+			dump.LockedHash = dump.ProposalHash
+		}
+	}
+	if dump.Locked {
+		dump.LockedRound = s.lockedRound
+	}
+	dump.Prepares = newMessageTally(s.prepared)
+	dump.Commits = newMessageTally(s.committed)
+	dump.RoundChanges = make(map[uint64]MessageTally, len(s.roundMessages))
+	for round, msgs := range s.roundMessages {
+		dump.RoundChanges[round] = newMessageTally(msgs)
+	}
+	return dump
+}
+
+// DumpState returns the JSON encoded StateDump of the node, to be attached to the bug reports.
+// It can be called concurrently with the state machine.
+func (p *Pbft) DumpState() ([]byte, error) {
+	dump := p.state.dump()
+	dump.RecentRoundChanges = p.roundChangeHistory.get()
+	return json.MarshalIndent(dump, "", "  ")
+}
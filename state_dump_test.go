@@ -0,0 +1,62 @@
+package pbft
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPbft_DumpState(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.state.view = ViewMsg(1, 0)
+	m.state.proposer = "A"
+	m.recordRoundChange(RoundChange_Timeout)
+	m.state.view = ViewMsg(1, 1)
+	m.setState(ValidateState)
+	m.state.lock()
+
+	require.NoError(t, m.state.addPrepareMsg(createMessage("C", MessageReq_Prepare, ViewMsg(1, 1))))
+	require.NoError(t, m.state.addPrepareMsg(createMessage("B", MessageReq_Prepare, ViewMsg(1, 1))))
+	require.NoError(t, m.state.addCommitMsg(createMessage("D", MessageReq_Commit, ViewMsg(1, 1))))
+	require.NoError(t, m.state.addRoundChangeMsg(createMessage("B", MessageReq_RoundChange, ViewMsg(1, 2))))
+
+	data, err := m.DumpState()
+	require.NoError(t, err)
+
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &raw))
+	assert.ElementsMatch(t, []string{
+		"view", "state", "proposer", "proposalHash", "locked", "lockedRound", "lockedHash",
+		"prepares", "commits", "roundChanges", "recentRoundChanges",
+	}, jsonKeys(raw))
+	assert.NotContains(t, string(data), hex.EncodeToString(mockProposal))
+
+	var dump StateDump
+	require.NoError(t, json.Unmarshal(data, &dump))
+	assert.Equal(t, StateDump{
+		View:         View{Sequence: 1, Round: 1},
+		State:        "ValidateState",
+		Proposer:     "A",
+		ProposalHash: hex.EncodeToString(digest),
+		Locked:       true,
+		LockedRound:  1,
+		LockedHash:   hex.EncodeToString(digest),
+		Prepares:     MessageTally{VotingPower: 2, Signers: []NodeID{"B", "C"}},
+		Commits:      MessageTally{VotingPower: 1, Signers: []NodeID{"D"}},
+		RoundChanges: map[uint64]MessageTally{2: {VotingPower: 1, Signers: []NodeID{"B"}}},
+		RecentRoundChanges: []RoundChangeRecord{
+			{View: View{Sequence: 1, Round: 0}, Reason: "Timeout"},
+		},
+	}, dump)
+}
+
+func jsonKeys(m map[string]interface{}) []string {
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+	return result
+}
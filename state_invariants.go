@@ -0,0 +1,75 @@
+package pbft
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// errStateInvariant is returned by checkInvariants when the state is not internally consistent
+var errStateInvariant = errors.New("state invariant violated")
+
+// checkInvariants verifies the internal consistency of the state, for the tests and the assertions:
+// every prepared, committed and round change message is from a current validator and of the current sequence,
+// the accumulated voting power of every message list matches the voting power of its senders, and a locked
+// state has a proposal. It returns the first violated invariant, the lists and the senders are checked in order.
+func (s *state) checkInvariants() error {
+	if s.view == nil {
+		return fmt.Errorf("%w: no current view", errStateInvariant)
+	}
+	sequence := s.GetSequence()
+
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	lists := []struct {
+		name string
+		msgs *messages
+	}{
+		{"prepared", s.prepared},
+		{"committed", s.committed},
+	}
+	rounds := make([]uint64, 0, len(s.roundMessages))
+	for round := range s.roundMessages {
+		rounds = append(rounds, round)
+	}
+	sort.Slice(rounds, func(i, j int) bool { return rounds[i] < rounds[j] })
+	for _, round := range rounds {
+		lists = append(lists, struct {
+			name string
+			msgs *messages
+		}{fmt.Sprintf("round %d changes", round), s.roundMessages[round]})
+	}
+
+	votingPower := s.validators.VotingPower()
+	for _, list := range lists {
+		senders := make([]NodeID, 0, len(list.msgs.messageMap))
+		for from := range list.msgs.messageMap {
+			senders = append(senders, from)
+		}
+		sort.Slice(senders, func(i, j int) bool { return senders[i] < senders[j] })
+
+		accumulated := uint64(0)
+		for _, from := range senders {
+			msg := list.msgs.messageMap[from]
+			if msg.From != from {
+				return fmt.Errorf("%w: %s message of %s held as the message of %s", errStateInvariant, list.name, msg.From, from)
+			}
+			if !s.validators.Includes(from) {
+				return fmt.Errorf("%w: %s message from %s, not a validator", errStateInvariant, list.name, from)
+			}
+			if msg.View == nil || msg.View.Sequence != sequence {
+				return fmt.Errorf("%w: %s message from %s of view %v, current sequence %d", errStateInvariant, list.name, from, msg.View, sequence)
+			}
+			accumulated += votingPower[from]
+		}
+		if accumulated != list.msgs.accumulatedVotingPower {
+			return fmt.Errorf("%w: %s voting power %d, recomputed %d", errStateInvariant, list.name, list.msgs.accumulatedVotingPower, accumulated)
+		}
+	}
+
+	if s.IsLocked() && s.proposal == nil {
+		return fmt.Errorf("%w: locked without a proposal", errStateInvariant)
+	}
+	return nil
+}
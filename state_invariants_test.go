@@ -0,0 +1,81 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newInvariantsState(t *testing.T) *state {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+	s, err := initState(pool)
+	require.NoError(t, err)
+	s.view = ViewMsg(1, 0)
+	s.proposal = &Proposal{Data: mockProposal, Hash: digest}
+
+	for _, from := range []NodeID{"A", "B"} {
+		require.NoError(t, s.addPrepareMsg(createMessage(from, MessageReq_Prepare, ViewMsg(1, 0))))
+		require.NoError(t, s.addCommitMsg(createMessage(from, MessageReq_Commit, ViewMsg(1, 0))))
+		require.NoError(t, s.addRoundChangeMsg(createMessage(from, MessageReq_RoundChange, ViewMsg(1, 1))))
+	}
+	s.lock()
+	require.NoError(t, s.checkInvariants())
+	return s
+}
+
+func TestState_CheckInvariants(t *testing.T) {
+	cases := []struct {
+		name    string
+		corrupt func(s *state)
+		err     string
+	}{
+		{
+			"message from a non validator",
+			func(s *state) {
+				msg := createMessage("X", MessageReq_Prepare, ViewMsg(1, 0))
+				s.prepared.messageMap["X"] = msg
+			},
+			"prepared message from X, not a validator",
+		},
+		{
+			"message of another sequence",
+			func(s *state) {
+				s.committed.messageMap["B"].View = ViewMsg(2, 0)
+			},
+			"committed message from B of view",
+		},
+		{
+			"message held for another sender",
+			func(s *state) {
+				s.roundMessages[1].messageMap["B"].From = "C"
+			},
+			"round 1 changes message of C held as the message of B",
+		},
+		{
+			"voting power mismatch",
+			func(s *state) {
+				s.prepared.accumulatedVotingPower++
+			},
+			"prepared voting power 3, recomputed 2",
+		},
+		{
+			"locked without a proposal",
+			func(s *state) {
+				s.proposal = nil
+			},
+			"locked without a proposal",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newInvariantsState(t)
+			c.corrupt(s)
+
+			err := s.checkInvariants()
+			assert.ErrorIs(t, err, errStateInvariant)
+			assert.Contains(t, err.Error(), c.err)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	crand "crypto/rand"
 	"fmt"
 	mrand "math/rand"
+	"sort"
 	"strconv"
 	"testing"
 	"time"
@@ -18,6 +19,31 @@ func init() {
 	mrand.Seed(time.Now().UnixNano())
 }
 
+// signer returns a MockSigner backed by the key of the account
+func (ap *testerAccountPool) signer(alias NodeID) *MockSigner {
+	account := ap.get(alias)
+	if account == nil {
+		return nil
+	}
+	return &MockSigner{ID: account.alias, Key: account.priv}
+}
+
+// verifier returns a MockVerifier knowing the public keys of every account of the pool
+func (ap *testerAccountPool) verifier() *MockVerifier {
+	v := NewMockVerifier()
+	for _, account := range ap.accounts {
+		v.Add(account.alias, &account.priv.PublicKey)
+	}
+	return v
+}
+
+// useMockSigners makes every account of the pool sign with its key, so that the signatures pass the pool verifier
+func (ap *testerAccountPool) useMockSigners() {
+	for _, account := range ap.accounts {
+		account.signFn = ap.signer(account.alias).Sign
+	}
+}
+
 // Helper function which enables creation of MessageReq.
 func createMessage(sender NodeID, messageType MsgType, view *View) *MessageReq {
 	if view == nil {
@@ -33,7 +59,7 @@ func createMessage(sender NodeID, messageType MsgType, view *View) *MessageReq {
 		msg.Proposal = mockProposal
 		msg.Hash = digest
 
-	case MessageReq_Commit:
+	case MessageReq_Commit, MessageReq_PrepareCommit:
 		seal := make([]byte, 2)
 		mrand.Read(seal)
 		msg.Seal = seal
@@ -51,22 +77,22 @@ func TestState_AddMessages(t *testing.T) {
 	s.validators = pool.validatorSet()
 
 	// Send message from node which is not amongst validator nodes
-	s.addMessage(createMessage("E", MessageReq_Prepare, ViewMsg(1, 0)))
+	assert.ErrorIs(t, s.addMessage(createMessage("E", MessageReq_Prepare, ViewMsg(1, 0))), ErrNotValidator)
 	assert.Empty(t, s.committed.messageMap)
 	assert.Empty(t, s.prepared.messageMap)
 	assert.Empty(t, s.roundMessages)
 
 	// -- test committed messages --
-	s.addMessage(pool.createMessage("A", MessageReq_Commit))
-	s.addMessage(pool.createMessage("B", MessageReq_Commit))
-	s.addMessage(pool.createMessage("B", MessageReq_Commit))
+	assert.NoError(t, s.addMessage(pool.createMessage("A", MessageReq_Commit)))
+	assert.NoError(t, s.addMessage(pool.createMessage("B", MessageReq_Commit)))
+	assert.ErrorIs(t, s.addMessage(pool.createMessage("B", MessageReq_Commit)), ErrDuplicate)
 
 	assert.Equal(t, 2, s.numCommitted())
 
 	// -- test prepare messages --
-	s.addMessage(pool.createMessage("C", MessageReq_Prepare))
-	s.addMessage(pool.createMessage("C", MessageReq_Prepare))
-	s.addMessage(pool.createMessage("D", MessageReq_Prepare))
+	assert.NoError(t, s.addMessage(pool.createMessage("C", MessageReq_Prepare)))
+	assert.ErrorIs(t, s.addMessage(pool.createMessage("C", MessageReq_Prepare)), ErrDuplicate)
+	assert.NoError(t, s.addMessage(pool.createMessage("D", MessageReq_Prepare)))
 
 	assert.Equal(t, 2, s.numPrepared())
 
@@ -85,6 +111,30 @@ func TestState_AddMessages(t *testing.T) {
 	}
 }
 
+func TestState_AddMessages_DropReasons(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B"}))
+
+	s, err := initState(pool)
+	require.NoError(t, err)
+	s.view = ViewMsg(2, 0)
+
+	// message from another sequence
+	assert.ErrorIs(t, s.addMessage(createMessage("A", MessageReq_Prepare, ViewMsg(1, 0))), ErrWrongView)
+
+	// message without view
+	msg := createMessage("A", MessageReq_Prepare, ViewMsg(2, 0))
+	msg.View = nil
+	assert.ErrorIs(t, s.addMessage(msg), ErrWrongView)
+
+	// message type not expected by the list
+	assert.ErrorIs(t, s.addPrepareMsg(createMessage("A", MessageReq_Commit, ViewMsg(2, 0))), ErrWrongType)
+	assert.ErrorIs(t, s.addMessage(createMessage("A", MessageReq_Preprepare, ViewMsg(2, 0))), ErrWrongType)
+
+	assert.NoError(t, s.addPrepareMsg(createMessage("A", MessageReq_Prepare, ViewMsg(2, 0))))
+	assert.Equal(t, 1, s.numPrepared())
+}
+
 func TestState_MaxRound_Found(t *testing.T) {
 	const (
 		validatorsCount = 5
@@ -152,6 +202,108 @@ func TestState_MaxRound_NotFound(t *testing.T) {
 	assert.Equal(t, false, found)
 }
 
+func TestState_RoundsWithQuorum(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D", "E", "F", "G"}
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap(validatorIds))
+	s, err := initState(pool)
+	require.NoError(t, err)
+	assert.Empty(t, s.roundsWithQuorum())
+
+	// the quorum of 7 validators is 5
+	senders := map[uint64]int{0: 5, 1: 3, 2: 7, 3: 4, 5: 5, 6: 1}
+	for round, count := range senders {
+		for _, id := range validatorIds[:count] {
+			s.addMessage(createMessage(id, MessageReq_RoundChange, ViewMsg(1, round)))
+		}
+	}
+
+	assert.Equal(t, []uint64{0, 2, 5}, s.roundsWithQuorum())
+	// the fast-track only keeps the highest round above the max faulty voting power
+	maxRound, found := s.maxRound()
+	assert.True(t, found)
+	assert.Equal(t, uint64(5), maxRound)
+}
+
+func TestPbft_RoundSelection(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	roundChange := func(from NodeID, round uint64, justification *Justification) *MessageReq {
+		msg := createMessage(from, MessageReq_RoundChange, ViewMsg(1, round))
+		msg.Justification = justification
+		return msg
+	}
+	lower := &Justification{Round: 0, Proposal: &Proposal{Data: mockProposal, Hash: digest}}
+	higher := &Justification{Round: 1, Proposal: &Proposal{Data: mockProposal1, Hash: digest1}}
+
+	newNode := func(opts ...ConfigOption) *mockPbft {
+		m := newMockPbft(t, validatorIds, nil, "A")
+		m.config.ApplyOps(opts...)
+		m.state.view = ViewMsg(1, 0)
+		// both rounds 2 and 3 reach the fast-track threshold (F+1)
+		for _, msg := range []*MessageReq{
+			roundChange("A", 2, lower),
+			roundChange("B", 2, lower),
+			roundChange("C", 3, higher),
+			roundChange("D", 3, nil),
+		} {
+			require.NoError(t, m.state.addRoundChangeMsg(msg))
+		}
+		return m
+	}
+
+	// the higher round is selected by default
+	m := newNode()
+	round, found := m.state.maxRound()
+	assert.True(t, found)
+	assert.Equal(t, uint64(3), round)
+	round, _ = m.state.selectRound(m.config.RoundSelection)
+	assert.Equal(t, uint64(3), round)
+	proposal, err := m.JustifiedProposal()
+	require.NoError(t, err)
+	assert.Equal(t, digest1, proposal.Hash)
+
+	// the lowest round when configured
+	m = newNode(WithRoundSelection(RoundSelection_Lowest))
+	round, _ = m.state.selectRound(m.config.RoundSelection)
+	assert.Equal(t, uint64(2), round)
+	proposal, err = m.JustifiedProposal()
+	require.NoError(t, err)
+	assert.Equal(t, digest, proposal.Hash)
+}
+
+func TestState_ResetForSequence(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	s := newState()
+	s.validators = NewValStringStub(validatorIds, CreateEqualVotingPowerMap(validatorIds))
+	s.setView(ViewMsg(1, 2))
+
+	for _, typ := range []MsgType{MessageReq_Prepare, MessageReq_Commit, MessageReq_RoundChange} {
+		require.NoError(t, s.addMessage(createMessage("A", typ, ViewMsg(1, 2))))
+	}
+	s.proposal = &Proposal{Data: mockProposal, Hash: digest}
+	s.lock()
+
+	// re-entering the same sequence keeps the messages and the lock
+	s.resetForSequence(1)
+	assert.Equal(t, ViewMsg(1, 0), s.view)
+	assert.True(t, s.IsLocked())
+	assert.Equal(t, uint64(2), s.lockedRound)
+	assert.Equal(t, 1, s.numPrepared())
+	assert.Equal(t, 1, s.numCommitted())
+	assert.Len(t, s.roundMessages, 1)
+
+	// a new sequence starts from scratch
+	s.resetForSequence(2)
+	assert.Equal(t, ViewMsg(2, 0), s.view)
+	assert.False(t, s.IsLocked())
+	assert.Nil(t, s.proposal)
+	assert.Zero(t, s.numPrepared())
+	assert.Zero(t, s.numCommitted())
+	assert.Empty(t, s.roundMessages)
+	assert.Zero(t, s.prepared.getAccumulatedVotingPower())
+	assert.Zero(t, s.committed.getAccumulatedVotingPower())
+}
+
 func TestState_AddRoundMessage(t *testing.T) {
 	s := newState()
 	validatorIds := []NodeID{"A", "B"}
@@ -248,20 +400,60 @@ func TestState_getCommittedSeals(t *testing.T) {
 	s.addCommitMsg(createMessage("A", MessageReq_Commit, ViewMsg(1, 0)))
 	s.addCommitMsg(createMessage("B", MessageReq_Commit, ViewMsg(1, 0)))
 	s.addCommitMsg(createMessage("C", MessageReq_Commit, ViewMsg(1, 0)))
-	committedSeals := s.getCommittedSeals()
+	committedSeals := s.getCommittedSeals(SealOrdering_NodeID, SealSelection_All)
 
 	assert.Len(t, committedSeals, 3)
+	signers := []NodeID{}
+	for _, commSeal := range committedSeals {
+		signers = append(signers, commSeal.NodeID)
+	}
+	assert.Equal(t, FingerprintNodes([]NodeID{"A", "B", "C"}), FingerprintNodes(signers))
+
+	committed := map[NodeID]*MessageReq{}
+	s.rangeCommitted(func(from NodeID, msg *MessageReq) bool {
+		committed[from] = msg
+		return true
+	})
 	processed := map[NodeID]struct{}{}
 	for _, commSeal := range committedSeals {
 		_, exists := processed[commSeal.NodeID]
 		assert.False(t, exists) // all entries in committedSeals should be different
 		processed[commSeal.NodeID] = struct{}{}
-		msg := s.committed.messageMap[commSeal.NodeID]
+		msg := committed[commSeal.NodeID]
 		assert.NotNil(t, msg)                         // there should be entry in currentState.committed...
 		assert.Equal(t, commSeal.Signature, msg.Seal) // ...and signatures should match
 	}
 }
 
+func TestState_RangeMessages(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B", "C"}))
+
+	s := newState()
+	s.validators = pool.validatorSet()
+
+	s.addPrepareMsg(createMessage("A", MessageReq_Prepare, ViewMsg(1, 0)))
+	s.addPrepareMsg(createMessage("B", MessageReq_Prepare, ViewMsg(1, 0)))
+	s.addCommitMsg(createMessage("C", MessageReq_Commit, ViewMsg(1, 0)))
+
+	// iteration hands out copies of the messages
+	s.rangeCommitted(func(from NodeID, msg *MessageReq) bool {
+		assert.Equal(t, NodeID("C"), from)
+		assert.NotSame(t, s.committed.messageMap[from], msg)
+		msg.Seal = nil
+		return true
+	})
+	assert.NotNil(t, s.committed.messageMap["C"].Seal)
+
+	// returning false stops the iteration
+	visited := 0
+	s.rangePrepared(func(NodeID, *MessageReq) bool {
+		visited++
+		return false
+	})
+	assert.Equal(t, 1, visited)
+}
+
 func TestMsgType_ToString(t *testing.T) {
 	expectedMapping := map[MsgType]string{
 		MessageReq_RoundChange: "RoundChange",
@@ -470,6 +662,288 @@ func generateKey() *ecdsa.PrivateKey {
 	return prv
 }
 
+func TestState_PhaseQuorums(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D", "E", "F", "G"}))
+	fixed := func(size uint64) QuorumCalculator {
+		return func(uint64) uint64 { return size }
+	}
+
+	cases := []struct {
+		name          string
+		prepare       QuorumCalculator
+		commit        QuorumCalculator
+		prepareQuorum uint64
+		commitQuorum  uint64
+		err           bool
+	}{
+		{"defaults", nil, nil, 5, 5, false},
+		{"harder commit", nil, fixed(6), 5, 6, false},
+		{"harder prepare and commit", fixed(6), fixed(7), 6, 7, false},
+		{"commit lower than prepare", fixed(6), nil, 0, 0, true},
+		{"below quorum", nil, fixed(4), 0, 0, true},
+		{"above total voting power", nil, fixed(8), 0, 0, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, err := initState(pool)
+			require.NoError(t, err)
+
+			err = s.initializePhaseQuorums(c.prepare, c.commit)
+			if c.err {
+				assert.ErrorIs(t, err, errInvalidPhaseQuorum)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.prepareQuorum, s.getPrepareQuorumSize())
+			assert.Equal(t, c.commitQuorum, s.getCommitQuorumSize())
+		})
+	}
+}
+
+func TestState_assertCommittedAgreement(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+
+	s := newState()
+	s.validators = pool.validatorSet()
+	assert.NoError(t, s.assertCommittedAgreement())
+
+	commit := func(from NodeID, hash []byte) {
+		msg := createMessage(from, MessageReq_Commit, ViewMsg(1, 0))
+		msg.Hash = hash
+		require.NoError(t, s.addCommitMsg(msg))
+	}
+	commit("A", digest)
+	commit("B", digest)
+	assert.NoError(t, s.assertCommittedAgreement())
+
+	commit("D", []byte{0x2})
+	err := s.assertCommittedAgreement()
+	assert.ErrorIs(t, err, ErrCommittedDisagreement)
+	assert.Contains(t, err.Error(), "dissenters: D")
+}
+
+func TestState_getCommittedSeals_Ordering(t *testing.T) {
+	nodes := []NodeID{"C", "A", "E", "B", "D"}
+	s := newState()
+	s.validators = NewValStringStub(nodes, CreateEqualVotingPowerMap(nodes))
+
+	// commits are received in the order B, E, A, C
+	for _, from := range []NodeID{"B", "E", "A", "C"} {
+		msg := createMessage(from, MessageReq_Commit, ViewMsg(1, 0))
+		msg.Seal = []byte(from)
+		require.NoError(t, s.addCommitMsg(msg))
+	}
+
+	cases := []struct {
+		ordering SealOrdering
+		signers  []NodeID
+	}{
+		{SealOrdering_NodeID, []NodeID{"A", "B", "C", "E"}},
+		{SealOrdering_ValidatorIndex, []NodeID{"C", "A", "E", "B"}},
+		{SealOrdering_SigningTime, []NodeID{"B", "E", "A", "C"}},
+	}
+	for _, c := range cases {
+		t.Run(c.ordering.String(), func(t *testing.T) {
+			signers := []NodeID{}
+			for _, seal := range s.getCommittedSeals(c.ordering, SealSelection_All) {
+				assert.Equal(t, []byte(seal.NodeID), seal.Signature)
+				signers = append(signers, seal.NodeID)
+			}
+			assert.Equal(t, c.signers, signers)
+		})
+	}
+}
+
+// newLargeSealState returns a state of n validators, in a shuffled validator order, with the commits of the given
+// share of them received in a random order
+func newLargeSealState(tb testing.TB, n int, signed float64) *state {
+	nodes := make([]NodeID, n)
+	for i := range nodes {
+		nodes[i] = NodeID(fmt.Sprintf("validator_%03d", i))
+	}
+	mrand.Shuffle(n, func(i, j int) { nodes[i], nodes[j] = nodes[j], nodes[i] })
+
+	s := newState()
+	s.validators = NewValStringStub(nodes, CreateEqualVotingPowerMap(nodes))
+	s.view = ViewMsg(1, 0)
+	require.NoError(tb, s.initializeVotingInfo())
+
+	for _, i := range mrand.Perm(n)[:int(float64(n)*signed)] {
+		msg := createMessage(nodes[i], MessageReq_Commit, ViewMsg(1, 0))
+		require.NoError(tb, s.addCommitMsg(msg))
+	}
+	return s
+}
+
+func TestState_getCommittedSeals_ValidatorIndexMatchesSort(t *testing.T) {
+	s := newLargeSealState(t, 300, 0.7)
+
+	position := map[NodeID]int{}
+	for i, id := range s.validators.(*ValStringStub).Nodes {
+		position[id] = i
+	}
+	sorted := append([]NodeID{}, s.committed.arrival...)
+	sort.Slice(sorted, func(i, j int) bool { return position[sorted[i]] < position[sorted[j]] })
+
+	seals := s.getCommittedSeals(SealOrdering_ValidatorIndex, SealSelection_All)
+	signers := make([]NodeID, 0, len(seals))
+	for _, seal := range seals {
+		assert.Equal(t, s.committed.messageMap[seal.NodeID].Seal, seal.Signature)
+		signers = append(signers, seal.NodeID)
+	}
+	assert.Equal(t, sorted, signers)
+}
+
+func TestState_getCommittedSeals_Minimal(t *testing.T) {
+	cases := []struct {
+		name        string
+		votingPower map[NodeID]uint64
+		arrival     []NodeID
+		signers     []NodeID
+	}{
+		{
+			"equal voting power, ties by NodeID",
+			CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}),
+			[]NodeID{"D", "C", "B", "A"},
+			[]NodeID{"A", "B", "C"},
+		},
+		{
+			"heaviest signers first",
+			map[NodeID]uint64{"A": 1, "B": 3, "C": 5, "D": 3, "E": 1},
+			[]NodeID{"A", "E", "D", "B", "C"},
+			[]NodeID{"B", "C", "D"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			nodes := []NodeID{}
+			for id := range c.votingPower {
+				nodes = append(nodes, id)
+			}
+			s := newState()
+			s.validators = NewValStringStub(nodes, c.votingPower)
+			require.NoError(t, s.initializeVotingInfo())
+			for _, from := range c.arrival {
+				require.NoError(t, s.addCommitMsg(createMessage(from, MessageReq_Commit, ViewMsg(1, 0))))
+			}
+
+			signers := []NodeID{}
+			accumulated, lightest := uint64(0), uint64(0)
+			for _, seal := range s.getCommittedSeals(SealOrdering_NodeID, SealSelection_Minimal) {
+				signers = append(signers, seal.NodeID)
+				power := c.votingPower[seal.NodeID]
+				accumulated += power
+				if lightest == 0 || power < lightest {
+					lightest = power
+				}
+			}
+			assert.Equal(t, c.signers, signers)
+
+			// the quorum is met, but not without any of the selected signers
+			assert.GreaterOrEqual(t, accumulated, s.getCommitQuorumSize())
+			assert.Less(t, accumulated-lightest, s.getCommitQuorumSize())
+
+			// all the seals are included by default
+			assert.Len(t, s.getCommittedSeals(SealOrdering_NodeID, SealSelection_All), len(c.arrival))
+		})
+	}
+}
+
+// Test that the nodes receiving the same over-quorum commits in different orders build identical minimal seal sets.
+func TestState_getCommittedSeals_MinimalAcrossNodes(t *testing.T) {
+	votingPower := map[NodeID]uint64{"A": 2, "B": 3, "C": 3, "D": 2, "E": 1, "F": 3}
+	nodes := []NodeID{"A", "B", "C", "D", "E", "F"}
+
+	build := func(arrival []NodeID, ordering SealOrdering) []CommittedSeal {
+		s := newState()
+		s.validators = NewValStringStub(nodes, votingPower)
+		require.NoError(t, s.initializeVotingInfo())
+		for _, from := range arrival {
+			msg := createMessage(from, MessageReq_Commit, ViewMsg(1, 0))
+			msg.Seal = []byte(from)
+			require.NoError(t, s.addCommitMsg(msg))
+		}
+		return s.getCommittedSeals(ordering, SealSelection_Minimal)
+	}
+
+	for _, ordering := range []SealOrdering{SealOrdering_NodeID, SealOrdering_ValidatorIndex, SealOrdering_SigningTime} {
+		t.Run(ordering.String(), func(t *testing.T) {
+			first := build([]NodeID{"A", "B", "C", "D", "E", "F"}, ordering)
+			second := build([]NodeID{"F", "E", "D", "C", "B", "A"}, ordering)
+			third := build([]NodeID{"D", "F", "A", "E", "C", "B"}, ordering)
+			assert.Equal(t, first, second)
+			assert.Equal(t, first, third)
+
+			// B, C and F (voting power 9) reach the quorum of 9
+			signers := []NodeID{}
+			for _, seal := range first {
+				signers = append(signers, seal.NodeID)
+			}
+			assert.ElementsMatch(t, []NodeID{"B", "C", "F"}, signers)
+		})
+	}
+}
+
+func TestState_HighestObservedRound(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+	s, err := initState(pool)
+	require.NoError(t, err)
+	assert.Zero(t, s.highestObservedRound())
+
+	require.NoError(t, s.addRoundChangeMsg(createMessage("A", MessageReq_RoundChange, ViewMsg(1, 2))))
+	assert.Equal(t, uint64(2), s.highestObservedRound())
+
+	// a single message is enough, no quorum is required
+	require.NoError(t, s.addPrepareMsg(createMessage("B", MessageReq_Prepare, ViewMsg(1, 4))))
+	assert.Equal(t, uint64(4), s.highestObservedRound())
+	_, found := s.maxRound()
+	assert.False(t, found)
+
+	require.NoError(t, s.addCommitMsg(createMessage("C", MessageReq_Commit, ViewMsg(1, 7))))
+	assert.Equal(t, uint64(7), s.highestObservedRound())
+
+	s.resetRoundMsgs()
+	assert.Zero(t, s.highestObservedRound())
+}
+
+func TestState_RangeRoundMessages(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+	s, err := initState(pool)
+	require.NoError(t, err)
+
+	for _, round := range []uint64{5, 1, 3, 0, 4} {
+		require.NoError(t, s.addRoundChangeMsg(createMessage("A", MessageReq_RoundChange, ViewMsg(1, round))))
+	}
+	require.NoError(t, s.addRoundChangeMsg(createMessage("B", MessageReq_RoundChange, ViewMsg(1, 3))))
+
+	rounds := []uint64{}
+	s.RangeRoundMessages(func(round uint64, msgs map[NodeID]*MessageReq) bool {
+		rounds = append(rounds, round)
+		if round == 3 {
+			assert.Len(t, msgs, 2)
+		}
+		// the messages are copies
+		msgs["A"].View.Round = 100
+		delete(msgs, "A")
+		return true
+	})
+	assert.Equal(t, []uint64{0, 1, 3, 4, 5}, rounds)
+	assert.Equal(t, 2, s.roundMessages[3].length())
+	assert.Equal(t, uint64(3), s.roundMessages[3].messageMap["A"].View.Round)
+
+	// the iteration stops early
+	rounds = []uint64{}
+	s.RangeRoundMessages(func(round uint64, _ map[NodeID]*MessageReq) bool {
+		rounds = append(rounds, round)
+		return round < 3
+	})
+	assert.Equal(t, []uint64{0, 1, 3}, rounds)
+}
+
 func initState(accountPool *testerAccountPool) (*state, error) {
 	s := newState()
 	s.validators = accountPool.validatorSet()
@@ -479,3 +953,96 @@ func initState(accountPool *testerAccountPool) (*state, error) {
 	}
 	return s, nil
 }
+
+// recomputedVotingPower sums the voting power of the senders of the messages from scratch
+func recomputedVotingPower(msgs *messages, votingPower map[NodeID]uint64) uint64 {
+	total := uint64(0)
+	for from := range msgs.messageMap {
+		total += votingPower[from]
+	}
+	return total
+}
+
+func TestState_VotingPower_Consistency(t *testing.T) {
+	ids := []NodeID{}
+	votingPower := map[NodeID]uint64{}
+	for i := 0; i < 20; i++ {
+		id := NodeID(fmt.Sprintf("validator_%d", i))
+		ids = append(ids, id)
+		votingPower[id] = uint64(i + 1)
+	}
+
+	s := newState()
+	s.validators = NewValStringStub(ids, votingPower)
+	s.view = ViewMsg(1, 0)
+
+	assertConsistent := func() {
+		power := s.validators.VotingPower()
+		for _, msgs := range s.messageLists() {
+			assert.Equal(t, recomputedVotingPower(msgs, power), msgs.getAccumulatedVotingPower())
+			assert.Len(t, msgs.arrival, msgs.length())
+		}
+	}
+
+	rnd := mrand.New(mrand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		from := ids[rnd.Intn(len(ids))]
+		typ := []MsgType{MessageReq_Prepare, MessageReq_Commit, MessageReq_RoundChange}[rnd.Intn(3)]
+		_ = s.addMessage(createMessage(from, typ, ViewMsg(1, uint64(rnd.Intn(3)))))
+		assertConsistent()
+	}
+
+	// the validators removed from the set are not counted anymore
+	previous := s.validators
+	remaining := ids[:10]
+	s.validators = NewValStringStub(remaining, CreateEqualVotingPowerMap(remaining))
+	s.retainValidators(previous)
+	assertConsistent()
+	for _, msgs := range s.messageLists() {
+		for from := range msgs.messageMap {
+			assert.True(t, s.validators.Includes(from))
+		}
+	}
+
+	// removing a message keeps the running total
+	for from := range s.committed.messageMap {
+		require.True(t, s.committed.removeMessage(from, 1))
+		assert.False(t, s.committed.removeMessage(from, 1))
+		break
+	}
+	assertConsistent()
+}
+
+func BenchmarkState_AddMessage_QuorumCheck(b *testing.B) {
+	ids := []NodeID{}
+	for i := 0; i < 100; i++ {
+		ids = append(ids, NodeID(fmt.Sprintf("validator_%d", i)))
+	}
+	s := newState()
+	s.validators = NewValStringStub(ids, CreateEqualVotingPowerMap(ids))
+	s.view = ViewMsg(1, 0)
+	require.NoError(b, s.initializeVotingInfo())
+
+	msgs := make([]*MessageReq, len(ids))
+	for i, id := range ids {
+		msgs[i] = createMessage(id, MessageReq_Commit, ViewMsg(1, 0))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if i%len(ids) == 0 {
+			s.resetRoundMsgs()
+		}
+		_ = s.addCommitMsg(msgs[i%len(ids)])
+		_ = s.committed.getAccumulatedVotingPower() >= s.getCommitQuorumSize()
+	}
+}
+
+func BenchmarkState_getCommittedSeals_ValidatorIndex(b *testing.B) {
+	s := newLargeSealState(b, 500, 0.7)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = s.getCommittedSeals(SealOrdering_ValidatorIndex, SealSelection_All)
+	}
+}
@@ -14,6 +14,8 @@ type Stats struct {
 	msgCount       map[string]uint64
 	msgVotingPower map[string]uint64
 	stateDuration  map[string]time.Duration
+
+	droppedMsgCount map[string]uint64
 }
 
 func NewStats() *Stats {
@@ -22,6 +24,8 @@ func NewStats() *Stats {
 		msgCount:       make(map[string]uint64),
 		msgVotingPower: make(map[string]uint64),
 		stateDuration:  make(map[string]time.Duration),
+
+		droppedMsgCount: make(map[string]uint64),
 	}
 }
 
@@ -39,6 +43,18 @@ func (s *Stats) IncrMsgCount(msgType string, votingPower uint64) {
 	s.msgVotingPower[msgType] += votingPower
 }
 
+func (s *Stats) IncrDroppedMsgCount(msgType string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.droppedMsgCount[msgType]++
+}
+
+func (s *Stats) DroppedMsgCount(msgType string) uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.droppedMsgCount[msgType]
+}
+
 func (s *Stats) StateDuration(state string, t time.Time) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -66,6 +82,10 @@ func (s *Stats) Snapshot() Stats {
 		stats.stateDuration[msgType] = duration
 	}
 
+	for msgType, count := range s.droppedMsgCount {
+		stats.droppedMsgCount[msgType] = count
+	}
+
 	return *stats
 }
 
@@ -76,4 +96,5 @@ func (s *Stats) Reset() {
 	s.msgCount = make(map[string]uint64)
 	s.msgVotingPower = make(map[string]uint64)
 	s.stateDuration = make(map[string]time.Duration)
+	s.droppedMsgCount = make(map[string]uint64)
 }
@@ -76,8 +76,22 @@ func TestReset(t *testing.T) {
 
 	stats.IncrMsgCount(preprepare, 1)
 	stats.IncrMsgCount(preprepare, 1)
+	stats.IncrDroppedMsgCount(preprepare)
 	stats.Reset()
 
 	assert.Equal(t, uint64(0), stats.msgCount[preprepare])
 	assert.Equal(t, uint64(0), stats.msgVotingPower[preprepare])
+	assert.Equal(t, uint64(0), stats.DroppedMsgCount(preprepare))
+}
+
+func TestIncrDroppedMsgCount(t *testing.T) {
+	stats := NewStats()
+	preprepare := "Preprepare"
+
+	stats.IncrDroppedMsgCount(preprepare)
+	stats.IncrDroppedMsgCount(preprepare)
+
+	snapshot := stats.Snapshot()
+	assert.Equal(t, uint64(2), snapshot.DroppedMsgCount(preprepare))
+	assert.Equal(t, uint64(0), snapshot.DroppedMsgCount("Commit"))
 }
@@ -0,0 +1,118 @@
+package pbft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrSyncFailed is returned by SyncFrom when no peer served the request before the sync deadline
+var ErrSyncFailed = errors.New("no peer served the sync request")
+
+// SyncFetchFunc requests the sync data (e.g. a finalization proof) from the peer. It must give up once the
+// context is done and fail if the peer served invalid data, so that the next peer is tried.
+type SyncFetchFunc func(ctx context.Context, peer NodeID) error
+
+// syncPeers tracks the peers that failed to serve a sync request
+type syncPeers struct {
+	lock sync.Mutex
+
+	// failed is the time of the last failure of each peer
+	failed map[NodeID]time.Time
+}
+
+func newSyncPeers() *syncPeers {
+	return &syncPeers{failed: map[NodeID]time.Time{}}
+}
+
+// order returns the peers to try, the ones that never failed first and then the least recently failed ones
+func (s *syncPeers) order(peers []NodeID) []NodeID {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ordered := append([]NodeID{}, peers...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, failedA := s.failed[ordered[i]]
+		b, failedB := s.failed[ordered[j]]
+		if failedA != failedB {
+			return !failedA
+		}
+		return a.Before(b)
+	})
+	return ordered
+}
+
+// fail records a failure of the peer
+func (s *syncPeers) fail(peer NodeID, now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.failed[peer] = now
+}
+
+// succeed forgets the failures of the peer
+func (s *syncPeers) succeed(peer NodeID) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.failed, peer)
+}
+
+// SyncFrom requests the sync data from the peers in turn, until one of them serves it, and returns that peer.
+// Every request is bounded by the SyncPeerTimeout: a peer failing or timing out is skipped for the next one and
+// each peer is tried at most once. The peers that failed a previous request are tried last. The whole sync is
+// bounded by the SyncDeadline, in addition to the context.
+// It can be called while the state machine runs, the data is typically applied with ApplyFinalizationProof.
+func (p *Pbft) SyncFrom(ctx context.Context, peers []NodeID, fetch SyncFetchFunc) (NodeID, error) {
+	if p.config.SyncDeadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.SyncDeadline)
+		defer cancel()
+	}
+
+	var lastErr error
+	for _, peer := range p.syncPeers.order(peers) {
+		if ctx.Err() != nil {
+			break
+		}
+		err := p.fetchFrom(ctx, peer, fetch)
+		if err == nil {
+			p.syncPeers.succeed(peer)
+			return peer, nil
+		}
+		p.logger.Printf("[DEBUG] sync request to %s failed: %v", peer, err)
+		p.syncPeers.fail(peer, p.config.Clock.Now())
+		lastErr = fmt.Errorf("peer %s: %w", peer, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrSyncFailed, err)
+	}
+	if lastErr == nil {
+		return "", fmt.Errorf("%w: no peers", ErrSyncFailed)
+	}
+	return "", fmt.Errorf("%w: %v", ErrSyncFailed, lastErr)
+}
+
+// fetchFrom requests the sync data from the peer within the SyncPeerTimeout. It does not wait for a fetch
+// ignoring the timeout to return.
+func (p *Pbft) fetchFrom(ctx context.Context, peer NodeID, fetch SyncFetchFunc) error {
+	if p.config.SyncPeerTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.SyncPeerTimeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fetch(ctx, peer)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,68 @@
+package pbft
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPbft_SyncFrom_Failover(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithSyncTimeouts(20*time.Millisecond, time.Second)(m.config)
+
+	var lock sync.Mutex
+	var tried []NodeID
+	// B does not respond, C serves the data
+	fetch := func(ctx context.Context, peer NodeID) error {
+		lock.Lock()
+		tried = append(tried, peer)
+		lock.Unlock()
+		if peer == "B" {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	peer, err := m.SyncFrom(context.Background(), []NodeID{"B", "C", "D"}, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, NodeID("C"), peer)
+	lock.Lock()
+	assert.Equal(t, []NodeID{"B", "C"}, tried)
+	tried = nil
+	lock.Unlock()
+
+	// B failed, it is tried last on the next sync
+	peer, err = m.SyncFrom(context.Background(), []NodeID{"B", "C", "D"}, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, NodeID("C"), peer)
+	assert.Equal(t, []NodeID{"C"}, tried)
+}
+
+func TestPbft_SyncFrom_Deadline(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithSyncTimeouts(0, 20*time.Millisecond)(m.config)
+
+	// a peer ignoring the context does not block the sync past the deadline
+	block := make(chan struct{})
+	defer close(block)
+	_, err := m.SyncFrom(context.Background(), []NodeID{"B", "C"}, func(context.Context, NodeID) error {
+		<-block
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrSyncFailed)
+
+	// every peer is tried once
+	tried := 0
+	_, err = m.SyncFrom(context.Background(), []NodeID{"B", "C"}, func(context.Context, NodeID) error {
+		tried++
+		return errors.New("invalid data")
+	})
+	assert.ErrorIs(t, err, ErrSyncFailed)
+	assert.Equal(t, 2, tried)
+}
@@ -0,0 +1,113 @@
+package pbft
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoSyncTarget is returned when none of the sync candidates backs its height with a valid finalization proof
+var ErrNoSyncTarget = errors.New("no sync candidate with a valid finalization proof")
+
+// SyncPreference is the choice of the peer to follow while syncing, among the peers reporting different heights
+type SyncPreference uint8
+
+const (
+	// SyncPreference_HighestProof follows the highest sequence whose own finalization proof is valid,
+	// falling back to the longest chain of valid proofs when no peer proves its highest sequence
+	SyncPreference_HighestProof SyncPreference = iota
+
+	// SyncPreference_LongestChain follows the longest chain of consecutive valid finalization proofs
+	SyncPreference_LongestChain
+)
+
+func (s SyncPreference) String() string {
+	switch s {
+	case SyncPreference_HighestProof:
+		return "HighestProof"
+	case SyncPreference_LongestChain:
+		return "LongestChain"
+	default:
+		return fmt.Sprintf("SyncPreference(%d)", uint8(s))
+	}
+}
+
+// SyncCandidate is a peer reporting its height with the finalization proofs of the sequences
+// following the local one, in ascending order
+type SyncCandidate struct {
+	Peer   NodeID
+	Proofs []*FinalizationProof
+}
+
+// SyncTarget is the peer to sync from and the sequence it proved
+type SyncTarget struct {
+	Peer     NodeID
+	Sequence uint64
+}
+
+// SelectSyncTarget deterministically chooses the peer to sync from, as set by the preference. Only the heights
+// backed by valid finalization proofs are considered, so that a peer claiming a higher height it can not prove
+// is ignored. The ties are broken by the lowest peer id. The proofs are verified against the given validator set
+// and sealing.
+func SelectSyncTarget(candidates []SyncCandidate, validators ValidatorSet, verifySeal SealVerifier, sealing ProofSealing, preference SyncPreference) (SyncTarget, error) {
+	var target SyncTarget
+	var err error
+	if preference == SyncPreference_HighestProof {
+		target, err = selectSyncTarget(candidates, func(c SyncCandidate) (uint64, bool) {
+			return highestProvenSequence(c, validators, verifySeal, sealing)
+		})
+		if err == nil {
+			return target, nil
+		}
+	}
+	return selectSyncTarget(candidates, func(c SyncCandidate) (uint64, bool) {
+		return longestProvenChain(c, validators, verifySeal, sealing)
+	})
+}
+
+// selectSyncTarget returns the candidate with the highest sequence, as evaluated by the given function
+func selectSyncTarget(candidates []SyncCandidate, provenSequence func(SyncCandidate) (uint64, bool)) (SyncTarget, error) {
+	var target SyncTarget
+	found := false
+	for _, candidate := range candidates {
+		sequence, ok := provenSequence(candidate)
+		if !ok {
+			continue
+		}
+		if !found || sequence > target.Sequence || (sequence == target.Sequence && candidate.Peer < target.Peer) {
+			target = SyncTarget{Peer: candidate.Peer, Sequence: sequence}
+			found = true
+		}
+	}
+	if !found {
+		return SyncTarget{}, ErrNoSyncTarget
+	}
+	return target, nil
+}
+
+// highestProvenSequence returns the highest sequence reported by the candidate, if its finalization proof is valid
+func highestProvenSequence(candidate SyncCandidate, validators ValidatorSet, verifySeal SealVerifier, sealing ProofSealing) (uint64, bool) {
+	if len(candidate.Proofs) == 0 {
+		return 0, false
+	}
+	highest := candidate.Proofs[len(candidate.Proofs)-1]
+	if err := VerifyFinalizationProof(highest, validators, verifySeal, sealing); err != nil {
+		return 0, false
+	}
+	return highest.View.Sequence, true
+}
+
+// longestProvenChain returns the last sequence of the longest prefix of consecutive valid finalization proofs
+func longestProvenChain(candidate SyncCandidate, validators ValidatorSet, verifySeal SealVerifier, sealing ProofSealing) (uint64, bool) {
+	var sequence uint64
+	found := false
+	for _, proof := range candidate.Proofs {
+		if err := VerifyFinalizationProof(proof, validators, verifySeal, sealing); err != nil {
+			break
+		}
+		if found && proof.View.Sequence != sequence+1 {
+			break
+		}
+		sequence, found = proof.View.Sequence, true
+	}
+	return sequence, found
+}
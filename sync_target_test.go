@@ -0,0 +1,69 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoProof returns a finalization proof of the sequence sealed by the given signers with verifyEchoSeal seals
+func echoProof(sequence uint64, signers ...NodeID) *FinalizationProof {
+	hash := []byte{byte(sequence)}
+	proof := &FinalizationProof{Hash: hash, View: ViewMsg(sequence, 0)}
+	for _, signer := range signers {
+		proof.CommittedSeals = append(proof.CommittedSeals, CommittedSeal{NodeID: signer, Signature: hash})
+	}
+	return proof
+}
+
+func TestSelectSyncTarget(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	validators := NewValStringStub(validatorIds, CreateEqualVotingPowerMap(validatorIds))
+
+	honest := SyncCandidate{Peer: "B", Proofs: []*FinalizationProof{
+		echoProof(5, "A", "B", "C"),
+		echoProof(6, "B", "C", "D"),
+	}}
+	// the lying peer claims a higher height, but only its first proof reaches the quorum
+	liar := SyncCandidate{Peer: "A", Proofs: []*FinalizationProof{
+		echoProof(5, "A", "B", "C"),
+		echoProof(100, "A"),
+	}}
+
+	for _, preference := range []SyncPreference{SyncPreference_HighestProof, SyncPreference_LongestChain} {
+		t.Run(preference.String(), func(t *testing.T) {
+			target, err := SelectSyncTarget([]SyncCandidate{liar, honest}, validators, verifyEchoSeal, ProofSealing{}, preference)
+			require.NoError(t, err)
+			assert.Equal(t, SyncTarget{Peer: "B", Sequence: 6}, target)
+		})
+	}
+
+	// without a proven highest sequence, the longest chain of valid proofs is followed
+	target, err := SelectSyncTarget([]SyncCandidate{liar}, validators, verifyEchoSeal, ProofSealing{}, SyncPreference_HighestProof)
+	require.NoError(t, err)
+	assert.Equal(t, SyncTarget{Peer: "A", Sequence: 5}, target)
+
+	// the ties are broken by the peer id
+	target, err = SelectSyncTarget([]SyncCandidate{honest, {Peer: "C", Proofs: honest.Proofs}}, validators, verifyEchoSeal, ProofSealing{}, SyncPreference_HighestProof)
+	require.NoError(t, err)
+	assert.Equal(t, NodeID("B"), target.Peer)
+
+	// nothing proven
+	_, err = SelectSyncTarget([]SyncCandidate{{Peer: "A", Proofs: []*FinalizationProof{echoProof(100, "A")}}, {Peer: "B"}}, validators, verifyEchoSeal, ProofSealing{}, SyncPreference_HighestProof)
+	assert.ErrorIs(t, err, ErrNoSyncTarget)
+}
+
+// Test that the longest chain stops at the first gap in the sequences.
+func TestSelectSyncTarget_LongestChainGap(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	validators := NewValStringStub(validatorIds, CreateEqualVotingPowerMap(validatorIds))
+
+	gapped := SyncCandidate{Peer: "A", Proofs: []*FinalizationProof{
+		echoProof(5, "A", "B", "C"),
+		echoProof(7, "A", "B", "C"),
+	}}
+	target, err := SelectSyncTarget([]SyncCandidate{gapped}, validators, verifyEchoSeal, ProofSealing{}, SyncPreference_LongestChain)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), target.Sequence)
+}
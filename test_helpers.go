@@ -1,5 +1,18 @@
 package pbft
 
+import (
+	"context"
+	"crypto/ecdsa"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
 type ValidatorKeyMock string
 
 func (k ValidatorKeyMock) NodeID() NodeID {
@@ -13,6 +26,115 @@ func (k ValidatorKeyMock) Sign(b []byte) ([]byte, error) {
 type TransportStub struct {
 	Nodes      []*Pbft
 	GossipFunc func(ft *TransportStub, msg *MessageReq) error
+	SendFunc   func(ft *TransportStub, to NodeID, msg *MessageReq) error
+
+	latencyLock sync.Mutex
+	latency     LatencyDistribution
+	rand        *rand.Rand
+	clock       Clock
+	pending     []delayedMessage
+}
+
+// delayedMessage is a message held by the TransportStub until its delivery time
+type delayedMessage struct {
+	to        *Pbft
+	msg       *MessageReq
+	deliverAt time.Time
+}
+
+// LatencyDistribution draws the delivery delay of a message out of the random source
+type LatencyDistribution func(r *rand.Rand) time.Duration
+
+// FixedLatency delays every message by d
+func FixedLatency(d time.Duration) LatencyDistribution {
+	return func(*rand.Rand) time.Duration {
+		return d
+	}
+}
+
+// UniformLatency delays the messages uniformly between min (included) and max (excluded)
+func UniformLatency(min, max time.Duration) LatencyDistribution {
+	return func(r *rand.Rand) time.Duration {
+		if max <= min {
+			return min
+		}
+		return min + time.Duration(r.Int63n(int64(max-min)))
+	}
+}
+
+// NormalLatency delays the messages following a normal distribution, the negative delays are clamped to zero
+func NormalLatency(mean, stddev time.Duration) LatencyDistribution {
+	return func(r *rand.Rand) time.Duration {
+		d := mean + time.Duration(r.NormFloat64()*float64(stddev))
+		if d < 0 {
+			return 0
+		}
+		return d
+	}
+}
+
+// SetLatency delays the delivery of every message by a duration drawn from the distribution, with a random source
+// seeded with seed so that the delays are reproducible. The delayed messages are delivered by DeliverDue once
+// their delay elapsed, as measured by the clock. A nil distribution delivers the messages right away.
+func (ft *TransportStub) SetLatency(latency LatencyDistribution, seed int64, clock Clock) {
+	ft.latencyLock.Lock()
+	defer ft.latencyLock.Unlock()
+
+	ft.latency = latency
+	ft.rand = rand.New(rand.NewSource(seed))
+	ft.clock = clock
+}
+
+// deliver pushes the message to the node, once its delay elapsed if a latency is set
+func (ft *TransportStub) deliver(to *Pbft, msg *MessageReq) {
+	ft.latencyLock.Lock()
+	if ft.latency == nil {
+		ft.latencyLock.Unlock()
+		to.PushMessage(msg)
+		return
+	}
+	deliverAt := ft.clock.Now().Add(ft.latency(ft.rand))
+	ft.pending = append(ft.pending, delayedMessage{to: to, msg: msg, deliverAt: deliverAt})
+	ft.latencyLock.Unlock()
+}
+
+// DeliverDue delivers the delayed messages whose delay elapsed and returns the number of messages still pending
+func (ft *TransportStub) DeliverDue() int {
+	ft.latencyLock.Lock()
+	due := []delayedMessage{}
+	pending := ft.pending[:0]
+	if ft.clock != nil {
+		now := ft.clock.Now()
+		for _, delayed := range ft.pending {
+			if delayed.deliverAt.After(now) {
+				pending = append(pending, delayed)
+				continue
+			}
+			due = append(due, delayed)
+		}
+	}
+	ft.pending = pending
+	ft.latencyLock.Unlock()
+
+	for _, delayed := range due {
+		delayed.to.PushMessage(delayed.msg)
+	}
+	return len(pending)
+}
+
+// RunDelivery calls DeliverDue every interval until the context is done
+func (ft *TransportStub) RunDelivery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ft.DeliverDue()
+		case <-ctx.Done():
+			return
+		}
+	}
 }
 
 func (ft *TransportStub) Gossip(msg *MessageReq) error {
@@ -22,12 +144,26 @@ func (ft *TransportStub) Gossip(msg *MessageReq) error {
 
 	for _, node := range ft.Nodes {
 		if msg.From != node.GetValidatorId() {
-			node.PushMessage(msg.Copy())
+			ft.deliver(node, msg.Copy())
 		}
 	}
 	return nil
 }
 
+func (ft *TransportStub) Send(to NodeID, msg *MessageReq) error {
+	if ft.SendFunc != nil {
+		return ft.SendFunc(ft, to, msg)
+	}
+
+	for _, node := range ft.Nodes {
+		if node.GetValidatorId() == to {
+			ft.deliver(node, msg.Copy())
+			return nil
+		}
+	}
+	return fmt.Errorf("node %s not found", to)
+}
+
 func NewValStringStub(nodes []NodeID, votingPowerMap map[NodeID]uint64) *ValStringStub {
 	return &ValStringStub{
 		Nodes:          nodes,
@@ -87,3 +223,207 @@ func CreateEqualVotingPowerMap(validatorIds []NodeID) map[NodeID]uint64 {
 	}
 	return weightedValidators
 }
+
+// ManualClock is a Clock implementation whose time only moves when advanced explicitly
+type ManualClock struct {
+	lock sync.Mutex
+	now  time.Time
+}
+
+// NewManualClock creates a new ManualClock set to the provided time
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+// Now implements Clock interface
+func (c *ManualClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by the given duration
+func (c *ManualClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// FingerprintNodes returns a stable fingerprint of the given set of node ids, regardless of their order.
+// It is meant for order independent assertions over map iterations.
+func FingerprintNodes(ids []NodeID) string {
+	sorted := make([]string, len(ids))
+	for i, id := range ids {
+		sorted[i] = string(id)
+	}
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	for _, id := range sorted {
+		// length prefix the ids so that different sets can not produce the same input
+		h.Write([]byte{byte(len(id) >> 8), byte(len(id))})
+		h.Write([]byte(id))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DeterministicScheduler is a Scheduler that only feeds the run loop with the injected events, in order.
+// Timers and messages from the transport are ignored, so that a test fully controls the ordering of the events.
+// The run loop stops once every injected event got processed.
+type DeterministicScheduler struct {
+	lock   sync.Mutex
+	events []Event
+}
+
+// NewDeterministicScheduler creates a DeterministicScheduler with the given events
+func NewDeterministicScheduler(events ...Event) *DeterministicScheduler {
+	return &DeterministicScheduler{events: events}
+}
+
+// Inject appends the events to the ones to process
+func (s *DeterministicScheduler) Inject(events ...Event) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.events = append(s.events, events...)
+}
+
+// Pending returns the number of events not processed yet
+func (s *DeterministicScheduler) Pending() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.events)
+}
+
+// Next implements Scheduler interface
+func (s *DeterministicScheduler) Next(ctx context.Context, _ <-chan time.Time, _ <-chan struct{}) (Event, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.events) == 0 || ctx.Err() != nil {
+		return Event{}, false
+	}
+	event := s.events[0]
+	s.events = s.events[1:]
+	return event, true
+}
+
+// MessageEvent creates an event delivering the message
+func MessageEvent(msg *MessageReq) Event {
+	return Event{Type: EventMessage, Msg: msg}
+}
+
+// TimeoutEvent creates an event firing the round timeout
+func TimeoutEvent() Event {
+	return Event{Type: EventTimeout}
+}
+
+// CommandEvent creates an event running the command on the run loop
+func CommandEvent(command func(p *Pbft)) Event {
+	return Event{Type: EventCommand, Command: command}
+}
+
+// MockSigner is a SignKey signing the sha256 digest of the data with an ECDSA key
+type MockSigner struct {
+	ID  NodeID
+	Key *ecdsa.PrivateKey
+}
+
+// NodeID implements SignKey interface
+func (s *MockSigner) NodeID() NodeID {
+	return s.ID
+}
+
+// Sign implements SignKey interface
+func (s *MockSigner) Sign(b []byte) ([]byte, error) {
+	digest := sha256.Sum256(b)
+	return ecdsa.SignASN1(crand.Reader, s.Key, digest[:])
+}
+
+// MockVerifier verifies the signatures produced by MockSigners against their public keys.
+// With a key rotation allowance (see SetKeyRotation), the keys rotated out of a node are kept
+// for a grace window, so that the messages signed before the rotation still verify.
+type MockVerifier struct {
+	lock sync.RWMutex
+	keys map[NodeID]*ecdsa.PublicKey
+
+	// rotated are the previous keys of the nodes, the most recently rotated first
+	rotated map[NodeID][]rotatedKey
+
+	clock          Clock
+	rotationWindow time.Duration
+	historyDepth   int
+}
+
+// rotatedKey is a public key rotated out of a node
+type rotatedKey struct {
+	key       *ecdsa.PublicKey
+	rotatedAt time.Time
+}
+
+// NewMockVerifier creates a MockVerifier knowing the public keys of the given signers
+func NewMockVerifier(signers ...*MockSigner) *MockVerifier {
+	v := &MockVerifier{
+		keys:    map[NodeID]*ecdsa.PublicKey{},
+		rotated: map[NodeID][]rotatedKey{},
+		clock:   realClock{},
+	}
+	for _, signer := range signers {
+		v.Add(signer.ID, &signer.Key.PublicKey)
+	}
+	return v
+}
+
+// SetKeyRotation keeps up to depth keys rotated out of every node, accepted for the window after their rotation
+// as measured by the clock. A zero depth, the default, accepts the current keys only.
+func (v *MockVerifier) SetKeyRotation(window time.Duration, depth int, clock Clock) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.rotationWindow = window
+	v.historyDepth = depth
+	v.clock = clock
+}
+
+// Add registers the public key of the node. A different key already registered is rotated out
+// and kept in the history of the node, as configured with SetKeyRotation.
+func (v *MockVerifier) Add(id NodeID, key *ecdsa.PublicKey) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if current, ok := v.keys[id]; ok && !current.Equal(key) && v.historyDepth > 0 {
+		history := append([]rotatedKey{{key: current, rotatedAt: v.clock.Now()}}, v.rotated[id]...)
+		if len(history) > v.historyDepth {
+			history = history[:v.historyDepth]
+		}
+		v.rotated[id] = history
+	}
+	v.keys[id] = key
+}
+
+// Verify validates that the signature of the data was produced by the node, with its current key
+// or with a key rotated out within the rotation window.
+// It has the SealVerifier signature, so that it verifies committed seals over the proposal hash too.
+func (v *MockVerifier) Verify(from NodeID, data []byte, signature []byte) error {
+	v.lock.RLock()
+	key, ok := v.keys[from]
+	var previous []*ecdsa.PublicKey
+	if ok {
+		now := v.clock.Now()
+		for _, rotated := range v.rotated[from] {
+			if now.Sub(rotated.rotatedAt) <= v.rotationWindow {
+				previous = append(previous, rotated.key)
+			}
+		}
+	}
+	v.lock.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotValidator, from)
+	}
+
+	digest := sha256.Sum256(data)
+	for _, key := range append([]*ecdsa.PublicKey{key}, previous...) {
+		if ecdsa.VerifyASN1(key, digest[:], signature) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: from %s", ErrBadSignature, from)
+}
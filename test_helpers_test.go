@@ -0,0 +1,82 @@
+package pbft
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprintNodes(t *testing.T) {
+	assert.Equal(t, FingerprintNodes([]NodeID{"A", "B", "C"}), FingerprintNodes([]NodeID{"C", "A", "B"}))
+	assert.NotEqual(t, FingerprintNodes([]NodeID{"A", "B"}), FingerprintNodes([]NodeID{"A", "B", "C"}))
+	assert.NotEqual(t, FingerprintNodes([]NodeID{"AB", "C"}), FingerprintNodes([]NodeID{"A", "BC"}))
+	assert.Equal(t, FingerprintNodes(nil), FingerprintNodes([]NodeID{}))
+}
+
+func TestMockSignerVerifier(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B"}))
+	verifier := pool.verifier()
+
+	data := []byte("data")
+	signature, err := pool.signer("A").Sign(data)
+	require.NoError(t, err)
+
+	assert.NoError(t, verifier.Verify("A", data, signature))
+	assert.ErrorIs(t, verifier.Verify("B", data, signature), ErrBadSignature)
+	assert.ErrorIs(t, verifier.Verify("A", []byte("other"), signature), ErrBadSignature)
+	assert.ErrorIs(t, verifier.Verify("C", data, signature), ErrNotValidator)
+}
+
+func TestMockVerifier_KeyRotation(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	verifier := NewMockVerifier()
+	verifier.SetKeyRotation(time.Minute, 1, clock)
+
+	old := &MockSigner{ID: "A", Key: generateKey()}
+	verifier.Add("A", &old.Key.PublicKey)
+	data := []byte("data")
+	signature, err := old.Sign(data)
+	require.NoError(t, err)
+
+	// the message signed with the key just rotated out verifies within the window
+	rotated := &MockSigner{ID: "A", Key: generateKey()}
+	verifier.Add("A", &rotated.Key.PublicKey)
+	clock.Advance(30 * time.Second)
+	assert.NoError(t, verifier.Verify("A", data, signature))
+
+	current, err := rotated.Sign(data)
+	require.NoError(t, err)
+	assert.NoError(t, verifier.Verify("A", data, current))
+
+	// and fails once the window elapsed
+	clock.Advance(time.Minute)
+	assert.ErrorIs(t, verifier.Verify("A", data, signature), ErrBadSignature)
+	assert.NoError(t, verifier.Verify("A", data, current))
+
+	// the history keeps the last rotated keys only
+	verifier.Add("A", &old.Key.PublicKey)
+	verifier.Add("A", &generateKey().PublicKey)
+	assert.ErrorIs(t, verifier.Verify("A", data, current), ErrBadSignature)
+	assert.NoError(t, verifier.Verify("A", data, signature))
+}
+
+func TestMockSignerVerifier_CommitSeals(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.pool.useMockSigners()
+	verifier := m.pool.verifier()
+
+	m.sendCommitMsg()
+	require.Len(t, m.respMsg, 1)
+	commit := m.respMsg[0]
+
+	items := []SealItem{
+		{From: "A", Hash: m.state.proposal.Hash, Seal: commit.Seal},
+		{From: "B", Hash: m.state.proposal.Hash, Seal: commit.Seal},
+	}
+	errs := BatchVerifySeals(items, verifier.Verify, 2)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], ErrBadSignature)
+}
@@ -0,0 +1,109 @@
+package pbft
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// SignatureAggregator combines the partial signatures of a threshold signature scheme. When configured
+// (see WithSignatureAggregator), the commit seals are the partial signatures of the validators and the
+// finalization proof carries their combined signature.
+type SignatureAggregator interface {
+	// VerifyPartial verifies the partial signature of the node over the content (see SignableContent)
+	VerifyPartial(from NodeID, content []byte, partial []byte) error
+
+	// Aggregate combines the partial signatures, reaching quorum voting power, into the signature of the content
+	Aggregate(content []byte, partials []CommittedSeal) ([]byte, error)
+}
+
+// PartialAggregator collects the partial signatures of a content until they reach quorum voting power.
+// Every partial is verified before it is collected.
+type PartialAggregator struct {
+	aggregator  SignatureAggregator
+	content     []byte
+	votingPower map[NodeID]uint64
+	quorumSize  uint64
+
+	partials               map[NodeID][]byte
+	accumulatedVotingPower uint64
+}
+
+// NewPartialAggregator creates a PartialAggregator of the content, aggregating once the partials of the
+// validators reach the quorum size
+func NewPartialAggregator(aggregator SignatureAggregator, content []byte, votingPower map[NodeID]uint64, quorumSize uint64) *PartialAggregator {
+	return &PartialAggregator{
+		aggregator:  aggregator,
+		content:     append([]byte{}, content...),
+		votingPower: votingPower,
+		quorumSize:  quorumSize,
+		partials:    map[NodeID][]byte{},
+	}
+}
+
+// Add verifies and collects the partial signature of the node. A partial already collected from the node is
+// ignored, while a different one is rejected with ErrConflictingSeals.
+func (a *PartialAggregator) Add(from NodeID, partial []byte) error {
+	power, ok := a.votingPower[from]
+	if !ok {
+		return ErrNotValidator
+	}
+	existing, exists := a.partials[from]
+	if exists && bytes.Equal(existing, partial) {
+		return nil
+	}
+	// verify before checking for conflicts, so that a forged partial does not accuse the node
+	if err := a.aggregator.VerifyPartial(from, a.content, partial); err != nil {
+		return fmt.Errorf("partial of node %s: %w", from, err)
+	}
+	if exists {
+		return fmt.Errorf("%w: node %s", ErrConflictingSeals, from)
+	}
+
+	a.partials[from] = append([]byte{}, partial...)
+	a.accumulatedVotingPower += power
+	return nil
+}
+
+// VotingPower returns the accumulated voting power of the collected partials
+func (a *PartialAggregator) VotingPower() uint64 {
+	return a.accumulatedVotingPower
+}
+
+// Aggregate combines the collected partials, sorted by node id. It fails if they do not reach quorum voting power.
+func (a *PartialAggregator) Aggregate() ([]byte, error) {
+	if a.accumulatedVotingPower < a.quorumSize {
+		return nil, errInsufficientSeals
+	}
+
+	partials := make([]CommittedSeal, 0, len(a.partials))
+	for nodeID, partial := range a.partials {
+		partials = append(partials, CommittedSeal{NodeID: nodeID, Signature: partial})
+	}
+	sort.Slice(partials, func(i, j int) bool { return partials[i].NodeID < partials[j].NodeID })
+	return a.aggregator.Aggregate(a.content, partials)
+}
+
+// collectCommitSeal verifies the seal of the commit message of the current proposal and, with a SignatureAggregator,
+// collects it as a partial signature. The partials are collected across the rounds of the sequence, as long as
+// the proposal does not change, since they are produced over its seal hash only (unless bound to the round).
+func (p *Pbft) collectCommitSeal(msg *MessageReq) error {
+	sealHash := p.commitSealHash(msg.View.Round, p.state.proposal)
+	if p.config.SignatureAggregator == nil {
+		return p.validateCommitSeal(msg.From, sealHash, msg.Seal)
+	}
+
+	content := SignableContent(p.config.SealDomain, sealHash)
+	if p.partials == nil || !bytes.Equal(p.partials.content, content) {
+		p.partials = NewPartialAggregator(p.config.SignatureAggregator, content, p.state.validators.VotingPower(), p.state.getCommitQuorumSize())
+	}
+	return p.partials.Add(msg.From, msg.Seal)
+}
+
+// aggregateSignature combines the partial signatures collected for the seal hash
+func (p *Pbft) aggregateSignature(sealHash []byte) ([]byte, error) {
+	if p.partials == nil || !bytes.Equal(p.partials.content, SignableContent(p.config.SealDomain, sealHash)) {
+		return nil, errInsufficientSeals
+	}
+	return p.partials.Aggregate()
+}
@@ -0,0 +1,103 @@
+package pbft
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoAggregator is a SignatureAggregator for the mock keys which sign by echoing the payload. A partial may carry
+// a suffix after the payload, so that a node can produce two different valid partials, and the aggregated
+// signature is the payload followed by the ids of the signers.
+type echoAggregator struct{}
+
+func (echoAggregator) VerifyPartial(_ NodeID, content []byte, partial []byte) error {
+	if !bytes.HasPrefix(partial, content) {
+		return errors.New("partial does not match")
+	}
+	return nil
+}
+
+func (echoAggregator) Aggregate(content []byte, partials []CommittedSeal) ([]byte, error) {
+	signature := append([]byte{}, content...)
+	for _, partial := range partials {
+		signature = append(signature, partial.NodeID...)
+	}
+	return signature, nil
+}
+
+func TestPartialAggregator(t *testing.T) {
+	votingPower := CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"})
+	a := NewPartialAggregator(echoAggregator{}, digest, votingPower, 3)
+
+	// partials are verified before inclusion
+	assert.Error(t, a.Add("A", digest1))
+	assert.ErrorIs(t, a.Add("E", digest), ErrNotValidator)
+	assert.Equal(t, uint64(0), a.VotingPower())
+
+	require.NoError(t, a.Add("B", digest))
+	require.NoError(t, a.Add("A", digest))
+	_, err := a.Aggregate()
+	assert.ErrorIs(t, err, errInsufficientSeals)
+
+	// a repeated partial is counted once, a different one is a conflict
+	require.NoError(t, a.Add("A", digest))
+	assert.ErrorIs(t, a.Add("A", append(append([]byte{}, digest...), 0x1)), ErrConflictingSeals)
+	assert.Equal(t, uint64(2), a.VotingPower())
+
+	require.NoError(t, a.Add("D", digest))
+	signature, err := a.Aggregate()
+	require.NoError(t, err)
+	assert.Equal(t, append(append([]byte{}, digest...), "ABD"...), signature)
+}
+
+func TestPbft_SignatureAggregator_FinalizationProof(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithSignatureAggregator(echoAggregator{})(m.config)
+	m.pool.get("A").signFn = func(b []byte) ([]byte, error) {
+		return b, nil
+	}
+	m.setProposal(&Proposal{Data: mockProposal, Hash: digest})
+	m.setState(ValidateState)
+
+	for _, from := range []NodeID{"A", "B", "C"} {
+		msg := createMessage(from, MessageReq_Prepare, nil)
+		msg.Hash = digest
+		m.emitMsg(msg)
+	}
+	commit := func(from NodeID, seal []byte) {
+		msg := createMessage(from, MessageReq_Commit, nil)
+		msg.Hash = digest
+		msg.Seal = seal
+		m.emitMsg(msg)
+	}
+	// a forged partial is not collected
+	commit("D", digest1)
+	commit("B", digest)
+	commit("C", digest)
+
+	m.runCycle(context.Background())
+	assert.Equal(t, CommitState, m.getState())
+	assert.Equal(t, uint64(3), m.partials.VotingPower())
+
+	// the own partial of A is collected through its commit message
+	proof, err := m.BuildFinalizationProof()
+	require.NoError(t, err)
+	assert.Equal(t, append(append([]byte{}, digest...), "ABC"...), proof.AggregatedSignature)
+	assert.NoError(t, VerifyFinalizationProof(proof, m.state.validators, m.validateCommitSeal, ProofSealing{}))
+
+	// the aggregated signature survives the binary encoding
+	data, err := proof.MarshalBinary()
+	require.NoError(t, err)
+	decoded := &FinalizationProof{}
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, proof.AggregatedSignature, decoded.AggregatedSignature)
+
+	// the partials are collected again in the next sequence
+	m.setSequence(2)
+	assert.Nil(t, m.partials)
+}
@@ -0,0 +1,107 @@
+package pbft
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ToleranceStatus is a snapshot of how many more faults the current validator set tolerates
+type ToleranceStatus struct {
+	// MaxFaultyVotingPower is the fault tolerance f of the validator set, the voting power that can be faulty
+	// without threatening the safety of the consensus
+	MaxFaultyVotingPower uint64
+
+	// Faulty are the validators with evidence of a fault, sorted by id (see FaultyValidators)
+	Faulty []NodeID
+
+	// Offline are the validators considered offline and not already faulty, sorted by id (see InactiveValidators)
+	Offline []NodeID
+
+	// FaultyVotingPower is the voting power of the faulty and the offline validators
+	FaultyVotingPower uint64
+
+	// Margin is the voting power that can still fail before the fault tolerance is exceeded, zero once it is reached
+	Margin uint64
+
+	// Threatened is whether the faulty and the offline validators exceed the fault tolerance
+	Threatened bool
+}
+
+// ToleranceStatus reports the fault tolerance of the current validator set, the validators provably faulty or
+// offline and the remaining margin. The offline validators are only known with the inactivity tracking enabled
+// (see WithInactivityTracking). They are counted with the faulty ones since, while offline, they do not help to
+// reach the quorum either.
+func (p *Pbft) ToleranceStatus() ToleranceStatus {
+	status := ToleranceStatus{
+		MaxFaultyVotingPower: p.state.getMaxFaultyVotingPower(),
+		Faulty:               p.FaultyValidators(),
+		Offline:              []NodeID{},
+	}
+
+	faulty := make(map[NodeID]struct{}, len(status.Faulty))
+	for _, id := range status.Faulty {
+		faulty[id] = struct{}{}
+	}
+	for _, id := range p.InactiveValidators() {
+		if _, ok := faulty[id]; !ok {
+			status.Offline = append(status.Offline, id)
+		}
+	}
+
+	votingPower := p.state.validators.VotingPower()
+	for _, ids := range [][]NodeID{status.Faulty, status.Offline} {
+		for _, id := range ids {
+			status.FaultyVotingPower += votingPower[id]
+		}
+	}
+	if status.FaultyVotingPower > status.MaxFaultyVotingPower {
+		status.Threatened = true
+	} else {
+		status.Margin = status.MaxFaultyVotingPower - status.FaultyVotingPower
+	}
+	return status
+}
+
+// EffectiveQuorum returns the quorum among the validators of the current validator set left once the given
+// provably faulty validators are excluded, i.e. the voting power the remaining validators need to make progress
+// on their own. It fails with ErrNotValidator for an unknown validator, and with ErrSafetyThresholdBreached if the
+// faulty validators exceed the max faulty voting power, since the consensus is then no longer safe.
+func (p *Pbft) EffectiveQuorum(faulty []NodeID) (uint64, error) {
+	votingPower := p.state.validators.VotingPower()
+	excluded := make(map[NodeID]struct{}, len(faulty))
+	faultyVotingPower := uint64(0)
+	for _, id := range faulty {
+		power, ok := votingPower[id]
+		if !ok {
+			return 0, fmt.Errorf("%w: %s", ErrNotValidator, id)
+		}
+		if _, ok := excluded[id]; ok {
+			continue
+		}
+		excluded[id] = struct{}{}
+		faultyVotingPower += power
+	}
+	if maxFaulty := p.state.getMaxFaultyVotingPower(); faultyVotingPower > maxFaulty {
+		return 0, fmt.Errorf("%w: faulty voting power %d, max %d", ErrSafetyThresholdBreached, faultyVotingPower, maxFaulty)
+	}
+
+	remaining := make(map[NodeID]uint64, len(votingPower)-len(excluded))
+	ids := make([]NodeID, 0, len(votingPower)-len(excluded))
+	for id, power := range votingPower {
+		if _, ok := excluded[id]; !ok {
+			remaining[id] = power
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	s := newState()
+	s.validators = NewValStringStub(ids, remaining)
+	if err := s.initializeVotingInfo(); err != nil {
+		return 0, err
+	}
+	if err := s.initializePhaseQuorums(p.config.PrepareQuorum, p.config.CommitQuorum); err != nil {
+		return 0, err
+	}
+	return s.getCommitQuorumSize(), nil
+}
@@ -0,0 +1,87 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPbft_ToleranceStatus(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D", "E", "F", "G"}, nil, "A")
+	m.liveness = newLivenessTracker(2)
+	require.NoError(t, m.SetBackend(m.backend))
+
+	status := m.ToleranceStatus()
+	assert.Equal(t, uint64(2), status.MaxFaultyVotingPower)
+	assert.Equal(t, uint64(2), status.Margin)
+	assert.False(t, status.Threatened)
+
+	// the validators going silent reduce the margin
+	online := []NodeID{"B", "C", "D", "E", "F", "G"}
+	margins := []uint64{1, 0, 0}
+	for i, sequence := range []uint64{4, 5, 6} {
+		online = online[:len(online)-1]
+		for _, from := range online {
+			m.PushMessageInternal(createMessage(from, MessageReq_RoundChange, ViewMsg(sequence, 0)))
+		}
+		m.sequence = sequence + 2
+		require.NoError(t, m.SetBackend(m.backend))
+
+		status = m.ToleranceStatus()
+		assert.Len(t, status.Offline, i+1)
+		assert.Equal(t, margins[i], status.Margin)
+	}
+	assert.Equal(t, []NodeID{"E", "F", "G"}, status.Offline)
+	assert.Equal(t, uint64(3), status.FaultyVotingPower)
+	assert.True(t, status.Threatened)
+}
+
+func TestPbft_ToleranceStatus_FaultyOffline(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D", "E", "F", "G"}, nil, "A")
+	m.liveness = newLivenessTracker(2)
+	require.NoError(t, m.SetBackend(m.backend))
+	require.Equal(t, NodeID("G"), m.calcProposer(6))
+	equivocate(m, "G", 6)
+
+	for _, from := range []NodeID{"B", "C", "D", "E"} {
+		m.PushMessageInternal(createMessage(from, MessageReq_RoundChange, ViewMsg(4, 0)))
+	}
+	m.sequence = 4
+	require.NoError(t, m.SetBackend(m.backend))
+
+	// a faulty validator is counted once, even if it is offline too
+	status := m.ToleranceStatus()
+	assert.Equal(t, []NodeID{"G"}, status.Faulty)
+	assert.Equal(t, []NodeID{"F"}, status.Offline)
+	assert.Equal(t, uint64(2), status.FaultyVotingPower)
+	assert.Equal(t, uint64(0), status.Margin)
+	assert.False(t, status.Threatened)
+}
+
+func TestPbft_EffectiveQuorum(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D", "E", "F", "G"}, nil, "A")
+
+	// 7 validators tolerate 2 faulty ones
+	quorum, err := m.EffectiveQuorum(nil)
+	require.NoError(t, err)
+	assert.Equal(t, m.state.getCommitQuorumSize(), quorum)
+
+	// the quorum is recomputed among the remaining validators, a repeated id is excluded once
+	quorum, err = m.EffectiveQuorum([]NodeID{"G"})
+	require.NoError(t, err)
+	expected, _ := ComputeQuorum(nil, 6, nil)
+	assert.Equal(t, expected, quorum)
+
+	quorum, err = m.EffectiveQuorum([]NodeID{"F", "G", "G"})
+	require.NoError(t, err)
+	expected, _ = ComputeQuorum(nil, 5, nil)
+	assert.Equal(t, expected, quorum)
+
+	// removing more than the max faulty voting power breaks the safety
+	_, err = m.EffectiveQuorum([]NodeID{"E", "F", "G"})
+	assert.ErrorIs(t, err, ErrSafetyThresholdBreached)
+
+	_, err = m.EffectiveQuorum([]NodeID{"X"})
+	assert.ErrorIs(t, err, ErrNotValidator)
+}
@@ -0,0 +1,94 @@
+package pbft
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyDistribution_Reproducible(t *testing.T) {
+	for _, latency := range []LatencyDistribution{
+		FixedLatency(10 * time.Millisecond),
+		UniformLatency(10*time.Millisecond, 50*time.Millisecond),
+		NormalLatency(30*time.Millisecond, 10*time.Millisecond),
+	} {
+		a, b := rand.New(rand.NewSource(7)), rand.New(rand.NewSource(7))
+		for i := 0; i < 100; i++ {
+			delay := latency(a)
+			assert.Equal(t, delay, latency(b))
+			assert.GreaterOrEqual(t, delay, time.Duration(0))
+		}
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		delay := UniformLatency(10*time.Millisecond, 50*time.Millisecond)(r)
+		assert.GreaterOrEqual(t, delay, 10*time.Millisecond)
+		assert.Less(t, delay, 50*time.Millisecond)
+	}
+}
+
+func TestTransportStub_Latency(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+	transport := &TransportStub{Nodes: []*Pbft{m.Pbft}}
+	transport.SetLatency(FixedLatency(time.Second), 1, clock)
+
+	require.NoError(t, transport.Gossip(createMessage("A", MessageReq_RoundChange, ViewMsg(1, 0))))
+
+	// the message is held until its delay elapsed
+	assert.Equal(t, 1, transport.DeliverDue())
+	assert.Equal(t, 0, m.msgQueue.getQueue(RoundChangeState).Len())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, 0, transport.DeliverDue())
+	assert.Equal(t, 1, m.msgQueue.getQueue(RoundChangeState).Len())
+}
+
+func TestTransportStub_HighLatencyWithoutRoundChange(t *testing.T) {
+	ids := []NodeID{"A", "B", "C", "D"}
+	transport := &TransportStub{}
+	transport.SetLatency(NormalLatency(100*time.Millisecond, 30*time.Millisecond), 42, realClock{})
+
+	nodes := make([]*mockPbft, 0, len(ids))
+	for _, id := range ids {
+		m := newMockPbft(t, ids, nil, id)
+		m.pool.get(id).signFn = ValidatorKeyMock(id).Sign
+		m.gossipFn = transport.Gossip
+		// the round timeout covers a few message delays
+		m.roundTimeout = func(uint64) <-chan time.Time {
+			return time.After(2 * time.Second)
+		}
+		require.NoError(t, m.SetBackend(m.backend))
+		m.setProposal(&Proposal{Data: mockProposal, Time: time.Now()})
+		transport.Nodes = append(transport.Nodes, m.Pbft)
+		nodes = append(nodes, m)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	go transport.RunDelivery(ctx, time.Millisecond)
+
+	var wg sync.WaitGroup
+	for _, m := range nodes {
+		wg.Add(1)
+		go func(m *mockPbft) {
+			defer wg.Done()
+			m.Run(ctx)
+		}(m)
+	}
+	wg.Wait()
+	require.NoError(t, ctx.Err())
+
+	// every node finalized the proposal of round 0
+	for _, m := range nodes {
+		proof := m.LastFinalizationProof()
+		require.NotNil(t, proof)
+		assert.Equal(t, ViewMsg(1, 0), proof.View)
+	}
+}
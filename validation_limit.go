@@ -0,0 +1,40 @@
+package pbft
+
+import "context"
+
+// ValidationLimiter caps the number of proposal validations running at once. A node validates its proposals one
+// at a time, in the state machine loop, so a limiter is meant to be shared by the nodes of a process (see
+// WithValidationLimiter) to bound the validations running under round-change storms. The validations beyond
+// the limit are queued until a running one completes.
+type ValidationLimiter struct {
+	slots chan struct{}
+}
+
+// NewValidationLimiter creates a ValidationLimiter running at most max validations at once
+func NewValidationLimiter(max int) *ValidationLimiter {
+	if max < 1 {
+		max = 1
+	}
+	return &ValidationLimiter{slots: make(chan struct{}, max)}
+}
+
+// Acquire waits for a validation slot, it fails if the context is done first.
+// The slot must be released with Release once the validation completes.
+func (l *ValidationLimiter) Acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release releases a slot acquired with Acquire
+func (l *ValidationLimiter) Release() {
+	<-l.slots
+}
+
+// Running returns the number of validations holding a slot
+func (l *ValidationLimiter) Running() int {
+	return len(l.slots)
+}
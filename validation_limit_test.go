@@ -0,0 +1,63 @@
+package pbft
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationLimiter_Serializes(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	votingPowerMap := CreateEqualVotingPowerMap(validatorIds)
+	limiter := NewValidationLimiter(1)
+
+	var running, maxRunning, calls int32
+	validate := func(*Proposal) error {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}
+
+	// the nodes of the process share the limiter
+	var wg sync.WaitGroup
+	for _, id := range validatorIds {
+		backend := newMockBackend(validatorIds, votingPowerMap, nil).HookValidateHandler(validate)
+		m := newMockPbft(t, validatorIds, votingPowerMap, id, backend)
+		WithValidationLimiter(limiter)(m.config)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, m.validateProposal(context.Background(), &Proposal{Data: mockProposal, Time: time.Now(), Hash: digest}))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(len(validatorIds)), calls)
+	assert.Equal(t, int32(1), maxRunning)
+	assert.Equal(t, 0, limiter.Running())
+}
+
+func TestValidationLimiter_AcquireCanceled(t *testing.T) {
+	limiter := NewValidationLimiter(1)
+	assert.NoError(t, limiter.Acquire(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, limiter.Acquire(ctx), context.Canceled)
+
+	limiter.Release()
+	assert.NoError(t, limiter.Acquire(context.Background()))
+}
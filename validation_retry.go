@@ -0,0 +1,50 @@
+package pbft
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// RetryableError marks a proposal validation failure as transient (i.e. a dependency not available yet).
+// The validation is retried, as configured with WithValidationRetry, instead of starting a round change.
+type RetryableError struct {
+	Err error
+}
+
+// NewRetryableError wraps the error of a transient validation failure
+func NewRetryableError(err error) error {
+	return &RetryableError{Err: err}
+}
+
+func (e *RetryableError) Error() string {
+	return "retryable: " + e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable returns whether the error is a transient validation failure
+func IsRetryable(err error) bool {
+	var retryable *RetryableError
+	return errors.As(err, &retryable)
+}
+
+// validateProposalWithRetry validates the proposal, retrying up to ValidationRetries times, ValidationRetryDelay apart,
+// as long as the validation fails with a RetryableError. Any other error is returned right away.
+func (p *Pbft) validateProposalWithRetry(ctx context.Context, proposal *Proposal) error {
+	for attempt := 0; ; attempt++ {
+		err := p.validateProposal(ctx, proposal)
+		if err == nil || !IsRetryable(err) || attempt >= p.config.ValidationRetries {
+			return err
+		}
+		p.logger.Printf("[WARN] proposal validation failed, retrying: attempt=%d, err=%v", attempt+1, err)
+
+		select {
+		case <-time.After(p.config.jitter(p.config.ValidationRetryDelay)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package pbft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	errNotReady := errors.New("not ready")
+	assert.True(t, IsRetryable(NewRetryableError(errNotReady)))
+	assert.True(t, IsRetryable(fmt.Errorf("wrapped: %w", NewRetryableError(errNotReady))))
+	assert.ErrorIs(t, NewRetryableError(errNotReady), errNotReady)
+	assert.False(t, IsRetryable(errNotReady))
+	assert.False(t, IsRetryable(nil))
+}
+
+func TestTransition_AcceptState_ValidationRetry(t *testing.T) {
+	errNotReady := NewRetryableError(errors.New("parent state not available yet"))
+	errInvalid := errors.New("invalid block")
+
+	cases := []struct {
+		name  string
+		errs  []error
+		state State
+		calls int
+	}{
+		{"transient error recovered", []error{errNotReady, errNotReady}, ValidateState, 3},
+		{"transient error exhausting the retries", []error{errNotReady, errNotReady, errNotReady, errNotReady}, RoundChangeState, 4},
+		{"permanent error", []error{errInvalid, errNotReady}, RoundChangeState, 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			validatorIds := []NodeID{"A", "B", "C", "D"}
+			votingPowerMap := CreateEqualVotingPowerMap(validatorIds)
+			calls := 0
+			backend := newMockBackend(validatorIds, votingPowerMap, nil).HookValidateHandler(func(*Proposal) error {
+				calls++
+				if calls <= len(c.errs) {
+					return c.errs[calls-1]
+				}
+				return nil
+			})
+
+			m := newMockPbft(t, validatorIds, votingPowerMap, "B", backend)
+			WithValidationRetry(3, time.Millisecond)(m.config)
+			m.state.view = ViewMsg(1, 0)
+			m.setState(AcceptState)
+			m.emitMsg(createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0)))
+
+			m.runCycle(context.Background())
+
+			assert.Equal(t, c.state, m.getState())
+			assert.Equal(t, c.calls, calls)
+		})
+	}
+}
+
+// Test that a validation failing with a retryable error starts a round change right away when the retries are disabled.
+func TestTransition_AcceptState_ValidationRetry_Disabled(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	votingPowerMap := CreateEqualVotingPowerMap(validatorIds)
+	calls := 0
+	backend := newMockBackend(validatorIds, votingPowerMap, nil).HookValidateHandler(func(*Proposal) error {
+		calls++
+		return NewRetryableError(errors.New("not ready"))
+	})
+
+	m := newMockPbft(t, validatorIds, votingPowerMap, "B", backend)
+	m.state.view = ViewMsg(1, 0)
+	m.setState(AcceptState)
+	m.emitMsg(createMessage("A", MessageReq_Preprepare, ViewMsg(1, 0)))
+
+	m.runCycle(context.Background())
+
+	assert.Equal(t, RoundChangeState, m.getState())
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,115 @@
+package pbft
+
+import (
+	"fmt"
+	"sync"
+)
+
+// validatorCacheSize is the number of epochs whose validator set is kept by ValidatorsForSequence
+const validatorCacheSize = 8
+
+// validatorSetCache keeps the validator sets of the most recently looked up epochs
+type validatorSetCache struct {
+	lock sync.Mutex
+
+	// sets are the cached validator sets by epoch
+	sets map[uint64]ValidatorSet
+
+	// recent are the cached epochs, the least recently used first
+	recent []uint64
+}
+
+func newValidatorSetCache() *validatorSetCache {
+	return &validatorSetCache{
+		sets: map[uint64]ValidatorSet{},
+	}
+}
+
+// get returns the cached validator set of the epoch
+func (c *validatorSetCache) get(epoch uint64) (ValidatorSet, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	set, ok := c.sets[epoch]
+	if ok {
+		c.touch(epoch)
+	}
+	return set, ok
+}
+
+// put caches the validator set of the epoch, evicting the least recently used epoch when full
+func (c *validatorSetCache) put(epoch uint64, set ValidatorSet) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.sets[epoch]; !ok && len(c.recent) == validatorCacheSize {
+		delete(c.sets, c.recent[0])
+		c.recent = c.recent[1:]
+	}
+	c.sets[epoch] = set
+	c.touch(epoch)
+}
+
+// invalidate drops the cached validator set of the epoch
+func (c *validatorSetCache) invalidate(epoch uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.sets, epoch)
+	c.remove(epoch)
+}
+
+// touch moves the epoch to the most recently used position
+func (c *validatorSetCache) touch(epoch uint64) {
+	c.remove(epoch)
+	c.recent = append(c.recent, epoch)
+}
+
+func (c *validatorSetCache) remove(epoch uint64) {
+	for i, recent := range c.recent {
+		if recent == epoch {
+			c.recent = append(c.recent[:i], c.recent[i+1:]...)
+			return
+		}
+	}
+}
+
+// validatorsEpoch returns the epoch of the sequence for the source of the validator sets: the epochs of the
+// ValidatorStore take precedence over the ones of the EpochManager
+func (p *Pbft) validatorsEpoch(sequence uint64) uint64 {
+	if p.config.ValidatorStore == nil && p.config.EpochManager != nil {
+		return p.config.EpochManager.EpochForSequence(sequence)
+	}
+	return p.epoch(sequence)
+}
+
+// ValidatorsForSequence returns the validator set that applies to the sequence, as persisted in the ValidatorStore
+// or, without a store, as provided by the EpochManager. The sets of the recently looked up epochs are cached,
+// the cached set of the current epoch is dropped whenever the node applies a validator set change.
+// It returns ErrValidatorSetNotFound if the set is not known (i.e. the first epoch of an EpochManager,
+// which comes from the backend).
+func (p *Pbft) ValidatorsForSequence(sequence uint64) (ValidatorSet, error) {
+	epoch := p.validatorsEpoch(sequence)
+	if set, ok := p.validatorCache.get(epoch); ok {
+		return set, nil
+	}
+
+	var (
+		set ValidatorSet
+		err error
+	)
+	switch {
+	case p.config.ValidatorStore != nil:
+		set, err = p.config.ValidatorStore.Load(epoch)
+	case p.config.EpochManager != nil:
+		set, err = p.config.EpochManager.lookup(epoch)
+	default:
+		err = ErrValidatorSetNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("validator set of sequence %d: %w", sequence, err)
+	}
+
+	p.validatorCache.put(epoch, set)
+	return set, nil
+}
@@ -0,0 +1,112 @@
+package pbft
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingValidatorStore is a ValidatorStore counting the loads of each epoch
+type countingValidatorStore struct {
+	ValidatorStore
+
+	lock  sync.Mutex
+	loads map[uint64]int
+}
+
+func (s *countingValidatorStore) Load(epoch uint64) (ValidatorSet, error) {
+	s.lock.Lock()
+	s.loads[epoch]++
+	s.lock.Unlock()
+	return s.ValidatorStore.Load(epoch)
+}
+
+func TestValidatorsForSequence_Cached(t *testing.T) {
+	fileStore, err := NewFileValidatorStore(t.TempDir())
+	require.NoError(t, err)
+	store := &countingValidatorStore{ValidatorStore: fileStore, loads: map[uint64]int{}}
+
+	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
+	WithValidatorStore(store, 10)(m.config)
+	require.NoError(t, m.SetBackend(m.backend))
+
+	nextSet := NewValStringStub([]NodeID{"A", "B", "C", "D"}, CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+	require.NoError(t, fileStore.Save(1, nextSet))
+	loads := store.loads[1]
+
+	// the set of an epoch is loaded once for all its sequences
+	for sequence := uint64(10); sequence < 20; sequence++ {
+		set, err := m.ValidatorsForSequence(sequence)
+		require.NoError(t, err)
+		assert.Equal(t, 4, set.Len())
+	}
+	assert.Equal(t, loads+1, store.loads[1])
+
+	// an unknown epoch is not cached
+	_, err = m.ValidatorsForSequence(25)
+	assert.ErrorIs(t, err, ErrValidatorSetNotFound)
+
+	// applying a validator set change drops the cached set of its epoch
+	require.NoError(t, fileStore.Save(1, NewValStringStub([]NodeID{"A", "B"}, CreateEqualVotingPowerMap([]NodeID{"A", "B"}))))
+	m.sequence = 10
+	require.NoError(t, m.SetBackend(m.backend))
+	assert.Equal(t, 2, m.state.validators.Len())
+
+	loads = store.loads[1]
+	set, err := m.ValidatorsForSequence(15)
+	require.NoError(t, err)
+	assert.Equal(t, 2, set.Len())
+	assert.Equal(t, loads+1, store.loads[1])
+
+	// the sets of the other epochs are kept
+	loads = store.loads[0]
+	set, err = m.ValidatorsForSequence(5)
+	require.NoError(t, err)
+	assert.Equal(t, 3, set.Len())
+	_, err = m.ValidatorsForSequence(5)
+	require.NoError(t, err)
+	assert.Equal(t, loads+1, store.loads[0])
+}
+
+func TestValidatorsForSequence_EpochManager(t *testing.T) {
+	requested := 0
+	manager := NewEpochManager(10, func(epoch uint64, lastSequence uint64) (ValidatorSet, error) {
+		requested++
+		ids := []NodeID{"A", "B", "C", "D"}[:epoch+1]
+		return NewValStringStub(ids, CreateEqualVotingPowerMap(ids)), nil
+	})
+
+	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
+	WithEpochManager(manager)(m.config)
+
+	// the first epoch comes from the backend
+	_, err := m.ValidatorsForSequence(3)
+	assert.ErrorIs(t, err, ErrValidatorSetNotFound)
+
+	for _, sequence := range []uint64{20, 25, 29} {
+		set, err := m.ValidatorsForSequence(sequence)
+		require.NoError(t, err)
+		assert.Equal(t, 3, set.Len())
+	}
+	assert.Equal(t, 1, requested)
+}
+
+func TestValidatorSetCache_Evicts(t *testing.T) {
+	c := newValidatorSetCache()
+	set := NewValStringStub([]NodeID{"A"}, nil)
+	for epoch := uint64(0); epoch < validatorCacheSize; epoch++ {
+		c.put(epoch, set)
+	}
+
+	// epoch 0 becomes the most recently used, epoch 1 is evicted
+	_, ok := c.get(0)
+	require.True(t, ok)
+	c.put(validatorCacheSize, set)
+
+	_, ok = c.get(1)
+	assert.False(t, ok)
+	_, ok = c.get(0)
+	assert.True(t, ok)
+}
@@ -0,0 +1,42 @@
+package pbft
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrValidatorChangeRejected is returned when the ValidatorChangePolicy rejects a validator set update
+var ErrValidatorChangeRejected = errors.New("validator set change rejected")
+
+// ValidatorChangePolicy enforces the application rules on the validator set updates (i.e. a removed validator
+// with pending obligations). It is consulted only when the validator set, or its voting power, changes.
+type ValidatorChangePolicy interface {
+	// Approve returns an error to reject the change from the old to the new validator set
+	Approve(old, new ValidatorSet) error
+}
+
+// approveValidatorChange consults the ValidatorChangePolicy when the given validator set differs from the current one
+func (p *Pbft) approveValidatorChange(validators ValidatorSet) error {
+	policy := p.config.ValidatorChangePolicy
+	if policy == nil || p.state.validators == nil || sameValidators(p.state.validators, validators) {
+		return nil
+	}
+	if err := policy.Approve(p.state.validators, validators); err != nil {
+		return fmt.Errorf("%w: %v", ErrValidatorChangeRejected, err)
+	}
+	return nil
+}
+
+// sameValidators returns whether the two validator sets have the same validators with the same voting power
+func sameValidators(a, b ValidatorSet) bool {
+	if a.Len() != b.Len() {
+		return false
+	}
+	votingPower := b.VotingPower()
+	for id, power := range a.VotingPower() {
+		if other, ok := votingPower[id]; !ok || other != power {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package pbft
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validatorChangeFunc is a ValidatorChangePolicy out of a function
+type validatorChangeFunc func(old, new ValidatorSet) error
+
+func (f validatorChangeFunc) Approve(old, new ValidatorSet) error {
+	return f(old, new)
+}
+
+func TestPbft_ValidatorChangePolicy(t *testing.T) {
+	errPendingObligations := errors.New("D has pending obligations")
+	calls := 0
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithValidatorChangePolicy(validatorChangeFunc(func(old, new ValidatorSet) error {
+		calls++
+		if old.Includes("D") && !new.Includes("D") {
+			return errPendingObligations
+		}
+		return nil
+	}))(m.config)
+	backend := m.backend.(*mockBackend)
+	original := m.state.validators
+
+	// an unchanged set is not submitted to the policy
+	m.sequence = 2
+	require.NoError(t, m.SetBackend(backend))
+	assert.Zero(t, calls)
+
+	// the removal of D is rejected and the current set is kept
+	ids := []NodeID{"A", "B", "C"}
+	backend.validators = NewValStringStub(ids, CreateEqualVotingPowerMap(ids))
+	m.sequence = 3
+	err := m.SetBackend(backend)
+	assert.ErrorIs(t, err, ErrValidatorChangeRejected)
+	assert.Contains(t, err.Error(), errPendingObligations.Error())
+	assert.Equal(t, 1, calls)
+	assert.Same(t, original, m.state.validators)
+
+	// adding E is approved
+	ids = []NodeID{"A", "B", "C", "D", "E"}
+	backend.validators = NewValStringStub(ids, CreateEqualVotingPowerMap(ids))
+	require.NoError(t, m.SetBackend(backend))
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 5, m.state.validators.Len())
+}
+
+func TestSameValidators(t *testing.T) {
+	ids := []NodeID{"A", "B", "C"}
+	set := NewValStringStub(ids, CreateEqualVotingPowerMap(ids))
+
+	assert.True(t, sameValidators(set, NewValStringStub(ids, CreateEqualVotingPowerMap(ids))))
+	assert.False(t, sameValidators(set, NewValStringStub(ids, map[NodeID]uint64{"A": 1, "B": 1, "C": 2})))
+	assert.False(t, sameValidators(set, NewValStringStub([]NodeID{"A", "B", "D"}, CreateEqualVotingPowerMap([]NodeID{"A", "B", "D"}))))
+	assert.False(t, sameValidators(set, NewValStringStub(ids[:2], CreateEqualVotingPowerMap(ids[:2]))))
+}
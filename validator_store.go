@@ -0,0 +1,184 @@
+package pbft
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ErrValidatorSetNotFound is returned by a ValidatorStore when no validator set was saved for the epoch
+var ErrValidatorSetNotFound = errors.New("validator set not found")
+
+// ValidatorStore persists the validator set of each epoch so that it survives restarts
+type ValidatorStore interface {
+	// Save stores the validator set of the epoch
+	Save(epoch uint64, set ValidatorSet) error
+
+	// Load returns the validator set of the epoch or ErrValidatorSetNotFound
+	Load(epoch uint64) (ValidatorSet, error)
+}
+
+// StaticValidatorSet is a fixed validator set with a round robin proposer rotation over Nodes
+type StaticValidatorSet struct {
+	Nodes          []NodeID          `json:"nodes"`
+	VotingPowerMap map[NodeID]uint64 `json:"votingPower"`
+}
+
+// NewStaticValidatorSet creates a static copy of the given validator set.
+// The nodes are ordered as the proposer rotation of the set, so that the copy rotates proposers the same way.
+func NewStaticValidatorSet(set ValidatorSet) *StaticValidatorSet {
+	votingPower := map[NodeID]uint64{}
+	for id, power := range set.VotingPower() {
+		votingPower[id] = power
+	}
+
+	return &StaticValidatorSet{
+		Nodes:          validatorOrder(set),
+		VotingPowerMap: votingPower,
+	}
+}
+
+// validatorOrder returns the validators of the set in the order of its proposer rotation.
+// The validators never selected in the first rounds (i.e. weighted rotations) come last, sorted by id.
+func validatorOrder(set ValidatorSet) []NodeID {
+	nodes := make([]NodeID, 0, set.Len())
+	seen := map[NodeID]struct{}{}
+	for round := 0; round < set.Len(); round++ {
+		id := set.CalcProposer(uint64(round))
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		nodes = append(nodes, id)
+	}
+	remaining := []NodeID{}
+	for id := range set.VotingPower() {
+		if _, ok := seen[id]; !ok {
+			remaining = append(remaining, id)
+		}
+	}
+	sort.Slice(remaining, func(i, j int) bool { return remaining[i] < remaining[j] })
+	return append(nodes, remaining...)
+}
+
+// CalcProposer implements ValidatorSet interface
+func (s *StaticValidatorSet) CalcProposer(round uint64) NodeID {
+	return s.Nodes[round%uint64(len(s.Nodes))]
+}
+
+// Includes implements ValidatorSet interface
+func (s *StaticValidatorSet) Includes(id NodeID) bool {
+	_, ok := s.VotingPowerMap[id]
+	return ok
+}
+
+// Len implements ValidatorSet interface
+func (s *StaticValidatorSet) Len() int {
+	return len(s.Nodes)
+}
+
+// VotingPower implements ValidatorSet interface
+func (s *StaticValidatorSet) VotingPower() map[NodeID]uint64 {
+	return s.VotingPowerMap
+}
+
+// FileValidatorStore is a ValidatorStore keeping a JSON file per epoch in a directory
+type FileValidatorStore struct {
+	lock sync.Mutex
+	dir  string
+}
+
+// NewFileValidatorStore creates a file backed ValidatorStore in the given directory
+func NewFileValidatorStore(dir string) (*FileValidatorStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileValidatorStore{dir: dir}, nil
+}
+
+func (f *FileValidatorStore) path(epoch uint64) string {
+	return filepath.Join(f.dir, fmt.Sprintf("validators-%d.json", epoch))
+}
+
+// Save implements ValidatorStore interface
+func (f *FileValidatorStore) Save(epoch uint64, set ValidatorSet) error {
+	data, err := json.Marshal(NewStaticValidatorSet(set))
+	if err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	// write to a temporary file first so that a crash never leaves a partially written set
+	tmp := f.path(epoch) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path(epoch))
+}
+
+// Load implements ValidatorStore interface
+func (f *FileValidatorStore) Load(epoch uint64) (ValidatorSet, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	data, err := os.ReadFile(f.path(epoch))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrValidatorSetNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	set := &StaticValidatorSet{}
+	if err := json.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("failed to decode validator set of epoch %d: %w", epoch, err)
+	}
+	if len(set.Nodes) == 0 {
+		return nil, fmt.Errorf("validator set of epoch %d is empty", epoch)
+	}
+	return set, nil
+}
+
+// epoch returns the epoch of the sequence, all the sequences belong to the epoch 0 when EpochSize is not set
+func (p *Pbft) epoch(sequence uint64) uint64 {
+	if p.config.EpochSize == 0 {
+		return 0
+	}
+	return sequence / p.config.EpochSize
+}
+
+// epochValidators returns the validator set of the epoch of the sequence when a ValidatorStore is configured.
+// The set of an epoch is loaded from the store if it was persisted (i.e. before a restart), otherwise the set
+// provided by the backend is saved. The set is then kept for the whole epoch.
+func (p *Pbft) epochValidators(sequence uint64, validators ValidatorSet) (ValidatorSet, error) {
+	store := p.config.ValidatorStore
+	if store == nil {
+		return validators, nil
+	}
+
+	epoch := p.epoch(sequence)
+	if p.epochSet != nil && p.epochSetEpoch == epoch {
+		return p.epochSet, nil
+	}
+
+	loaded, err := store.Load(epoch)
+	switch {
+	case err == nil:
+		validators = loaded
+	case errors.Is(err, ErrValidatorSetNotFound):
+		if err := store.Save(epoch, validators); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	p.epochSet, p.epochSetEpoch = validators, epoch
+	return validators, nil
+}
@@ -0,0 +1,58 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileValidatorStore_SaveLoad(t *testing.T) {
+	store, err := NewFileValidatorStore(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = store.Load(1)
+	assert.ErrorIs(t, err, ErrValidatorSetNotFound)
+
+	votingPower := map[NodeID]uint64{"C": 5, "A": 1, "B": 3}
+	set := NewValStringStub([]NodeID{"C", "A", "B"}, votingPower)
+	require.NoError(t, store.Save(1, set))
+
+	loaded, err := store.Load(1)
+	require.NoError(t, err)
+	assert.Equal(t, votingPower, loaded.VotingPower())
+	assert.Equal(t, set.Len(), loaded.Len())
+	// the proposer rotation is preserved
+	for round := uint64(0); round < 6; round++ {
+		assert.Equal(t, set.CalcProposer(round), loaded.CalcProposer(round))
+	}
+	assert.True(t, loaded.Includes("B"))
+	assert.False(t, loaded.Includes("D"))
+}
+
+func TestPbft_ValidatorStore(t *testing.T) {
+	store, err := NewFileValidatorStore(t.TempDir())
+	require.NoError(t, err)
+
+	// the first node saves the set provided by the backend
+	m := newMockPbft(t, []NodeID{"A", "B", "C"}, nil, "A")
+	WithValidatorStore(store, 10)(m.config)
+	require.NoError(t, m.SetBackend(m.backend))
+	saved, err := store.Load(0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, saved.Len())
+
+	// after a restart, the persisted set of the epoch takes precedence over the backend one
+	restarted := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithValidatorStore(store, 10)(restarted.config)
+	require.NoError(t, restarted.SetBackend(restarted.backend))
+	assert.Equal(t, 3, restarted.state.validators.Len())
+
+	// the set of a new epoch comes from the backend
+	restarted.sequence = 10
+	require.NoError(t, restarted.SetBackend(restarted.backend))
+	assert.Equal(t, 4, restarted.state.validators.Len())
+	saved, err = store.Load(1)
+	require.NoError(t, err)
+	assert.Equal(t, 4, saved.Len())
+}
@@ -1,6 +1,16 @@
 package pbft
 
-import "fmt"
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ViewEncodingSize is the size of the binary encoding of a View (see View.MarshalBinary)
+const ViewEncodingSize = 16
+
+// ErrViewEncoding is returned when a binary encoded view is malformed
+var ErrViewEncoding = errors.New("invalid view encoding")
 
 type View struct {
 	// round is the current round/height being finalized
@@ -27,3 +37,24 @@ func (v *View) Copy() *View {
 func (v *View) String() string {
 	return fmt.Sprintf("(Sequence=%d, Round=%d)", v.Sequence, v.Round)
 }
+
+// MarshalBinary encodes the view in its canonical fixed-width layout, the one signed wherever a view is sealed
+// (see SignableViewContent):
+//
+//	sequence (8 bytes, big endian) | round (8 bytes, big endian)
+func (v *View) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, ViewEncodingSize)
+	binary.BigEndian.PutUint64(buf[0:8], v.Sequence)
+	binary.BigEndian.PutUint64(buf[8:16], v.Round)
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a view encoded by MarshalBinary, the data must be exactly ViewEncodingSize bytes long
+func (v *View) UnmarshalBinary(data []byte) error {
+	if len(data) != ViewEncodingSize {
+		return fmt.Errorf("%w: %d bytes, expected %d", ErrViewEncoding, len(data), ViewEncodingSize)
+	}
+	v.Sequence = binary.BigEndian.Uint64(data[0:8])
+	v.Round = binary.BigEndian.Uint64(data[8:16])
+	return nil
+}
@@ -0,0 +1,140 @@
+package pbft
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// viewSyncTracker keeps the last view advertised by each validator with a view sync message
+type viewSyncTracker struct {
+	lock  sync.Mutex
+	views map[NodeID]View
+}
+
+func newViewSyncTracker() *viewSyncTracker {
+	return &viewSyncTracker{views: map[NodeID]View{}}
+}
+
+// observe records the view advertised by the validator, an older view than the one already known is ignored
+func (v *viewSyncTracker) observe(from NodeID, view View) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if known, ok := v.views[from]; ok && cmpView(&view, &known) <= 0 {
+		return
+	}
+	v.views[from] = view
+}
+
+// aheadVotingPower returns the voting power of the validators which advertised a sequence higher than the given one
+func (v *viewSyncTracker) aheadVotingPower(validators ValidatorSet, sequence uint64) uint64 {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	votingPower := validators.VotingPower()
+	ahead := uint64(0)
+	for from, view := range v.views {
+		if view.Sequence > sequence {
+			ahead += votingPower[from]
+		}
+	}
+	return ahead
+}
+
+// viewSyncBroadcaster marks the view sync message as due every interval (see WithViewSync)
+type viewSyncBroadcaster struct {
+	stop chan struct{}
+	done chan struct{}
+
+	// due is set when the view sync message has to be sent
+	due uint32
+}
+
+// BroadcastViewSync sends the current view and state of the node to the peers, so that the lagging ones learn
+// the view of the network. The nodes observing more than the max faulty voting power at a higher sequence sync.
+func (p *Pbft) BroadcastViewSync() {
+	if p.IsPaused() {
+		return
+	}
+	view := p.state.CurrentView()
+	msg := &MessageReq{
+		Type:      MessageReq_ViewSync,
+		From:      p.validator.NodeID(),
+		View:      &view,
+		NodeState: p.getState(),
+	}
+	msg, ok := p.interceptOutgoing(msg)
+	if !ok {
+		return
+	}
+	if err := p.transport.Gossip(msg); err != nil {
+		p.logger.Printf("[ERROR] failed to gossip view sync. Error message: %v", err)
+	}
+}
+
+// handleViewSync records the view advertised by a validator
+func (p *Pbft) handleViewSync(msg *MessageReq) {
+	if msg.View == nil || !p.state.validators.Includes(msg.From) {
+		return
+	}
+	p.viewSync.observe(msg.From, *msg.View)
+
+	select {
+	case p.updateCh <- struct{}{}:
+	default:
+	}
+}
+
+// viewSyncBehind returns whether more than the max faulty voting power (f+1) advertised a higher sequence,
+// i.e. at least one honest validator is ahead and the node has to sync
+func (p *Pbft) viewSyncBehind() bool {
+	if p.state.validators == nil {
+		return false
+	}
+	sequence := p.state.CurrentView().Sequence
+	return p.viewSync.aheadVotingPower(p.state.validators, sequence) > p.state.getMaxFaultyVotingPower()
+}
+
+// startViewSync broadcasts the view sync message every ViewSyncInterval until the returned function is called
+func (p *Pbft) startViewSync() func() {
+	interval := p.config.ViewSyncInterval
+	if interval <= 0 {
+		return func() {}
+	}
+
+	b := &viewSyncBroadcaster{stop: make(chan struct{}), done: make(chan struct{})}
+	p.viewSyncBroadcaster = b
+	go func() {
+		defer close(b.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				atomic.StoreUint32(&b.due, 1)
+				select {
+				case p.updateCh <- struct{}{}:
+				default:
+				}
+			case <-b.stop:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(b.stop)
+		<-b.done
+		p.viewSyncBroadcaster = nil
+	}
+}
+
+// broadcastViewSyncIfDue sends the view sync message from the state machine, if due
+func (p *Pbft) broadcastViewSyncIfDue() {
+	b := p.viewSyncBroadcaster
+	if b == nil || !atomic.CompareAndSwapUint32(&b.due, 1, 0) {
+		return
+	}
+	p.BroadcastViewSync()
+}
@@ -0,0 +1,57 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func viewSyncMsg(from NodeID, view *View) *MessageReq {
+	return &MessageReq{Type: MessageReq_ViewSync, From: from, View: view, NodeState: ValidateState}
+}
+
+// Test that f+1 validators advertising a higher sequence move a lagging node to the sync state.
+func TestTransition_AcceptState_ViewSync(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "B")
+	m.setState(AcceptState)
+
+	// f voting power ahead, a non validator and a validator at the local sequence are not enough
+	m.emitMsg(viewSyncMsg("A", ViewMsg(5, 0)))
+	m.emitMsg(viewSyncMsg("X", ViewMsg(5, 0)))
+	m.emitMsg(viewSyncMsg("C", ViewMsg(1, 3)))
+	assert.False(t, m.viewSyncBehind())
+
+	m.emitMsg(viewSyncMsg("D", ViewMsg(4, 1)))
+	assert.True(t, m.viewSyncBehind())
+
+	m.runCycle(context.Background())
+
+	assert.Equal(t, SyncState, m.getState())
+	// the view sync messages are not queued for the consensus
+	assert.Empty(t, m.msgQueue.acceptStateQueue)
+	assert.Empty(t, m.msgQueue.validateStateQueue)
+	assert.Empty(t, m.msgQueue.roundChangeStateQueue)
+}
+
+func TestPbft_BroadcastViewSync(t *testing.T) {
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	m.state.view = ViewMsg(3, 2)
+	m.setState(RoundChangeState)
+
+	m.BroadcastViewSync()
+
+	require.Len(t, m.respMsg, 1)
+	msg := m.respMsg[0]
+	assert.Equal(t, MessageReq_ViewSync, msg.Type)
+	assert.Equal(t, NodeID("A"), msg.From)
+	assert.Equal(t, ViewMsg(3, 2), msg.View)
+	assert.Equal(t, RoundChangeState, msg.NodeState)
+	assert.NoError(t, msg.Validate())
+
+	// a paused node does not advertise its view
+	m.Pause()
+	m.BroadcastViewSync()
+	assert.Len(t, m.respMsg, 1)
+}
@@ -0,0 +1,40 @@
+package pbft
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestView_MarshalBinary_RoundTrip(t *testing.T) {
+	for _, view := range []*View{ViewMsg(0, 0), ViewMsg(1, 2), ViewMsg(^uint64(0), 1<<40)} {
+		data, err := view.MarshalBinary()
+		require.NoError(t, err)
+		assert.Len(t, data, ViewEncodingSize)
+
+		decoded := &View{}
+		require.NoError(t, decoded.UnmarshalBinary(data))
+		assert.Equal(t, view, decoded)
+	}
+
+	assert.ErrorIs(t, (&View{}).UnmarshalBinary(make([]byte, ViewEncodingSize-1)), ErrViewEncoding)
+	assert.ErrorIs(t, (&View{}).UnmarshalBinary(make([]byte, ViewEncodingSize+1)), ErrViewEncoding)
+}
+
+func TestView_MarshalBinary_Deterministic(t *testing.T) {
+	// the layout is fixed, so that the nodes sign the same bytes for the same view
+	data, err := ViewMsg(0x0102, 0x03).MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, "00000000000001020000000000000003", hex.EncodeToString(data))
+
+	other, err := (&View{Round: 0x03, Sequence: 0x0102}).MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, data, other)
+
+	hash := []byte("hash")
+	assert.Equal(t, SignableViewContent([]byte("a"), ViewMsg(1, 2), hash), SignableViewContent([]byte("a"), ViewMsg(1, 2), hash))
+	assert.NotEqual(t, SignableViewContent([]byte("a"), ViewMsg(1, 2), hash), SignableViewContent([]byte("a"), ViewMsg(2, 1), hash))
+	assert.Equal(t, SignableContent(nil, append(data, hash...)), SignableViewContent(nil, ViewMsg(0x0102, 0x03), hash))
+}
@@ -0,0 +1,132 @@
+package pbft
+
+import "fmt"
+
+// reweightedValidatorSet is a validator set with the voting power replaced, the membership and the proposer
+// rotation are the ones of the wrapped set
+type reweightedValidatorSet struct {
+	ValidatorSet
+	votingPower map[NodeID]uint64
+}
+
+// VotingPower implements ValidatorSet interface
+func (r *reweightedValidatorSet) VotingPower() map[NodeID]uint64 {
+	return r.votingPower
+}
+
+// updateVotingPower replaces the voting power of the validators, recalculates the quorum and
+// the accumulated voting power of the messages already received. The map must cover exactly the current validators.
+func (s *state) updateVotingPower(votingPower map[NodeID]uint64) error {
+	if len(votingPower) != s.validators.Len() {
+		return fmt.Errorf("%w: %d validators, %d voting powers", errInvalidVotingPowerUpdate, s.validators.Len(), len(votingPower))
+	}
+	copied := make(map[NodeID]uint64, len(votingPower))
+	for id, power := range votingPower {
+		if !s.validators.Includes(id) {
+			return fmt.Errorf("%w: %s is not a validator", errInvalidVotingPowerUpdate, id)
+		}
+		copied[id] = power
+	}
+
+	previous := s.validators
+	if r, ok := previous.(*reweightedValidatorSet); ok {
+		previous = r.ValidatorSet
+	}
+	s.validators = &reweightedValidatorSet{ValidatorSet: previous, votingPower: copied}
+	if err := s.initializeVotingInfo(); err != nil {
+		s.validators = previous
+		return err
+	}
+
+	s.msgLock.Lock()
+	defer s.msgLock.Unlock()
+
+	s.prepared.reweight(copied)
+	s.committed.reweight(copied)
+	for _, msgs := range s.roundMessages {
+		msgs.reweight(copied)
+	}
+	return nil
+}
+
+// retainValidators removes the messages of the senders which are not in the new validator set anymore and
+// recalculates the accumulated voting power with the voting power of the new set
+func (s *state) retainValidators(previous ValidatorSet) {
+	s.msgLock.Lock()
+	defer s.msgLock.Unlock()
+
+	previousVotingPower := map[NodeID]uint64{}
+	if previous != nil {
+		previousVotingPower = previous.VotingPower()
+	}
+	votingPower := s.validators.VotingPower()
+	for _, msgs := range s.messageLists() {
+		for from := range msgs.messageMap {
+			if !s.validators.Includes(from) {
+				msgs.removeMessage(from, previousVotingPower[from])
+			}
+		}
+		msgs.reweight(votingPower)
+	}
+}
+
+// messageLists returns the prepared, committed and round change message lists
+func (s *state) messageLists() []*messages {
+	lists := []*messages{s.prepared, s.committed}
+	for _, msgs := range s.roundMessages {
+		lists = append(lists, msgs)
+	}
+	return lists
+}
+
+// reweight recalculates the accumulated voting power of the messages with the given voting power
+func (m *messages) reweight(votingPower map[NodeID]uint64) {
+	m.accumulatedVotingPower = 0
+	for from := range m.messageMap {
+		m.accumulatedVotingPower += votingPower[from]
+	}
+}
+
+// UpdateVotingPower replaces the voting power of the current validators, without changing the validator set.
+// The quorum is recalculated and the messages already received are counted with the new voting power,
+// so the next message processed is evaluated against the new thresholds. An update without voting power is
+// handled as set by the ZeroVotingPowerPolicy.
+// Like SetBackend, it must not be called concurrently with the state machine (i.e. use a CommandEvent).
+func (p *Pbft) UpdateVotingPower(votingPower map[NodeID]uint64) error {
+	if err := p.state.updateVotingPower(p.equalWeightVotingPower(votingPower)); err != nil {
+		return err
+	}
+	return p.state.initializePhaseQuorums(p.config.PrepareQuorum, p.config.CommitQuorum)
+}
+
+// TotalVotingPower returns the voting power of the current validator set. A set without voting power
+// counts one per validator.
+func (p *Pbft) TotalVotingPower() uint64 {
+	validators := p.state.validators
+	if validators == nil {
+		return 0
+	}
+	votingPower := validators.VotingPower()
+	if len(votingPower) == 0 {
+		return uint64(validators.Len())
+	}
+	total := uint64(0)
+	for _, power := range votingPower {
+		total += power
+	}
+	return total
+}
+
+// VotingPowerOf returns the voting power of the node in the current validator set, zero if it is not a validator.
+// A set without voting power counts one per validator.
+func (p *Pbft) VotingPowerOf(id NodeID) uint64 {
+	validators := p.state.validators
+	if validators == nil || !validators.Includes(id) {
+		return 0
+	}
+	votingPower := validators.VotingPower()
+	if len(votingPower) == 0 {
+		return 1
+	}
+	return votingPower[id]
+}
@@ -0,0 +1,81 @@
+package pbft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestState_UpdateVotingPower_Validation(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.addAccounts(CreateEqualVotingPowerMap([]NodeID{"A", "B", "C", "D"}))
+	s, err := initState(pool)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name        string
+		votingPower map[NodeID]uint64
+	}{
+		{"missing validator", map[NodeID]uint64{"A": 1, "B": 1, "C": 1}},
+		{"unknown validator", map[NodeID]uint64{"A": 1, "B": 1, "C": 1, "E": 1}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.ErrorIs(t, s.updateVotingPower(c.votingPower), errInvalidVotingPowerUpdate)
+			assert.Equal(t, uint64(3), s.getQuorumSize())
+		})
+	}
+
+	assert.ErrorIs(t, s.updateVotingPower(map[NodeID]uint64{"A": 0, "B": 0, "C": 0, "D": 0}), errInvalidTotalVotingPower)
+	assert.Equal(t, uint64(1), s.validators.VotingPower()["A"])
+}
+
+// Test that raising the voting power of a validator makes the prepared messages already received reach the quorum.
+func TestPbft_UpdateVotingPower_ReevaluatesTallies(t *testing.T) {
+	prepare := func(from NodeID) Event {
+		msg := createMessage(from, MessageReq_Prepare, ViewMsg(1, 0))
+		msg.Hash = digest
+		return MessageEvent(msg)
+	}
+	raise := CommandEvent(func(p *Pbft) {
+		// A and B prepares (voting power 2) do not reach the quorum
+		assert.False(t, p.state.IsLocked())
+		require.NoError(t, p.UpdateVotingPower(map[NodeID]uint64{"A": 1, "B": 3, "C": 1, "D": 1}))
+	})
+
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, nil, "A")
+	WithScheduler(NewDeterministicScheduler(prepare("A"), prepare("B"), raise, prepare("A")))(m.config)
+	m.setState(ValidateState)
+
+	m.runCycle(context.Background())
+
+	// total voting power 6: the quorum stays 3, met by A and B prepares (voting power 4)
+	assert.Equal(t, uint64(3), m.state.getQuorumSize())
+	assert.Equal(t, uint64(4), m.state.prepared.getAccumulatedVotingPower())
+	assert.True(t, m.state.IsLocked())
+	assert.Equal(t, uint64(1), m.state.committed.getAccumulatedVotingPower())
+	assert.Len(t, m.respMsg, 1) // A commit message
+}
+
+func TestPbft_VotingPowerQueries(t *testing.T) {
+	votingPower := map[NodeID]uint64{"A": 5, "B": 3, "C": 1, "D": 1}
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, votingPower, "A")
+
+	assert.Equal(t, uint64(10), m.TotalVotingPower())
+	for id, power := range votingPower {
+		assert.Equal(t, power, m.VotingPowerOf(id))
+	}
+	assert.Equal(t, uint64(0), m.VotingPowerOf("E"))
+
+	// the queries follow a voting power update
+	require.NoError(t, m.UpdateVotingPower(map[NodeID]uint64{"A": 1, "B": 1, "C": 4, "D": 2}))
+	assert.Equal(t, uint64(8), m.TotalVotingPower())
+	assert.Equal(t, uint64(4), m.VotingPowerOf("C"))
+
+	// a validator set without voting power counts the validators
+	m.state.validators = NewValStringStub([]NodeID{"A", "B", "C"}, nil)
+	assert.Equal(t, uint64(3), m.TotalVotingPower())
+	assert.Equal(t, uint64(1), m.VotingPowerOf("B"))
+}
@@ -0,0 +1,137 @@
+package pbft
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// NeededMessages describes the messages the node is waiting for to advance from its current state
+type NeededMessages struct {
+	State State
+	View  View
+
+	// None reports that the node does not wait for any message (i.e. it is proposing, committing or syncing)
+	None bool
+
+	// Type is the type of the messages the node is waiting for
+	Type MsgType
+
+	// From are the validators that did not send the message yet, sorted by id. For a Preprepare, it is the proposer.
+	From []NodeID
+
+	// VotingPower is the voting power still missing to the quorum of Type
+	VotingPower uint64
+
+	// Validators is the minimum number of validators of From whose messages reach VotingPower
+	Validators int
+}
+
+// String describes the needed messages, e.g. "Prepare messages from 2 more validators totaling 30 voting power"
+func (n NeededMessages) String() string {
+	if n.None {
+		return fmt.Sprintf("no message needed in %s", n.State)
+	}
+	if n.Type == MessageReq_Preprepare {
+		return fmt.Sprintf("a Preprepare from proposer %s", n.From[0])
+	}
+	ids := make([]string, 0, len(n.From))
+	for _, id := range n.From {
+		ids = append(ids, string(id))
+	}
+	return fmt.Sprintf("%s messages from %d more validators totaling %d voting power (missing: %s)",
+		n.Type, n.Validators, n.VotingPower, strings.Join(ids, ", "))
+}
+
+// WhatsNeeded describes the messages the node is waiting for in its current state: the Preprepare of the proposer
+// in the accept state, the prepare or the commit messages in the validate state depending on the prepare quorum,
+// and the round change messages of the current round in the round change state.
+func (p *Pbft) WhatsNeeded() NeededMessages {
+	needed := NeededMessages{State: p.getState(), View: p.state.CurrentView()}
+
+	switch needed.State {
+	case AcceptState:
+		if p.state.proposer == "" || p.state.proposer == p.validator.NodeID() {
+			needed.None = true
+			return needed
+		}
+		needed.Type = MessageReq_Preprepare
+		needed.From = []NodeID{p.state.proposer}
+		needed.Validators = 1
+		return needed
+
+	case ValidateState:
+		if remaining := p.RemainingPowerForPrepare(); remaining > 0 {
+			needed.Type = MessageReq_Prepare
+			needed.VotingPower = remaining
+		} else {
+			needed.Type = MessageReq_Commit
+			needed.VotingPower = p.RemainingPowerForCommit()
+		}
+
+	case RoundChangeState:
+		needed.Type = MessageReq_RoundChange
+		needed.VotingPower = remainingPower(p.state.getRoundChangePower(needed.View.Round), 2*p.state.getMaxFaultyVotingPower())
+
+	default:
+		needed.None = true
+		return needed
+	}
+
+	needed.From, needed.Validators = p.state.missingSenders(needed.Type, needed.View.Round, needed.VotingPower)
+	return needed
+}
+
+// getRoundChangePower returns the accumulated voting power of the round change messages of the round
+func (s *state) getRoundChangePower(round uint64) uint64 {
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	if msgs, ok := s.roundMessages[round]; ok {
+		return msgs.getAccumulatedVotingPower()
+	}
+	return 0
+}
+
+// missingSenders returns the validators that did not send a message of the given type (in the given round,
+// for the round changes), sorted by id, and the minimum number of them whose voting power reaches votingPower
+func (s *state) missingSenders(typ MsgType, round uint64, votingPower uint64) ([]NodeID, int) {
+	s.msgLock.RLock()
+	defer s.msgLock.RUnlock()
+
+	var msgs *messages
+	switch typ {
+	case MessageReq_Prepare:
+		msgs = s.prepared
+	case MessageReq_Commit:
+		msgs = s.committed
+	case MessageReq_RoundChange:
+		msgs = s.roundMessages[round]
+	}
+
+	powers := s.validators.VotingPower()
+	missing := []NodeID{}
+	for id := range powers {
+		if msgs != nil {
+			if _, ok := msgs.messageMap[id]; ok {
+				continue
+			}
+		}
+		missing = append(missing, id)
+	}
+
+	// the fewest validators to reach the voting power are the most powerful ones
+	sort.Slice(missing, func(i, j int) bool {
+		if powers[missing[i]] != powers[missing[j]] {
+			return powers[missing[i]] > powers[missing[j]]
+		}
+		return missing[i] < missing[j]
+	})
+	count := 0
+	for accumulated := uint64(0); accumulated < votingPower && count < len(missing); count++ {
+		accumulated += powers[missing[count]]
+	}
+
+	sort.Slice(missing, func(i, j int) bool { return missing[i] < missing[j] })
+	return missing, count
+}
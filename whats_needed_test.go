@@ -0,0 +1,47 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPbft_WhatsNeeded(t *testing.T) {
+	votingPower := map[NodeID]uint64{"A": 10, "B": 20, "C": 30, "D": 40}
+	m := newMockPbft(t, []NodeID{"A", "B", "C", "D"}, votingPower, "A")
+	m.state.view = ViewMsg(1, 0)
+	require.Equal(t, uint64(67), m.PrepareQuorumSize())
+
+	// the node waits for the proposal of B
+	m.setState(AcceptState)
+	m.state.proposer = "B"
+	assert.Equal(t, "a Preprepare from proposer B", m.WhatsNeeded().String())
+
+	// prepare phase, A and B prepared (30), C and D are missing 37
+	m.setState(ValidateState)
+	require.NoError(t, m.state.addPrepareMsg(createMessage("A", MessageReq_Prepare, ViewMsg(1, 0))))
+	require.NoError(t, m.state.addPrepareMsg(createMessage("B", MessageReq_Prepare, ViewMsg(1, 0))))
+
+	needed := m.WhatsNeeded()
+	assert.Equal(t, NeededMessages{
+		State:       ValidateState,
+		View:        View{Sequence: 1, Round: 0},
+		Type:        MessageReq_Prepare,
+		From:        []NodeID{"C", "D"},
+		VotingPower: 37,
+		Validators:  1,
+	}, needed)
+	assert.Equal(t, "Prepare messages from 1 more validators totaling 37 voting power (missing: C, D)", needed.String())
+
+	// once prepared, the commits are needed
+	require.NoError(t, m.state.addPrepareMsg(createMessage("D", MessageReq_Prepare, ViewMsg(1, 0))))
+	needed = m.WhatsNeeded()
+	assert.Equal(t, MessageReq_Commit, needed.Type)
+	assert.Equal(t, uint64(67), needed.VotingPower)
+	assert.Equal(t, 2, needed.Validators)
+
+	// nothing is awaited while committing
+	m.setState(CommitState)
+	assert.True(t, m.WhatsNeeded().None)
+}
@@ -0,0 +1,180 @@
+package pbft
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// WireVersion is the version of the wire format used to encode the messages
+	WireVersion uint8 = 2
+
+	// WireVersionCompression is the first version of the wire format able to carry a compressed proposal.
+	// It adds a flags byte after the version.
+	WireVersionCompression uint8 = 2
+
+	// MinWireVersion is the oldest version of the wire format the node is able to decode
+	MinWireVersion uint8 = 1
+
+	// MaxWireVersion is the newest version of the wire format the node is able to decode
+	MaxWireVersion = WireVersion
+)
+
+// wireMagic prefixes every encoded message, so that data which is not a message fails fast
+var wireMagic = []byte("PBFT")
+
+// wireHeaderSize is the size of the magic followed by the version byte
+var wireHeaderSize = len(wireMagic) + 1
+
+const (
+	// wireFlagCompressedProposal marks the proposal of the payload as gzip compressed
+	wireFlagCompressedProposal byte = 1 << 0
+
+	// maxWireProposalSize bounds the size of a decompressed proposal
+	maxWireProposalSize = 64 << 20
+)
+
+var (
+	// ErrWireMagic is returned when the encoded message does not start with the wire magic
+	ErrWireMagic = errors.New("invalid wire magic")
+
+	// ErrUnsupportedWireVersion is returned when the encoded message has a version out of the supported range
+	ErrUnsupportedWireVersion = errors.New("unsupported wire version")
+)
+
+// SupportedWireVersions returns the range of wire format versions the node is able to decode
+func SupportedWireVersions() (min, max uint8) {
+	return MinWireVersion, MaxWireVersion
+}
+
+// NegotiateWireVersion returns the newest wire format version supported by both the node and a peer
+// supporting the versions in [peerMin, peerMax], or ErrUnsupportedWireVersion if the ranges do not overlap.
+func NegotiateWireVersion(peerMin, peerMax uint8) (uint8, error) {
+	version := MaxWireVersion
+	if peerMax < version {
+		version = peerMax
+	}
+	if version < MinWireVersion || version < peerMin {
+		return 0, fmt.Errorf("%w: peer supports [%d, %d], node supports [%d, %d]",
+			ErrUnsupportedWireVersion, peerMin, peerMax, MinWireVersion, MaxWireVersion)
+	}
+	return version, nil
+}
+
+// WireEncoder encodes the messages in a given wire format version, typically the one negotiated with the peer
+type WireEncoder struct {
+	// Version is the wire format version of the encoded messages
+	Version uint8
+
+	// CompressionThreshold is the proposal size from which the proposal is compressed (zero disables the compression).
+	// The compression requires at least WireVersionCompression and only changes the encoding: the proposal hash
+	// is still the one of the uncompressed data.
+	CompressionThreshold int
+}
+
+// MarshalWire encodes the message in the current wire format: the wire magic, the version byte and the payload
+func (m *MessageReq) MarshalWire() ([]byte, error) {
+	return WireEncoder{Version: WireVersion}.Marshal(m)
+}
+
+// Marshal encodes the message in the wire format version of the encoder
+func (e WireEncoder) Marshal(m *MessageReq) ([]byte, error) {
+	if e.Version < MinWireVersion || e.Version > MaxWireVersion {
+		return nil, fmt.Errorf("%w: version %d, supported [%d, %d]", ErrUnsupportedWireVersion, e.Version, MinWireVersion, MaxWireVersion)
+	}
+
+	flags := byte(0)
+	if e.Version >= WireVersionCompression && e.CompressionThreshold > 0 && len(m.Proposal) >= e.CompressionThreshold {
+		compressed, err := compressProposal(m.Proposal)
+		if err != nil {
+			return nil, err
+		}
+		msg := *m
+		msg.Proposal = compressed
+		m = &msg
+		flags |= wireFlagCompressedProposal
+	}
+
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, wireHeaderSize+1+len(payload))
+	data = append(data, wireMagic...)
+	data = append(data, e.Version)
+	if e.Version >= WireVersionCompression {
+		data = append(data, flags)
+	}
+	return append(data, payload...), nil
+}
+
+// UnmarshalWire decodes a message encoded with MarshalWire.
+// It fails with ErrWireMagic or ErrUnsupportedWireVersion before looking at the payload.
+func UnmarshalWire(data []byte) (*MessageReq, error) {
+	if len(data) < wireHeaderSize || !bytes.Equal(data[:len(wireMagic)], wireMagic) {
+		return nil, ErrWireMagic
+	}
+
+	version := data[len(wireMagic)]
+	if version < MinWireVersion || version > MaxWireVersion {
+		return nil, fmt.Errorf("%w: version %d, supported [%d, %d]", ErrUnsupportedWireVersion, version, MinWireVersion, MaxWireVersion)
+	}
+
+	payload, flags := data[wireHeaderSize:], byte(0)
+	if version >= WireVersionCompression {
+		if len(payload) == 0 {
+			return nil, fmt.Errorf("failed to decode message of wire version %d: missing flags", version)
+		}
+		payload, flags = payload[1:], payload[0]
+		if flags&^wireFlagCompressedProposal != 0 {
+			return nil, fmt.Errorf("failed to decode message of wire version %d: unknown flags %#x", version, flags)
+		}
+	}
+
+	msg := &MessageReq{}
+	if err := json.Unmarshal(payload, msg); err != nil {
+		return nil, fmt.Errorf("failed to decode message of wire version %d: %w", version, err)
+	}
+	if flags&wireFlagCompressedProposal != 0 {
+		proposal, err := decompressProposal(msg.Proposal)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress proposal of wire version %d: %w", version, err)
+		}
+		msg.Proposal = proposal
+	}
+	return msg, nil
+}
+
+func compressProposal(proposal []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(proposal); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompressProposal(compressed []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	proposal, err := io.ReadAll(io.LimitReader(r, maxWireProposalSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(proposal) > maxWireProposalSize {
+		return nil, fmt.Errorf("proposal exceeds %d bytes", maxWireProposalSize)
+	}
+	return proposal, nil
+}
@@ -0,0 +1,135 @@
+package pbft
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWire_RoundTrip(t *testing.T) {
+	msg := createMessage("A", MessageReq_Preprepare, ViewMsg(3, 1))
+	msg.ProposalTime = time.Unix(1000, 0)
+	msg.Justification = &Justification{Round: 1, Proposal: &Proposal{Data: mockProposal, Hash: digest}}
+
+	data, err := msg.MarshalWire()
+	require.NoError(t, err)
+	assert.Equal(t, wireMagic, data[:len(wireMagic)])
+	assert.Equal(t, WireVersion, data[len(wireMagic)])
+
+	decoded, err := UnmarshalWire(data)
+	require.NoError(t, err)
+	assert.True(t, msg.Equal(decoded))
+}
+
+func TestWire_Unmarshal_Rejects(t *testing.T) {
+	data, err := createMessage("A", MessageReq_Prepare, ViewMsg(1, 0)).MarshalWire()
+	require.NoError(t, err)
+
+	withVersion := func(version uint8) []byte {
+		d := append([]byte{}, data...)
+		d[len(wireMagic)] = version
+		return d
+	}
+
+	cases := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"empty", nil, ErrWireMagic},
+		{"header only magic", wireMagic, ErrWireMagic},
+		{"bad magic", append([]byte("XBFT"), data[len(wireMagic):]...), ErrWireMagic},
+		{"version too old", withVersion(MinWireVersion - 1), ErrUnsupportedWireVersion},
+		{"version too new", withVersion(MaxWireVersion + 1), ErrUnsupportedWireVersion},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := UnmarshalWire(c.data)
+			assert.ErrorIs(t, err, c.err)
+		})
+	}
+
+	_, err = UnmarshalWire(append(data[:wireHeaderSize:wireHeaderSize], '{'))
+	assert.Error(t, err)
+}
+
+func TestWire_NegotiateVersion(t *testing.T) {
+	min, max := SupportedWireVersions()
+	assert.Equal(t, MinWireVersion, min)
+	assert.Equal(t, MaxWireVersion, max)
+
+	version, err := NegotiateWireVersion(min, max+5)
+	require.NoError(t, err)
+	assert.Equal(t, MaxWireVersion, version)
+
+	_, err = NegotiateWireVersion(max+1, max+5)
+	assert.ErrorIs(t, err, ErrUnsupportedWireVersion)
+
+	_, err = NegotiateWireVersion(0, min-1)
+	assert.ErrorIs(t, err, ErrUnsupportedWireVersion)
+}
+
+func TestWire_Compression_RoundTrip(t *testing.T) {
+	msg := createMessage("A", MessageReq_Preprepare, ViewMsg(3, 1))
+	msg.Proposal = bytes.Repeat([]byte("proposal"), 1024)
+	msg.Hash = HashProposal(&Proposal{Data: msg.Proposal})
+
+	encoder := WireEncoder{Version: WireVersion, CompressionThreshold: 1024}
+	data, err := encoder.Marshal(msg)
+	require.NoError(t, err)
+	assert.Equal(t, wireFlagCompressedProposal, data[wireHeaderSize])
+
+	plain, err := msg.MarshalWire()
+	require.NoError(t, err)
+	assert.Less(t, len(data), len(plain))
+
+	// the proposal is decompressed and its hash is the one of the uncompressed data
+	decoded, err := UnmarshalWire(data)
+	require.NoError(t, err)
+	assert.True(t, msg.Equal(decoded))
+	assert.Equal(t, HashProposal(&Proposal{Data: decoded.Proposal}), decoded.Hash)
+
+	// a proposal below the threshold is not compressed
+	small := createMessage("A", MessageReq_Preprepare, ViewMsg(3, 1))
+	data, err = encoder.Marshal(small)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0), data[wireHeaderSize])
+}
+
+func TestWire_Compression_OlderVersion(t *testing.T) {
+	msg := createMessage("A", MessageReq_Preprepare, ViewMsg(3, 1))
+	msg.Proposal = bytes.Repeat([]byte("proposal"), 1024)
+
+	// the version negotiated with a peer not supporting the compression is encoded as is
+	data, err := WireEncoder{Version: 1, CompressionThreshold: 1}.Marshal(msg)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(1), data[len(wireMagic)])
+	assert.Equal(t, byte('{'), data[wireHeaderSize])
+
+	decoded, err := UnmarshalWire(data)
+	require.NoError(t, err)
+	assert.True(t, msg.Equal(decoded))
+
+	_, err = WireEncoder{Version: MaxWireVersion + 1}.Marshal(msg)
+	assert.ErrorIs(t, err, ErrUnsupportedWireVersion)
+}
+
+func TestWire_Compression_Rejects(t *testing.T) {
+	data, err := createMessage("A", MessageReq_Prepare, ViewMsg(1, 0)).MarshalWire()
+	require.NoError(t, err)
+
+	// unknown flags
+	unknown := append([]byte{}, data...)
+	unknown[wireHeaderSize] = 0x80
+	_, err = UnmarshalWire(unknown)
+	assert.Error(t, err)
+
+	// the flag is set but the proposal is not compressed
+	corrupted := append([]byte{}, data...)
+	corrupted[wireHeaderSize] = wireFlagCompressedProposal
+	_, err = UnmarshalWire(corrupted)
+	assert.Error(t, err)
+}
@@ -0,0 +1,65 @@
+package pbft
+
+import "fmt"
+
+// ZeroVotingPowerPolicy is the handling of a validator set whose total voting power is zero, for which
+// the quorum can not be calculated
+type ZeroVotingPowerPolicy uint8
+
+const (
+	// ZeroVotingPower_Error refuses the validator set or the voting power update
+	ZeroVotingPower_Error ZeroVotingPowerPolicy = iota
+
+	// ZeroVotingPower_EqualWeight falls back to one voting power per validator
+	ZeroVotingPower_EqualWeight
+)
+
+func (z ZeroVotingPowerPolicy) String() string {
+	switch z {
+	case ZeroVotingPower_Error:
+		return "Error"
+	case ZeroVotingPower_EqualWeight:
+		return "EqualWeight"
+	default:
+		return fmt.Sprintf("ZeroVotingPowerPolicy(%d)", uint8(z))
+	}
+}
+
+// hasVotingPower returns whether the total voting power is positive
+func hasVotingPower(votingPower map[NodeID]uint64) bool {
+	for _, power := range votingPower {
+		if power > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// equalWeightValidators applies the ZeroVotingPower_EqualWeight policy: a validator set without voting power
+// is given one voting power per validator. Any other set is returned as is.
+func (p *Pbft) equalWeightValidators(validators ValidatorSet) ValidatorSet {
+	if p.config.ZeroVotingPowerPolicy != ZeroVotingPower_EqualWeight || validators.Len() == 0 || hasVotingPower(validators.VotingPower()) {
+		return validators
+	}
+
+	votingPower := map[NodeID]uint64{}
+	for _, id := range validatorOrder(validators) {
+		votingPower[id] = 1
+	}
+	p.logger.Printf("[WARN] validator set without voting power, falling back to equal weights: %d validators", len(votingPower))
+	return &reweightedValidatorSet{ValidatorSet: validators, votingPower: votingPower}
+}
+
+// equalWeightVotingPower applies the ZeroVotingPower_EqualWeight policy to a voting power update
+func (p *Pbft) equalWeightVotingPower(votingPower map[NodeID]uint64) map[NodeID]uint64 {
+	if p.config.ZeroVotingPowerPolicy != ZeroVotingPower_EqualWeight || len(votingPower) == 0 || hasVotingPower(votingPower) {
+		return votingPower
+	}
+
+	equal := make(map[NodeID]uint64, len(votingPower))
+	for id := range votingPower {
+		equal[id] = 1
+	}
+	p.logger.Printf("[WARN] voting power update without voting power, falling back to equal weights: %d validators", len(equal))
+	return equal
+}
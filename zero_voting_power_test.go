@@ -0,0 +1,34 @@
+package pbft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPbft_ZeroVotingPower(t *testing.T) {
+	validatorIds := []NodeID{"A", "B", "C", "D"}
+	zero := map[NodeID]uint64{"A": 0, "B": 0, "C": 0, "D": 0}
+
+	// the validator set and the update are refused by default
+	m := newMockPbft(t, validatorIds, nil, "A")
+	assert.ErrorIs(t, m.SetBackend(newMockBackend(validatorIds, zero, m)), errInvalidTotalVotingPower)
+	require.NoError(t, m.SetBackend(newMockBackend(validatorIds, CreateEqualVotingPowerMap(validatorIds), m)))
+	assert.ErrorIs(t, m.UpdateVotingPower(zero), errInvalidTotalVotingPower)
+	assert.Equal(t, uint64(3), m.state.getQuorumSize())
+
+	// the fallback counts one voting power per validator
+	m = newMockPbft(t, validatorIds, nil, "A")
+	WithZeroVotingPowerPolicy(ZeroVotingPower_EqualWeight)(m.config)
+	require.NoError(t, m.SetBackend(newMockBackend(validatorIds, zero, m)))
+	assert.Equal(t, uint64(4), m.TotalVotingPower())
+	assert.Equal(t, uint64(1), m.state.getMaxFaultyVotingPower())
+	assert.Equal(t, uint64(3), m.state.getQuorumSize())
+
+	require.NoError(t, m.UpdateVotingPower(map[NodeID]uint64{"A": 7, "B": 1, "C": 1, "D": 1}))
+	assert.Equal(t, uint64(7), m.state.getQuorumSize())
+	require.NoError(t, m.UpdateVotingPower(zero))
+	assert.Equal(t, uint64(4), m.TotalVotingPower())
+	assert.Equal(t, uint64(3), m.state.getQuorumSize())
+}